@@ -0,0 +1,188 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Defaults for AnalysisOptions
+const (
+	DefaultSilenceThreshold = -60.0 // dBFS
+	DefaultMinSilence       = 0.5   // Seconds
+)
+
+// silenceWindow is the length in seconds of the windows silence is detected in
+const silenceWindow = 0.01
+
+// AnalysisOptions configures Analyze
+type AnalysisOptions struct {
+	SilenceThreshold float64 // Peak level in dBFS below which audio counts as silence (0 = DefaultSilenceThreshold)
+	MinSilence       float64 // Shortest silence reported, in seconds (0 = DefaultMinSilence)
+}
+
+// TimeRange is a span of the audio in seconds
+type TimeRange struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// Duration returns the length of the range in seconds
+func (r TimeRange) Duration() float64 {
+	return r.End - r.Start
+}
+
+// Analysis is a summary of the levels and problems of a file, e.g. for gating uploads
+type Analysis struct {
+	Duration       float64        `json:"duration"`
+	SampleRate     int            `json:"sample_rate"`
+	Channels       int            `json:"channels"`
+	BitsPerSample  int            `json:"bits_per_sample"`
+	PeakDBFS       float64        `json:"peak_dbfs"`      // Highest sample peak of any channel (-Inf for silence)
+	TruePeakDBTP   float64        `json:"true_peak_dbtp"` // Highest 4x oversampled peak of any channel (-Inf for silence)
+	RMSDBFS        float64        `json:"rms_dbfs"`       // RMS level across all channels (-Inf for silence)
+	Loudness       float64        `json:"loudness_lufs"`  // Integrated loudness in LUFS (-Inf for silence)
+	ClippedSamples int            `json:"clipped_samples"`
+	Overs          []float64      `json:"inter_sample_overs"` // Times in seconds of inter-sample overs (see TruePeak)
+	ChannelDelay   int            `json:"interchannel_delay"` // Frames the right channel lags the left (see EstimateInterchannelDelay; 0 for mono)
+	Levels         []ChannelLevel `json:"levels"`             // Peak and RMS level per channel
+	Silence        []TimeRange    `json:"silence"`            // Silent stretches of at least MinSilence
+}
+
+// MarshalJSON encodes -Inf levels of silent audio as null, which JSON cannot represent otherwise
+func (a Analysis) MarshalJSON() ([]byte, error) {
+	finite := func(v float64) *float64 {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+	return json.Marshal(struct {
+		Duration       float64        `json:"duration"`
+		SampleRate     int            `json:"sample_rate"`
+		Channels       int            `json:"channels"`
+		BitsPerSample  int            `json:"bits_per_sample"`
+		PeakDBFS       *float64       `json:"peak_dbfs"`
+		TruePeakDBTP   *float64       `json:"true_peak_dbtp"`
+		RMSDBFS        *float64       `json:"rms_dbfs"`
+		Loudness       *float64       `json:"loudness_lufs"`
+		ClippedSamples int            `json:"clipped_samples"`
+		Overs          []float64      `json:"inter_sample_overs"`
+		ChannelDelay   int            `json:"interchannel_delay"`
+		Levels         []ChannelLevel `json:"levels"`
+		Silence        []TimeRange    `json:"silence"`
+	}{
+		a.Duration, a.SampleRate, a.Channels, a.BitsPerSample,
+		finite(a.PeakDBFS), finite(a.TruePeakDBTP), finite(a.RMSDBFS), finite(a.Loudness),
+		a.ClippedSamples, a.Overs, a.ChannelDelay, a.Levels, a.Silence,
+	})
+}
+
+// Analyze measures the duration, levels, true peak, loudness, clipping, inter-sample
+// overs, delay between the channels and silence of the whole file
+func (w *Waveform) Analyze(opts AnalysisOptions) (*Analysis, error) {
+	if opts.SilenceThreshold == 0 {
+		opts.SilenceThreshold = DefaultSilenceThreshold
+	}
+	if opts.MinSilence <= 0 {
+		opts.MinSilence = DefaultMinSilence
+	}
+
+	levels, err := w.ChannelLevels(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var peak, energy float64
+	for _, l := range levels {
+		if l.Peak > peak {
+			peak = l.Peak
+		}
+		energy += l.RMS * l.RMS
+	}
+
+	truePeak, overs := TruePeak(w)
+	a := &Analysis{
+		Duration:       w.Duration(),
+		SampleRate:     w.SampleRate,
+		Channels:       w.Channels,
+		BitsPerSample:  w.BitsPerSample,
+		PeakDBFS:       ToDBFS(peak),
+		TruePeakDBTP:   truePeak,
+		RMSDBFS:        ToDBFS(math.Sqrt(energy / float64(len(levels)))),
+		Loudness:       w.IntegratedLoudness(),
+		ClippedSamples: w.ClippedSamples(),
+		Overs:          overs,
+		Levels:         levels,
+		Silence:        w.DetectSilence(opts.SilenceThreshold, opts.MinSilence),
+	}
+	// Mono and silent channels have no delay to report
+	if delay, err := EstimateInterchannelDelay(w); err == nil {
+		a.ChannelDelay = delay
+	}
+	if a.Overs == nil {
+		a.Overs = []float64{}
+	}
+	if a.Silence == nil {
+		a.Silence = []TimeRange{}
+	}
+
+	return a, nil
+}
+
+// ClippedSamples counts the samples at full scale in any channel, a sign of clipping
+func (w *Waveform) ClippedSamples() int {
+	count := 0
+	for _, v := range w.audioData[:w.availableFrames()*w.Channels] {
+		if v == math.MaxInt16 || v == math.MinInt16 {
+			count++
+		}
+	}
+	return count
+}
+
+// DetectSilence returns the stretches of at least minDuration seconds in which the peak
+// of every channel stays below threshold dBFS. Silence is detected in 10 ms windows.
+func (w *Waveform) DetectSilence(threshold, minDuration float64) []TimeRange {
+	frames := w.availableFrames()
+	window := int(silenceWindow * float64(w.SampleRate))
+	if window < 1 {
+		window = 1
+	}
+	limit := int16(math.Min(32767, math.Pow(10, threshold/20)*32768))
+
+	var ranges []TimeRange
+	runStart := -1
+	for start := 0; start < frames; start += window {
+		end := start + window
+		if end > frames {
+			end = frames
+		}
+		min, max := peakScan(w.audioData[start*w.Channels:end*w.Channels], math.MaxInt16, math.MinInt16)
+		silent := max < limit && min > -limit
+
+		if silent && runStart < 0 {
+			runStart = start
+		}
+		if !silent && runStart >= 0 {
+			ranges = w.appendSilence(ranges, runStart, start, minDuration)
+			runStart = -1
+		}
+	}
+	if runStart >= 0 {
+		ranges = w.appendSilence(ranges, runStart, frames, minDuration)
+	}
+
+	return ranges
+}
+
+// appendSilence adds the frames from start to end as a silent range if it is long enough
+func (w *Waveform) appendSilence(ranges []TimeRange, start, end int, minDuration float64) []TimeRange {
+	r := TimeRange{
+		Start: float64(start) / float64(w.SampleRate),
+		End:   float64(end) / float64(w.SampleRate),
+	}
+	if r.Duration() >= minDuration {
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
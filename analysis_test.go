@@ -0,0 +1,113 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	tmpFile := "/tmp/test_analyze.wav"
+	defer os.Remove(tmpFile)
+
+	// 1s of silence, 1s of a half scale tone with three clipped samples, 0.2s of silence
+	samples := make([]int16, 44100)
+	tone := sineSamples(44100, 1, 440, 0.5, 1)
+	tone[100], tone[200], tone[300] = math.MaxInt16, math.MinInt16, math.MaxInt16
+	samples = append(samples, tone...)
+	samples = append(samples, make([]int16, 8820)...)
+	writeTestWAV(t, tmpFile, 44100, 1, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	a, err := waveform.Analyze(AnalysisOptions{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	if math.Abs(a.Duration-2.2) > 0.001 || a.SampleRate != 44100 || a.Channels != 1 || a.BitsPerSample != 16 {
+		t.Errorf("Unexpected format: %+v", a)
+	}
+	if math.Abs(a.PeakDBFS) > 0.01 {
+		t.Errorf("Expected a 0 dBFS peak from the clipped samples, got %.2f", a.PeakDBFS)
+	}
+	if a.TruePeakDBTP < a.PeakDBFS {
+		t.Errorf("Expected the true peak at or above the sample peak, got %.2f", a.TruePeakDBTP)
+	}
+	if a.ClippedSamples != 3 {
+		t.Errorf("Expected 3 clipped samples, got %d", a.ClippedSamples)
+	}
+	if len(a.Levels) != 1 {
+		t.Errorf("Expected levels for 1 channel, got %d", len(a.Levels))
+	}
+	if math.IsInf(a.Loudness, 0) || a.Loudness > a.PeakDBFS {
+		t.Errorf("Expected a finite loudness below the peak, got %.2f", a.Loudness)
+	}
+
+	// The leading second is reported; the trailing 0.2s is shorter than the default minimum
+	if len(a.Silence) != 1 {
+		t.Fatalf("Expected 1 silent range, got %v", a.Silence)
+	}
+	if a.Silence[0].Start != 0 || math.Abs(a.Silence[0].End-1.0) > 0.011 {
+		t.Errorf("Expected silence from 0 to 1s, got %v", a.Silence[0])
+	}
+
+	a, err = waveform.Analyze(AnalysisOptions{MinSilence: 0.1})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(a.Silence) != 2 || math.Abs(a.Silence[1].End-2.2) > 0.001 {
+		t.Errorf("Expected the trailing silence with a shorter minimum, got %v", a.Silence)
+	}
+}
+
+func TestAnalyzeSilentJSON(t *testing.T) {
+	tmpFile := "/tmp/test_analyze_silent.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 2, make([]int16, 44100*2))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	a, err := waveform.Analyze(AnalysisOptions{})
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+
+	// -Inf levels are encoded as null rather than failing to marshal
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	for _, field := range []string{`"peak_dbfs":null`, `"rms_dbfs":null`, `"loudness_lufs":null`, `"true_peak_dbtp":null`, `"inter_sample_overs":[]`, `"interchannel_delay":0`, `"sample_rate":44100`, `"silence":[{"start":0,"end":1}]`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("Expected %s in %s", field, data)
+		}
+	}
+}
+
+func TestDetectSilenceThreshold(t *testing.T) {
+	tmpFile := "/tmp/test_detect_silence.wav"
+	defer os.Remove(tmpFile)
+
+	// A tone at -40 dBFS is silence for a -30 dBFS threshold but not for -60 dBFS
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 440, 0.01, 1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if r := waveform.DetectSilence(-30, 0.5); len(r) != 1 {
+		t.Errorf("Expected the whole file to be silent at -30 dBFS, got %v", r)
+	}
+	if r := waveform.DetectSilence(-60, 0.5); len(r) != 0 {
+		t.Errorf("Expected no silence at -60 dBFS, got %v", r)
+	}
+}
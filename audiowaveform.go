@@ -0,0 +1,148 @@
+package gowaveform
+
+import "fmt"
+
+// audiowaveformMinZoom is the fewest samples per pixel audiowaveform accepts
+const audiowaveformMinZoom = 2
+
+// MixToMono returns a single-channel copy of the waveform with the channels of each
+// frame averaged, rounding toward zero as audiowaveform does when it mixes channels
+// down. A mono waveform is returned as is.
+func (w *Waveform) MixToMono() *Waveform {
+	if w.Channels <= 1 {
+		return w
+	}
+	frames := w.availableFrames()
+	audio := make([]int16, frames)
+	for frame := range audio {
+		sum := 0
+		for ch := 0; ch < w.Channels; ch++ {
+			sum += int(w.audioData[frame*w.Channels+ch])
+		}
+		audio[frame] = int16(sum / w.Channels)
+	}
+	mono := w.withAudio(w.SampleRate, audio, frames)
+	mono.Channels = 1
+	return mono
+}
+
+// Channel returns one channel of the waveform as a mono waveform
+func (w *Waveform) Channel(channel int) (*Waveform, error) {
+	samples := w.Samples(channel)
+	if samples == nil {
+		return nil, fmt.Errorf("invalid channel %d: audio has %d channels", channel, w.Channels)
+	}
+	mono := w.withAudio(w.SampleRate, samples, len(samples))
+	mono.Channels = 1
+	return mono, nil
+}
+
+// GenerateAudiowaveformView generates the view the C++ audiowaveform tool outputs for
+// the same range and zoom, peak for peak: the channels are averaged into one, or with
+// splitChannels kept apart and interleaved per pixel as with --split-channels. Only the
+// range, zoom and format version of opts apply. Like audiowaveform, it refuses zooms
+// below 2 samples per pixel.
+func (w *Waveform) GenerateAudiowaveformView(opts WaveformOptions, splitChannels bool) (*WaveformData, error) {
+	view := WaveformOptions{
+		Start:           opts.Start,
+		End:             opts.End,
+		SamplesPerPixel: opts.SamplesPerPixel,
+		Width:           opts.Width,
+		FormatVersion:   opts.FormatVersion,
+	}
+	_, _, samplesPerPixel, err := w.resolveRange(view)
+	if err != nil {
+		return nil, err
+	}
+	if samplesPerPixel < audiowaveformMinZoom {
+		return nil, fmt.Errorf("invalid zoom: %d samples per pixel, audiowaveform requires at least %d",
+			samplesPerPixel, audiowaveformMinZoom)
+	}
+
+	sources := []*Waveform{w.MixToMono()}
+	if splitChannels {
+		sources = sources[:0]
+		for ch := range w.Channels {
+			source, err := w.Channel(ch)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, source)
+		}
+	}
+	views := make([]*WaveformData, len(sources))
+	for i, source := range sources {
+		if views[i], err = source.GenerateView(view); err != nil {
+			return nil, err
+		}
+	}
+	return NormalizeToAudiowaveform(views...)
+}
+
+// NormalizeToAudiowaveform converts views to the fields audiowaveform writes, so
+// consumers such as peaks.js see the same output whichever tool made the peaks. A
+// single view becomes audiowaveform's default output: channels is the number of
+// min/max pairs per pixel, 1 for views made by gowaveform, rather than the channels of
+// the source. Several views of the same range and zoom, one per channel, are
+// interleaved per pixel as with --split-channels. Bits is 16, the resolution of the
+// peaks, and the blocks audiowaveform does not write (bands, time axis and metadata)
+// are left out. Version 1 views stay version 1, which only holds one channel.
+//
+// The peaks are kept. GenerateView takes the peaks of multi-channel audio over all
+// channels while audiowaveform averages the channels first, so use
+// GenerateAudiowaveformView for the same peaks as audiowaveform.
+func NormalizeToAudiowaveform(views ...*WaveformData) (*WaveformData, error) {
+	if len(views) == 0 {
+		return nil, fmt.Errorf("no views to normalize")
+	}
+	first := views[0]
+	for i, v := range views {
+		if v == nil {
+			return nil, fmt.Errorf("view %d: no waveform data", i)
+		}
+		if err := v.validate(); err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+		if v.SampleRate != first.SampleRate || v.SamplesPerPixel != first.SamplesPerPixel || v.Length != first.Length {
+			return nil, fmt.Errorf("view %d: %d pixels of %d samples at %d Hz do not line up with %d pixels of %d samples at %d Hz",
+				i, v.Length, v.SamplesPerPixel, v.SampleRate, first.Length, first.SamplesPerPixel, first.SampleRate)
+		}
+		if len(views) > 1 && v.peakChannels() != 1 {
+			return nil, fmt.Errorf("view %d: already holds %d channels", i, v.peakChannels())
+		}
+	}
+
+	channels := first.peakChannels()
+	if len(views) > 1 {
+		channels = len(views)
+	}
+	out := &WaveformData{
+		Version:         2,
+		Channels:        channels,
+		SampleRate:      first.SampleRate,
+		SamplesPerPixel: first.SamplesPerPixel,
+		Bits:            16,
+		Length:          first.Length,
+		Data:            make([]int16, 0, 2*first.Length*channels),
+		Start:           first.Start,
+		End:             first.End,
+		SourceDuration:  first.SourceDuration,
+	}
+	if first.Version == 1 {
+		if channels != 1 {
+			return nil, fmt.Errorf("format version 1 holds a single channel, not %d", channels)
+		}
+		out.Version, out.Channels = 1, 0
+	}
+
+	if len(views) == 1 {
+		out.Data = append(out.Data, first.Data...)
+		return out, nil
+	}
+	for pixel := range first.Length {
+		for _, v := range views {
+			out.Data = append(out.Data, v.Data[2*pixel], v.Data[2*pixel+1])
+		}
+	}
+	return out, nil
+}
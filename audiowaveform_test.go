@@ -0,0 +1,227 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// audiowaveformFixture is a file in testdata/audiowaveform: audio, the audiowaveform
+// arguments it was run with besides -i and -o, and the JSON audiowaveform writes for it
+type audiowaveformFixture struct {
+	Description string   `json:"description"`
+	Args        []string `json:"args"`
+	Input       struct {
+		SampleRate int     `json:"sample_rate"`
+		Channels   int     `json:"channels"`
+		Samples    []int16 `json:"samples"`
+	} `json:"input"`
+	Output json.RawMessage `json:"output"`
+}
+
+// loadAudiowaveformFixtures reads all fixtures, writing the input of each to a WAV
+// file in dir
+func loadAudiowaveformFixtures(t *testing.T, dir string) map[string]audiowaveformFixture {
+	t.Helper()
+	files, err := filepath.Glob("testdata/audiowaveform/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No fixtures found: %v", err)
+	}
+	fixtures := map[string]audiowaveformFixture{}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fixture audiowaveformFixture
+		if err := json.Unmarshal(raw, &fixture); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		name := filepath.Base(file)
+		in := fixture.Input
+		writeTestWAV(t, filepath.Join(dir, name+".wav"), uint32(in.SampleRate), uint16(in.Channels), in.Samples)
+		fixtures[name] = fixture
+	}
+	return fixtures
+}
+
+// parseAudiowaveformArgs turns the arguments of a fixture into view options
+func parseAudiowaveformArgs(t *testing.T, args []string) (WaveformOptions, bool) {
+	t.Helper()
+	var opts WaveformOptions
+	split := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--split-channels":
+			split = true
+		case "-z":
+			i++
+			spp, err := strconv.Atoi(args[i])
+			if err != nil {
+				t.Fatalf("Invalid zoom %q", args[i])
+			}
+			opts.SamplesPerPixel = spp
+		default:
+			t.Fatalf("Unsupported argument %q", args[i])
+		}
+	}
+	return opts, split
+}
+
+func TestAudiowaveformFixtures(t *testing.T) {
+	dir := t.TempDir()
+	for name, fixture := range loadAudiowaveformFixtures(t, dir) {
+		t.Run(name, func(t *testing.T) {
+			w, err := LoadWaveform(filepath.Join(dir, name+".wav"))
+			if err != nil {
+				t.Fatalf("LoadWaveform failed: %v", err)
+			}
+			opts, split := parseAudiowaveformArgs(t, fixture.Args)
+			data, err := w.GenerateAudiowaveformView(opts, split)
+			if err != nil {
+				t.Fatalf("GenerateAudiowaveformView failed: %v", err)
+			}
+
+			expected, err := ReadJSON(bytes.NewReader(fixture.Output))
+			if err != nil {
+				t.Fatalf("ReadJSON failed: %v", err)
+			}
+			got := [...]int{data.Version, data.Channels, data.SampleRate, data.SamplesPerPixel, data.Bits, data.Length}
+			want := [...]int{expected.Version, expected.Channels, expected.SampleRate, expected.SamplesPerPixel, expected.Bits, expected.Length}
+			if got != want {
+				t.Errorf("%s: header %v, audiowaveform wrote %v", fixture.Description, got, want)
+			}
+			if !reflect.DeepEqual(data.Data, expected.Data) {
+				t.Errorf("%s: peaks differ from audiowaveform\n got: %v\nwant: %v", fixture.Description, data.Data, expected.Data)
+			}
+
+			// The JSON has the same fields and values
+			out, err := GenerateJSON(data)
+			if err != nil {
+				t.Fatalf("GenerateJSON failed: %v", err)
+			}
+			var gotJSON, wantJSON map[string]any
+			json.Unmarshal(out, &gotJSON)
+			json.Unmarshal(fixture.Output, &wantJSON)
+			if !reflect.DeepEqual(gotJSON, wantJSON) {
+				t.Errorf("JSON differs from audiowaveform\n got: %s\nwant: %s", out, fixture.Output)
+			}
+		})
+	}
+}
+
+// TestAudiowaveformFixturesMatchTool checks the fixtures against the installed
+// audiowaveform, so they can be trusted and regenerated
+func TestAudiowaveformFixturesMatchTool(t *testing.T) {
+	tool, err := exec.LookPath("audiowaveform")
+	if err != nil {
+		t.Skip("audiowaveform not installed")
+	}
+	dir := t.TempDir()
+	for name, fixture := range loadAudiowaveformFixtures(t, dir) {
+		output := filepath.Join(dir, name)
+		args := append([]string{"-q", "-i", output + ".wav", "-o", output, "-b", "16"}, fixture.Args...)
+		if out, err := exec.Command(tool, args...).CombinedOutput(); err != nil {
+			t.Fatalf("%s: audiowaveform failed: %v\n%s", name, err, out)
+		}
+		raw, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got, want map[string]any
+		json.Unmarshal(raw, &got)
+		json.Unmarshal(fixture.Output, &want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: fixture differs from audiowaveform\n got: %s\nwant: %s", name, raw, fixture.Output)
+		}
+	}
+}
+
+func TestNormalizeToAudiowaveform(t *testing.T) {
+	// Stereo audio, the right channel at half level
+	samples := make([]int16, 2*1000)
+	for i := range 1000 {
+		samples[2*i] = int16(i*30 - 15000)
+		samples[2*i+1] = samples[2*i] / 2
+	}
+	w := &Waveform{SampleRate: 8000, Channels: 2, BitsPerSample: 24, audioData: samples, totalSamples: 1000}
+
+	view, err := w.GenerateView(WaveformOptions{SamplesPerPixel: 100, Bands: []float64{500}, TimeAxis: true, Metadata: true})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	normalized, err := NormalizeToAudiowaveform(view)
+	if err != nil {
+		t.Fatalf("NormalizeToAudiowaveform failed: %v", err)
+	}
+	if normalized.Version != 2 || normalized.Channels != 1 || normalized.Bits != 16 || normalized.Length != 10 {
+		t.Errorf("Unexpected header: %+v", normalized)
+	}
+	if normalized.Bands != nil || normalized.TimeAxis != nil || normalized.Metadata != nil {
+		t.Error("Expected the blocks audiowaveform does not write to be left out")
+	}
+	if !reflect.DeepEqual(normalized.Data, view.Data) {
+		t.Error("Expected the peaks to be kept")
+	}
+
+	// One view per channel is interleaved per pixel
+	left, _ := w.Channel(0)
+	right, _ := w.Channel(1)
+	leftView, _ := left.GenerateView(WaveformOptions{SamplesPerPixel: 100})
+	rightView, _ := right.GenerateView(WaveformOptions{SamplesPerPixel: 100})
+	split, err := NormalizeToAudiowaveform(leftView, rightView)
+	if err != nil {
+		t.Fatalf("NormalizeToAudiowaveform failed: %v", err)
+	}
+	if split.Channels != 2 || split.Length != 10 || len(split.Data) != 40 {
+		t.Fatalf("Unexpected split view: %d channels, length %d, %d values", split.Channels, split.Length, len(split.Data))
+	}
+	if split.Data[0] != leftView.Data[0] || split.Data[2] != rightView.Data[0] || split.Data[5] != leftView.Data[3] {
+		t.Errorf("Expected the channels to be interleaved per pixel, got %v", split.Data[:8])
+	}
+	splitView, err := w.GenerateAudiowaveformView(WaveformOptions{SamplesPerPixel: 100}, true)
+	if err != nil {
+		t.Fatalf("GenerateAudiowaveformView failed: %v", err)
+	}
+	if !reflect.DeepEqual(splitView.Data, split.Data) {
+		t.Error("Expected GenerateAudiowaveformView to interleave the same peaks")
+	}
+
+	// Averaging the channels gives smaller peaks than taking them over both
+	mixed, err := w.GenerateAudiowaveformView(WaveformOptions{SamplesPerPixel: 100}, false)
+	if err != nil {
+		t.Fatalf("GenerateAudiowaveformView failed: %v", err)
+	}
+	if mixed.Data[0] <= view.Data[0] {
+		t.Errorf("Expected the averaged minimum %d above the minimum over all channels %d", mixed.Data[0], view.Data[0])
+	}
+
+	v1, err := w.GenerateAudiowaveformView(WaveformOptions{SamplesPerPixel: 100, FormatVersion: 1}, false)
+	if err != nil || v1.Version != 1 || v1.Channels != 0 {
+		t.Errorf("Expected a version 1 view, got %+v, %v", v1, err)
+	}
+
+	coarse, _ := left.GenerateView(WaveformOptions{SamplesPerPixel: 200})
+	for name, views := range map[string][]*WaveformData{
+		"no views":           nil,
+		"nil view":           {nil},
+		"different zoom":     {leftView, coarse},
+		"already split":      {split, leftView},
+		"version 1 channels": {v1, v1},
+	} {
+		if _, err := NormalizeToAudiowaveform(views...); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := w.GenerateAudiowaveformView(WaveformOptions{SamplesPerPixel: 1}, false); err == nil {
+		t.Error("Expected an error for a zoom audiowaveform refuses")
+	}
+	if _, err := w.Channel(2); err == nil {
+		t.Error("Expected an error for a missing channel")
+	}
+}
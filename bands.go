@@ -0,0 +1,130 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// BandData holds the min/max peaks of one frequency band of a view
+type BandData struct {
+	LowFrequency  float64 `json:"low_frequency"`  // Lower band edge in Hz (0 for the lowest band)
+	HighFrequency float64 `json:"high_frequency"` // Upper band edge in Hz (Nyquist for the highest band)
+	Data          []int16 `json:"data"`           // Min/max pairs, one per pixel, same layout as WaveformData.Data
+}
+
+// generateBandPeaks splits the range into the frequency bands delimited by edges
+// and computes min/max peaks per pixel for each band, of the audio passed through
+// filter if it is not nil
+func (w *Waveform) generateBandPeaks(startSample, endSample, samplesPerPixel int, edges []float64, filter *Filter) ([]BandData, error) {
+	nyquist := float64(w.SampleRate) / 2
+	for i, edge := range edges {
+		if edge <= 0 || edge >= nyquist {
+			return nil, fmt.Errorf("invalid band edge %.1f Hz: must be between 0 and %.1f Hz", edge, nyquist)
+		}
+		if i > 0 && edge <= edges[i-1] {
+			return nil, fmt.Errorf("invalid band edges: must be in ascending order")
+		}
+	}
+
+	// Band i spans from edge i-1 to edge i, with open ends at the extremes
+	numBands := len(edges) + 1
+	bands := make([]BandData, numBands)
+	filters := make([][]*bandPass, numBands)
+	for b := 0; b < numBands; b++ {
+		low, high := 0.0, 0.0
+		bands[b].HighFrequency = nyquist
+		if b > 0 {
+			low = edges[b-1]
+			bands[b].LowFrequency = low
+		}
+		if b < len(edges) {
+			high = edges[b]
+			bands[b].HighFrequency = high
+		}
+
+		// Each channel needs its own filter state
+		filters[b] = make([]*bandPass, w.Channels)
+		for ch := range filters[b] {
+			filters[b][ch] = newBandPass(w.SampleRate, low, high)
+		}
+	}
+
+	// The view filter, warmed up on its own, comes before the band filters
+	var viewFilters []*bandPass
+	if filter != nil {
+		viewFilters = w.newChannelFilters(filter, startSample-filterPreroll)
+	}
+	sample := func(i, ch int) float64 {
+		x := float64(w.audioData[i*w.Channels+ch])
+		if viewFilters != nil {
+			x = viewFilters[ch].process(x)
+		}
+		return x
+	}
+
+	// Warm up the filters on the audio just before the range
+	prerollStart := startSample - filterPreroll
+	if prerollStart < 0 {
+		prerollStart = 0
+	}
+	for i := prerollStart; i < startSample; i++ {
+		for ch := 0; ch < w.Channels; ch++ {
+			x := sample(i, ch)
+			for b := range filters {
+				filters[b][ch].process(x)
+			}
+		}
+	}
+
+	numPixels := (endSample - startSample + samplesPerPixel - 1) / samplesPerPixel
+	for b := range bands {
+		bands[b].Data = getInt16s(&peakPool, numPixels*2)
+	}
+
+	mins := make([]float64, numBands)
+	maxs := make([]float64, numBands)
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		pixelEnd := pixelStart + samplesPerPixel
+		if pixelEnd > endSample {
+			pixelEnd = endSample
+		}
+
+		for b := range mins {
+			mins[b] = math.Inf(1)
+			maxs[b] = math.Inf(-1)
+		}
+
+		for i := pixelStart; i < pixelEnd; i++ {
+			for ch := 0; ch < w.Channels; ch++ {
+				x := sample(i, ch)
+				for b := range filters {
+					y := filters[b][ch].process(x)
+					if y < mins[b] {
+						mins[b] = y
+					}
+					if y > maxs[b] {
+						maxs[b] = y
+					}
+				}
+			}
+		}
+
+		for b := range bands {
+			bands[b].Data = append(bands[b].Data, clampInt16(mins[b]), clampInt16(maxs[b]))
+		}
+	}
+
+	return bands, nil
+}
+
+// clampInt16 rounds a sample value and clamps it to the int16 range
+func clampInt16(v float64) int16 {
+	v = math.Round(v)
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
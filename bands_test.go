@@ -0,0 +1,122 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// bandPeak returns the largest absolute value in a band's min/max data, skipping the first pixels
+func bandPeak(data []int16, skipPixels int) int {
+	peak := 0
+	for _, v := range data[skipPixels*2:] {
+		a := int(v)
+		if a < 0 {
+			a = -a
+		}
+		if a > peak {
+			peak = a
+		}
+	}
+	return peak
+}
+
+func TestGenerateViewWithBands(t *testing.T) {
+	tmpFile := "/tmp/test_bands.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 60)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	data, err := waveform.GenerateView(WaveformOptions{Width: 50, Bands: []float64{200, 2000}})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	if len(data.Bands) != 3 {
+		t.Fatalf("Expected 3 bands, got %d", len(data.Bands))
+	}
+
+	if data.Bands[0].LowFrequency != 0 || data.Bands[0].HighFrequency != 200 {
+		t.Errorf("Unexpected low band edges: %+v", data.Bands[0])
+	}
+	if data.Bands[2].LowFrequency != 2000 || data.Bands[2].HighFrequency != 22050 {
+		t.Errorf("Unexpected high band edges: %.1f-%.1f", data.Bands[2].LowFrequency, data.Bands[2].HighFrequency)
+	}
+
+	for i, band := range data.Bands {
+		if len(band.Data) != len(data.Data) {
+			t.Errorf("Band %d: expected %d values to match data, got %d", i, len(data.Data), len(band.Data))
+		}
+	}
+
+	// A 60 Hz tone should end up almost entirely in the low band
+	low := bandPeak(data.Bands[0].Data, 2)
+	high := bandPeak(data.Bands[2].Data, 2)
+	if low < 10000 {
+		t.Errorf("Expected strong low band peaks, got %d", low)
+	}
+	if high > low/10 {
+		t.Errorf("Expected weak high band peaks, got %d (low %d)", high, low)
+	}
+}
+
+func TestGenerateViewInvalidBands(t *testing.T) {
+	tmpFile := "/tmp/test_bands_invalid.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	invalid := [][]float64{
+		{2000, 200}, // Not ascending
+		{0},         // Zero edge
+		{30000},     // Above Nyquist
+		{500, 500},  // Duplicate edge
+	}
+	for _, edges := range invalid {
+		if _, err := waveform.GenerateView(WaveformOptions{Width: 10, Bands: edges}); err == nil {
+			t.Errorf("Expected error for band edges %v", edges)
+		}
+	}
+}
+
+func TestBandsOmittedFromJSONByDefault(t *testing.T) {
+	tmpFile := "/tmp/test_bands_json.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	jsonData, err := GenerateWaveformJSON(tmpFile, WaveformOptions{SamplesPerPixel: 256})
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if _, ok := fields["bands"]; ok {
+		t.Error("Expected no bands field when Bands option is not set")
+	}
+
+	jsonData, err = GenerateWaveformJSON(tmpFile, WaveformOptions{SamplesPerPixel: 256, Bands: []float64{1000}})
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+	var data WaveformData
+	if err := json.Unmarshal(jsonData, &data); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(data.Bands) != 2 {
+		t.Errorf("Expected 2 bands in JSON, got %d", len(data.Bands))
+	}
+}
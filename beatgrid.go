@@ -0,0 +1,146 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// BeatsPerBar is the number of beats in a bar used by the beat grid (4/4 time)
+const BeatsPerBar = 4
+
+// Beat is a position on a beat grid
+type Beat struct {
+	Time float64 // Time in seconds
+	Bar  int     // Bar number, starting at 1 for the bar beginning at the grid offset
+	Beat int     // Beat within the bar, from 1 to BeatsPerBar
+}
+
+// IsDownbeat reports whether the beat is the first beat of a bar
+func (b Beat) IsDownbeat() bool {
+	return b.Beat == 1
+}
+
+// Label returns the bar number for downbeats and "bar.beat" for other beats
+func (b Beat) Label() string {
+	if b.IsDownbeat() {
+		return fmt.Sprintf("%d", b.Bar)
+	}
+	return fmt.Sprintf("%d.%d", b.Bar, b.Beat)
+}
+
+// BeatGrid returns all beats between start and end (inclusive) for the given tempo,
+// with the first downbeat at offset seconds. Beats before the offset get bar numbers
+// below 1. Returns nil if bpm is not positive or the range is empty.
+func BeatGrid(start, end, bpm, offset float64) []Beat {
+	if bpm <= 0 || end < start {
+		return nil
+	}
+
+	beatDuration := 60.0 / bpm
+	first := int(math.Ceil((start - offset) / beatDuration))
+	last := int(math.Floor((end - offset) / beatDuration))
+
+	beats := make([]Beat, 0, last-first+1)
+	for n := first; n <= last; n++ {
+		// Floor division so beats before the offset count down into earlier bars
+		bar := int(math.Floor(float64(n) / BeatsPerBar))
+		beats = append(beats, Beat{
+			Time: offset + float64(n)*beatDuration,
+			Bar:  bar + 1,
+			Beat: n - bar*BeatsPerBar + 1,
+		})
+	}
+
+	return beats
+}
+
+// BeatSlices returns the start times of equal slices of a bar, divisions per bar, from 0
+// up to duration, aligned so that one slice starts at the downbeat at offset seconds.
+// Returns nil if bpm or divisions is not positive.
+func BeatSlices(duration, bpm float64, divisions int, offset float64) []float64 {
+	if bpm <= 0 || divisions <= 0 || duration <= 0 {
+		return nil
+	}
+
+	step := 60.0 / bpm * BeatsPerBar / float64(divisions)
+	first := int(math.Ceil(-offset/step - 1e-9))
+	var times []float64
+	for n := first; ; n++ {
+		t := offset + float64(n)*step
+		if t >= duration {
+			break
+		}
+		times = append(times, math.Max(t, 0))
+	}
+	return times
+}
+
+// SliceByBeats chops the audio into equal slices of a bar, divisions per bar (16 for
+// classic sixteenth-note break chopping), and returns the slice start times in seconds.
+// The grid is aligned to the downbeat found by DetectDownbeat, or to the start of the
+// file if it is silent.
+func SliceByBeats(w *Waveform, bpm float64, divisions int) ([]float64, error) {
+	if bpm <= 0 {
+		return nil, fmt.Errorf("bpm must be positive")
+	}
+	if divisions <= 0 {
+		return nil, fmt.Errorf("divisions must be positive")
+	}
+	return BeatSlices(w.Duration(), bpm, divisions, w.DetectDownbeat()), nil
+}
+
+// Attack detection for DetectDownbeat
+const (
+	attackHop       = 0.005 // Seconds per energy hop
+	attackThreshold = 0.3   // Fraction of the largest energy rise that counts as an attack
+)
+
+// DetectDownbeat estimates the first downbeat as the first strong attack: the first
+// energy rise of at least 30% of the largest rise in the file, refined to the first
+// frame that reaches a tenth of the peak around it. Returns 0 for silent audio.
+func (w *Waveform) DetectDownbeat() float64 {
+	mix := w.monoMix()
+	hop := max(1, int(attackHop*float64(w.SampleRate)))
+	if len(mix) < 2*hop {
+		return 0
+	}
+
+	energies := make([]float64, len(mix)/hop)
+	for i := range energies {
+		for _, v := range mix[i*hop : (i+1)*hop] {
+			energies[i] += v * v
+		}
+	}
+
+	var maxRise float64
+	for i := 1; i < len(energies); i++ {
+		maxRise = math.Max(maxRise, energies[i]-energies[i-1])
+	}
+	if maxRise == 0 {
+		return 0
+	}
+
+	// The first hop is an attack too if the file starts with sound
+	attack := 0
+	if energies[0] < attackThreshold*maxRise {
+		for i := 1; i < len(energies); i++ {
+			if energies[i]-energies[i-1] >= attackThreshold*maxRise {
+				attack = i
+				break
+			}
+		}
+	}
+
+	// Refine to the first frame of the attack within the hop before and the hop itself
+	from, to := max(0, attack-1)*hop, (attack+1)*hop
+	var peak float64
+	for _, v := range mix[from:to] {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	for i := from; i < to; i++ {
+		if math.Abs(mix[i]) >= 0.1*peak {
+			return float64(i) / float64(w.SampleRate)
+		}
+	}
+	return float64(from) / float64(w.SampleRate)
+}
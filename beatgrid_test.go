@@ -0,0 +1,164 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestBeatGrid(t *testing.T) {
+	// 120 BPM = 0.5s per beat
+	beats := BeatGrid(0, 2.5, 120, 0)
+	if len(beats) != 6 {
+		t.Fatalf("Expected 6 beats, got %d", len(beats))
+	}
+
+	expected := []struct {
+		time  float64
+		label string
+	}{
+		{0, "1"}, {0.5, "1.2"}, {1.0, "1.3"}, {1.5, "1.4"}, {2.0, "2"}, {2.5, "2.2"},
+	}
+	for i, e := range expected {
+		if math.Abs(beats[i].Time-e.time) > 1e-9 {
+			t.Errorf("Beat %d: expected time %.2f, got %.2f", i, e.time, beats[i].Time)
+		}
+		if beats[i].Label() != e.label {
+			t.Errorf("Beat %d: expected label %q, got %q", i, e.label, beats[i].Label())
+		}
+	}
+
+	if !beats[4].IsDownbeat() || beats[5].IsDownbeat() {
+		t.Error("Expected beat 4 to be a downbeat and beat 5 not to be")
+	}
+}
+
+func TestBeatGridOffset(t *testing.T) {
+	// Beats before the offset belong to bar 0 and below
+	beats := BeatGrid(0, 1.0, 120, 0.75)
+	if len(beats) != 2 {
+		t.Fatalf("Expected 2 beats, got %d", len(beats))
+	}
+	if beats[0].Bar != 0 || beats[0].Beat != 4 || math.Abs(beats[0].Time-0.25) > 1e-9 {
+		t.Errorf("Expected bar 0 beat 4 at 0.25s, got %+v", beats[0])
+	}
+	if beats[1].Bar != 1 || beats[1].Beat != 1 || math.Abs(beats[1].Time-0.75) > 1e-9 {
+		t.Errorf("Expected bar 1 beat 1 at 0.75s, got %+v", beats[1])
+	}
+}
+
+func TestBeatGridInvalid(t *testing.T) {
+	if beats := BeatGrid(0, 10, 0, 0); beats != nil {
+		t.Errorf("Expected nil for zero BPM, got %d beats", len(beats))
+	}
+	if beats := BeatGrid(5, 1, 120, 0); beats != nil {
+		t.Errorf("Expected nil for empty range, got %d beats", len(beats))
+	}
+}
+
+func TestBeatGridAmenBreak(t *testing.T) {
+	const amenFile = "data/amen_170.wav"
+
+	// Check if file exists, skip if not
+	if _, err := os.Stat(amenFile); os.IsNotExist(err) {
+		t.Skip("Skipping test: data/amen_170.wav not found")
+	}
+
+	waveform, err := LoadWaveform(amenFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	beats := BeatGrid(0, waveform.Duration(), 170, 0)
+	if len(beats) < 16 {
+		t.Fatalf("Expected at least 16 beats (4 bars), got %d", len(beats))
+	}
+
+	// Each beat line should land on a hit: the 20ms after the beat are louder than the 20ms before
+	window := 0.02
+	onHit := 0
+	for _, b := range beats[1:] {
+		if b.Time+window > waveform.Duration() {
+			continue
+		}
+		before, err := waveform.GenerateView(WaveformOptions{Start: b.Time - window, End: b.Time, Width: 1})
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		after, err := waveform.GenerateView(WaveformOptions{Start: b.Time, End: b.Time + window, Width: 1})
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		if after.Data[1] > before.Data[1] {
+			onHit++
+		}
+	}
+
+	if onHit < 12 {
+		t.Errorf("Expected most beats to land on hits, only %d of %d did", onHit, len(beats)-1)
+	}
+}
+
+func TestBeatSlices(t *testing.T) {
+	// 120 BPM in 4/4 = 2s per bar, 4 divisions = 0.5s slices aligned to 0.25s
+	times := BeatSlices(1.5, 120, 4, 0.25)
+	expected := []float64{0.25, 0.75, 1.25}
+	if len(times) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, times)
+	}
+	for i, e := range expected {
+		if math.Abs(times[i]-e) > 1e-9 {
+			t.Errorf("Slice %d: expected %.2f, got %.2f", i, e, times[i])
+		}
+	}
+
+	if BeatSlices(1, 0, 16, 0) != nil || BeatSlices(1, 120, 0, 0) != nil {
+		t.Error("Expected nil for a non-positive bpm or number of divisions")
+	}
+}
+
+func TestSliceByBeats(t *testing.T) {
+	tmpFile := "/tmp/test_slice_by_beats.wav"
+	defer os.Remove(tmpFile)
+
+	// 2s at 120 BPM with a decaying click on every beat, the first at 0.25s
+	samples := make([]int16, 88200)
+	for beat := 0; beat < 4; beat++ {
+		start := 11025 + beat*22050
+		for i := 0; i < 2000; i++ {
+			v := int16(20000 * (1 - float64(i)/2000))
+			if i%2 == 1 {
+				v = -v
+			}
+			samples[start+i] = v
+		}
+	}
+	writeTestWAV(t, tmpFile, 44100, 1, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if downbeat := waveform.DetectDownbeat(); math.Abs(downbeat-0.25) > 0.001 {
+		t.Errorf("Expected the downbeat at 0.25s, got %.4f", downbeat)
+	}
+
+	times, err := SliceByBeats(waveform, 120, 16)
+	if err != nil {
+		t.Fatalf("SliceByBeats failed: %v", err)
+	}
+	// Sixteenths of a 2s bar are 0.125s long: 0, 0.125, 0.25 (downbeat), ... 1.875
+	if len(times) != 16 {
+		t.Fatalf("Expected 16 slices, got %d", len(times))
+	}
+	for i, tm := range times {
+		if math.Abs(tm-float64(i)*0.125) > 0.001 {
+			t.Errorf("Slice %d: expected %.3f, got %.4f", i, float64(i)*0.125, tm)
+		}
+	}
+
+	if _, err := SliceByBeats(waveform, 0, 16); err == nil {
+		t.Error("Expected an error for a zero bpm")
+	}
+}
@@ -0,0 +1,145 @@
+package gowaveform
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Benchmarks of the main paths, each run over the amen break and over a synthetic
+// 30-minute file, so changes made for performance can be measured the same way:
+//
+//	go test -run '^$' -bench '^Benchmark(Load|Overview|DeepZoom|SavePlot|TerminalRefresh)$' -benchmem
+//
+// The synthetic file is skipped with -short.
+
+const (
+	benchAmenFile        = "data/amen_170.wav"
+	benchSyntheticLength = 30 * 60 // Seconds
+)
+
+// benchSyntheticWaveform is a 30-minute mono tone at 44.1 kHz, built once
+var benchSyntheticWaveform = sync.OnceValue(func() *Waveform {
+	return toneWaveform(44100, 1, 440, 0.5, benchSyntheticLength)
+})
+
+// benchAmenWaveform is the decoded amen break, loaded once
+var benchAmenWaveform = sync.OnceValues(func() (*Waveform, error) {
+	return LoadWaveform(benchAmenFile)
+})
+
+// benchSources runs fn as a sub-benchmark for the amen break and the synthetic file
+func benchSources(b *testing.B, fn func(b *testing.B, w *Waveform)) {
+	b.Run("amen", func(b *testing.B) {
+		if _, err := os.Stat(benchAmenFile); os.IsNotExist(err) {
+			b.Skip("Skipping benchmark: " + benchAmenFile + " not found")
+		}
+		w, err := benchAmenWaveform()
+		if err != nil {
+			b.Fatalf("LoadWaveform failed: %v", err)
+		}
+		fn(b, w)
+	})
+	b.Run("synthetic30m", func(b *testing.B) {
+		if testing.Short() {
+			b.Skip("Skipping the 30-minute file in short mode")
+		}
+		fn(b, benchSyntheticWaveform())
+	})
+}
+
+func BenchmarkLoad(b *testing.B) {
+	b.Run("amen", func(b *testing.B) {
+		if _, err := os.Stat(benchAmenFile); os.IsNotExist(err) {
+			b.Skip("Skipping benchmark: " + benchAmenFile + " not found")
+		}
+		benchLoad(b, benchAmenFile)
+	})
+	b.Run("synthetic30m", func(b *testing.B) {
+		if testing.Short() {
+			b.Skip("Skipping the 30-minute file in short mode")
+		}
+		filename := filepath.Join(b.TempDir(), "synthetic.wav")
+		w := benchSyntheticWaveform()
+		if err := os.WriteFile(filename, testWAVBytes(uint32(w.SampleRate), uint16(w.Channels), w.audioData), 0644); err != nil {
+			b.Fatalf("Failed to write the synthetic file: %v", err)
+		}
+		benchLoad(b, filename)
+	})
+}
+
+func benchLoad(b *testing.B, filename string) {
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := LoadWaveform(filename); err != nil {
+			b.Fatalf("LoadWaveform failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkOverview generates a full-file view, as for a scrollbar or thumbnail
+func BenchmarkOverview(b *testing.B) {
+	benchSources(b, func(b *testing.B, w *Waveform) {
+		b.ReportAllocs()
+		for b.Loop() {
+			data, err := w.GenerateView(WaveformOptions{Width: 1000})
+			if err != nil {
+				b.Fatalf("GenerateView failed: %v", err)
+			}
+			data.Release()
+		}
+	})
+}
+
+// BenchmarkDeepZoom generates a view of 50 ms from the middle of the file, a few
+// samples per pixel
+func BenchmarkDeepZoom(b *testing.B) {
+	benchSources(b, func(b *testing.B, w *Waveform) {
+		middle := w.Duration() / 2
+		opts := WaveformOptions{Start: middle, End: middle + 0.05, Width: 1000}
+		b.ReportAllocs()
+		for b.Loop() {
+			data, err := w.GenerateView(opts)
+			if err != nil {
+				b.Fatalf("GenerateView failed: %v", err)
+			}
+			data.Release()
+		}
+	})
+}
+
+func BenchmarkSavePlot(b *testing.B) {
+	benchSources(b, func(b *testing.B, w *Waveform) {
+		filename := filepath.Join(b.TempDir(), "plot.png")
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := SavePlot(w, filename, OptionSetWidth(1200), OptionSetHeight(300)); err != nil {
+				b.Fatalf("SavePlot failed: %v", err)
+			}
+		}
+	})
+}
+
+// BenchmarkTerminalRefresh does the work of one TUI refresh of a 160-column terminal in
+// quadrant mode: the peaks of two columns per cell, the level meters and the band
+// colors. Drawing the characters happens in the command and is not included.
+func BenchmarkTerminalRefresh(b *testing.B) {
+	benchSources(b, func(b *testing.B, w *Waveform) {
+		opts := WaveformOptions{Width: 2 * 160}
+		b.ReportAllocs()
+		for b.Loop() {
+			data, err := w.GenerateView(opts)
+			if err != nil {
+				b.Fatalf("GenerateView failed: %v", err)
+			}
+			if _, err := w.ChannelLevels(0, 0); err != nil {
+				b.Fatalf("ChannelLevels failed: %v", err)
+			}
+			if _, err := w.GenerateBandEnergies(opts); err != nil {
+				b.Fatalf("GenerateBandEnergies failed: %v", err)
+			}
+			data.Release()
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the analyze command
+var (
+	analyzeInput     string
+	analyzeJSON      bool
+	silenceThreshold float64
+	minSilence       float64
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze",
+	Short: "Report levels, loudness, clipping and silence of an audio file",
+	Long: `Analyze an audio file and print its duration, sample rate, channels, peak, true
+peak (dBTP) and RMS level, integrated loudness (LUFS, ITU-R BS.1770), number of
+clipped samples, inter-sample overs, delay between the left and right channel and
+silent regions. Use --json for a machine-readable report, e.g. to reject uploads
+that clip; levels of silent audio are null in JSON.`,
+	Example: `  # Print a report
+  gowaveform analyze -i audio.wav
+
+  # Print a JSON report and reject files with clipping
+  gowaveform analyze -i audio.wav --json | jq -e '.clipped_samples == 0'
+
+  # Reject masters with a true peak above -1 dBTP
+  gowaveform analyze -i master.wav --json | jq -e '.true_peak_dbtp <= -1'`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(analyzeInput); err != nil {
+			fail(err)
+		}
+
+		waveform, err := gowaveform.LoadWaveform(analyzeInput, loadOptions()...)
+		if err != nil {
+			fail(withExitCode(exitDecodeError, fmt.Errorf("failed to load waveform: %w", err)))
+		}
+
+		analysis, err := waveform.Analyze(gowaveform.AnalysisOptions{
+			SilenceThreshold: silenceThreshold,
+			MinSilence:       minSilence,
+		})
+		if err != nil {
+			fail(fmt.Errorf("failed to analyze audio: %w", err))
+		}
+
+		if analyzeJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(analysis); err != nil {
+				fail(withExitCode(exitWriteError, err))
+			}
+			return
+		}
+
+		printAnalysis(os.Stdout, analyzeInput, analysis)
+	},
+}
+
+// maxPrintedOvers limits the inter-sample overs listed in the report; a hot master can
+// have thousands
+const maxPrintedOvers = 10
+
+// printAnalysis writes a human-readable analysis report
+func printAnalysis(w io.Writer, filename string, a *gowaveform.Analysis) {
+	fmt.Fprintf(w, "File:         %s\n", filename)
+	fmt.Fprintf(w, "Duration:     %.3fs\n", a.Duration)
+	fmt.Fprintf(w, "Sample rate:  %d Hz\n", a.SampleRate)
+	fmt.Fprintf(w, "Channels:     %d\n", a.Channels)
+	fmt.Fprintf(w, "Bit depth:    %d\n", a.BitsPerSample)
+	fmt.Fprintf(w, "Peak:         %s dBFS\n", formatDBFS(a.PeakDBFS))
+	fmt.Fprintf(w, "True peak:    %s dBTP\n", formatDBFS(a.TruePeakDBTP))
+	fmt.Fprintf(w, "RMS:          %s dBFS\n", formatDBFS(a.RMSDBFS))
+	for ch, level := range a.Levels {
+		if len(a.Levels) > 1 {
+			fmt.Fprintf(w, "  %-11s peak %s, rms %s dBFS\n", channelName(ch, len(a.Levels))+":", formatDBFS(level.PeakDBFS()), formatDBFS(level.RMSDBFS()))
+		}
+	}
+	fmt.Fprintf(w, "Loudness:     %s LUFS\n", formatDBFS(a.Loudness))
+	if a.Channels > 1 {
+		fmt.Fprintf(w, "Delay:        %d samples (%.2f ms, right relative to left)\n", a.ChannelDelay, float64(a.ChannelDelay)*1000/float64(a.SampleRate))
+	}
+	fmt.Fprintf(w, "Clipped:      %d samples\n", a.ClippedSamples)
+	fmt.Fprintf(w, "Overs:        %d inter-sample\n", len(a.Overs))
+	for i, t := range a.Overs {
+		if i == maxPrintedOvers {
+			fmt.Fprintf(w, "  ... %d more\n", len(a.Overs)-i)
+			break
+		}
+		fmt.Fprintf(w, "  %.3fs\n", t)
+	}
+
+	if len(a.Silence) == 0 {
+		fmt.Fprintf(w, "Silence:      none\n")
+		return
+	}
+	fmt.Fprintf(w, "Silence:      %d regions\n", len(a.Silence))
+	for _, r := range a.Silence {
+		fmt.Fprintf(w, "  %.3fs - %.3fs (%.3fs)\n", r.Start, r.End, r.Duration())
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().StringVarP(&analyzeInput, "input", "i", "", "Input audio file")
+	analyzeCmd.Flags().BoolVar(&analyzeJSON, "json", false, "Print the report as JSON")
+	analyzeCmd.Flags().Float64Var(&silenceThreshold, "silence-threshold", gowaveform.DefaultSilenceThreshold, "Peak level in dBFS below which audio counts as silence")
+	analyzeCmd.Flags().Float64Var(&minSilence, "min-silence", gowaveform.DefaultMinSilence, "Shortest silent region to report, in seconds")
+	analyzeCmd.MarkFlagRequired("input")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "ramp.wav")
+
+	stdout, stderr, code := runCLI(t, dir, "analyze", "-i", "ramp.wav", "--json")
+	if code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	var report struct {
+		Duration       float64 `json:"duration"`
+		SampleRate     int     `json:"sample_rate"`
+		Channels       int     `json:"channels"`
+		PeakDBFS       float64 `json:"peak_dbfs"`
+		ClippedSamples int     `json:"clipped_samples"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Invalid JSON report %q: %v", stdout, err)
+	}
+	// The ramp peaks at 100 of 32768
+	if report.Duration != 1 || report.SampleRate != 8000 || report.Channels != 1 || report.ClippedSamples != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if want := 20 * math.Log10(100.0/32768); math.Abs(report.PeakDBFS-want) > 0.01 {
+		t.Errorf("Expected a peak of %.2f dBFS, got %.2f", want, report.PeakDBFS)
+	}
+
+	stdout, _, code = runCLI(t, dir, "analyze", "-i", "ramp.wav")
+	if code != 0 || !strings.Contains(stdout, "8000 Hz") {
+		t.Errorf("Expected a text report with the sample rate, got %d: %s", code, stdout)
+	}
+}
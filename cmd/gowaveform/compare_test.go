@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "a.wav")
+	copyRamp(t, dir, "b.wav")
+
+	stdout, stderr, code := runCLI(t, dir, "compare", "a.wav", "b.wav", "--json", "--plot", "diff.png")
+	if code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	var report struct {
+		Offset      float64 `json:"offset"`
+		Correlation float64 `json:"correlation"`
+		Overlap     float64 `json:"overlap"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("Invalid JSON report %q: %v", stdout, err)
+	}
+	if report.Offset != 0 || report.Correlation != 1 || report.Overlap != 1 {
+		t.Errorf("Expected identical files to be aligned, got %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "diff.png")); err != nil {
+		t.Errorf("Expected a difference plot: %v", err)
+	}
+
+	stdout, _, _ = runCLI(t, dir, "compare", "a.wav", "b.wav")
+	if !strings.Contains(stdout, "(aligned)") {
+		t.Errorf("Expected the text report to call the files aligned:\n%s", stdout)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the convert command
+var (
+	convertInput           string
+	convertOutput          string
+	convertWidth           int
+	convertSamplesPerPixel int
+	convertCompress        string
+	convertCompact         bool
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Convert peak files between JSON and the audiowaveform binary format",
+	Long: `Convert waveform peaks between JSON (.json, optionally .gz or .zst compressed) and
+the audiowaveform binary format (.dat) without decoding the audio again. Formats
+are chosen by file extension; compressed JSON input is detected automatically.
+
+Peaks can be made coarser with --width or --samples-per-pixel, which merge whole
+pixels, so the new samples per pixel is always a multiple of the original.
+Band peaks and metadata are kept in JSON output but cannot be stored in .dat files.`,
+	Example: `  # Convert JSON peaks to the binary format
+  gowaveform convert -i peaks.json -o peaks.dat
+
+  # Convert binary peaks to gzip-compressed JSON
+  gowaveform convert -i peaks.dat -o peaks.json.gz
+
+  # Shrink archived peaks to at most 1000 pixels
+  gowaveform convert -i peaks.json -o overview.json --width 1000`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(convertInput); err != nil {
+			fail(err)
+		}
+
+		if err := convertPeaks(); err != nil {
+			fail(fmt.Errorf("failed to convert peaks: %w", err))
+		}
+		fmt.Printf("Waveform data saved to: %s\n", convertOutput)
+	},
+}
+
+// convertPeaks reads, rescales and writes the peaks described by the convert command flags
+func convertPeaks() error {
+	if !gowaveform.IsBinaryPeakFile(convertOutput) && !isJSONOutput(convertOutput) {
+		return withExitCode(exitInvalidOptions, fmt.Errorf("unsupported output format: %s (supported: .dat, .json, .json.gz, .json.zst)", convertOutput))
+	}
+	if convertWidth > 0 && convertSamplesPerPixel > 0 {
+		return withExitCode(exitInvalidOptions, fmt.Errorf("--width and --samples-per-pixel cannot be combined"))
+	}
+
+	data, err := gowaveform.LoadWaveformData(convertInput)
+	if err != nil {
+		return withExitCode(exitDecodeError, err)
+	}
+
+	switch {
+	case convertWidth > 0:
+		data, err = data.RescaleToWidth(convertWidth)
+	case convertSamplesPerPixel > 0:
+		data, err = data.Rescale(convertSamplesPerPixel)
+	}
+	if err != nil {
+		return withExitCode(exitInvalidOptions, err)
+	}
+
+	f, err := os.Create(convertOutput)
+	if err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to create output file: %w", err))
+	}
+	defer f.Close()
+
+	if gowaveform.IsBinaryPeakFile(convertOutput) {
+		if len(data.Bands) > 0 || data.Metadata != nil {
+			fmt.Fprintf(os.Stderr, "Warning: band peaks and metadata are not stored in .dat files\n")
+		}
+		if err := gowaveform.WriteBinary(f, data); err != nil {
+			return withExitCode(exitWriteError, err)
+		}
+		return withExitCode(exitWriteError, f.Close())
+	}
+
+	codec, err := outputCompression(convertCompress, convertOutput)
+	if err != nil {
+		return withExitCode(exitInvalidOptions, err)
+	}
+	zw, err := gowaveform.NewCompressWriter(f, codec)
+	if err != nil {
+		return err
+	}
+	if convertCompact {
+		err = gowaveform.WriteJSONCompact(zw, data)
+	} else {
+		err = gowaveform.WriteJSON(zw, data)
+	}
+	if err != nil {
+		zw.Close()
+		return withExitCode(exitWriteError, err)
+	}
+	if err := zw.Close(); err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to compress JSON file: %w", err))
+	}
+
+	return withExitCode(exitWriteError, f.Close())
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+
+	convertCmd.Flags().StringVarP(&convertInput, "input", "i", "", "Input peak file (.json, .json.gz, .json.zst or .dat)")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", "", "Output peak file (.json, .json.gz, .json.zst or .dat)")
+	convertCmd.Flags().IntVar(&convertWidth, "width", 0, "Merge pixels to fit at most this many (0 = keep)")
+	convertCmd.Flags().IntVar(&convertSamplesPerPixel, "samples-per-pixel", 0, "Merge pixels to this many samples per pixel, a multiple of the input's (0 = keep)")
+	convertCmd.Flags().StringVar(&convertCompress, "compress", "", "Compress JSON output with gzip or zstd (default: inferred from .gz/.zst extension)")
+	convertCmd.Flags().BoolVar(&convertCompact, "compact", false, "Write JSON output without indentation")
+	convertCmd.MarkFlagRequired("input")
+	convertCmd.MarkFlagRequired("output")
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/schollz/gowaveform"
+)
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "ramp.wav")
+	if _, stderr, code := runCLI(t, dir, "ramp.wav", "-o", "peaks.json", "--samples-per-pixel", "100"); code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+
+	// To binary, merging four pixels into one
+	if _, stderr, code := runCLI(t, dir, "convert", "-i", "peaks.json", "-o", "peaks.dat", "--samples-per-pixel", "400"); code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	data, err := gowaveform.LoadWaveformData(filepath.Join(dir, "peaks.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.SamplesPerPixel != 400 || data.Length != 20 {
+		t.Errorf("Expected 20 pixels of 400 samples, got %d of %d", data.Length, data.SamplesPerPixel)
+	}
+	// Each pixel spans two periods of the ramp
+	if data.Data[0] != -100 || data.Data[1] != 99 {
+		t.Errorf("Expected the first pixel to span -100 to 99, got %d and %d", data.Data[0], data.Data[1])
+	}
+
+	// Back to compressed JSON, fitting a width
+	if _, stderr, code := runCLI(t, dir, "convert", "-i", "peaks.dat", "-o", "peaks.json.gz", "--width", "10"); code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	data, err = gowaveform.LoadWaveformData(filepath.Join(dir, "peaks.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Length > 10 || data.SamplesPerPixel%400 != 0 {
+		t.Errorf("Expected at most 10 pixels of a multiple of 400 samples, got %d of %d", data.Length, data.SamplesPerPixel)
+	}
+
+	// Rescaling only merges whole pixels
+	if _, _, code := runCLI(t, dir, "convert", "-i", "peaks.json", "-o", "x.dat", "--samples-per-pixel", "150"); code != exitInvalidOptions {
+		t.Errorf("Expected exit code %d for a rescale that is not a multiple, got %d", exitInvalidOptions, code)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Exit codes, so scripts can tell failures apart
+const (
+	exitError          = 1 // Any other failure
+	exitInvalidOptions = 2 // Invalid flags or arguments
+	exitFileNotFound   = 3 // An input file does not exist
+	exitDecodeError    = 4 // An input file cannot be read or decoded
+	exitWriteError     = 5 // An output file cannot be written
+)
+
+// errorTypes names the exit codes in --json-errors output
+var errorTypes = map[int]string{
+	exitError:          "error",
+	exitInvalidOptions: "invalid_options",
+	exitFileNotFound:   "file_not_found",
+	exitDecodeError:    "decode_error",
+	exitWriteError:     "write_error",
+}
+
+// jsonErrors prints errors as JSON objects instead of text
+var jsonErrors bool
+
+// cliError is an error that ends the run with a specific exit code
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+func (e *cliError) Unwrap() error { return e.err }
+
+// withExitCode returns err marked to end the run with code, or nil if err is nil
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &cliError{code: code, err: err}
+}
+
+// exitCode returns the exit code err ends the run with
+func exitCode(err error) int {
+	var e *cliError
+	switch {
+	case errors.As(err, &e):
+		return e.code
+	case errors.Is(err, fs.ErrNotExist):
+		return exitFileNotFound
+	default:
+		return exitError
+	}
+}
+
+// validArgs tags the errors of a cobra argument check with exitInvalidOptions
+func validArgs(check cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return withExitCode(exitInvalidOptions, check(cmd, args))
+	}
+}
+
+// validFlags returns an error with exitInvalidOptions if a required flag of cmd is
+// missing or flags of a group are combined wrongly. Cobra checks this after the
+// pre-run hooks, without an exit code.
+func validFlags(cmd *cobra.Command) error {
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return withExitCode(exitInvalidOptions, err)
+	}
+	return withExitCode(exitInvalidOptions, cmd.ValidateFlagGroups())
+}
+
+// plotError tags an error of SavePlot or SaveMultiPlot: an invalid view window is an
+// invalid option, and only a failure to write the image is a write error
+func plotError(err error) error {
+	var pathErr *fs.PathError
+	switch {
+	case errors.Is(err, gowaveform.ErrInvalidWindow):
+		return withExitCode(exitInvalidOptions, err)
+	case errors.As(err, &pathErr):
+		return withExitCode(exitWriteError, err)
+	default:
+		return err
+	}
+}
+
+// checkImageOutput returns an error with exitInvalidOptions unless name is a PNG or
+// JPEG file, so a wrong extension is reported before any audio is decoded
+func checkImageOutput(name string) error {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".png", ".jpg", ".jpeg":
+		return nil
+	}
+	return withExitCode(exitInvalidOptions, fmt.Errorf("unsupported image format: %s (supported: .png, .jpg, .jpeg)", name))
+}
+
+// checkInputFile returns an error with exitFileNotFound if the input file cannot be found
+func checkInputFile(name string) error {
+	if _, err := os.Stat(name); err != nil {
+		return withExitCode(exitFileNotFound, fmt.Errorf("file not found: %s", name))
+	}
+	return nil
+}
+
+// fail writes out the profiles of the run, prints err to stderr, as text or with
+// --json-errors as a JSON object, and exits with the code of err
+func fail(err error) {
+	if stopErr := stopProfiling(); stopErr != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", stopErr)
+	}
+
+	code := exitCode(err)
+	if jsonErrors {
+		json.NewEncoder(os.Stderr).Encode(struct {
+			Error    string `json:"error"`
+			Type     string `json:"type"`
+			ExitCode int    `json:"exit_code"`
+		}{err.Error(), errorTypes[code], code})
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/schollz/gowaveform"
+)
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "ramp.wav")
+	os.WriteFile(filepath.Join(dir, "noise.wav"), []byte("not audio"), 0644)
+	os.WriteFile(filepath.Join(dir, "bad.json"), []byte("{"), 0644)
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"ramp.wav", "-o", "ok.png"}, 0},
+		{[]string{"--bogus"}, exitInvalidOptions},
+		{[]string{}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.png", "--start", "100"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.png", "--start", "0.1", "--zoom", "0.2", "--end", "0.5"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.bmp"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.json", "--format-version", "3"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.json", "--start", "100"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "-o", "x.json", "--rms", "--float"}, exitInvalidOptions},
+		{[]string{"ramp.wav", "ramp.wav", "-o", "x.json"}, exitInvalidOptions},
+		{[]string{"missing.wav", "-o", "x.png"}, exitFileNotFound},
+		{[]string{"ramp.wav", "-o", "x.png", "--font", "missing.ttf"}, exitFileNotFound},
+		{[]string{"noise.wav", "-o", "x.png"}, exitDecodeError},
+		{[]string{"ramp.wav", "-o", filepath.Join("missing", "x.png")}, exitWriteError},
+		{[]string{"ramp.wav", "-o", filepath.Join("missing", "x.json")}, exitWriteError},
+		{[]string{"analyze"}, exitInvalidOptions},
+		{[]string{"analyze", "-i", "missing.wav"}, exitFileNotFound},
+		{[]string{"analyze", "-i", "noise.wav"}, exitDecodeError},
+		{[]string{"compare", "ramp.wav"}, exitInvalidOptions},
+		{[]string{"compare", "ramp.wav", "missing.wav"}, exitFileNotFound},
+		{[]string{"compare", "ramp.wav", "ramp.wav", "--plot", filepath.Join("missing", "x.png")}, exitWriteError},
+		{[]string{"convert", "-i", "bad.json", "-o", "x.dat"}, exitDecodeError},
+		{[]string{"convert", "-i", "bad.json"}, exitInvalidOptions},
+		{[]string{"markers", "-i", "ramp.wav", "--format", "csv"}, exitInvalidOptions},
+		{[]string{"plot-all", "-i", ".", "-o", "thumbs", "--theme", "neon"}, exitInvalidOptions},
+		{[]string{"plot-all", "-i", "missing", "-o", "thumbs"}, exitFileNotFound},
+		{[]string{"gallery", "-i", ".", "-o", "site", "--width", "0"}, exitInvalidOptions},
+	} {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			_, stderr, code := runCLI(t, dir, tc.args...)
+			if code != tc.code {
+				t.Errorf("Expected exit code %d, got %d: %s", tc.code, code, stderr)
+			}
+		})
+	}
+}
+
+func TestJSONErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, stderr, code := runCLI(t, dir, "missing.wav", "-o", "x.png", "--json-errors")
+	if code != exitFileNotFound {
+		t.Fatalf("Expected exit code %d, got %d", exitFileNotFound, code)
+	}
+	var report struct {
+		Error    string `json:"error"`
+		Type     string `json:"type"`
+		ExitCode int    `json:"exit_code"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &report); err != nil {
+		t.Fatalf("Expected a JSON error, got %q: %v", stderr, err)
+	}
+	if report.Type != "file_not_found" || report.ExitCode != exitFileNotFound || !strings.Contains(report.Error, "missing.wav") {
+		t.Errorf("Unexpected error report %+v", report)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{errors.New("failed"), exitError},
+		{fmt.Errorf("reading: %w", fs.ErrNotExist), exitFileNotFound},
+		{withExitCode(exitDecodeError, fs.ErrNotExist), exitDecodeError},
+		{fmt.Errorf("wrapped: %w", withExitCode(exitWriteError, errors.New("disk full"))), exitWriteError},
+		{plotError(fmt.Errorf("%w: end before start", gowaveform.ErrInvalidWindow)), exitInvalidOptions},
+		{plotError(&fs.PathError{Op: "open", Path: "x.png", Err: fs.ErrPermission}), exitWriteError},
+		{plotError(errors.New("failed to parse font")), exitError},
+	} {
+		if code := exitCode(tc.err); code != tc.code {
+			t.Errorf("exitCode(%v) = %d, want %d", tc.err, code, tc.code)
+		}
+	}
+	if withExitCode(exitWriteError, nil) != nil {
+		t.Error("Expected withExitCode to keep nil errors nil")
+	}
+}
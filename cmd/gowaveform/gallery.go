@@ -0,0 +1,370 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the gallery command
+var (
+	galleryInput    string
+	galleryOutput   string
+	galleryWidth    int
+	galleryHeight   int
+	galleryTheme    string
+	galleryTitle    string
+	galleryWorkers  int
+	galleryLink     bool
+	galleryManifest string
+)
+
+var galleryCmd = &cobra.Command{
+	Use:   "gallery",
+	Short: "Export a static HTML page to browse and play the audio files in a directory",
+	Long: `Export a static HTML page with a waveform image of every audio file under the input
+directory, captioned with its duration, sample rate, channels, bit depth and size.
+Clicking a waveform plays the file from that point with the browser's audio element.
+
+The page is written to index.html in the output directory, with the images in
+images/, named after their audio files as plot-all names them, and copies of the
+audio files in audio/, so the directory can be opened or served as is. With --link
+the page refers to the original audio files instead of copying them, which keeps
+large sessions small but ties the page to their location.
+The output directory may be inside the input directory; it is not searched for audio.
+
+With --manifest, a JSON manifest lists every input with its SHA-256 checksum,
+duration, image and copied audio (with their checksums) and any error, as plot-all
+writes it.`,
+	Example: `  # Browse a recording session
+  gowaveform gallery -i session/ -o site/
+  open site/index.html
+
+  # Dark page without copying the audio
+  gowaveform gallery -i session/ -o site/ --theme dark --link
+
+  # Write a manifest for the deploy step
+  gowaveform gallery -i session/ -o site/ --manifest site/manifest.json`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(galleryInput); err != nil {
+			fail(err)
+		}
+
+		if err := exportGallery(); err != nil {
+			fail(err)
+		}
+	},
+}
+
+// galleryPage is the data of the gallery template
+type galleryPage struct {
+	Title      string
+	Background template.CSS
+	Foreground template.CSS
+	Text       template.CSS
+	Items      []galleryItem
+}
+
+// galleryItem is one audio file on the gallery page
+type galleryItem struct {
+	Name    string // Path relative to the input directory
+	Image   string // URL of the waveform image, relative to the page
+	Audio   string // URL of the audio file, relative to the page
+	Caption string
+	Error   string // Why the file could not be shown
+}
+
+// exportGallery writes the gallery described by the gallery command flags
+func exportGallery() error {
+	theme, err := lookupTheme(galleryTheme)
+	if err != nil {
+		return err
+	}
+	opts, err := thumbnailOptions(theme, galleryWidth, galleryHeight, galleryWorkers)
+	if err != nil {
+		return err
+	}
+
+	// The gallery itself is skipped, should it be written inside the input directory
+	images := filepath.Join(galleryOutput, "images")
+	jobs, err := findPlotJobs(galleryInput, images, galleryOutput, images, filepath.Join(galleryOutput, "audio"))
+	if err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to list input files: %w", err))
+	}
+	render := make([]int, len(jobs))
+	for i := range jobs {
+		render[i] = i
+	}
+	results := renderThumbnails(jobs, render, galleryWorkers, opts)
+
+	title := galleryTitle
+	if title == "" {
+		title = filepath.Base(filepath.Clean(galleryInput))
+	}
+	page := galleryPage{
+		Title:      title,
+		Background: template.CSS(theme.background),
+		Foreground: template.CSS(theme.foreground),
+		Text:       template.CSS(theme.text),
+	}
+	// Files that fail are listed with their error, and fail the run once the page and
+	// manifest are written
+	var firstErr error
+	failed := 0
+	entries := make([]manifestEntry, len(jobs))
+	for i, job := range jobs {
+		item, err := galleryEntry(job, results[i])
+		entries[i].Input = job.input
+		if err == nil && galleryManifest != "" {
+			err = galleryManifestEntry(&entries[i], job, results[i], item)
+		}
+		if err != nil {
+			entries[i].setError(err)
+			if results[i].err == nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", job.input, err)
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			item.Error = err.Error()
+			failed++
+		}
+		page.Items = append(page.Items, item)
+	}
+
+	filename := filepath.Join(galleryOutput, "index.html")
+	if err := writeGalleryPage(filename, page); err != nil {
+		return withExitCode(exitWriteError, err)
+	}
+	fmt.Printf("Gallery saved to: %s\n", filename)
+	if galleryManifest != "" {
+		if err := writeManifest(galleryManifest, manifest{Files: entries}); err != nil {
+			return withExitCode(exitWriteError, err)
+		}
+		fmt.Printf("Manifest saved to: %s\n", galleryManifest)
+	}
+	if failed > 0 {
+		return withExitCode(exitCode(firstErr), fmt.Errorf("failed to show %d of %d files", failed, len(jobs)))
+	}
+	return nil
+}
+
+// galleryEntry returns the gallery item of a rendered job, copying its audio into the
+// gallery unless --link is set
+func galleryEntry(job plotAllJob, result *thumbnailResult) (galleryItem, error) {
+	rel, err := filepath.Rel(galleryInput, job.input)
+	if err != nil {
+		return galleryItem{Name: job.input}, err
+	}
+	item := galleryItem{Name: filepath.ToSlash(rel)}
+	if result.err != nil {
+		return item, result.err
+	}
+	item.Image = "images/" + pathURL(rel+".png")
+
+	info, err := os.Stat(job.input)
+	if err != nil {
+		return item, withExitCode(exitDecodeError, err)
+	}
+	item.Caption = fmt.Sprintf("%s · %d Hz · %s · %d-bit · %s",
+		gowaveform.FormatTime(result.duration, gowaveform.TimeFormatMinutes, result.sampleRate, 0),
+		result.sampleRate, channelCount(result.channels), result.bitsPerSample, formatSize(info.Size()))
+
+	if galleryLink {
+		output, err := filepath.Abs(galleryOutput)
+		if err != nil {
+			return item, err
+		}
+		input, err := filepath.Abs(job.input)
+		if err != nil {
+			return item, err
+		}
+		link, err := filepath.Rel(output, input)
+		if err != nil {
+			return item, err
+		}
+		item.Audio = pathURL(link)
+		return item, nil
+	}
+
+	item.Audio = "audio/" + pathURL(rel)
+	if err := copyFile(filepath.Join(galleryOutput, "audio", rel), job.input); err != nil {
+		return item, withExitCode(exitWriteError, fmt.Errorf("failed to copy audio: %w", err))
+	}
+	return item, nil
+}
+
+// galleryManifestEntry fills in the manifest entry of a job shown on the page: the
+// checksum and duration of the input, its image and its copied audio
+func galleryManifestEntry(entry *manifestEntry, job plotAllJob, result *thumbnailResult, item galleryItem) error {
+	sum, err := fileSHA256(job.input)
+	if err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to read input: %w", err))
+	}
+	entry.InputSHA256, entry.Duration = sum, result.duration
+
+	outputs := []string{job.output}
+	if !galleryLink {
+		outputs = append(outputs, filepath.Join(galleryOutput, "audio", filepath.FromSlash(item.Name)))
+	}
+	for _, output := range outputs {
+		if err := entry.addOutput(output); err != nil {
+			return withExitCode(exitWriteError, fmt.Errorf("failed to read output: %w", err))
+		}
+	}
+	return nil
+}
+
+// pathURL escapes each element of a relative file path for use in a URL
+func pathURL(path string) string {
+	elems := strings.Split(filepath.ToSlash(path), "/")
+	for i, elem := range elems {
+		elems[i] = url.PathEscape(elem)
+	}
+	return strings.Join(elems, "/")
+}
+
+// channelCount describes a number of channels in words
+func channelCount(channels int) string {
+	switch channels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	default:
+		return fmt.Sprintf("%d channels", channels)
+	}
+}
+
+// formatSize formats a file size in bytes with a binary unit
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// copyFile copies the file at src to dst, creating the directory of dst as needed
+func copyFile(dst, src string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// writeGalleryPage renders page to filename
+func writeGalleryPage(filename string, page galleryPage) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create gallery page: %w", err)
+	}
+	defer f.Close()
+	if err := galleryTemplate.Execute(f, page); err != nil {
+		return fmt.Errorf("failed to write gallery page: %w", err)
+	}
+	return f.Close()
+}
+
+// galleryTemplate is the gallery page. Clicking a waveform seeks its audio to the
+// clicked point and plays it, pausing any other file; a line follows the playback.
+var galleryTemplate = template.Must(template.New("gallery").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 2em auto; max-width: 60em; padding: 0 1em; font-family: system-ui, sans-serif; background: {{.Background}}; color: {{.Text}}; }
+figure { margin: 0 0 2em; }
+.wave { position: relative; cursor: pointer; }
+.wave img { display: block; width: 100%; height: auto; }
+.playhead { position: absolute; top: 0; bottom: 0; left: 0; width: 2px; background: {{.Text}}; display: none; pointer-events: none; }
+figcaption { margin-top: 0.4em; font-size: 0.9em; }
+figcaption b { color: {{.Foreground}}; }
+.error { opacity: 0.7; }
+audio { width: 100%; margin-top: 0.4em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Items}}<figure>
+{{- if .Error}}
+<figcaption class="error"><b>{{.Name}}</b> · {{.Error}}</figcaption>
+{{- else}}
+<div class="wave"><img src="{{.Image}}" alt="Waveform of {{.Name}}"><div class="playhead"></div></div>
+<figcaption><b>{{.Name}}</b> · {{.Caption}}</figcaption>
+<audio controls preload="metadata" src="{{.Audio}}"></audio>
+{{- end}}
+</figure>
+{{end}}<script>
+document.querySelectorAll("figure").forEach(function (figure) {
+  var wave = figure.querySelector(".wave"), audio = figure.querySelector("audio");
+  if (!wave || !audio) return;
+  var playhead = wave.querySelector(".playhead");
+  wave.addEventListener("click", function (e) {
+    var rect = wave.getBoundingClientRect();
+    var seek = function () {
+      audio.currentTime = (e.clientX - rect.left) / rect.width * audio.duration;
+      audio.play();
+    };
+    if (audio.readyState > 0) seek(); else { audio.addEventListener("loadedmetadata", seek, { once: true }); audio.load(); }
+  });
+  audio.addEventListener("play", function () {
+    document.querySelectorAll("audio").forEach(function (other) { if (other !== audio) other.pause(); });
+    playhead.style.display = "block";
+  });
+  audio.addEventListener("timeupdate", function () {
+    if (audio.duration) playhead.style.left = (100 * audio.currentTime / audio.duration) + "%";
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+func init() {
+	rootCmd.AddCommand(galleryCmd)
+
+	galleryCmd.Flags().StringVarP(&galleryInput, "input", "i", "", "Directory of audio files, searched recursively")
+	galleryCmd.Flags().StringVarP(&galleryOutput, "output", "o", "", "Directory the page, images and audio are written to")
+	galleryCmd.Flags().IntVar(&galleryWidth, "width", 1200, "Width of each waveform image in pixels")
+	galleryCmd.Flags().IntVar(&galleryHeight, "height", 160, "Height of each waveform image in pixels")
+	galleryCmd.Flags().StringVar(&galleryTheme, "theme", "light", "Page and waveform colors: light or dark")
+	galleryCmd.Flags().StringVar(&galleryTitle, "title", "", "Title of the page (default: the name of the input directory)")
+	galleryCmd.Flags().IntVar(&galleryWorkers, "workers", runtime.NumCPU(), "Number of files rendered at once")
+	galleryCmd.Flags().BoolVar(&galleryLink, "link", false, "Refer to the original audio files instead of copying them")
+	galleryCmd.Flags().StringVar(&galleryManifest, "manifest", "", "Write a JSON manifest of every input with its duration, checksums, outputs and errors to this file")
+	galleryCmd.MarkFlagRequired("input")
+	galleryCmd.MarkFlagRequired("output")
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGallery(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "session/take 1.wav")
+	copyRamp(t, dir, "session/drums/kick.wav")
+
+	// The gallery is written inside the input directory and not picked up as input
+	for range 2 {
+		_, stderr, code := runCLI(t, dir, "gallery", "-i", "session", "-o", "session/site", "--manifest", "session/site/manifest.json")
+		if code != 0 {
+			t.Fatalf("Exit code %d: %s", code, stderr)
+		}
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "session", "site", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<title>session</title>`, `audio/take%201.wav`, `audio/drums/kick.wav`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("Expected %q in the page", want)
+		}
+	}
+	m := readTestManifest(t, filepath.Join(dir, "session", "site", "manifest.json"))
+	if len(m.Files) != 2 {
+		t.Fatalf("Expected two manifest entries, got %+v", m.Files)
+	}
+	for _, entry := range m.Files {
+		// The image and the copied audio
+		if len(entry.Outputs) != 2 || entry.Duration != 1 {
+			t.Errorf("Unexpected manifest entry %+v", entry)
+		}
+		for _, output := range entry.Outputs {
+			if _, err := os.Stat(filepath.Join(dir, output.Path)); err != nil {
+				t.Errorf("Expected output %s: %v", output.Path, err)
+			}
+		}
+	}
+}
+
+func TestGallerySameBaseName(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "session/kick.wav")
+	copyRamp(t, dir, "session/kick.WAV")
+	if entries, _ := os.ReadDir(filepath.Join(dir, "session")); len(entries) != 2 {
+		t.Skip("The file system does not tell kick.wav and kick.WAV apart")
+	}
+
+	if _, stderr, code := runCLI(t, dir, "gallery", "-i", "session", "-o", "site"); code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "site", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each file has an image of its own
+	for _, image := range []string{"kick.wav.png", "kick.WAV.png"} {
+		if !strings.Contains(string(page), "images/"+image) {
+			t.Errorf("Expected images/%s in the page", image)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "site", "images", image)); err != nil {
+			t.Errorf("Expected image %s: %v", image, err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// keyAction is a command of the interactive viewer
+type keyAction int
+
+const (
+	actionNone keyAction = iota
+	actionHelp
+	actionQuit
+	actionMarker
+	actionOnsets
+	actionBeatSlices
+	actionColor
+	actionSpectrogram
+	actionQuadrant
+	actionSymmetric
+	actionBeats
+	actionTimeFormat
+	actionNextFile
+	actionPrevFile
+	actionNextSlice
+	actionNextMarker
+	actionDelete
+	actionLabelExport
+	actionExportImage
+	actionUnselect
+	actionPrevTransient
+	actionNextTransient
+	actionJogLeft
+	actionJogRight
+	actionFastLeft
+	actionFastRight
+	actionZoomIn
+	actionZoomOut
+)
+
+// keyBinding maps the keys of an action to its description in the help overlay
+type keyBinding struct {
+	action keyAction
+	keys   []string // Key names as reported by tea.KeyMsg.String()
+	help   string
+}
+
+// keymap lists every binding of the viewer. Update dispatches through it and the
+// help overlay is generated from it, so the two cannot drift apart.
+var keymap = []keyBinding{
+	{actionMarker, []string{"m", " "}, "Create marker at center of view"},
+	{actionOnsets, []string{"o"}, "Run onset detection and create markers"},
+	{actionBeatSlices, []string{"g"}, "Slice each bar into equal divisions (needs --bpm)"},
+	{actionColor, []string{"c"}, "Toggle frequency-colored rendering"},
+	{actionSpectrogram, []string{"s"}, "Toggle waveform/spectrogram"},
+	{actionQuadrant, []string{"h"}, "Toggle half-block (double width) rendering"},
+	{actionSymmetric, []string{"y"}, "Toggle symmetric (mirrored absolute) rendering"},
+	{actionBeats, []string{"b"}, "Toggle seconds/bars ruler (needs --bpm)"},
+	{actionTimeFormat, []string{"t"}, "Cycle the ruler format"},
+	{actionNextFile, []string{"n"}, "Next file"},
+	{actionPrevFile, []string{"p"}, "Previous file"},
+	{actionNextSlice, []string{"tab"}, "Cycle through slices"},
+	{actionNextMarker, []string{"shift+tab"}, "Cycle through markers"},
+	{actionDelete, []string{"d", "backspace"}, "Delete selected marker/slice"},
+	{actionLabelExport, []string{"e"}, "Label selected marker, or export slices"},
+	{actionExportImage, []string{"i"}, "Save the current view as a PNG"},
+	{actionUnselect, []string{"esc"}, "Unselect marker/slice"},
+	{actionPrevTransient, []string{"["}, "Snap marker or view to previous transient"},
+	{actionNextTransient, []string{"]"}, "Snap marker or view to next transient"},
+	{actionJogLeft, []string{"left"}, "Jog view or selection left"},
+	{actionJogRight, []string{"right"}, "Jog view or selection right"},
+	{actionFastLeft, []string{"shift+left"}, "Fast jog view left"},
+	{actionFastRight, []string{"shift+right"}, "Fast jog view right"},
+	{actionZoomIn, []string{"up"}, "Zoom in"},
+	{actionZoomOut, []string{"down"}, "Zoom out"},
+	{actionHelp, []string{"?"}, "Show/hide this help"},
+	{actionQuit, []string{"q", "ctrl+c"}, "Quit"},
+}
+
+// actionForKey returns the action bound to a key, or actionNone
+func actionForKey(key string) keyAction {
+	for _, b := range keymap {
+		for _, k := range b.keys {
+			if k == key {
+				return b.action
+			}
+		}
+	}
+	return actionNone
+}
+
+// keyDisplayNames spells out keys whose tea.KeyMsg name is not what is printed on the keyboard
+var keyDisplayNames = map[string]string{
+	" ":     "Space",
+	"left":  "←",
+	"right": "→",
+	"up":    "↑",
+	"down":  "↓",
+}
+
+// keyDisplayName formats a key name for the help overlay, e.g. "shift+left" as "Shift+←"
+func keyDisplayName(key string) string {
+	if name, ok := keyDisplayNames[key]; ok {
+		return name
+	}
+
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		if name, ok := keyDisplayNames[part]; ok {
+			parts[i] = name
+		} else if len(part) > 1 || i > 0 {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// renderHelp renders the full-screen key cheat sheet, splitting the bindings into
+// columns when they do not fit the terminal height
+func renderHelp(width, height int) string {
+	entries := make([]string, len(keymap))
+	keyWidth := 0
+	for i, b := range keymap {
+		names := make([]string, len(b.keys))
+		for j, k := range b.keys {
+			names[j] = keyDisplayName(k)
+		}
+		entries[i] = strings.Join(names, " / ")
+		if n := len([]rune(entries[i])); n > keyWidth {
+			keyWidth = n
+		}
+	}
+
+	entryWidth := 0
+	for i, b := range keymap {
+		entries[i] = fmt.Sprintf("%-*s  %s", keyWidth, entries[i], b.help)
+		if n := len([]rune(entries[i])); n > entryWidth {
+			entryWidth = n
+		}
+	}
+
+	// Title and footer take two lines each. Use as many columns as needed to fit the
+	// height, but no more than fit the width.
+	rows := height - 4
+	if rows < 1 {
+		rows = 1
+	}
+	columns := (len(entries) + rows - 1) / rows
+	if fit := (width + 4) / (entryWidth + 4); width > 0 && columns > fit {
+		columns = fit
+	}
+	if columns < 1 {
+		columns = 1
+	}
+	rows = (len(entries) + columns - 1) / columns
+
+	var sb strings.Builder
+	sb.WriteString("Key bindings\n\n")
+	for row := 0; row < rows; row++ {
+		var line strings.Builder
+		for col := 0; col < columns; col++ {
+			i := col*rows + row
+			if i >= len(entries) {
+				break
+			}
+			if col > 0 {
+				line.WriteString("    ")
+			}
+			line.WriteString(entries[i])
+			// Pad to the column width only when another column follows on this row
+			if (col+1)*rows+row < len(entries) {
+				line.WriteString(strings.Repeat(" ", entryWidth-len([]rune(entries[i]))))
+			}
+		}
+		text := []rune(line.String())
+		if width > 0 && len(text) > width {
+			text = text[:width]
+		}
+		sb.WriteString(string(text))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nWhile labeling a marker, Enter saves and Esc cancels. Press ? or Esc to close.\n")
+
+	return sb.String()
+}
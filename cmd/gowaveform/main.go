@@ -3,9 +3,13 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/schollz/gowaveform"
@@ -14,7 +18,15 @@ import (
 )
 
 type marker struct {
-	time float64 // Time position in seconds
+	time  float64 // Time position in seconds
+	label string  // Optional label shown above the waveform and included in exports
+}
+
+// fileState is the per-file part of a session, kept while other files are shown
+type fileState struct {
+	markers []marker
+	start   float64
+	end     float64 // 0 until the file has been opened
 }
 
 type model struct {
@@ -39,23 +51,241 @@ type model struct {
 
 	// Export status
 	exportMessage string
+
+	// Multiple-file session
+	files     []string    // All files given on the command line
+	fileIndex int         // Index of the file being shown
+	sessions  []fileState // Saved state of each file
+
+	// Marker label editing
+	editingLabel bool   // Typing a label for the selected marker
+	labelInput   string // Label being typed
+
+	// Transients for snapping markers, detected on first use
+	transients []float64 // Onset times in seconds (nil until detected)
+	snapTime   float64   // Transient the view was last centered on (-1 if none)
+
+	// Key cheat sheet
+	showHelp bool // Show the help overlay instead of the waveform
+
+	// Frequency-colored rendering
+	spectral     bool                    // Color columns by dominant frequency band
+	bandEnergies []gowaveform.BandEnergy // Band energies for the current view (nil if not spectral)
+
+	// Half-block rendering
+	quadrant bool // Draw two columns per cell with quadrant blocks
+
+	// Symmetric rendering
+	symmetric bool // Mirror the absolute peak of each column around the center line
+
+	// Level meter for the visible range
+	levels []gowaveform.ChannelLevel
+
+	// Spectrogram display
+	showSpectrogram bool                    // Show the spectrogram instead of the waveform
+	spectrogram     *gowaveform.Spectrogram // Spectrogram for the current view (nil if not shown)
+
+	// Beat grid ruler
+	bpm        float64 // Tempo for the beat ruler (0 = seconds ruler only)
+	beatOffset float64 // Time in seconds of the first downbeat
+	showBeats  bool    // Show bars/beats instead of seconds on the ruler
+	divisions  int     // Slices per bar for beat slicing
+
+	// Time ruler format
+	timeFormat gowaveform.TimeFormat // Format of the ruler labels
+	frameRate  float64               // Frame rate for SMPTE timecode labels
 }
 
-func initialModel(wavFile string) model {
+func initialModel(files []string, spectral, symmetric bool, bpm, beatOffset float64, divisions int, timeFormat gowaveform.TimeFormat, frameRate float64) model {
 	return model{
-		wavFile:        wavFile,
+		wavFile:        files[0],
+		files:          files,
+		sessions:       make([]fileState, len(files)),
 		start:          0.0,
 		end:            0.0, // Will be set to total duration
 		markers:        []marker{},
 		selectedMarker: -1,
 		selectedSlice:  -1,
+		snapTime:       -1,
+		spectral:       spectral,
+		symmetric:      symmetric,
+		bpm:            bpm,
+		beatOffset:     beatOffset,
+		showBeats:      bpm > 0,
+		divisions:      divisions,
+		timeFormat:     timeFormat,
+		frameRate:      frameRate,
+	}
+}
+
+// loadFile opens the current file, restoring its markers and view if it was shown before
+func (m *model) loadFile() (tea.Cmd, error) {
+	// Decode in the background so long files open immediately
+	wf, err := gowaveform.LoadWaveform(m.wavFile, loadOptions(gowaveform.OptionProgressive())...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load waveform: %w", err)
+	}
+	m.waveform = wf
+
+	// Calculate total duration
+	m.totalDuration = wf.Duration()
+
+	state := m.sessions[m.fileIndex]
+	m.markers = state.markers
+	if m.markers == nil {
+		m.markers = []marker{}
+	}
+	m.start, m.end = state.start, state.end
+	if m.end == 0 {
+		m.start, m.end = 0, m.totalDuration
+	}
+	m.selectedMarker = -1
+	m.selectedSlice = -1
+	m.exportMessage = ""
+	m.transients = nil
+	m.snapTime = -1
+
+	if err := m.refreshView(); err != nil {
+		return nil, fmt.Errorf("failed to generate view: %w", err)
+	}
+	return loadTick(wf), nil
+}
+
+// switchFile saves the state of the current file and opens the file offset positions away
+func (m *model) switchFile(offset int) (tea.Cmd, error) {
+	if len(m.files) < 2 {
+		m.exportMessage = "Only one file open"
+		return nil, nil
+	}
+
+	m.sessions[m.fileIndex] = fileState{markers: m.markers, start: m.start, end: m.end}
+	m.fileIndex = (m.fileIndex + offset + len(m.files)) % len(m.files)
+	m.wavFile = m.files[m.fileIndex]
+	return m.loadFile()
+}
+
+// refreshView regenerates the waveform view (and band energies in spectral mode)
+// for the current start, end and width
+func (m *model) refreshView() error {
+	if m.waveform == nil {
+		return nil
+	}
+
+	opts := gowaveform.WaveformOptions{
+		Start:     m.start,
+		End:       m.end,
+		Width:     m.width,
+		Symmetric: m.symmetric,
+	}
+
+	// Peaks and colors are taken per drawn column, two per cell in quadrant mode
+	columnOpts := opts
+	columnOpts.Width = m.width * columnsPerCell(m.quadrant)
+
+	view, err := m.waveform.GenerateView(columnOpts)
+	if err != nil {
+		return err
+	}
+	m.currentView = view
+
+	levels, err := m.waveform.ChannelLevels(m.start, m.end)
+	if err != nil {
+		return err
+	}
+	m.levels = levels
+
+	m.spectrogram = nil
+	if m.showSpectrogram {
+		spectrogram, err := m.waveform.GenerateSpectrogram(opts, 0)
+		if err != nil {
+			return err
+		}
+		m.spectrogram = spectrogram
+	}
+
+	m.bandEnergies = nil
+	if m.spectral {
+		energies, err := m.waveform.GenerateBandEnergies(columnOpts)
+		if err != nil {
+			return err
+		}
+		m.bandEnergies = energies
+	}
+
+	return nil
+}
+
+// updateLabel handles keys while typing a marker label
+func (m model) updateLabel(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+	case tea.KeyEnter:
+		if m.selectedMarker >= 0 && m.selectedMarker < len(m.markers) {
+			m.markers[m.selectedMarker].label = strings.TrimSpace(m.labelInput)
+		}
+		m.editingLabel = false
+	case tea.KeyEsc:
+		m.editingLabel = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.labelInput); len(runes) > 0 {
+			m.labelInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeySpace:
+		m.labelInput += " "
+	case tea.KeyRunes:
+		m.labelInput += string(msg.Runes)
 	}
+	return m, nil
+}
+
+// loadTickMsg triggers a redraw while the waveform is still being decoded
+type loadTickMsg struct {
+	waveform *gowaveform.Waveform // Waveform being loaded when the tick was scheduled
+}
+
+// loadTickInterval is how often the view is refreshed while loading
+const loadTickInterval = 200 * time.Millisecond
+
+func loadTick(wf *gowaveform.Waveform) tea.Cmd {
+	return tea.Tick(loadTickInterval, func(time.Time) tea.Msg {
+		return loadTickMsg{waveform: wf}
+	})
 }
 
 func (m model) Init() tea.Cmd {
 	return nil
 }
 
+// Navigation steps, as fractions of the current view (zoomStep divides its duration)
+const (
+	jogStep     = 0.005
+	fastJogStep = 0.05
+	zoomStep    = 1.25
+)
+
+// viewState returns the visible range as a navigation state
+func (m model) viewState() gowaveform.ViewState {
+	return gowaveform.ViewState{Start: m.start, End: m.end, Width: m.width}
+}
+
+// setViewState shows the range of a navigation state, clamped to the file
+func (m *model) setViewState(v gowaveform.ViewState) {
+	v = v.ClampTo(m.totalDuration)
+	m.start, m.end = v.Start, v.End
+}
+
+// zoomCenter returns the time to zoom around: the center of the view, moved 30% of
+// the way toward the center of the selected slice so repeated zooms bring it into view
+func (m model) zoomCenter() float64 {
+	center := (m.start + m.end) / 2.0
+	if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
+		sliceCenter := (m.markers[m.selectedSlice].time + m.markers[m.selectedSlice+1].time) / 2.0
+		center += (sliceCenter - center) * 0.3
+	}
+	return center
+}
+
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -64,40 +294,68 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Load waveform on first window size message if not already loaded
 		if m.waveform == nil {
-			wf, err := gowaveform.LoadWaveform(m.wavFile)
+			cmd, err := m.loadFile()
 			if err != nil {
-				m.err = fmt.Errorf("failed to load waveform: %w", err)
+				m.err = err
 				return m, tea.Quit
 			}
-			m.waveform = wf
-
-			// Calculate total duration
-			m.totalDuration = wf.Duration()
-			m.end = m.totalDuration
+			return m, cmd
 		}
 
 		// Generate view with current width
-		if m.waveform != nil {
-			view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-				Start: m.start,
-				End:   m.end,
-				Width: m.width,
-			})
-			if err != nil {
-				m.err = fmt.Errorf("failed to generate view: %w", err)
+		if err := m.refreshView(); err != nil {
+			m.err = fmt.Errorf("failed to generate view: %w", err)
+			return m, tea.Quit
+		}
+
+		return m, nil
+
+	case loadTickMsg:
+		// Ticks for a file that is no longer shown stop here
+		if msg.waveform != m.waveform {
+			return m, nil
+		}
+
+		// Redraw with the newly decoded audio until loading finishes
+		loading := m.waveform.Loading()
+		if !loading {
+			if err := m.waveform.Wait(); err != nil {
+				m.err = fmt.Errorf("failed to load waveform: %w", err)
 				return m, tea.Quit
 			}
-			m.currentView = view
 		}
-
+		if err := m.refreshView(); err != nil {
+			m.err = fmt.Errorf("failed to generate view: %w", err)
+			return m, tea.Quit
+		}
+		if loading {
+			return m, loadTick(m.waveform)
+		}
 		return m, nil
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", "ctrl+c":
+		if m.editingLabel {
+			return m.updateLabel(msg)
+		}
+
+		action := actionForKey(msg.String())
+		if m.showHelp {
+			// Any key other than quit only closes the help overlay
+			if action == actionQuit {
+				return m, tea.Quit
+			}
+			m.showHelp = false
+			return m, nil
+		}
+
+		switch action {
+		case actionHelp:
+			m.showHelp = true
+
+		case actionQuit:
 			return m, tea.Quit
 
-		case "m", " ":
+		case actionMarker:
 			// Create new marker at midpoint of current view
 			midpoint := (m.start + m.end) / 2.0
 			m.markers = append(m.markers, marker{time: midpoint})
@@ -113,7 +371,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-		case "tab":
+		case actionNextSlice:
 			// Cycle through slices in view
 			if len(m.markers) < 2 {
 				m.selectedSlice = -1
@@ -156,7 +414,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Unselect marker when selecting slice
 			m.selectedMarker = -1
 
-		case "shift+tab":
+		case actionNextMarker:
 			// Cycle through markers in view
 			if len(m.markers) == 0 {
 				m.selectedMarker = -1
@@ -196,12 +454,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Unselect slice when selecting marker
 			m.selectedSlice = -1
 
-		case "esc":
+		case actionUnselect:
 			// Unselect marker and slice
 			m.selectedMarker = -1
 			m.selectedSlice = -1
 
-		case "d", "backspace":
+		case actionDelete:
 			// Delete selected slice or marker
 			if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
 				// Delete the start marker of the selected slice
@@ -237,49 +495,152 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// No need to re-sort, we just removed an element
 			}
 
-		case "o":
-			// Onset detection - find all onsets and create markers
-			m.exportMessage = "Running onset detection..."
-			options := onset.SliceAnalyzerOptions{
-				NumSlices:        0,     // Find all onsets
-				Method:           "hfc", // High Frequency Content method
-				Optimize:         true,  // Optimize onset positions
-				OptimizeWindowMs: 15.0,  // 15ms optimization window
+		case actionColor:
+			// Toggle frequency-colored rendering
+			m.spectral = !m.spectral
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
+			}
+
+		case actionQuadrant:
+			// Toggle between eighth blocks and quadrant blocks with twice the columns
+			m.quadrant = !m.quadrant
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
 			}
 
-			result, err := onset.AnalyzeSlices(m.wavFile, options)
+		case actionSymmetric:
+			// Toggle between true min/max and mirrored absolute peaks
+			m.symmetric = !m.symmetric
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
+			}
+
+		case actionSpectrogram:
+			// Toggle between the waveform and the spectrogram
+			m.showSpectrogram = !m.showSpectrogram
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
+			}
+
+		case actionNextFile, actionPrevFile:
+			// Switch to the next or previous file, keeping each file's markers
+			offset := 1
+			if action == actionPrevFile {
+				offset = -1
+			}
+			cmd, err := m.switchFile(offset)
+			if err != nil {
+				m.err = err
+				return m, tea.Quit
+			}
+			return m, cmd
+
+		case actionBeats:
+			// Toggle between the seconds and bars/beats ruler
+			if m.bpm > 0 {
+				m.showBeats = !m.showBeats
+			} else {
+				m.exportMessage = "Set --bpm to use the beat ruler"
+			}
+
+		case actionTimeFormat:
+			// Cycle the ruler through seconds, mm:ss.mmm, timecode and samples
+			m.timeFormat = (m.timeFormat + 1) % (gowaveform.TimeFormatSamples + 1)
+			m.showBeats = false
+			m.exportMessage = fmt.Sprintf("Ruler: %s", m.timeFormat)
+
+		case actionOnsets:
+			// Onset detection - find all onsets and create markers
+			m.exportMessage = "Running onset detection..."
+			onsets, err := detectOnsets(m.wavFile)
 			if err != nil {
 				m.exportMessage = fmt.Sprintf("Onset detection failed: %v", err)
 			} else {
+				m.transients = onsets
 				// Clear existing markers
 				m.markers = []marker{}
 				// Create markers from detected onsets
-				for _, onsetTime := range result.Onsets {
+				for _, onsetTime := range onsets {
 					m.markers = append(m.markers, marker{time: onsetTime})
 				}
-				m.exportMessage = fmt.Sprintf("Found %d onsets", len(result.Onsets))
+				m.exportMessage = fmt.Sprintf("Found %d onsets", len(onsets))
 				m.selectedMarker = -1
 				m.selectedSlice = -1
 			}
 
-		case "e":
+		case actionBeatSlices:
+			// Replace the markers with equal slices of each bar
+			if m.bpm <= 0 {
+				m.exportMessage = "Set --bpm to slice by beats"
+				break
+			}
+			var times []float64
+			if m.beatOffset != 0 {
+				times = gowaveform.BeatSlices(m.waveform.Duration(), m.bpm, m.divisions, m.beatOffset)
+			} else {
+				var err error
+				if times, err = gowaveform.SliceByBeats(m.waveform, m.bpm, m.divisions); err != nil {
+					m.exportMessage = fmt.Sprintf("Beat slicing failed: %v", err)
+					break
+				}
+			}
+			m.markers = []marker{}
+			for _, t := range times {
+				m.markers = append(m.markers, marker{time: t})
+			}
+			m.exportMessage = fmt.Sprintf("Created %d slices (%d per bar)", len(times), m.divisions)
+			m.selectedMarker = -1
+			m.selectedSlice = -1
+
+		case actionPrevTransient, actionNextTransient:
+			// Jump to the neighbouring transient instead of jogging towards it
+			dir := 1
+			if action == actionPrevTransient {
+				dir = -1
+			}
+			if err := m.snapToTransient(dir); err != nil {
+				m.err = err
+				return m, tea.Quit
+			}
+
+		case actionLabelExport:
+			// Edit the label of the selected marker
+			if m.selectedMarker >= 0 {
+				m.editingLabel = true
+				m.labelInput = m.markers[m.selectedMarker].label
+				m.exportMessage = ""
+				break
+			}
+
 			// Export slices to JSON
 			m.exportMessage = ""
 			if len(m.markers) < 2 {
 				m.exportMessage = "Need at least 2 markers to create slices"
 			} else {
-				if err := m.exportSlices(); err != nil {
+				if filename, err := m.exportSlices(); err != nil {
 					m.exportMessage = fmt.Sprintf("Export failed: %v", err)
 				} else {
-					m.exportMessage = "Slices exported to slices.json"
+					m.exportMessage = "Slices exported to " + filename
 				}
 			}
 			// Clear message after a moment (we'll just show it until next action)
 			// In a real implementation, you might want to use a tea.Tick to clear this
 
-		case "left":
-			duration := m.end - m.start
-			step := duration * 0.005 // Move 0.5% of current view
+		case actionExportImage:
+			// Save exactly what is on screen, including markers, as a PNG
+			if filename, err := m.exportImage(); err != nil {
+				m.exportMessage = fmt.Sprintf("Image export failed: %v", err)
+			} else {
+				m.exportMessage = "View saved to " + filename
+			}
+
+		case actionJogLeft:
+			step := (m.end - m.start) * jogStep
 
 			if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
 				// Jog selected slice start position (move the marker at the start of the slice)
@@ -316,33 +677,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				})
 			} else {
 				// Jog view
-				m.start -= step
-				m.end -= step
-
-				// Clamp to valid range
-				if m.start < 0 {
-					m.start = 0
-					m.end = duration
-				}
+				m.setViewState(m.viewState().Pan(-jogStep))
 
 				// Regenerate view
-				if m.waveform != nil {
-					view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-						Start: m.start,
-						End:   m.end,
-						Width: m.width,
-					})
-					if err != nil {
-						m.err = err
-						return m, tea.Quit
-					}
-					m.currentView = view
+				if err := m.refreshView(); err != nil {
+					m.err = err
+					return m, tea.Quit
 				}
 			}
 
-		case "right":
-			duration := m.end - m.start
-			step := duration * 0.005 // Move 0.5% of current view
+		case actionJogRight:
+			step := (m.end - m.start) * jogStep
 
 			if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
 				// Jog selected slice start position (move the marker at the start of the slice)
@@ -379,195 +724,53 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				})
 			} else {
 				// Jog view
-				m.start += step
-				m.end += step
-
-				// Clamp to valid range
-				if m.end > m.totalDuration {
-					m.end = m.totalDuration
-					m.start = m.end - duration
-					if m.start < 0 {
-						m.start = 0
-					}
-				}
+				m.setViewState(m.viewState().Pan(jogStep))
 
 				// Regenerate view
-				if m.waveform != nil {
-					view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-						Start: m.start,
-						End:   m.end,
-						Width: m.width,
-					})
-					if err != nil {
-						m.err = err
-						return m, tea.Quit
-					}
-					m.currentView = view
+				if err := m.refreshView(); err != nil {
+					m.err = err
+					return m, tea.Quit
 				}
 			}
 
-		case "shift+left":
+		case actionFastLeft:
 			// Shift+left always jogs the waveform (fast)
-			duration := m.end - m.start
-			step := duration * 0.05 // Move 5% of current view
-
-			m.start -= step
-			m.end -= step
-
-			// Clamp to valid range
-			if m.start < 0 {
-				m.start = 0
-				m.end = duration
-			}
+			m.setViewState(m.viewState().Pan(-fastJogStep))
 
 			// Regenerate view
-			if m.waveform != nil {
-				view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-					Start: m.start,
-					End:   m.end,
-					Width: m.width,
-				})
-				if err != nil {
-					m.err = err
-					return m, tea.Quit
-				}
-				m.currentView = view
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
 			}
 
-		case "shift+right":
+		case actionFastRight:
 			// Shift+right always jogs the waveform (fast)
-			duration := m.end - m.start
-			step := duration * 0.05 // Move 5% of current view
-
-			m.start += step
-			m.end += step
-
-			// Clamp to valid range
-			if m.end > m.totalDuration {
-				m.end = m.totalDuration
-				m.start = m.end - duration
-				if m.start < 0 {
-					m.start = 0
-				}
-			}
+			m.setViewState(m.viewState().Pan(fastJogStep))
 
 			// Regenerate view
-			if m.waveform != nil {
-				view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-					Start: m.start,
-					End:   m.end,
-					Width: m.width,
-				})
-				if err != nil {
-					m.err = err
-					return m, tea.Quit
-				}
-				m.currentView = view
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
 			}
 
-		case "up":
+		case actionZoomIn:
 			// Zoom in - make start and end closer together
-			duration := m.end - m.start
-			var center float64
-
-			// If a slice is selected, progressively align it to the center
-			if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
-				sliceStart := m.markers[m.selectedSlice].time
-				sliceEnd := m.markers[m.selectedSlice+1].time
-				sliceCenter := (sliceStart + sliceEnd) / 2.0
-				currentCenter := (m.start + m.end) / 2.0
-
-				// Progressively move toward the slice center (30% of the way each zoom)
-				center = currentCenter + (sliceCenter-currentCenter)*0.3
-			} else {
-				center = (m.start + m.end) / 2.0
-			}
-
-			newDuration := duration * 0.8 // Zoom in by 20%
-
-			m.start = center - newDuration/2.0
-			m.end = center + newDuration/2.0
-
-			// Clamp to valid range
-			if m.start < 0 {
-				m.start = 0
-				m.end = newDuration
-			}
-			if m.end > m.totalDuration {
-				m.end = m.totalDuration
-				m.start = m.end - newDuration
-				if m.start < 0 {
-					m.start = 0
-				}
-			}
+			m.setViewState(m.viewState().ZoomAt(zoomStep, m.zoomCenter()))
 
 			// Regenerate view
-			if m.waveform != nil {
-				view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-					Start: m.start,
-					End:   m.end,
-					Width: m.width,
-				})
-				if err != nil {
-					m.err = err
-					return m, tea.Quit
-				}
-				m.currentView = view
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
 			}
 
-		case "down":
+		case actionZoomOut:
 			// Zoom out - make start and end further apart
-			duration := m.end - m.start
-			var center float64
-
-			// If a slice is selected, progressively align it to the center
-			if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
-				sliceStart := m.markers[m.selectedSlice].time
-				sliceEnd := m.markers[m.selectedSlice+1].time
-				sliceCenter := (sliceStart + sliceEnd) / 2.0
-				currentCenter := (m.start + m.end) / 2.0
-
-				// Progressively move toward the slice center (30% of the way each zoom)
-				center = currentCenter + (sliceCenter-currentCenter)*0.3
-			} else {
-				center = (m.start + m.end) / 2.0
-			}
-
-			newDuration := duration * 1.25 // Zoom out by 25%
-
-			// Don't zoom out beyond total duration
-			if newDuration > m.totalDuration {
-				newDuration = m.totalDuration
-			}
-
-			m.start = center - newDuration/2.0
-			m.end = center + newDuration/2.0
-
-			// Clamp to valid range
-			if m.start < 0 {
-				m.start = 0
-				m.end = newDuration
-			}
-			if m.end > m.totalDuration {
-				m.end = m.totalDuration
-				m.start = m.end - newDuration
-				if m.start < 0 {
-					m.start = 0
-				}
-			}
+			m.setViewState(m.viewState().ZoomAt(1/zoomStep, m.zoomCenter()))
 
 			// Regenerate view
-			if m.waveform != nil {
-				view, err := m.waveform.GenerateView(gowaveform.WaveformOptions{
-					Start: m.start,
-					End:   m.end,
-					Width: m.width,
-				})
-				if err != nil {
-					m.err = err
-					return m, tea.Quit
-				}
-				m.currentView = view
+			if err := m.refreshView(); err != nil {
+				m.err = err
+				return m, tea.Quit
 			}
 		}
 	}
@@ -584,43 +787,100 @@ func (m model) View() string {
 		return "Loading waveform...\n"
 	}
 
+	if m.showHelp {
+		return renderHelp(m.width, m.height)
+	}
+
 	var sb strings.Builder
 
-	// Draw the waveform
-	waveformStr := renderWaveform(m.currentView, m.width, m.height-6, m.start, m.end, m.markers, m.selectedMarker, m.selectedSlice)
-	sb.WriteString(waveformStr)
+	// Everything below is laid out on the range the view was generated for
+	start, end := m.currentView.Start, m.currentView.End
+
+	// Marker labels above the waveform
+	sb.WriteString(renderMarkerLabels(m.markers, m.selectedMarker, m.width, start, end))
+	sb.WriteString("\n")
+
+	// Draw the waveform or the spectrogram
+	if m.showSpectrogram {
+		sb.WriteString(renderSpectrogram(m.spectrogram, m.width, m.height-6, start, end, m.markers, m.selectedMarker))
+	} else {
+		waveformStr := renderWaveform(m.currentView, m.bandEnergies, m.width, m.height-6, m.quadrant, m.markers, m.selectedMarker, m.selectedSlice)
+		sb.WriteString(waveformStr)
+	}
+
+	// Add the ruler below the waveform
+	if m.showBeats {
+		sb.WriteString(generateBeatRuler(m.width, start, end, m.bpm, m.beatOffset))
+	} else {
+		sb.WriteString(generateTimestampRuler(m.width, start, end, m.timeFormat, m.waveform.SampleRate, m.frameRate))
+	}
+	sb.WriteString("\n")
+
+	// Level meter for the visible range
+	sb.WriteString(renderMeter(m.levels))
 	sb.WriteString("\n")
 
 	// Display information
-	sb.WriteString(fmt.Sprintf("File: %s | Duration: %.2fs | Markers: %d",
-		m.wavFile, m.totalDuration, len(m.markers)))
+	sb.WriteString(fmt.Sprintf("File: %s", m.wavFile))
+	if len(m.files) > 1 {
+		sb.WriteString(fmt.Sprintf(" (%d/%d)", m.fileIndex+1, len(m.files)))
+	}
+	sb.WriteString(fmt.Sprintf(" | Duration: %.2fs | Markers: %d", m.totalDuration, len(m.markers)))
 	if m.selectedMarker >= 0 {
 		sb.WriteString(fmt.Sprintf(" | Selected Marker: %.3fs", m.markers[m.selectedMarker].time))
+		sb.WriteString(m.markerReadout(m.markers[m.selectedMarker].time))
+	}
+	if m.selectedSlice >= 0 && m.selectedSlice < len(m.markers)-1 {
+		sb.WriteString(m.selectionReadout(m.markers[m.selectedSlice].time, m.markers[m.selectedSlice+1].time))
+	}
+	if m.waveform.Loading() {
+		sb.WriteString(fmt.Sprintf(" | Loading %.0f%%", m.waveform.LoadedFraction()*100))
 	}
 	if m.exportMessage != "" {
 		sb.WriteString(fmt.Sprintf(" | %s", m.exportMessage))
 	}
 	sb.WriteString("\n")
-	sb.WriteString("Controls: m/Space (marker) | o (onset detect) | Tab (slice) | Shift+Tab (marker) | d/Backspace (delete) | e (export) | Esc (unselect) | ← → (jog) | Shift+← → (fast) | ↑ ↓ (zoom) | q (quit)\n")
+	if m.editingLabel {
+		sb.WriteString(fmt.Sprintf("Label: %s█ (Enter to save, Esc to cancel)\n", m.labelInput))
+		return sb.String()
+	}
+	sb.WriteString("Press ? for key bindings, q to quit\n")
 
 	return sb.String()
 }
 
+// columnsPerCell returns how many waveform columns are drawn in each terminal cell
+func columnsPerCell(quadrant bool) int {
+	if quadrant {
+		return 2
+	}
+	return 1
+}
+
 // renderWaveform renders the waveform data as high-resolution art using Unicode block characters
-func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end float64, markers []marker, selectedMarker int, selectedSlice int) string {
+// If band energies are given, each column is colored by its dominant frequency band.
+// In quadrant mode each cell holds two columns drawn with quadrant blocks, doubling the
+// horizontal resolution at the cost of vertical resolution (2 instead of 8 levels per cell).
+// Markers and the selected slice are placed on the time range the view covers.
+func renderWaveform(data *gowaveform.WaveformData, energies []gowaveform.BandEnergy, width, height int, quadrant bool, markers []marker, selectedMarker int, selectedSlice int) string {
 	if data == nil || len(data.Data) == 0 {
 		return "No waveform data"
 	}
 
-	// Use 8 vertical segments per character for higher resolution
-	// This means we multiply height by 8 for our internal grid
-	const segmentsPerChar = 8
+	// Use 8 vertical segments per character for higher resolution, or 2x2 in quadrant mode
+	// This means we multiply height by the segments for our internal grid
+	segmentsPerChar := 8
+	if quadrant {
+		segmentsPerChar = 2
+	}
+	cols := columnsPerCell(quadrant)
 	virtualHeight := height * segmentsPerChar
+	virtualWidth := width * cols
 
-	// Create a higher resolution grid (8 segments per character height)
+	// Create a higher resolution grid
 	grid := make([][]bool, virtualHeight)
 	for i := range grid {
-		grid[i] = make([]bool, width)
+		grid[i] = make([]bool, virtualWidth)
 	}
 
 	// Find the maximum absolute value for normalization
@@ -642,7 +902,7 @@ func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end
 	}
 
 	// Plot each min/max pair
-	for i := 0; i < len(data.Data)/2 && i < width; i++ {
+	for i := 0; i < len(data.Data)/2 && i < virtualWidth; i++ {
 		minVal := data.Data[i*2]
 		maxVal := data.Data[i*2+1]
 
@@ -678,24 +938,11 @@ func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end
 	}
 
 	// Calculate marker positions in pixels
-	markerPositions := make(map[int]bool) // x positions of all markers
-	selectedMarkerPos := -1               // x position of selected marker
-	selectedSliceRange := [2]int{-1, -1}  // x range of selected slice [start, end]
+	start, end := data.Start, data.End
+	markerPositions, selectedMarkerPos := markerColumns(markers, selectedMarker, width, start, end)
+	selectedSliceRange := [2]int{-1, -1} // x range of selected slice [start, end]
 	duration := end - start
 
-	for i, mrk := range markers {
-		if mrk.time >= start && mrk.time <= end {
-			// Calculate x position
-			xPos := int(float64(width-1) * (mrk.time - start) / duration)
-			if xPos >= 0 && xPos < width {
-				markerPositions[xPos] = true
-				if i == selectedMarker {
-					selectedMarkerPos = xPos
-				}
-			}
-		}
-	}
-
 	// Calculate selected slice range
 	if selectedSlice >= 0 && selectedSlice < len(markers)-1 {
 		sliceStart := markers[selectedSlice].time
@@ -737,7 +984,9 @@ func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end
 		for x := 0; x < width; x++ {
 			// Determine if we're in upper or lower half
 			var char string
-			if y < centerY {
+			if quadrant {
+				char = getQuadrantChar(grid, x, y)
+			} else if y < centerY {
 				// Upper half: use lower blocks inverted (hanging from top of cell)
 				char = getUpperHalfChar(grid, x, y, segmentsPerChar)
 			} else {
@@ -755,6 +1004,9 @@ func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end
 				sb.WriteString(colorYellow + char + colorReset)
 			} else if inSelectedSlice {
 				sb.WriteString(colorGreen + char + colorReset)
+			} else if x*cols < len(energies) && char != " " {
+				c := energies[x*cols].Color()
+				sb.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm", c.R, c.G, c.B) + char + colorReset)
 			} else {
 				sb.WriteString(char)
 			}
@@ -762,8 +1014,201 @@ func renderWaveform(data *gowaveform.WaveformData, width, height int, start, end
 		sb.WriteString("\n")
 	}
 
-	// Add timestamp ruler
-	sb.WriteString(generateTimestampRuler(width, start, end))
+	return sb.String()
+}
+
+// Level meter layout
+const (
+	meterFloor = -60.0 // Lowest level shown on the meter in dBFS
+	meterWidth = 20    // Width of each channel's bar in characters
+)
+
+// channelName returns a short label for a channel: M for mono, L/R for stereo, numbers otherwise
+func channelName(channel, channels int) string {
+	switch {
+	case channels == 1:
+		return "M"
+	case channels == 2 && channel == 0:
+		return "L"
+	case channels == 2:
+		return "R"
+	default:
+		return fmt.Sprintf("%d", channel+1)
+	}
+}
+
+// formatDBFS formats a level in dBFS with one decimal, or -inf for silence
+func formatDBFS(db float64) string {
+	if math.IsInf(db, -1) {
+		return "-inf"
+	}
+	return fmt.Sprintf("%.1f", db)
+}
+
+// renderMeter renders a peak/RMS meter per channel. The solid part of each bar
+// is the RMS level and the shaded part extends to the peak.
+func renderMeter(levels []gowaveform.ChannelLevel) string {
+	var sb strings.Builder
+	for ch, level := range levels {
+		if ch > 0 {
+			sb.WriteString("  ")
+		}
+
+		rmsCells := meterCells(level.RMSDBFS())
+		peakCells := meterCells(level.PeakDBFS())
+		bar := strings.Repeat("█", rmsCells) + strings.Repeat("▒", peakCells-rmsCells) + strings.Repeat("░", meterWidth-peakCells)
+
+		sb.WriteString(fmt.Sprintf("%s %s peak %s rms %s dBFS", channelName(ch, len(levels)), bar,
+			formatDBFS(level.PeakDBFS()), formatDBFS(level.RMSDBFS())))
+		if level.Peak >= 32767.0/32768.0 {
+			sb.WriteString(" \033[31mCLIP\033[0m")
+		}
+	}
+	return sb.String()
+}
+
+// meterCells returns how many meter cells a level in dBFS fills
+func meterCells(db float64) int {
+	if db <= meterFloor {
+		return 0
+	}
+	cells := int(math.Round(float64(meterWidth) * (db - meterFloor) / -meterFloor))
+	if cells > meterWidth {
+		cells = meterWidth
+	}
+	return cells
+}
+
+// markerReadout returns the sample value and level of each channel at a marker
+func (m model) markerReadout(t float64) string {
+	var sb strings.Builder
+	for ch := 0; ch < m.waveform.Channels; ch++ {
+		value, err := m.waveform.SampleAtTime(t, ch)
+		if err != nil {
+			return ""
+		}
+		level := gowaveform.ToDBFS(math.Abs(float64(value)) / 32768.0)
+		sb.WriteString(fmt.Sprintf(" %s %d (%s dBFS)", channelName(ch, m.waveform.Channels), value, formatDBFS(level)))
+	}
+	return sb.String()
+}
+
+// selectionReadout returns the duration and levels of the selected slice
+func (m model) selectionReadout(start, end float64) string {
+	stats, err := m.waveform.StatsRange(min(start, end), max(start, end))
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf(" | Selection: %.3fs peak %s rms %s dBFS", stats.Duration, formatDBFS(stats.PeakDBFS()), formatDBFS(stats.RMSDBFS()))
+}
+
+// renderMarkerLabels places the labels of visible markers at their x positions.
+// Labels that would overlap an earlier label are skipped; the selected marker is highlighted.
+func renderMarkerLabels(markers []marker, selectedMarker, width int, start, end float64) string {
+	line := []rune(strings.Repeat(" ", width))
+	selectedFrom, selectedTo := -1, -1
+	nextFree := 0
+	duration := end - start
+
+	for i, mrk := range markers {
+		if mrk.label == "" || mrk.time < start || mrk.time > end {
+			continue
+		}
+		label := []rune(mrk.label)
+		if len(label) > width {
+			label = label[:width]
+		}
+
+		x := int(float64(width-1) * (mrk.time - start) / duration)
+		if x+len(label) > width {
+			x = width - len(label)
+		}
+		if x < nextFree {
+			continue
+		}
+
+		copy(line[x:], label)
+		if i == selectedMarker {
+			selectedFrom, selectedTo = x, x+len(label)
+		}
+		nextFree = x + len(label) + 1
+	}
+
+	if selectedFrom < 0 {
+		return string(line)
+	}
+	return string(line[:selectedFrom]) + "\033[36m" + string(line[selectedFrom:selectedTo]) + "\033[0m" + string(line[selectedTo:])
+}
+
+// markerColumns returns the x positions of all visible markers and the
+// x position of the selected marker (-1 if it is not visible)
+func markerColumns(markers []marker, selectedMarker, width int, start, end float64) (map[int]bool, int) {
+	positions := make(map[int]bool)
+	selectedPos := -1
+	duration := end - start
+
+	for i, mrk := range markers {
+		if mrk.time >= start && mrk.time <= end {
+			xPos := int(float64(width-1) * (mrk.time - start) / duration)
+			if xPos >= 0 && xPos < width {
+				positions[xPos] = true
+				if i == selectedMarker {
+					selectedPos = xPos
+				}
+			}
+		}
+	}
+
+	return positions, selectedPos
+}
+
+// spectrogramMinFrequency is the lowest frequency shown in the spectrogram view
+const spectrogramMinFrequency = 30.0
+
+// renderSpectrogram renders a spectrogram with truecolor half blocks, two frequency rows
+// per character, on a logarithmic frequency axis from spectrogramMinFrequency to Nyquist.
+// Markers are drawn as vertical lines over the spectrum.
+func renderSpectrogram(s *gowaveform.Spectrogram, width, height int, start, end float64, markers []marker, selectedMarker int) string {
+	if s == nil || len(s.Columns) == 0 {
+		return "No waveform data"
+	}
+
+	markerPositions, selectedMarkerPos := markerColumns(markers, selectedMarker, width, start, end)
+
+	// Frequency band edges for each half-character row, bottom to top
+	rows := height * 2
+	nyquist := float64(s.SampleRate) / 2
+	edges := make([]float64, rows+1)
+	for k := range edges {
+		edges[k] = spectrogramMinFrequency * math.Pow(nyquist/spectrogramMinFrequency, float64(k)/float64(rows))
+	}
+
+	var sb strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x == selectedMarkerPos {
+				sb.WriteString("\033[36m│\033[0m")
+				continue
+			}
+			if markerPositions[x] {
+				sb.WriteString("\033[33m│\033[0m")
+				continue
+			}
+			if x >= len(s.Columns) {
+				sb.WriteString(" ")
+				continue
+			}
+
+			// Row k counts up from the bottom of the view
+			upper := rows - 1 - 2*y
+			lower := upper - 1
+			top := gowaveform.SpectrogramColor(s.Level(x, edges[upper], edges[upper+1]))
+			bottom := gowaveform.SpectrogramColor(s.Level(x, edges[lower], edges[lower+1]))
+			sb.WriteString(fmt.Sprintf("\033[38;2;%d;%d;%dm\033[48;2;%d;%d;%dm▀\033[0m",
+				top.R, top.G, top.B, bottom.R, bottom.G, bottom.B))
+		}
+		sb.WriteString("\n")
+	}
 
 	return sb.String()
 }
@@ -860,18 +1305,37 @@ func getLowerHalfChar(grid [][]bool, x, y, segmentsPerChar int) string {
 	}
 }
 
+// quadrantChars holds the quadrant block for each combination of filled quarters,
+// indexed by upper left (1), upper right (2), lower left (4) and lower right (8)
+var quadrantChars = [16]string{" ", "▘", "▝", "▀", "▖", "▌", "▞", "▛", "▗", "▚", "▐", "▜", "▄", "▙", "▟", "█"}
+
+// getQuadrantChar returns the quadrant block for the 2x2 grid segments of a cell
+func getQuadrantChar(grid [][]bool, x, y int) string {
+	index := 0
+	for bit, seg := range [4][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+		segY, segX := y*2+seg[0], x*2+seg[1]
+		if segY < len(grid) && grid[segY][segX] {
+			index |= 1 << bit
+		}
+	}
+	return quadrantChars[index]
+}
+
 // Slice represents a segment of audio between two markers
 type Slice struct {
-	Index     int     `json:"index"`
-	StartTime float64 `json:"start_time"`
-	EndTime   float64 `json:"end_time"`
-	Duration  float64 `json:"duration"`
+	Index      int     `json:"index"`
+	StartTime  float64 `json:"start_time"`
+	EndTime    float64 `json:"end_time"`
+	Duration   float64 `json:"duration"`
+	StartLabel string  `json:"start_label,omitempty"` // Label of the marker that starts the slice
+	EndLabel   string  `json:"end_label,omitempty"`   // Label of the marker that ends the slice
 }
 
-// exportSlices exports the slices created by markers to a JSON file
-func (m *model) exportSlices() error {
+// exportSlices exports the slices created by markers to a JSON file and returns its name.
+// With several files open, each file gets its own export named after it.
+func (m *model) exportSlices() (string, error) {
 	if len(m.markers) < 2 {
-		return fmt.Errorf("need at least 2 markers to create slices")
+		return "", fmt.Errorf("need at least 2 markers to create slices")
 	}
 
 	// Sort markers to ensure they're in time order
@@ -887,30 +1351,170 @@ func (m *model) exportSlices() error {
 		startTime := sortedMarkers[i].time
 		endTime := sortedMarkers[i+1].time
 		slices = append(slices, Slice{
-			Index:     i,
-			StartTime: startTime,
-			EndTime:   endTime,
-			Duration:  endTime - startTime,
+			Index:      i,
+			StartTime:  startTime,
+			EndTime:    endTime,
+			Duration:   endTime - startTime,
+			StartLabel: sortedMarkers[i].label,
+			EndLabel:   sortedMarkers[i+1].label,
 		})
 	}
 
 	// Marshal to JSON with indentation
 	jsonData, err := json.MarshalIndent(slices, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
 	// Write to file
 	filename := "slices.json"
+	if len(m.files) > 1 {
+		base := filepath.Base(m.wavFile)
+		filename = strings.TrimSuffix(base, filepath.Ext(base)) + ".slices.json"
+	}
 	if err := os.WriteFile(filename, jsonData, 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return "", fmt.Errorf("failed to write file: %w", err)
 	}
 
-	return nil
+	return filename, nil
+}
+
+// detectOnsets runs onset detection on a file and returns the sorted onset times in seconds
+func detectOnsets(filename string) ([]float64, error) {
+	options := onset.SliceAnalyzerOptions{
+		NumSlices:        0,     // Find all onsets
+		Method:           "hfc", // High Frequency Content method
+		Optimize:         true,  // Optimize onset positions
+		OptimizeWindowMs: 15.0,  // 15ms optimization window
+	}
+
+	result, err := onset.AnalyzeSlices(filename, options)
+	if err != nil {
+		return nil, err
+	}
+
+	onsets := append([]float64{}, result.Onsets...)
+	sort.Float64s(onsets)
+	return onsets, nil
+}
+
+// adjacentTransient returns the first transient after t (dir > 0) or the last one before it (dir < 0)
+func adjacentTransient(transients []float64, t float64, dir int) (float64, bool) {
+	const epsilon = 1e-6 // Skip a transient the position already sits on
+
+	if dir > 0 {
+		i := sort.SearchFloat64s(transients, t+epsilon)
+		if i < len(transients) {
+			return transients[i], true
+		}
+		return 0, false
+	}
+
+	i := sort.SearchFloat64s(transients, t-epsilon)
+	if i > 0 {
+		return transients[i-1], true
+	}
+	return 0, false
+}
+
+// snapToTransient moves the selected marker to the previous (dir < 0) or next (dir > 0)
+// transient, or centers the view on it when no marker is selected
+func (m *model) snapToTransient(dir int) error {
+	if m.transients == nil {
+		onsets, err := detectOnsets(m.wavFile)
+		if err != nil {
+			m.exportMessage = fmt.Sprintf("Onset detection failed: %v", err)
+			return nil
+		}
+		m.transients = onsets
+	}
+
+	// Step from the selected marker, else from the last transient snapped to while it
+	// is still in view (the view cannot always center on it), else from the center
+	hasMarker := m.selectedMarker >= 0 && m.selectedMarker < len(m.markers)
+	from := (m.start + m.end) / 2.0
+	if hasMarker {
+		from = m.markers[m.selectedMarker].time
+	} else if m.snapTime >= m.start && m.snapTime <= m.end {
+		from = m.snapTime
+	}
+
+	t, ok := adjacentTransient(m.transients, from, dir)
+	if !ok {
+		m.exportMessage = "No more transients"
+		return nil
+	}
+	m.exportMessage = fmt.Sprintf("Transient at %.3fs", t)
+
+	if hasMarker {
+		// Move the marker, keeping it selected after re-sorting
+		moved := m.markers[m.selectedMarker]
+		moved.time = t
+		m.markers = append(m.markers[:m.selectedMarker], m.markers[m.selectedMarker+1:]...)
+		i := sort.Search(len(m.markers), func(i int) bool { return m.markers[i].time >= t })
+		m.markers = append(m.markers[:i], append([]marker{moved}, m.markers[i:]...)...)
+		m.selectedMarker = i
+
+		if t >= m.start && t <= m.end {
+			return nil
+		}
+	}
+
+	// Center the view on the transient, keeping the zoom level
+	if !hasMarker {
+		m.snapTime = t
+	}
+	m.setViewState(m.viewState().ZoomAt(1, t))
+	return m.refreshView()
+}
+
+// Pixels per terminal cell in exported images, so the image keeps the proportions of the screen
+const (
+	imageCellWidth  = 8
+	imageCellHeight = 16
+)
+
+// exportImage saves the current view with its markers to a timestamped PNG in the working directory
+func (m *model) exportImage() (string, error) {
+	markers := make([]gowaveform.Marker, len(m.markers))
+	for i, mrk := range m.markers {
+		markers[i] = gowaveform.Marker{Time: mrk.time, Label: mrk.label}
+	}
+
+	base := filepath.Base(m.wavFile)
+	filename := fmt.Sprintf("%s-%s.png", strings.TrimSuffix(base, filepath.Ext(base)), time.Now().Format("20060102-150405"))
+
+	// Match the waveform area, which leaves six lines for the labels, ruler and status
+	rows := m.height - 6
+	if rows < 1 {
+		rows = 1
+	}
+
+	opts := []gowaveform.Option{
+		gowaveform.OptionSetWidth(m.width * imageCellWidth),
+		gowaveform.OptionSetHeight(rows * imageCellHeight),
+		gowaveform.OptionSetStart(m.start),
+		gowaveform.OptionSetEnd(m.end),
+		gowaveform.OptionSetTitle(base),
+		gowaveform.OptionSpectral(m.spectral),
+		gowaveform.OptionSymmetric(m.symmetric),
+		gowaveform.OptionTimeFormat(m.timeFormat),
+		gowaveform.OptionSetFrameRate(m.frameRate),
+		gowaveform.OptionMarkers(markers),
+	}
+	if m.showBeats {
+		opts = append(opts, gowaveform.OptionBeatGrid(m.bpm, m.beatOffset))
+	}
+
+	if err := gowaveform.SavePlot(m.waveform, filename, opts...); err != nil {
+		return "", err
+	}
+	return filename, nil
 }
 
 // generateTimestampRuler creates a timestamp ruler below the waveform
-func generateTimestampRuler(width int, start, end float64) string {
+// Labels use the given time format; seconds get a precision that suits the zoom level
+func generateTimestampRuler(width int, start, end float64, format gowaveform.TimeFormat, sampleRate int, frameRate float64) string {
 	duration := end - start
 
 	// Determine the precision based on the duration
@@ -968,7 +1572,9 @@ func generateTimestampRuler(width int, start, end float64) string {
 
 			// Format timestamp based on precision
 			var label string
-			if precision == 0 {
+			if format != gowaveform.TimeFormatSeconds {
+				label = gowaveform.FormatTime(time, format, sampleRate, frameRate)
+			} else if precision == 0 {
 				label = fmt.Sprintf("%.0f", time)
 			} else {
 				label = fmt.Sprintf("%.*f", precision, time)
@@ -987,7 +1593,17 @@ func generateTimestampRuler(width int, start, end float64) string {
 		labelLine[i] = ' '
 	}
 
-	for pos, label := range timestamps {
+	// Place labels left to right so longer formats can skip labels that would overlap
+	positions := make([]int, 0, len(timestamps))
+	for pos := range timestamps {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+
+	nextFree := 0 // First column available for the next label
+	for _, pos := range positions {
+		label := timestamps[pos]
+
 		// Center the label on the tick mark
 		startPos := pos - len(label)/2
 		if startPos < 0 {
@@ -996,6 +1612,10 @@ func generateTimestampRuler(width int, start, end float64) string {
 		if startPos+len(label) > width {
 			startPos = width - len(label)
 		}
+		if startPos < nextFree {
+			continue
+		}
+		nextFree = startPos + len(label) + 1
 
 		// Write label
 		for i, ch := range label {
@@ -1011,24 +1631,109 @@ func generateTimestampRuler(width int, start, end float64) string {
 	return sb.String()
 }
 
+// generateBeatRuler creates a bars/beats ruler below the waveform for the given tempo
+func generateBeatRuler(width int, start, end, bpm, offset float64) string {
+	duration := end - start
+	beats := gowaveform.BeatGrid(start, end, bpm, offset)
+
+	tickLine := make([]rune, width)
+	labelLine := make([]rune, width)
+	for i := range tickLine {
+		tickLine[i] = ' '
+		labelLine[i] = ' '
+	}
+
+	// Only label individual beats when there is room for them
+	labelBeats := len(beats) > 0 && width/len(beats) >= 5
+
+	nextFree := 0 // First column available for the next label
+	for _, b := range beats {
+		pos := int(float64(width-1) * (b.Time - start) / duration)
+		if pos < 0 || pos >= width {
+			continue
+		}
+
+		// Bar lines take precedence when beats share a column
+		if b.IsDownbeat() {
+			tickLine[pos] = '|'
+		} else if tickLine[pos] != '|' {
+			tickLine[pos] = '\''
+		}
+
+		if !b.IsDownbeat() && !labelBeats {
+			continue
+		}
+
+		// Skip labels that would overlap the previous one
+		label := b.Label()
+		if pos < nextFree || pos+len(label) > width {
+			continue
+		}
+		for i, ch := range label {
+			labelLine[pos+i] = ch
+		}
+		nextFree = pos + len(label) + 1
+	}
+
+	var sb strings.Builder
+	sb.WriteString(string(tickLine))
+	sb.WriteString("\n")
+	sb.WriteString(string(labelLine))
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
 var (
 	outputFile      string
 	plotWidth       int
 	plotHeight      int
+	plotDPI         int
 	backgroundColor string
 	foregroundColor string
+	fontFile        string
+	fontSize        float64
+	labelColor      string
+	tickColor       string
 	noTimestamp     bool
 	hideYAxis       bool
 	hideXAxis       bool
+	sparkline       bool
+	antialias       bool
 	plotTitle       string
 	startTime       float64
 	endTime         float64
 	zoomDuration    float64
+	centerTime      float64
 	resolution      float64
+	spectral        bool
+	symmetric       bool
+	highPass        float64
+	lowPass         float64
+	bpm             float64
+	beatOffset      float64
+	divisions       int
+	timeFormatName  string
+	frameRate       float64
+	xTickInterval   float64
+	showRMS         bool
+	rmsColor        string
+	samplesPerPixel int
+	compressName    string
+	compactJSON     bool
+	jsonMetadata    bool
+	jsonTimeAxis    bool
+	floatJSON       bool
+	formatVersion   int
+	ffmpegFallback  bool
+	ditherName      string
+	maxSampleRate   int
+	loadMono        bool
+	deterministic   bool
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "gowaveform [file]",
+	Use:   "gowaveform [file...]",
 	Short: "Interactive WAV file waveform viewer",
 	Long: `gowaveform is an interactive terminal-based waveform viewer for WAV files.
 Navigate, zoom, and place markers in your audio files with an intuitive interface.
@@ -1043,6 +1748,9 @@ You can also generate waveform plots directly to image files using the --output
 	Example: `  # View a WAV file interactively
   gowaveform audio.wav
 
+  # Review a folder of stems, switching files with n/p
+  gowaveform stems/*.wav
+
   # Generate a waveform plot to a PNG file
   gowaveform audio.wav --output waveform.png
 
@@ -1067,26 +1775,69 @@ You can also generate waveform plots directly to image files using the --output
   # Generate a zoomed plot centered on the middle (5 seconds total duration)
   gowaveform audio.wav --output waveform.png --zoom 5.0
 
+  # Generate a plot of the 2 seconds around 30.0
+  gowaveform audio.wav --output waveform.png --center 30.0 --zoom 2.0
+
   # Generate a plot with half resolution (400px wide, but drawn with 200px of waveform data)
   gowaveform audio.wav --output waveform.png --width 400 --resolution 0.5
 
   # Generate a plot with double resolution for more detail
-  gowaveform audio.wav --output waveform.png --width 800 --resolution 2.0`,
-	Args: cobra.ExactArgs(1),
+  gowaveform audio.wav --output waveform.png --width 800 --resolution 2.0
+
+  # Generate a plot with a 170 BPM beat grid labeled in bars and beats
+  gowaveform audio.wav --output waveform.png --bpm 170
+
+  # Generate a plot with SMPTE timecode labels at 25 fps
+  gowaveform audio.wav --output waveform.png --time-format timecode --fps 25
+
+  # Stack stems as panels sharing one time axis
+  gowaveform drums.wav bass.wav keys.wav vox.wav --output stems.png
+
+  # Generate a frequency-colored plot (low = red, mid = green, high = blue)
+  gowaveform audio.wav --output waveform.png --spectral
+
+  # Export waveform data as gzip-compressed JSON
+  gowaveform audio.wav --output waveform.json.gz --samples-per-pixel 256`,
+	Args: validArgs(cobra.MinimumNArgs(1)),
 	Run: func(cmd *cobra.Command, args []string) {
 		wavFile := args[0]
 
-		// Check if file exists
-		if _, err := os.Stat(wavFile); os.IsNotExist(err) {
-			fmt.Fprintf(os.Stderr, "Error: File not found: %s\n", wavFile)
-			os.Exit(1)
+		// Check if files exist
+		for _, file := range args {
+			if err := checkInputFile(file); err != nil {
+				fail(err)
+			}
+		}
+
+		if isJSONOutput(outputFile) && len(args) > 1 {
+			fail(withExitCode(exitInvalidOptions, fmt.Errorf("JSON output takes a single input file")))
+		}
+
+		timeFormat, err := gowaveform.ParseTimeFormat(timeFormatName)
+		if err != nil {
+			fail(withExitCode(exitInvalidOptions, err))
+		}
+
+		// JSON output is selected by a .json extension, optionally followed by .gz or .zst
+		if isJSONOutput(outputFile) {
+			if err := generateJSON(wavFile, outputFile); err != nil {
+				fail(fmt.Errorf("failed to generate JSON: %w", err))
+			}
+			fmt.Printf("Waveform data saved to: %s\n", outputFile)
+			return
 		}
 
 		// If output file is specified, run in plot mode
 		if outputFile != "" {
-			if err := generatePlot(wavFile, outputFile); err != nil {
-				fmt.Fprintf(os.Stderr, "Error generating plot: %v\n", err)
-				os.Exit(1)
+			if err := checkImageOutput(outputFile); err != nil {
+				fail(err)
+			}
+			// Panels of several files are 150 pixels high unless a height is given
+			if len(args) > 1 && !cmd.Flags().Changed("height") {
+				plotHeight = 0
+			}
+			if err := generatePlot(args, outputFile, timeFormat); err != nil {
+				fail(fmt.Errorf("failed to generate plot: %w", err))
 			}
 			fmt.Printf("Waveform plot saved to: %s\n", outputFile)
 			return
@@ -1094,13 +1845,12 @@ You can also generate waveform plots directly to image files using the --output
 
 		// Otherwise, run interactive TUI
 		p := tea.NewProgram(
-			initialModel(wavFile),
+			initialModel(args, spectral, symmetric, bpm, beatOffset, divisions, timeFormat, frameRate),
 			tea.WithAltScreen(),
 		)
 
 		if _, err := p.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			fail(err)
 		}
 	},
 }
@@ -1113,33 +1863,65 @@ var versionCmd = &cobra.Command{
 	},
 }
 
-// generatePlot creates a waveform plot and saves it to a file
-func generatePlot(wavFile, outputFile string) error {
-	// Load the waveform
-	waveform, err := gowaveform.LoadWaveform(wavFile)
-	if err != nil {
-		return fmt.Errorf("failed to load waveform: %w", err)
+// generatePlot creates a waveform plot and saves it to a file. Several files are
+// stacked as panels sharing one time axis.
+func generatePlot(files []string, outputFile string, timeFormat gowaveform.TimeFormat) error {
+	// Load the waveforms
+	waveforms := make([]*gowaveform.Waveform, len(files))
+	for i, file := range files {
+		waveform, err := gowaveform.LoadWaveform(file, loadOptions()...)
+		if err != nil {
+			return withExitCode(exitDecodeError, fmt.Errorf("failed to load waveform %s: %w", file, err))
+		}
+		waveforms[i] = waveform
 	}
 
 	// Build options list
 	var opts []gowaveform.Option
-	
+
 	if plotWidth > 0 {
 		opts = append(opts, gowaveform.OptionSetWidth(plotWidth))
 	}
-	
+
 	if plotHeight > 0 {
 		opts = append(opts, gowaveform.OptionSetHeight(plotHeight))
 	}
-	
+
+	if plotDPI > 0 {
+		opts = append(opts, gowaveform.OptionSetDPI(plotDPI))
+	}
+
 	if backgroundColor != "" {
 		opts = append(opts, gowaveform.OptionSetBackgroundColor(backgroundColor))
 	}
-	
+
 	if foregroundColor != "" {
 		opts = append(opts, gowaveform.OptionSetForegroundColor(foregroundColor))
 	}
-	
+
+	if fontFile != "" {
+		if err := checkInputFile(fontFile); err != nil {
+			return err
+		}
+		ttf, err := os.ReadFile(fontFile)
+		if err != nil {
+			return withExitCode(exitDecodeError, fmt.Errorf("failed to read font: %w", err))
+		}
+		opts = append(opts, gowaveform.OptionSetFont(ttf))
+	}
+
+	if fontSize > 0 {
+		opts = append(opts, gowaveform.OptionSetFontSize(fontSize))
+	}
+
+	if labelColor != "" {
+		opts = append(opts, gowaveform.OptionSetLabelColor(labelColor))
+	}
+
+	if tickColor != "" {
+		opts = append(opts, gowaveform.OptionSetTickColor(tickColor))
+	}
+
 	if noTimestamp {
 		opts = append(opts, gowaveform.OptionShowTimestamp(false))
 	}
@@ -1152,59 +1934,290 @@ func generatePlot(wavFile, outputFile string) error {
 		opts = append(opts, gowaveform.OptionHideXAxis(true))
 	}
 
+	if sparkline {
+		opts = append(opts, gowaveform.OptionSparkline(true))
+	}
+
+	if antialias {
+		opts = append(opts, gowaveform.OptionAntialias(true))
+	}
+
 	if plotTitle != "" {
 		opts = append(opts, gowaveform.OptionSetTitle(plotTitle))
 	}
 
+	if spectral {
+		opts = append(opts, gowaveform.OptionSpectral(true))
+	}
+
+	if symmetric {
+		opts = append(opts, gowaveform.OptionSymmetric(true))
+	}
+
+	if filter := viewFilter(); filter != nil {
+		opts = append(opts, gowaveform.OptionFilter(filter))
+	}
+
+	if deterministic {
+		opts = append(opts, gowaveform.OptionDeterministic(true))
+	}
+
+	if bpm > 0 {
+		opts = append(opts, gowaveform.OptionBeatGrid(bpm, beatOffset))
+	}
+
+	if timeFormat != gowaveform.TimeFormatSeconds {
+		opts = append(opts, gowaveform.OptionTimeFormat(timeFormat), gowaveform.OptionSetFrameRate(frameRate))
+	}
+
+	if showRMS {
+		opts = append(opts, gowaveform.OptionShowRMS(true))
+		if rmsColor != "" {
+			opts = append(opts, gowaveform.OptionSetRMSColor(rmsColor))
+		}
+	}
+
+	if xTickInterval > 0 {
+		opts = append(opts, gowaveform.OptionXTickInterval(xTickInterval))
+	}
+
 	if resolution != 1.0 && resolution > 0 {
 		opts = append(opts, gowaveform.OptionSetResolution(resolution))
 	}
 
-	// Handle start/end/zoom options
+	// Handle start/end/zoom/center options; the plot rejects more than two of them
 	if zoomDuration > 0 {
 		opts = append(opts, gowaveform.OptionSetZoom(zoomDuration))
-		if startTime > 0 {
-			opts = append(opts, gowaveform.OptionSetStart(startTime))
-		}
-	} else {
-		if startTime > 0 {
-			opts = append(opts, gowaveform.OptionSetStart(startTime))
-		}
-		if endTime > 0 {
-			opts = append(opts, gowaveform.OptionSetEnd(endTime))
-		}
+	}
+	if startTime > 0 {
+		opts = append(opts, gowaveform.OptionSetStart(startTime))
+	}
+	if endTime > 0 {
+		opts = append(opts, gowaveform.OptionSetEnd(endTime))
+	}
+	if centerTime > 0 {
+		opts = append(opts, gowaveform.OptionSetCenter(centerTime))
 	}
 
 	// Save the plot
-	if err := gowaveform.SavePlot(waveform, outputFile, opts...); err != nil {
-		return fmt.Errorf("failed to save plot: %w", err)
+	if len(waveforms) > 1 {
+		if err := gowaveform.SaveMultiPlot(waveforms, outputFile, opts...); err != nil {
+			return plotError(fmt.Errorf("failed to save plot: %w", err))
+		}
+		return nil
+	}
+	if err := gowaveform.SavePlot(waveforms[0], outputFile, opts...); err != nil {
+		return plotError(fmt.Errorf("failed to save plot: %w", err))
 	}
 
 	return nil
 }
 
+// isJSONOutput reports whether the output file should be written as waveform JSON
+func isJSONOutput(filename string) bool {
+	name := strings.ToLower(filename)
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".zst")
+	return strings.HasSuffix(name, ".json")
+}
+
+// loadOptions adds the load options selected by global flags to opts
+func loadOptions(opts ...gowaveform.LoadOption) []gowaveform.LoadOption {
+	if ffmpegFallback {
+		opts = append(opts, gowaveform.OptionFFmpegFallback())
+	}
+	dither, err := gowaveform.ParseDither(ditherName)
+	if err != nil {
+		fail(withExitCode(exitInvalidOptions, err))
+	}
+	if dither != gowaveform.DitherNone {
+		opts = append(opts, gowaveform.OptionDither(dither))
+	}
+	if maxSampleRate > 0 {
+		opts = append(opts, gowaveform.OptionMaxSampleRate(maxSampleRate))
+	}
+	if loadMono {
+		opts = append(opts, gowaveform.OptionLoadMono(true))
+	}
+	return opts
+}
+
+// outputCompression parses a --compress value, inferring the codec from a .gz or .zst
+// extension of outputFile when none is given
+func outputCompression(name, outputFile string) (gowaveform.Compression, error) {
+	codec, err := gowaveform.ParseCompression(name)
+	if err != nil {
+		return codec, err
+	}
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(outputFile)) {
+		case ".gz":
+			codec = gowaveform.CompressionGzip
+		case ".zst":
+			codec = gowaveform.CompressionZstd
+		}
+	}
+	return codec, nil
+}
+
+// generateJSON writes waveform data as JSON, compressed if requested by --compress
+// or inferred from a .gz or .zst extension
+func generateJSON(wavFile, outputFile string) error {
+	codec, err := outputCompression(compressName, outputFile)
+	if err != nil {
+		return withExitCode(exitInvalidOptions, err)
+	}
+
+	if showRMS && floatJSON {
+		return withExitCode(exitInvalidOptions, fmt.Errorf("--rms cannot be combined with --float"))
+	}
+
+	opts := gowaveform.WaveformOptions{
+		Width:           plotWidth,
+		SamplesPerPixel: samplesPerPixel,
+		Start:           startTime,
+		End:             endTime,
+		Metadata:        jsonMetadata,
+		TimeAxis:        jsonTimeAxis,
+		FormatVersion:   formatVersion,
+		Symmetric:       symmetric,
+		Filter:          viewFilter(),
+	}
+	if samplesPerPixel > 0 {
+		// Width takes precedence over SamplesPerPixel, so drop it when a zoom level is given
+		opts.Width = 0
+	}
+
+	waveform, err := gowaveform.LoadWaveform(wavFile, loadOptions()...)
+	if err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to load waveform: %w", err))
+	}
+
+	// Peaks and RMS levels are generated in one pass when both are wanted
+	var data *gowaveform.WaveformData
+	var extended *gowaveform.WaveformDataExtended
+	if showRMS {
+		extended, err = waveform.GenerateViewExtended(opts)
+	} else {
+		data, err = waveform.GenerateView(opts)
+	}
+	if err != nil {
+		// The audio is decoded, so what remains to fail are the view options
+		return withExitCode(exitInvalidOptions, fmt.Errorf("failed to generate waveform data: %w", err))
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to create JSON file: %w", err))
+	}
+	defer f.Close()
+
+	zw, err := gowaveform.NewCompressWriter(f, codec)
+	if err != nil {
+		return withExitCode(exitWriteError, err)
+	}
+
+	// Stream straight to disk so long files at full resolution are never held as JSON in memory
+	switch {
+	case showRMS:
+		err = encodeJSON(zw, extended)
+	case floatJSON:
+		err = encodeJSON(zw, data.Float())
+	case compactJSON:
+		err = gowaveform.WriteJSONCompact(zw, data)
+	default:
+		err = gowaveform.WriteJSON(zw, data)
+	}
+	if err != nil {
+		zw.Close()
+		return withExitCode(exitWriteError, err)
+	}
+	if err := zw.Close(); err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to compress JSON file: %w", err))
+	}
+
+	return withExitCode(exitWriteError, f.Close())
+}
+
+// encodeJSON writes v as JSON, indented unless --compact is set
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	if !compactJSON {
+		enc.SetIndent("", "  ")
+	}
+	return enc.Encode(v)
+}
+
 func init() {
 	rootCmd.AddCommand(versionCmd)
 
+	rootCmd.PersistentFlags().BoolVar(&ffmpegFallback, "ffmpeg", false, "Decode formats the built-in decoders cannot read with ffmpeg (must be installed)")
+	rootCmd.PersistentFlags().StringVar(&ditherName, "dither", "none", "Dither 24- and 32-bit audio reduced to 16 bits on load: none, tpdf or shaped (TPDF with noise shaping)")
+	rootCmd.PersistentFlags().IntVar(&maxSampleRate, "max-rate", 0, "Resample audio above this rate in Hz after loading to save memory (0 = keep the file rate)")
+	rootCmd.PersistentFlags().BoolVar(&loadMono, "mono", false, "Mix the channels down to one while loading to save memory")
+	rootCmd.PersistentFlags().BoolVar(&jsonErrors, "json-errors", false, "Print errors to stderr as JSON objects with an error type and exit code")
+
 	// Add flags for plot generation
-	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for waveform plot (PNG or JPEG)")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file for waveform plot (PNG or JPEG) or waveform data (.json, .json.gz, .json.zst)")
 	rootCmd.Flags().IntVar(&plotWidth, "width", 800, "Width of the plot in pixels")
 	rootCmd.Flags().IntVar(&plotHeight, "height", 400, "Height of the plot in pixels")
+	rootCmd.Flags().IntVar(&plotDPI, "dpi", gowaveform.DefaultDPI, "Resolution in dots per inch; text and lines scale with it while the size in pixels stays the same (e.g. 192 for 2x)")
 	rootCmd.Flags().StringVar(&backgroundColor, "bg-color", "", "Background color in hex format (e.g., #FFFFFF)")
 	rootCmd.Flags().StringVar(&foregroundColor, "fg-color", "", "Foreground/waveform color in hex format (e.g., #0064C8)")
+	rootCmd.Flags().StringVar(&fontFile, "font", "", "TrueType or OpenType font file for the title and axis text")
+	rootCmd.Flags().Float64Var(&fontSize, "font-size", 0, "Size in points of the title and axis labels (default: 12; tick labels are drawn at 10/12 of it)")
+	rootCmd.Flags().StringVar(&labelColor, "label-color", "", "Title and axis label color in hex format (e.g., #333333)")
+	rootCmd.Flags().StringVar(&tickColor, "tick-color", "", "Tick label, tick mark and axis line color in hex format (e.g., #888888)")
 	rootCmd.Flags().BoolVar(&noTimestamp, "no-timestamp", false, "Disable timestamp axis on the plot")
 	rootCmd.Flags().BoolVar(&hideYAxis, "hide-y-axis", false, "Hide the y-axis (amplitude) on the plot")
 	rootCmd.Flags().BoolVar(&hideXAxis, "hide-x-axis", false, "Hide the x-axis (time) on the plot")
+	rootCmd.Flags().BoolVar(&sparkline, "sparkline", false, "Fill the whole image with the waveform, without title, axes or padding")
+	rootCmd.Flags().BoolVar(&antialias, "antialias", false, "Draw smooth waveform edges in sparklines and other axis-free plots")
 	rootCmd.Flags().StringVar(&plotTitle, "title", "", "Set the title for the plot")
 	rootCmd.Flags().Float64Var(&startTime, "start", 0, "Start time in seconds (default: 0)")
 	rootCmd.Flags().Float64Var(&endTime, "end", 0, "End time in seconds (default: full duration)")
-	rootCmd.Flags().Float64Var(&zoomDuration, "zoom", 0, "Duration in seconds to display (overrides end if start is set)")
+	rootCmd.Flags().Float64Var(&zoomDuration, "zoom", 0, "Duration in seconds to display (from --start, up to --end or around --center)")
+	rootCmd.Flags().Float64Var(&centerTime, "center", 0, "Time in seconds at the center of the view (with --zoom, --start or --end)")
 	rootCmd.Flags().Float64Var(&resolution, "resolution", 1.0, "Resolution multiplier for waveform generation (1.0 = full, 0.5 = half, 2.0 = double)")
+	rootCmd.Flags().Float64Var(&bpm, "bpm", 0, "Tempo in BPM for a bars/beats grid and ruler (0 = seconds)")
+	rootCmd.Flags().Float64Var(&beatOffset, "beat-offset", 0, "Time in seconds of the first downbeat for the beat grid")
+	rootCmd.Flags().IntVar(&divisions, "divisions", 16, "Slices per bar created with the g key in the viewer")
+	rootCmd.Flags().StringVar(&timeFormatName, "time-format", "seconds", "Time label format: seconds, minutes (mm:ss.mmm), timecode (SMPTE) or samples")
+	rootCmd.Flags().Float64Var(&frameRate, "fps", gowaveform.DefaultFrameRate, "Frame rate for SMPTE timecode labels")
+	rootCmd.Flags().Float64Var(&xTickInterval, "x-tick-interval", 0, "Place a time axis tick every N seconds (0 = automatic)")
+	rootCmd.Flags().BoolVar(&showRMS, "rms", false, "Draw the RMS level inside the peak envelope, or add an rms array to JSON output")
+	rootCmd.Flags().StringVar(&rmsColor, "rms-color", "", "RMS color in hex format (e.g., #5AAAFF)")
+	rootCmd.Flags().IntVar(&samplesPerPixel, "samples-per-pixel", 0, "Samples per pixel for JSON output (0 = derive from --width)")
+	rootCmd.Flags().StringVar(&compressName, "compress", "", "Compress JSON output with gzip or zstd (default: inferred from .gz/.zst extension)")
+	rootCmd.Flags().BoolVar(&jsonMetadata, "metadata", false, "Add a metadata block (source, duration, timestamp, version, options) to JSON output")
+	rootCmd.Flags().BoolVar(&jsonTimeAxis, "time-axis", false, "Add a time_axis block (start, end and the start time of each pixel in seconds) to JSON output")
+	rootCmd.Flags().IntVar(&formatVersion, "format-version", 2, "audiowaveform JSON format version (1 or 2)")
+	rootCmd.Flags().BoolVar(&floatJSON, "float", false, "Write JSON peaks as normalized floats (-1.0 to 1.0)")
+	rootCmd.Flags().BoolVar(&compactJSON, "compact", false, "Write JSON output without indentation")
+	rootCmd.Flags().BoolVar(&spectral, "spectral", false, "Color the waveform by dominant frequency band (low = red, mid = green, high = blue)")
+	rootCmd.Flags().BoolVar(&symmetric, "symmetric", false, "Mirror the absolute peak of each pixel around zero instead of drawing true min/max")
+	rootCmd.Flags().Float64Var(&highPass, "highpass", 0, "Remove frequencies below this cutoff in Hz before taking the peaks (0 = off)")
+	rootCmd.Flags().Float64Var(&lowPass, "lowpass", 0, "Remove frequencies above this cutoff in Hz before taking the peaks (0 = off)")
+	rootCmd.Flags().BoolVar(&deterministic, "deterministic", false, "Render byte-identical images for identical input and options (pinned fonts, rounded axis labels)")
+}
+
+// viewFilter returns the filter set with --highpass and --lowpass, or nil
+func viewFilter() *gowaveform.Filter {
+	if highPass <= 0 && lowPass <= 0 {
+		return nil
+	}
+	return gowaveform.BandPass(highPass, lowPass)
 }
 
 func main() {
+	// Errors are printed by fail, as text or JSON
+	rootCmd.SilenceErrors = true
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(exitInvalidOptions, err)
+	})
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		fail(err)
+	}
+	if err := stopProfiling(); err != nil {
+		fail(withExitCode(exitWriteError, err))
 	}
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runCLIEnv makes the test binary run the command instead of the tests
+const runCLIEnv = "GOWAVEFORM_RUN_CLI"
+
+// rampWAV is the one second WAV fixture shared with the gowaveform tests
+var rampWAV, _ = filepath.Abs("../../testdata/ramp.wav")
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runCLIEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runCLI runs gowaveform with args in dir and returns what it printed and its exit
+// code. The command runs in a child process because it exits on errors.
+func runCLI(t *testing.T, dir string, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runCLIEnv+"=1")
+	var out, errOut bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("Running gowaveform failed: %v", err)
+	}
+	return out.String(), errOut.String(), code
+}
+
+// copyRamp copies the WAV fixture to name in dir, creating directories as needed, and
+// returns its path
+func copyRamp(t *testing.T, dir, name string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := copyFile(path, rampWAV); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPlotAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "ramp.wav")
+
+	if _, stderr, code := runCLI(t, dir, "ramp.wav", "-o", "ramp.png", "--width", "200", "--height", "50"); code != 0 {
+		t.Fatalf("Plot failed with %d: %s", code, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ramp.png")); err != nil {
+		t.Errorf("Expected a plot: %v", err)
+	}
+
+	if _, stderr, code := runCLI(t, dir, "ramp.wav", "-o", "ramp.json", "--samples-per-pixel", "100"); code != 0 {
+		t.Fatalf("JSON failed with %d: %s", code, stderr)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ramp.json"))
+	if err != nil || !bytes.Contains(data, []byte(`"length": 80`)) {
+		t.Errorf("Expected 80 pixels of JSON, got %v: %.200s", err, data)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// manifest describes the results of a batch run, for build steps that consume them
+type manifest struct {
+	Files []manifestEntry `json:"files"`
+}
+
+// manifestEntry describes one input file of a batch run
+type manifestEntry struct {
+	Input       string           `json:"input"`
+	InputSHA256 string           `json:"input_sha256,omitempty"`
+	Duration    float64          `json:"duration,omitempty"`   // Seconds
+	Outputs     []manifestOutput `json:"outputs,omitempty"`    // Files written or kept for the input
+	Render      *renderOptions   `json:"render,omitempty"`     // Options the outputs were rendered with
+	Skipped     bool             `json:"skipped,omitempty"`    // Outputs were up to date and kept
+	Error       string           `json:"error,omitempty"`      // Why the input failed
+	ExitCode    int              `json:"exit_code,omitempty"`  // Exit code of the error
+	ErrorType   string           `json:"error_type,omitempty"` // Name of the exit code, as in --json-errors
+}
+
+// manifestOutput is one file written for an input
+type manifestOutput struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// renderOptions are the thumbnail options of a batch run. They are recorded in the
+// manifest so a later run with other options renders the thumbnails again.
+type renderOptions struct {
+	Theme  string `json:"theme"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// setError records err as the failure of e
+func (e *manifestEntry) setError(err error) {
+	code := exitCode(err)
+	e.Error, e.ExitCode, e.ErrorType = err.Error(), code, errorTypes[code]
+}
+
+// addOutput records the file at path, with its checksum, as an output of e
+func (e *manifestEntry) addOutput(path string) error {
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	e.Outputs = append(e.Outputs, manifestOutput{Path: path, SHA256: sum})
+	return nil
+}
+
+// readManifest reads the manifest of an earlier run, returning its entries by input.
+// A missing file gives no entries.
+func readManifest(filename string) (map[string]manifestEntry, error) {
+	entries := map[string]manifestEntry{}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return entries, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	for _, entry := range m.Files {
+		entries[entry.Input] = entry
+	}
+	return entries, nil
+}
+
+// writeManifest writes m to filename, replacing it only once it is complete
+func writeManifest(filename string, m manifest) error {
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to write manifest: %w", err)
+		}
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := os.Rename(tmp, filename); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// fileSHA256 returns the hex SHA-256 checksum of the file at path
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
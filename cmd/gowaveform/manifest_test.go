@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "out", "manifest.json")
+
+	// A missing manifest has no entries
+	entries, err := readManifest(filename)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Expected no entries, got %v, %v", entries, err)
+	}
+
+	output := copyRamp(t, dir, "thumb.png")
+	ok := manifestEntry{Input: "a.wav", InputSHA256: "abc", Duration: 1.5}
+	if err := ok.addOutput(output); err != nil {
+		t.Fatal(err)
+	}
+	failed := manifestEntry{Input: "b.wav"}
+	failed.setError(withExitCode(exitDecodeError, errors.New("bad header")))
+	if failed.ExitCode != exitDecodeError || failed.ErrorType != "decode_error" || failed.Error != "bad header" {
+		t.Errorf("Unexpected error entry %+v", failed)
+	}
+
+	if err := writeManifest(filename, manifest{Files: []manifestEntry{ok, failed}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the temporary file to be gone, got %v", err)
+	}
+	entries, err = readManifest(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(entries, map[string]manifestEntry{"a.wav": ok, "b.wav": failed}) {
+		t.Errorf("Entries changed in the round trip: %+v", entries)
+	}
+	// Checksums are hex SHA-256
+	if sum := ok.Outputs[0].SHA256; len(sum) != 64 {
+		t.Errorf("Expected a hex SHA-256, got %q", sum)
+	}
+
+	os.WriteFile(filename, []byte("{"), 0644)
+	if _, err := readManifest(filename); err == nil {
+		t.Error("Expected an error for a corrupt manifest")
+	}
+}
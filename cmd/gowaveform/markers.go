@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the markers command
+var (
+	markersInput  string
+	markersOutput string
+	markersFormat string
+	markersInject string
+)
+
+var markersCmd = &cobra.Command{
+	Use:   "markers",
+	Short: "List, export or inject the cue points and loops of a WAV file",
+	Long: `List the cue points (with their labels) and sampler loops embedded in a WAV file.
+With --format the markers are exported as JSON or as an Audacity label track
+(File > Import > Labels), to stdout or the file given with --output.
+
+With --inject, markers are read from a JSON file in the same format as the JSON
+export and written into a copy of the input saved to --output. Existing cue
+points, labels and loops are replaced; the audio is copied unchanged. Markers
+without an "id" are numbered automatically.`,
+	Example: `  # List the markers of a file
+  gowaveform markers -i audio.wav
+
+  # Export the markers as Audacity labels
+  gowaveform markers -i audio.wav --format audacity -o labels.txt
+
+  # Edit the markers as JSON and write them into a new file
+  gowaveform markers -i audio.wav --format json -o markers.json
+  gowaveform markers -i audio.wav --inject markers.json -o marked.wav`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(markersInput); err != nil {
+			fail(err)
+		}
+
+		if markersInject != "" {
+			if err := injectMarkers(); err != nil {
+				fail(fmt.Errorf("failed to inject markers: %w", err))
+			}
+			fmt.Printf("WAV file with markers saved to: %s\n", markersOutput)
+			return
+		}
+
+		if err := exportMarkers(); err != nil {
+			fail(fmt.Errorf("failed to export markers: %w", err))
+		}
+	},
+}
+
+// exportMarkers prints or saves the markers of the input in the format given by --format
+func exportMarkers() error {
+	f, err := os.Open(markersInput)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	markers, err := gowaveform.ReadWAVMarkers(f)
+	if err != nil {
+		return withExitCode(exitDecodeError, err)
+	}
+
+	var buf bytes.Buffer
+	switch markersFormat {
+	case "text":
+		printMarkers(&buf, markersInput, markers)
+	case "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(markers); err != nil {
+			return err
+		}
+	case "audacity":
+		if err := markers.WriteAudacityLabels(&buf); err != nil {
+			return err
+		}
+	default:
+		return withExitCode(exitInvalidOptions, fmt.Errorf("unknown format: %s (supported: text, json, audacity)", markersFormat))
+	}
+
+	if markersOutput == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return withExitCode(exitWriteError, err)
+	}
+	if err := os.WriteFile(markersOutput, buf.Bytes(), 0644); err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to write file: %w", err))
+	}
+	fmt.Printf("Markers saved to: %s\n", markersOutput)
+	return nil
+}
+
+// injectMarkers writes a copy of the input with the markers of the --inject JSON file
+func injectMarkers() error {
+	if markersOutput == "" {
+		return withExitCode(exitInvalidOptions, fmt.Errorf("--inject needs an output WAV file (--output)"))
+	}
+	if markersOutput == markersInput {
+		return withExitCode(exitInvalidOptions, fmt.Errorf("output must differ from the input file"))
+	}
+
+	data, err := os.ReadFile(markersInject)
+	if err != nil {
+		return err
+	}
+	var markers gowaveform.WAVMarkers
+	if err := json.Unmarshal(data, &markers); err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to parse %s: %w", markersInject, err))
+	}
+
+	in, err := os.Open(markersInput)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(markersOutput)
+	if err != nil {
+		return withExitCode(exitWriteError, fmt.Errorf("failed to create output file: %w", err))
+	}
+	defer out.Close()
+
+	if err := gowaveform.WriteWAVMarkers(out, in, &markers); err != nil {
+		return withExitCode(exitWriteError, err)
+	}
+	return withExitCode(exitWriteError, out.Close())
+}
+
+// printMarkers writes a human-readable list of markers
+func printMarkers(w io.Writer, filename string, m *gowaveform.WAVMarkers) {
+	fmt.Fprintf(w, "File:         %s\n", filename)
+	if len(m.Cues) == 0 {
+		fmt.Fprintf(w, "Cue points:   none\n")
+	} else {
+		fmt.Fprintf(w, "Cue points:   %d\n", len(m.Cues))
+		for _, cue := range m.Cues {
+			fmt.Fprintf(w, "  #%-4d %10.3fs  %s\n", cue.ID, cue.Time, cue.Label)
+		}
+	}
+	if len(m.Loops) == 0 {
+		fmt.Fprintf(w, "Loops:        none\n")
+		return
+	}
+	fmt.Fprintf(w, "Loops:        %d\n", len(m.Loops))
+	for _, loop := range m.Loops {
+		count := "forever"
+		if loop.PlayCount > 0 {
+			count = fmt.Sprintf("%dx", loop.PlayCount)
+		}
+		fmt.Fprintf(w, "  #%-4d %10.3fs - %.3fs (%s)  %s\n", loop.ID, loop.Start, loop.End, count, loop.Label)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(markersCmd)
+
+	markersCmd.Flags().StringVarP(&markersInput, "input", "i", "", "Input WAV file")
+	markersCmd.Flags().StringVarP(&markersOutput, "output", "o", "", "Output file for the export (default: stdout), or the WAV file to write with --inject")
+	markersCmd.Flags().StringVar(&markersFormat, "format", "text", "Export format: text, json or audacity")
+	markersCmd.Flags().StringVar(&markersInject, "inject", "", "JSON file of markers to write into a copy of the input (requires --output)")
+	markersCmd.MarkFlagRequired("input")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/schollz/gowaveform"
+)
+
+func TestMarkers(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "ramp.wav")
+	want := gowaveform.WAVMarkers{
+		Cues:  []gowaveform.CuePoint{{ID: 1, Time: 0.25, Label: "Verse"}, {ID: 2, Time: 0.5}},
+		Loops: []gowaveform.LoopPoint{},
+	}
+	data, _ := json.Marshal(want)
+	os.WriteFile(filepath.Join(dir, "markers.json"), data, 0644)
+
+	if _, stderr, code := runCLI(t, dir, "markers", "-i", "ramp.wav", "--inject", "markers.json", "-o", "marked.wav"); code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	stdout, stderr, code := runCLI(t, dir, "markers", "-i", "marked.wav", "--format", "json")
+	if code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	var got gowaveform.WAVMarkers
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", stdout, err)
+	}
+	if len(got.Cues) != 2 || got.Cues[0].Label != "Verse" || got.Cues[1].Time != 0.5 {
+		t.Errorf("Expected the injected cues back, got %+v", got.Cues)
+	}
+
+	// The audio is unchanged
+	if _, stderr, code := runCLI(t, dir, "compare", "ramp.wav", "marked.wav", "--json"); code != 0 {
+		t.Errorf("Expected the marked copy to load, got %d: %s", code, stderr)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"markers", "-i", "ramp.wav", "--inject", "missing.json", "-o", "x.wav"}, exitFileNotFound},
+		{[]string{"markers", "-i", "ramp.wav", "--inject", "markers.json"}, exitInvalidOptions},
+		{[]string{"markers", "-i", "ramp.wav", "--inject", "markers.json", "-o", "ramp.wav"}, exitInvalidOptions},
+		{[]string{"markers", "-i", "ramp.wav", "-o", filepath.Join("missing", "x.txt")}, exitWriteError},
+	} {
+		if _, stderr, code := runCLI(t, dir, tc.args...); code != tc.code {
+			t.Errorf("%v: expected exit code %d, got %d: %s", tc.args, tc.code, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/schollz/gowaveform"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the plot-all command
+var (
+	plotAllInput    string
+	plotAllOutput   string
+	plotAllWidth    int
+	plotAllHeight   int
+	plotAllTheme    string
+	plotAllWorkers  int
+	plotAllForce    bool
+	plotAllManifest string
+)
+
+// plotTheme is a named set of thumbnail and page colors
+type plotTheme struct {
+	background string
+	foreground string // Waveform color
+	text       string // Text color of HTML pages
+}
+
+// plotThemes are the themes selectable with --theme
+var plotThemes = map[string]plotTheme{
+	"light": {background: "#FFFFFF", foreground: "#0064C8", text: "#222222"},
+	"dark":  {background: "#1E1E1E", foreground: "#5AAAFF", text: "#DDDDDD"},
+}
+
+var plotAllCmd = &cobra.Command{
+	Use:   "plot-all",
+	Short: "Render a thumbnail PNG of every audio file in a directory",
+	Long: `Render a sparkline thumbnail PNG of every audio file under the input directory,
+several files at once. The directory layout is mirrored in the output directory, so
+dir/album/track.wav becomes thumbs/album/track.wav.png. The audio extension is kept so
+track.wav and track.flac get a thumbnail each.
+
+Thumbnails that are newer than their audio file are skipped, which makes repeated
+runs from a build script cheap; use --force to render all of them again, such as
+after changing --theme, --width or --height.
+
+With --manifest, a JSON manifest lists every input with its SHA-256 checksum,
+duration, thumbnail (with its checksum), the theme and size it was rendered with and
+any error, for build steps that consume the results. A thumbnail is then only
+skipped if the previous manifest records the same input checksum, theme and size, so
+changed options render it again and the durations of skipped files are carried over.`,
+	Example: `  # Render dark 600x120 thumbnails for a static site
+  gowaveform plot-all -i audio/ -o thumbs/ --width 600 --height 120 --theme dark
+
+  # Render everything again with two workers
+  gowaveform plot-all -i audio/ -o thumbs/ --force --workers 2
+
+  # Write a manifest for the site generator
+  gowaveform plot-all -i audio/ -o thumbs/ --manifest thumbs/manifest.json`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(plotAllInput); err != nil {
+			fail(err)
+		}
+
+		if err := plotAll(); err != nil {
+			fail(err)
+		}
+	},
+}
+
+// plotAllJob is one audio file and the thumbnail rendered from it
+type plotAllJob struct {
+	input    string
+	output   string
+	upToDate bool // The thumbnail exists and is newer than the input
+}
+
+// plotAll renders the thumbnails described by the plot-all command flags
+func plotAll() error {
+	theme, err := lookupTheme(plotAllTheme)
+	if err != nil {
+		return err
+	}
+	opts, err := thumbnailOptions(theme, plotAllWidth, plotAllHeight, plotAllWorkers)
+	if err != nil {
+		return err
+	}
+
+	jobs, err := findPlotJobs(plotAllInput, plotAllOutput, plotAllOutput)
+	if err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to list input files: %w", err))
+	}
+	render := renderOptions{Theme: plotAllTheme, Width: plotAllWidth, Height: plotAllHeight}
+	var previous map[string]manifestEntry
+	if plotAllManifest != "" {
+		if previous, err = readManifest(plotAllManifest); err != nil {
+			return withExitCode(exitDecodeError, err)
+		}
+	}
+
+	// Skip up-to-date thumbnails; with a manifest, only those whose input and options
+	// are unchanged since the last run, so every entry has its duration
+	entries := make([]manifestEntry, len(jobs))
+	errs := make([]error, len(jobs))
+	var pending []int
+	for i, job := range jobs {
+		entries[i].Input = job.input
+		if plotAllManifest != "" {
+			sum, err := fileSHA256(job.input)
+			if err != nil {
+				errs[i] = withExitCode(exitDecodeError, fmt.Errorf("failed to read input: %w", err))
+				continue
+			}
+			entries[i].InputSHA256 = sum
+		}
+		if job.upToDate && !plotAllForce {
+			prev, ok := previous[job.input]
+			if plotAllManifest == "" || (ok && prev.Error == "" && prev.InputSHA256 == entries[i].InputSHA256 &&
+				prev.Render != nil && *prev.Render == render) {
+				entries[i].Duration, entries[i].Skipped = prev.Duration, true
+				continue
+			}
+		}
+		pending = append(pending, i)
+	}
+
+	for i, result := range renderThumbnails(jobs, pending, plotAllWorkers, opts) {
+		if result != nil {
+			entries[i].Duration, errs[i] = result.duration, result.err
+		}
+	}
+
+	var firstErr error
+	rendered, skipped, failed := 0, 0, 0
+	for i := range entries {
+		if errs[i] == nil && plotAllManifest != "" {
+			if err := entries[i].addOutput(jobs[i].output); err != nil {
+				errs[i] = withExitCode(exitWriteError, fmt.Errorf("failed to read output: %w", err))
+			} else {
+				entries[i].Render = &render
+			}
+		}
+		switch {
+		case errs[i] != nil:
+			entries[i].setError(errs[i])
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			failed++
+		case entries[i].Skipped:
+			skipped++
+		default:
+			rendered++
+		}
+	}
+
+	fmt.Printf("Rendered %d thumbnails, skipped %d up to date, %d failed\n", rendered, skipped, failed)
+	if plotAllManifest != "" {
+		if err := writeManifest(plotAllManifest, manifest{Files: entries}); err != nil {
+			return withExitCode(exitWriteError, err)
+		}
+		fmt.Printf("Manifest saved to: %s\n", plotAllManifest)
+	}
+	if failed > 0 {
+		return withExitCode(exitCode(firstErr), fmt.Errorf("failed to render %d of %d thumbnails", failed, len(jobs)))
+	}
+	return nil
+}
+
+// findPlotJobs lists the audio files under inputDir, in lexical order, with their
+// thumbnails in outputDir. A thumbnail is named after the whole input file name, so
+// inputs that differ only in their extension do not share one. Directories in skipDirs other than inputDir itself are not
+// searched, so output written inside the input directory, such as copied audio, is
+// not picked up as input.
+func findPlotJobs(inputDir, outputDir string, skipDirs ...string) ([]plotAllJob, error) {
+	extensions := map[string]bool{}
+	for _, ext := range gowaveform.DefaultLibraryExtensions {
+		extensions[ext] = true
+	}
+	skip := map[string]bool{}
+	for _, dir := range skipDirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		skip[abs] = true
+	}
+
+	var jobs []plotAllJob
+	err := filepath.WalkDir(inputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && skip[abs] && path != inputDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !extensions[strings.ToLower(filepath.Ext(path))] {
+			return nil
+		}
+		rel, err := filepath.Rel(inputDir, path)
+		if err != nil {
+			return err
+		}
+		job := plotAllJob{
+			input:  path,
+			output: filepath.Join(outputDir, rel+".png"),
+		}
+		job.upToDate = thumbnailUpToDate(job)
+		jobs = append(jobs, job)
+		return nil
+	})
+	return jobs, err
+}
+
+// thumbnailUpToDate reports whether the thumbnail of job exists and is newer than its input
+func thumbnailUpToDate(job plotAllJob) bool {
+	in, err := os.Stat(job.input)
+	if err != nil {
+		return false
+	}
+	out, err := os.Stat(job.output)
+	if err != nil {
+		return false
+	}
+	return out.ModTime().After(in.ModTime())
+}
+
+// thumbnailResult describes the audio a thumbnail was rendered from
+type thumbnailResult struct {
+	duration      float64 // Seconds
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	err           error // Why the thumbnail could not be rendered
+}
+
+// thumbnailOptions checks the thumbnail flags shared by the batch commands and returns
+// the plot options of a sparkline thumbnail in the colors of theme
+func thumbnailOptions(theme plotTheme, width, height, workers int) ([]gowaveform.Option, error) {
+	switch {
+	case width <= 0 || height <= 0:
+		return nil, withExitCode(exitInvalidOptions, fmt.Errorf("invalid thumbnail size: %dx%d", width, height))
+	case workers <= 0:
+		return nil, withExitCode(exitInvalidOptions, fmt.Errorf("invalid number of workers: %d", workers))
+	}
+	return []gowaveform.Option{
+		gowaveform.OptionSparkline(true),
+		gowaveform.OptionSetWidth(width),
+		gowaveform.OptionSetHeight(height),
+		gowaveform.OptionSetBackgroundColor(theme.background),
+		gowaveform.OptionSetForegroundColor(theme.foreground),
+	}, nil
+}
+
+// renderThumbnails renders the thumbnails of the jobs at the indices in render with a
+// pool of workers, printing each as it is done. The results are indexed like jobs and
+// nil for jobs that were not rendered.
+func renderThumbnails(jobs []plotAllJob, render []int, workers int, opts []gowaveform.Option) []*thumbnailResult {
+	loadOpts := loadOptions()
+	results := make([]*thumbnailResult, len(jobs))
+
+	var mu sync.Mutex // Serializes output
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, max(len(render), 1)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				result := plotThumbnail(jobs[i], loadOpts, opts)
+				results[i] = result
+				mu.Lock()
+				if result.err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %s: %v\n", jobs[i].input, result.err)
+				} else {
+					fmt.Printf("Thumbnail saved to: %s\n", jobs[i].output)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, i := range render {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// plotThumbnail loads the input of job and saves its thumbnail, creating the output
+// directory as needed
+func plotThumbnail(job plotAllJob, loadOpts []gowaveform.LoadOption, opts []gowaveform.Option) *thumbnailResult {
+	waveform, err := gowaveform.LoadWaveform(job.input, loadOpts...)
+	if err != nil {
+		return &thumbnailResult{err: withExitCode(exitDecodeError, fmt.Errorf("failed to load waveform: %w", err))}
+	}
+	result := &thumbnailResult{
+		duration:      waveform.Duration(),
+		sampleRate:    waveform.SampleRate,
+		channels:      waveform.Channels,
+		bitsPerSample: waveform.BitsPerSample,
+	}
+	if err := os.MkdirAll(filepath.Dir(job.output), 0755); err != nil {
+		result.err = withExitCode(exitWriteError, fmt.Errorf("failed to create output directory: %w", err))
+	} else if err := gowaveform.SavePlot(waveform, job.output, opts...); err != nil {
+		result.err = withExitCode(exitWriteError, fmt.Errorf("failed to save plot: %w", err))
+	}
+	return result
+}
+
+// lookupTheme returns the theme with the given name
+func lookupTheme(name string) (plotTheme, error) {
+	theme, ok := plotThemes[name]
+	if !ok {
+		return plotTheme{}, withExitCode(exitInvalidOptions, fmt.Errorf("unknown theme: %s (supported: %s)", name, strings.Join(themeNames(), ", ")))
+	}
+	return theme, nil
+}
+
+// themeNames returns the names of plotThemes in order
+func themeNames() []string {
+	names := make([]string, 0, len(plotThemes))
+	for name := range plotThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	rootCmd.AddCommand(plotAllCmd)
+
+	plotAllCmd.Flags().StringVarP(&plotAllInput, "input", "i", "", "Directory of audio files, searched recursively")
+	plotAllCmd.Flags().StringVarP(&plotAllOutput, "output", "o", "", "Directory the PNG thumbnails are written to")
+	plotAllCmd.Flags().IntVar(&plotAllWidth, "width", 600, "Width of each thumbnail in pixels")
+	plotAllCmd.Flags().IntVar(&plotAllHeight, "height", 120, "Height of each thumbnail in pixels")
+	plotAllCmd.Flags().StringVar(&plotAllTheme, "theme", "light", "Thumbnail colors: light or dark")
+	plotAllCmd.Flags().IntVar(&plotAllWorkers, "workers", runtime.NumCPU(), "Number of files rendered at once")
+	plotAllCmd.Flags().BoolVar(&plotAllForce, "force", false, "Render every thumbnail, including those newer than their audio file")
+	plotAllCmd.Flags().StringVar(&plotAllManifest, "manifest", "", "Write a JSON manifest of every input with its duration, checksums, thumbnail and errors to this file")
+	plotAllCmd.MarkFlagRequired("input")
+	plotAllCmd.MarkFlagRequired("output")
+}
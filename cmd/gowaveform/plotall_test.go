@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTestManifest reads a manifest written by a run
+func readTestManifest(t *testing.T, filename string) manifest {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	return m
+}
+
+func TestPlotAll(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "audio/ramp.wav")
+	copyRamp(t, dir, "audio/album/track.wav")
+
+	stdout, stderr, code := runCLI(t, dir, "plot-all", "-i", "audio", "-o", "thumbs", "--manifest", "manifest.json")
+	if code != 0 {
+		t.Fatalf("Exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Rendered 2 thumbnails, skipped 0") {
+		t.Errorf("Expected two rendered thumbnails:\n%s", stdout)
+	}
+	m := readTestManifest(t, filepath.Join(dir, "manifest.json"))
+	if len(m.Files) != 2 {
+		t.Fatalf("Expected two manifest entries, got %+v", m.Files)
+	}
+	for _, entry := range m.Files {
+		if entry.Duration != 1 || entry.InputSHA256 == "" || len(entry.Outputs) != 1 || entry.Error != "" {
+			t.Errorf("Unexpected manifest entry %+v", entry)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Outputs[0].Path)); err != nil {
+			t.Errorf("Expected the thumbnail of %s: %v", entry.Input, err)
+		}
+	}
+
+	// A second run keeps the thumbnails, and the manifest still has the durations
+	stdout, _, _ = runCLI(t, dir, "plot-all", "-i", "audio", "-o", "thumbs", "--manifest", "manifest.json")
+	if !strings.Contains(stdout, "Rendered 0 thumbnails, skipped 2") {
+		t.Errorf("Expected the thumbnails to be skipped:\n%s", stdout)
+	}
+	for _, entry := range readTestManifest(t, filepath.Join(dir, "manifest.json")).Files {
+		if !entry.Skipped || entry.Duration != 1 {
+			t.Errorf("Expected a skipped entry with its duration, got %+v", entry)
+		}
+	}
+
+	// Other options render the thumbnails again and are recorded
+	stdout, _, _ = runCLI(t, dir, "plot-all", "-i", "audio", "-o", "thumbs", "--manifest", "manifest.json", "--theme", "dark", "--width", "300")
+	if !strings.Contains(stdout, "Rendered 2 thumbnails, skipped 0") {
+		t.Errorf("Expected the thumbnails to be rendered again with other options:\n%s", stdout)
+	}
+	want := renderOptions{Theme: "dark", Width: 300, Height: 120}
+	for _, entry := range readTestManifest(t, filepath.Join(dir, "manifest.json")).Files {
+		if entry.Skipped || entry.Render == nil || *entry.Render != want {
+			t.Errorf("Expected a rendered entry with options %+v, got %+v", want, entry)
+		}
+	}
+}
+
+func TestFindPlotJobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"kick.wav", "kick.flac", "notes.txt", "thumbs/old.wav"} {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		os.WriteFile(filepath.Join(dir, name), nil, 0644)
+	}
+
+	jobs, err := findPlotJobs(dir, filepath.Join(dir, "thumbs"), filepath.Join(dir, "thumbs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs []string
+	for _, job := range jobs {
+		rel, _ := filepath.Rel(dir, job.output)
+		outputs = append(outputs, filepath.ToSlash(rel))
+	}
+	// Inputs that differ only in their extension get a thumbnail each
+	if want := []string{"thumbs/kick.flac.png", "thumbs/kick.wav.png"}; strings.Join(outputs, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected outputs %v, got %v", want, outputs)
+	}
+}
+
+func TestPlotAllFailure(t *testing.T) {
+	dir := t.TempDir()
+	copyRamp(t, dir, "audio/ramp.wav")
+	os.WriteFile(filepath.Join(dir, "audio", "broken.wav"), []byte("not audio"), 0644)
+
+	_, _, code := runCLI(t, dir, "plot-all", "-i", "audio", "-o", "thumbs", "--manifest", "manifest.json")
+	if code != exitDecodeError {
+		t.Fatalf("Expected exit code %d, got %d", exitDecodeError, code)
+	}
+	for _, entry := range readTestManifest(t, filepath.Join(dir, "manifest.json")).Files {
+		broken := filepath.Base(entry.Input) == "broken.wav"
+		if broken && (entry.ErrorType != "decode_error" || entry.ExitCode != exitDecodeError) {
+			t.Errorf("Expected a decode error for the broken file, got %+v", entry)
+		}
+		if !broken && (entry.Error != "" || len(entry.Outputs) != 1) {
+			t.Errorf("Expected the other file to be rendered, got %+v", entry)
+		}
+	}
+}
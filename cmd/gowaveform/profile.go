@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/spf13/cobra"
+)
+
+// Flags for profiling a run
+var (
+	cpuProfile string
+	memProfile string
+	traceFile  string
+)
+
+// stopProfiles ends the profiles started by startProfiling, nil if none were started
+var stopProfiles func() error
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&cpuProfile, "cpuprofile", "", "Write a CPU profile of the run to this file, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&memProfile, "memprofile", "", "Write a heap profile at the end of the run to this file, for go tool pprof")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace", "", "Write an execution trace of the run to this file, for go tool trace")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		// Checked before profiling starts, which would otherwise be left running
+		if err := validFlags(cmd); err != nil {
+			return err
+		}
+		return withExitCode(exitWriteError, startProfiling())
+	}
+}
+
+// startProfiling starts the CPU profile and execution trace requested with flags
+func startProfiling() error {
+	var stops []func() error
+	stopProfiles = func() error {
+		var errs []error
+		for i := len(stops) - 1; i >= 0; i-- {
+			errs = append(errs, stops[i]())
+		}
+		if memProfile != "" {
+			errs = append(errs, writeHeapProfile(memProfile))
+		}
+		return errors.Join(errs...)
+	}
+
+	if cpuProfile != "" {
+		f, err := os.Create(cpuProfile)
+		if err != nil {
+			return fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return fmt.Errorf("failed to create trace: %w", err)
+		}
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to start trace: %w", err)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+	return nil
+}
+
+// stopProfiling writes out the profiles of the run, if any were started
+func stopProfiling() error {
+	if stopProfiles == nil {
+		return nil
+	}
+	stop := stopProfiles
+	stopProfiles = nil
+	return stop()
+}
+
+// writeHeapProfile writes a heap profile to filename after a garbage collection, so
+// it shows the memory still in use
+func writeHeapProfile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create memory profile: %w", err)
+	}
+	defer f.Close()
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return fmt.Errorf("failed to write memory profile: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/schollz/gowaveform"
+	"github.com/schollz/gowaveform/video"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the video command
+var (
+	videoInput         string
+	videoOutput        string
+	videoWidth         int
+	videoHeight        int
+	videoFPS           float64
+	videoStart         float64
+	videoEnd           float64
+	videoBgColor       string
+	videoFgColor       string
+	videoPlayheadColor string
+	videoTitle         string
+	videoNoAudio       bool
+)
+
+var videoCmd = &cobra.Command{
+	Use:   "video",
+	Short: "Render a waveform video with a moving playhead (requires ffmpeg)",
+	Long: `Render the waveform to an MP4 or WebM video with a playhead sweeping across it.
+Frames are piped to ffmpeg, which must be installed. The audio of the input file
+is muxed into the video unless --no-audio is given.`,
+	Example: `  # Render a 720p video with the audio track
+  gowaveform video -i audio.wav -o waveform.mp4
+
+  # Render seconds 10 to 20 as a square WebM with custom colors
+  gowaveform video -i audio.wav -o clip.webm --start 10 --end 20 --width 1080 --height 1080 --bg-color "#000000" --fg-color "#FFFFFF"`,
+	Args: validArgs(cobra.NoArgs),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := checkInputFile(videoInput); err != nil {
+			fail(err)
+		}
+
+		if err := generateVideo(); err != nil {
+			fail(fmt.Errorf("failed to generate video: %w", err))
+		}
+		fmt.Printf("Waveform video saved to: %s\n", videoOutput)
+	},
+}
+
+// generateVideo renders the video described by the video command flags
+func generateVideo() error {
+	waveform, err := gowaveform.LoadWaveform(videoInput, loadOptions()...)
+	if err != nil {
+		return withExitCode(exitDecodeError, fmt.Errorf("failed to load waveform: %w", err))
+	}
+
+	opts := []video.Option{
+		video.OptionSetSize(videoWidth, videoHeight),
+		video.OptionSetFPS(videoFPS),
+		video.OptionSetStart(videoStart),
+		video.OptionSetEnd(videoEnd),
+	}
+	if videoBgColor != "" {
+		opts = append(opts, video.OptionSetBackgroundColor(videoBgColor))
+	}
+	if videoFgColor != "" {
+		opts = append(opts, video.OptionSetForegroundColor(videoFgColor))
+	}
+	if videoPlayheadColor != "" {
+		opts = append(opts, video.OptionSetPlayheadColor(videoPlayheadColor))
+	}
+	if videoTitle != "" {
+		opts = append(opts, video.OptionPlot(gowaveform.OptionSetTitle(videoTitle)))
+	}
+	if !videoNoAudio {
+		opts = append(opts, video.OptionAudio(videoInput))
+	}
+
+	return withExitCode(exitWriteError, video.Save(waveform, videoOutput, opts...))
+}
+
+func init() {
+	rootCmd.AddCommand(videoCmd)
+
+	videoCmd.Flags().StringVarP(&videoInput, "input", "i", "", "Input audio file")
+	videoCmd.Flags().StringVarP(&videoOutput, "output", "o", "", "Output video file (.mp4, .m4v, .mov or .webm)")
+	videoCmd.Flags().IntVar(&videoWidth, "width", 1280, "Width of the video in pixels")
+	videoCmd.Flags().IntVar(&videoHeight, "height", 720, "Height of the video in pixels")
+	videoCmd.Flags().Float64Var(&videoFPS, "fps", 30, "Frame rate of the video")
+	videoCmd.Flags().Float64Var(&videoStart, "start", 0, "Start time in seconds (default: 0)")
+	videoCmd.Flags().Float64Var(&videoEnd, "end", 0, "End time in seconds (default: full duration)")
+	videoCmd.Flags().StringVar(&videoBgColor, "bg-color", "", "Background color in hex format (e.g., #FFFFFF)")
+	videoCmd.Flags().StringVar(&videoFgColor, "fg-color", "", "Foreground/waveform color in hex format (e.g., #0064C8)")
+	videoCmd.Flags().StringVar(&videoPlayheadColor, "playhead-color", "", "Playhead color in hex format (default: red)")
+	videoCmd.Flags().StringVar(&videoTitle, "title", "", "Set the title shown above the waveform")
+	videoCmd.Flags().BoolVar(&videoNoAudio, "no-audio", false, "Do not mux the input audio into the video")
+	videoCmd.MarkFlagRequired("input")
+	videoCmd.MarkFlagRequired("output")
+}
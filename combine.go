@@ -0,0 +1,126 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// ConcatWaveforms joins waveforms end to end, e.g. to show a playlist or an episode
+// stitched from segments as one continuous waveform. The result has the highest sample
+// rate and channel count of the inputs; other inputs are resampled, and mono inputs
+// are copied to every channel (any other channel mismatch uses the mono mix).
+func ConcatWaveforms(ws ...*Waveform) (*Waveform, error) {
+	rate, channels, err := commonFormat(ws)
+	if err != nil {
+		return nil, err
+	}
+
+	var audioData []int16
+	for i, w := range ws {
+		data, err := w.conform(rate, channels)
+		if err != nil {
+			return nil, fmt.Errorf("waveform %d: %w", i, err)
+		}
+		audioData = append(audioData, data...)
+	}
+
+	return (&Waveform{
+		SampleRate:    rate,
+		Channels:      channels,
+		BitsPerSample: 16,
+		audioData:     audioData,
+		totalSamples:  len(audioData) / channels,
+	}).track(), nil
+}
+
+// MixWaveforms sums sources that start together, each scaled by its linear gain (nil
+// gains mix every source at 1.0), e.g. to preview a folder of stems as the approximate
+// full mix. The mix lasts as long as the longest source and uses the highest sample rate
+// and channel count of the inputs, converted as in ConcatWaveforms. Peaks beyond full
+// scale are clipped; lower the gains to avoid that.
+func MixWaveforms(ws []*Waveform, gains []float64) (*Waveform, error) {
+	rate, channels, err := commonFormat(ws)
+	if err != nil {
+		return nil, err
+	}
+	if gains != nil && len(gains) != len(ws) {
+		return nil, fmt.Errorf("got %d gains for %d waveforms", len(gains), len(ws))
+	}
+
+	var sum []float64
+	for i, w := range ws {
+		data, err := w.conform(rate, channels)
+		if err != nil {
+			return nil, fmt.Errorf("waveform %d: %w", i, err)
+		}
+		gain := 1.0
+		if gains != nil {
+			gain = gains[i]
+		}
+		if len(data) > len(sum) {
+			sum = append(sum, make([]float64, len(data)-len(sum))...)
+		}
+		for j, v := range data {
+			sum[j] += gain * float64(v)
+		}
+	}
+
+	audioData := make([]int16, len(sum))
+	for i, v := range sum {
+		audioData[i] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+	}
+
+	return (&Waveform{
+		SampleRate:    rate,
+		Channels:      channels,
+		BitsPerSample: 16,
+		audioData:     audioData,
+		totalSamples:  len(audioData) / channels,
+	}).track(), nil
+}
+
+// commonFormat returns the highest sample rate and channel count of ws
+func commonFormat(ws []*Waveform) (int, int, error) {
+	if len(ws) == 0 {
+		return 0, 0, fmt.Errorf("no waveforms given")
+	}
+	var rate, channels int
+	for i, w := range ws {
+		if w == nil || w.SampleRate <= 0 || w.Channels <= 0 {
+			return 0, 0, fmt.Errorf("waveform %d has no audio format", i)
+		}
+		rate = max(rate, w.SampleRate)
+		channels = max(channels, w.Channels)
+	}
+	return rate, channels, nil
+}
+
+// conform returns the decoded audio of w at the given sample rate and channel count,
+// interleaved and possibly sharing memory with w. Mono audio is copied to every
+// channel; other channel counts that differ are replaced by the mono mix.
+func (w *Waveform) conform(sampleRate, channels int) ([]int16, error) {
+	src := w
+	if w.SampleRate != sampleRate {
+		var err error
+		if src, err = w.Resample(sampleRate); err != nil {
+			return nil, err
+		}
+	}
+
+	frames := src.availableFrames()
+	if src.Channels == channels {
+		return src.audioData[:frames*channels], nil
+	}
+
+	audioData := make([]int16, frames*channels)
+	for frame := 0; frame < frames; frame++ {
+		v := src.audioData[frame*src.Channels]
+		if src.Channels > 1 {
+			v = int16(src.monoSample(frame) * 32768)
+		}
+		for ch := 0; ch < channels; ch++ {
+			audioData[frame*channels+ch] = v
+		}
+	}
+	return audioData, nil
+}
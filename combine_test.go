@@ -0,0 +1,127 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestConcatWaveforms(t *testing.T) {
+	monoFile := "/tmp/test_concat_mono.wav"
+	stereoFile := "/tmp/test_concat_stereo.wav"
+	defer os.Remove(monoFile)
+	defer os.Remove(stereoFile)
+
+	// 0.5s of mono at 44.1 kHz and 1s of stereo at 22.05 kHz
+	writeTestWAV(t, monoFile, 44100, 1, sineSamples(44100, 1, 440, 0.5, 0.5))
+	writeTestWAV(t, stereoFile, 22050, 2, sineSamples(22050, 2, 220, 0.25, 1))
+
+	mono, err := LoadWaveform(monoFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	stereo, err := LoadWaveform(stereoFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	joined, err := ConcatWaveforms(mono, stereo)
+	if err != nil {
+		t.Fatalf("ConcatWaveforms failed: %v", err)
+	}
+	if joined.SampleRate != 44100 || joined.Channels != 2 {
+		t.Fatalf("Expected 44100 Hz stereo, got %d Hz with %d channels", joined.SampleRate, joined.Channels)
+	}
+	if math.Abs(joined.Duration()-1.5) > 1e-9 {
+		t.Errorf("Expected 1.5s, got %.6fs", joined.Duration())
+	}
+
+	// The mono part is on both channels, followed by the quieter stereo part
+	for frame := 0; frame < 22050; frame++ {
+		if joined.audioData[frame*2] != joined.audioData[frame*2+1] {
+			t.Fatalf("Frame %d: expected equal channels, got %d and %d", frame, joined.audioData[frame*2], joined.audioData[frame*2+1])
+		}
+	}
+	first, err := joined.ChannelLevels(0, 0.5)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	second, err := joined.ChannelLevels(0.6, 1.4)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	if math.Abs(first[1].Peak-0.5) > 0.01 || math.Abs(second[1].Peak-0.25) > 0.01 {
+		t.Errorf("Expected peaks of 0.5 and 0.25, got %.3f and %.3f", first[1].Peak, second[1].Peak)
+	}
+}
+
+func TestConcatWaveformsErrors(t *testing.T) {
+	if _, err := ConcatWaveforms(); err == nil {
+		t.Error("Expected an error without waveforms")
+	}
+	if _, err := ConcatWaveforms(nil); err == nil {
+		t.Error("Expected an error for a nil waveform")
+	}
+}
+
+func TestMixWaveforms(t *testing.T) {
+	longFile := "/tmp/test_mix_long.wav"
+	shortFile := "/tmp/test_mix_short.wav"
+	defer os.Remove(longFile)
+	defer os.Remove(shortFile)
+
+	// A 1s tone and a 0.5s copy of it in opposite polarity
+	tone := sineSamples(44100, 1, 440, 0.5, 1)
+	inverted := make([]int16, 22050)
+	for i := range inverted {
+		inverted[i] = -tone[i]
+	}
+	writeTestWAV(t, longFile, 44100, 1, tone)
+	writeTestWAV(t, shortFile, 44100, 1, inverted)
+
+	long, err := LoadWaveform(longFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	short, err := LoadWaveform(shortFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// At equal gains the sources cancel while both play
+	mix, err := MixWaveforms([]*Waveform{long, short}, nil)
+	if err != nil {
+		t.Fatalf("MixWaveforms failed: %v", err)
+	}
+	if mix.Duration() != 1 {
+		t.Errorf("Expected the length of the longest source, got %.6fs", mix.Duration())
+	}
+	cancelled, err := mix.ChannelLevels(0, 0.5)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	rest, err := mix.ChannelLevels(0.5, 1)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	if cancelled[0].Peak != 0 || math.Abs(rest[0].Peak-0.5) > 0.01 {
+		t.Errorf("Expected peaks of 0 and 0.5, got %.3f and %.3f", cancelled[0].Peak, rest[0].Peak)
+	}
+
+	// Halving the second source leaves half of the first
+	mix, err = MixWaveforms([]*Waveform{long, short}, []float64{1, 0.5})
+	if err != nil {
+		t.Fatalf("MixWaveforms failed: %v", err)
+	}
+	levels, err := mix.ChannelLevels(0, 0.5)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	if math.Abs(levels[0].Peak-0.25) > 0.01 {
+		t.Errorf("Expected a peak of 0.25, got %.3f", levels[0].Peak)
+	}
+
+	if _, err := MixWaveforms([]*Waveform{long, short}, []float64{1}); err == nil {
+		t.Error("Expected an error for a gain count mismatch")
+	}
+}
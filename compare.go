@@ -0,0 +1,283 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// DefaultMaxOffset is the largest alignment offset Compare searches, in seconds
+const DefaultMaxOffset = 1.0
+
+// maxCorrelationSize limits the FFT size of the coarse alignment search; longer audio
+// is averaged down until it fits
+const maxCorrelationSize = 1 << 21
+
+// CompareOptions configures Compare
+type CompareOptions struct {
+	MaxOffset float64 // Largest offset searched in either direction, in seconds (0 = DefaultMaxOffset)
+}
+
+// Comparison describes how two recordings of the same material differ
+type Comparison struct {
+	Offset         float64 `json:"offset"`          // Seconds b is delayed relative to a (negative = b is early)
+	Correlation    float64 `json:"correlation"`     // Normalized correlation of the aligned audio, -1.0..1.0 (1 = identical up to gain)
+	PeakDifference float64 `json:"peak_difference"` // Peak level of b minus peak level of a, in dB
+	MaxDifference  float64 `json:"max_difference"`  // Largest sample difference of the aligned audio, normalized to 0.0..2.0
+	DifferenceDBFS float64 `json:"difference_dbfs"` // RMS level of the aligned difference (-Inf if identical)
+	Overlap        float64 `json:"overlap"`         // Seconds of audio compared after alignment
+}
+
+// MarshalJSON encodes the infinite levels of silent or identical audio as null
+func (c Comparison) MarshalJSON() ([]byte, error) {
+	finite := func(v float64) *float64 {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil
+		}
+		return &v
+	}
+	return json.Marshal(struct {
+		Offset         float64  `json:"offset"`
+		Correlation    float64  `json:"correlation"`
+		PeakDifference *float64 `json:"peak_difference"`
+		MaxDifference  float64  `json:"max_difference"`
+		DifferenceDBFS *float64 `json:"difference_dbfs"`
+		Overlap        float64  `json:"overlap"`
+	}{
+		c.Offset, c.Correlation, finite(c.PeakDifference),
+		c.MaxDifference, finite(c.DifferenceDBFS), c.Overlap,
+	})
+}
+
+// Compare aligns b to a by cross-correlating their mono mixes and reports the offset,
+// how well the aligned audio correlates and how much it differs. Both files must
+// have the same sample rate.
+func Compare(a, b *Waveform, opts CompareOptions) (*Comparison, error) {
+	if a.SampleRate != b.SampleRate {
+		return nil, fmt.Errorf("sample rates differ: %d Hz and %d Hz", a.SampleRate, b.SampleRate)
+	}
+	if opts.MaxOffset <= 0 {
+		opts.MaxOffset = DefaultMaxOffset
+	}
+
+	monoA, monoB := a.monoMix(), b.monoMix()
+	if len(monoA) == 0 || len(monoB) == 0 {
+		return nil, fmt.Errorf("cannot compare empty audio")
+	}
+
+	lag := alignmentLag(monoA, monoB, int(opts.MaxOffset*float64(a.SampleRate)), false)
+
+	c := &Comparison{Offset: float64(lag) / float64(a.SampleRate)}
+
+	var dot, energyA, energyB, energyDiff float64
+	overlap := 0
+	for i := range monoA {
+		j := i + lag
+		if j < 0 || j >= len(monoB) {
+			continue
+		}
+		dot += monoA[i] * monoB[j]
+		energyA += monoA[i] * monoA[i]
+		energyB += monoB[j] * monoB[j]
+		diff := monoA[i] - monoB[j]
+		energyDiff += diff * diff
+		if d := math.Abs(diff); d > c.MaxDifference {
+			c.MaxDifference = d
+		}
+		overlap++
+	}
+	if energyA > 0 && energyB > 0 {
+		c.Correlation = dot / math.Sqrt(energyA*energyB)
+	}
+	c.Overlap = float64(overlap) / float64(a.SampleRate)
+	c.DifferenceDBFS = math.Inf(-1)
+	if overlap > 0 {
+		c.DifferenceDBFS = ToDBFS(math.Sqrt(energyDiff / float64(overlap)))
+	}
+
+	peakA, err := a.peakLevel()
+	if err != nil {
+		return nil, err
+	}
+	peakB, err := b.peakLevel()
+	if err != nil {
+		return nil, err
+	}
+	c.PeakDifference = ToDBFS(peakB) - ToDBFS(peakA)
+
+	return c, nil
+}
+
+// Difference returns a minus b delayed by offset seconds (as reported by Compare), e.g. to
+// plot where two renders differ. It has the length of a; where b has no audio the
+// difference is a itself. Channels are subtracted one by one if both files have the
+// same count, otherwise the mono mixes are.
+func Difference(a, b *Waveform, offset float64) (*Waveform, error) {
+	if a.SampleRate != b.SampleRate {
+		return nil, fmt.Errorf("sample rates differ: %d Hz and %d Hz", a.SampleRate, b.SampleRate)
+	}
+
+	lag := int(math.Round(offset * float64(a.SampleRate)))
+	frames := a.availableFrames()
+	framesB := b.availableFrames()
+
+	var channels int
+	var sampleA, sampleB func(frame, ch int) float64
+	if a.Channels == b.Channels {
+		channels = a.Channels
+		sampleA = func(frame, ch int) float64 { return float64(a.audioData[frame*channels+ch]) }
+		sampleB = func(frame, ch int) float64 { return float64(b.audioData[frame*channels+ch]) }
+	} else {
+		channels = 1
+		monoA, monoB := a.monoMix(), b.monoMix()
+		sampleA = func(frame, ch int) float64 { return monoA[frame] * 32768 }
+		sampleB = func(frame, ch int) float64 { return monoB[frame] * 32768 }
+	}
+
+	audioData := make([]int16, frames*channels)
+	for frame := 0; frame < frames; frame++ {
+		for ch := 0; ch < channels; ch++ {
+			v := sampleA(frame, ch)
+			if j := frame + lag; j >= 0 && j < framesB {
+				v -= sampleB(j, ch)
+			}
+			audioData[frame*channels+ch] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+		}
+	}
+
+	return (&Waveform{
+		SampleRate:    a.SampleRate,
+		Channels:      channels,
+		BitsPerSample: 16,
+		audioData:     audioData,
+		totalSamples:  frames,
+	}).track(), nil
+}
+
+// monoMix returns the average of all channels of each decoded frame, normalized to -1.0..1.0
+func (w *Waveform) monoMix() []float64 {
+	frames := w.availableFrames()
+	mix := make([]float64, frames)
+	if w.Channels == 0 {
+		return mix
+	}
+	for frame := range mix {
+		var sum float64
+		for ch := 0; ch < w.Channels; ch++ {
+			sum += float64(w.audioData[frame*w.Channels+ch])
+		}
+		mix[frame] = sum / float64(w.Channels) / 32768.0
+	}
+	return mix
+}
+
+// peakLevel returns the highest peak of any channel, normalized to 0.0..1.0
+func (w *Waveform) peakLevel() (float64, error) {
+	levels, err := w.ChannelLevels(0, 0)
+	if err != nil {
+		return 0, err
+	}
+	var peak float64
+	for _, l := range levels {
+		peak = math.Max(peak, l.Peak)
+	}
+	return peak, nil
+}
+
+// alignmentLag returns the lag in frames, at most maxLag in either direction, at which b
+// best matches a (b[i+lag] ≈ a[i]). The lag is found on block averages of both signals
+// with an FFT cross-correlation and then refined at full rate around the block found.
+// With absolute, the lag of the largest magnitude is found, so inverted audio aligns too.
+func alignmentLag(a, b []float64, maxLag int, absolute bool) int {
+	block := 1
+	for nextPowerOfTwo((len(a)+len(b))/block) > maxCorrelationSize {
+		block *= 2
+	}
+
+	coarseA, coarseB := blockAverage(a, block), blockAverage(b, block)
+	n := nextPowerOfTwo(len(coarseA) + len(coarseB))
+	fa := make([]complex128, n)
+	fb := make([]complex128, n)
+	for i, v := range coarseA {
+		fa[i] = complex(v, 0)
+	}
+	for i, v := range coarseB {
+		fb[i] = complex(v, 0)
+	}
+	fft(fa)
+	fft(fb)
+
+	// The inverse transform of conj(A)·B is the cross-correlation; computed here as
+	// conj(fft(conj(x))), skipping the 1/n scale which does not move the maximum
+	for i := range fa {
+		fa[i] = complex(real(fa[i]), -imag(fa[i])) * fb[i]
+		fa[i] = complex(real(fa[i]), -imag(fa[i]))
+	}
+	fft(fa)
+
+	maxCoarse := maxLag / block
+	best, bestValue := 0, math.Inf(-1)
+	for lag := max(-maxCoarse, 1-len(coarseA)); lag <= min(maxCoarse, len(coarseB)-1); lag++ {
+		idx := lag
+		if idx < 0 {
+			idx += n
+		}
+		v := real(fa[idx])
+		if absolute {
+			v = math.Abs(v)
+		}
+		if v > bestValue {
+			best, bestValue = lag, v
+		}
+	}
+	if block == 1 {
+		return best
+	}
+
+	// Refine within the neighboring blocks at full rate
+	refined, refinedValue := best*block, math.Inf(-1)
+	for lag := (best - 1) * block; lag <= (best+1)*block; lag++ {
+		if lag < -maxLag || lag > maxLag {
+			continue
+		}
+		var sum float64
+		for i := range a {
+			if j := i + lag; j >= 0 && j < len(b) {
+				sum += a[i] * b[j]
+			}
+		}
+		if absolute {
+			sum = math.Abs(sum)
+		}
+		if sum > refinedValue {
+			refined, refinedValue = lag, sum
+		}
+	}
+	return refined
+}
+
+// blockAverage returns the mean of every block of size samples
+func blockAverage(x []float64, size int) []float64 {
+	if size == 1 {
+		return x
+	}
+	out := make([]float64, 0, (len(x)+size-1)/size)
+	for start := 0; start < len(x); start += size {
+		end := min(start+size, len(x))
+		var sum float64
+		for _, v := range x[start:end] {
+			sum += v
+		}
+		out = append(out, sum/float64(end-start))
+	}
+	return out
+}
+
+// nextPowerOfTwo returns the smallest power of two of at least n
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
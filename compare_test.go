@@ -0,0 +1,122 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	fileA := "/tmp/test_compare_a.wav"
+	fileB := "/tmp/test_compare_b.wav"
+	defer os.Remove(fileA)
+	defer os.Remove(fileB)
+
+	// b is a at half gain, delayed by 441 frames (10 ms)
+	rng := rand.New(rand.NewSource(1))
+	samplesA := make([]int16, 22050)
+	for i := range samplesA {
+		samplesA[i] = int16(rng.Intn(20000) - 10000)
+	}
+	samplesB := make([]int16, 441, 441+len(samplesA))
+	for _, v := range samplesA {
+		samplesB = append(samplesB, v/2)
+	}
+	writeTestWAV(t, fileA, 44100, 1, samplesA)
+	writeTestWAV(t, fileB, 44100, 1, samplesB)
+
+	a, err := LoadWaveform(fileA)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	b, err := LoadWaveform(fileB)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	c, err := Compare(a, b, CompareOptions{})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if math.Abs(c.Offset-0.01) > 1e-9 {
+		t.Errorf("Expected an offset of 0.01s, got %.6fs", c.Offset)
+	}
+	if c.Correlation < 0.999 {
+		t.Errorf("Expected a correlation near 1, got %.4f", c.Correlation)
+	}
+	if math.Abs(c.PeakDifference+6.02) > 0.05 {
+		t.Errorf("Expected b to peak about 6 dB lower, got %.2f dB", c.PeakDifference)
+	}
+	if math.Abs(c.Overlap-0.5) > 1e-9 {
+		t.Errorf("Expected 0.5s of overlap, got %.6fs", c.Overlap)
+	}
+
+	// Comparing in the other direction reverses the offset
+	c, err = Compare(b, a, CompareOptions{})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if math.Abs(c.Offset+0.01) > 1e-9 {
+		t.Errorf("Expected an offset of -0.01s, got %.6fs", c.Offset)
+	}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	tmpFile := "/tmp/test_compare_identical.wav"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 44100, 2, sineSamples(44100, 2, 440, 0.5, 0.5))
+
+	w, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	c, err := Compare(w, w, CompareOptions{})
+	if err != nil {
+		t.Fatalf("Compare failed: %v", err)
+	}
+	if c.Offset != 0 || c.MaxDifference != 0 || !math.IsInf(c.DifferenceDBFS, -1) {
+		t.Errorf("Expected no offset or difference, got %+v", c)
+	}
+
+	// The level of the missing difference is null in JSON
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"difference_dbfs":null`) {
+		t.Errorf("Expected a null difference level, got %s", data)
+	}
+
+	diff, err := Difference(w, w, 0)
+	if err != nil {
+		t.Fatalf("Difference failed: %v", err)
+	}
+	if diff.Channels != 2 || diff.Duration() != w.Duration() {
+		t.Errorf("Expected a stereo difference as long as the input, got %d channels, %.3fs", diff.Channels, diff.Duration())
+	}
+	levels, err := diff.ChannelLevels(0, 0)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	for ch, l := range levels {
+		if l.Peak != 0 {
+			t.Errorf("Expected a silent difference in channel %d, got peak %.4f", ch, l.Peak)
+		}
+	}
+}
+
+func TestCompareSampleRateMismatch(t *testing.T) {
+	a := &Waveform{SampleRate: 44100, Channels: 1, audioData: make([]int16, 100), totalSamples: 100}
+	b := &Waveform{SampleRate: 48000, Channels: 1, audioData: make([]int16, 100), totalSamples: 100}
+
+	if _, err := Compare(a, b, CompareOptions{}); err == nil {
+		t.Error("Expected an error for different sample rates")
+	}
+	if _, err := Difference(a, b, 0); err == nil {
+		t.Error("Expected an error for different sample rates")
+	}
+}
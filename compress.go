@@ -0,0 +1,155 @@
+package gowaveform
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression selects the codec used for compressed JSON output
+type Compression string
+
+const (
+	// CompressionNone leaves the output uncompressed
+	CompressionNone Compression = ""
+	// CompressionGzip compresses the output with gzip
+	CompressionGzip Compression = "gzip"
+	// CompressionZstd compresses the output with Zstandard
+	CompressionZstd Compression = "zstd"
+)
+
+// ParseCompression parses a codec name: none, gzip or zstd
+func ParseCompression(name string) (Compression, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip", "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return CompressionZstd, nil
+	default:
+		return CompressionNone, fmt.Errorf("unknown compression: %s (supported: none, gzip, zstd)", name)
+	}
+}
+
+// Extension returns the conventional file extension for the codec (e.g. ".gz")
+func (c Compression) Extension() string {
+	switch c {
+	case CompressionGzip:
+		return ".gz"
+	case CompressionZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// NewCompressWriter wraps w in an encoder for the given codec. Close must be called
+// to flush the compressed stream; it does not close w. CompressionNone writes through unchanged.
+func NewCompressWriter(w io.Writer, codec Compression) (io.WriteCloser, error) {
+	switch codec {
+	case CompressionNone:
+		return nopWriteCloser{w}, nil
+	case CompressionGzip:
+		return gzip.NewWriter(w), nil
+	case CompressionZstd:
+		zw, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+		return zw, nil
+	default:
+		return nil, fmt.Errorf("unknown compression: %s", codec)
+	}
+}
+
+// Compress compresses data with the given codec. CompressionNone returns data unchanged.
+func Compress(data []byte, codec Compression) ([]byte, error) {
+	if codec == CompressionNone {
+		return data, nil
+	}
+
+	var buf bytes.Buffer
+	zw, err := NewCompressWriter(&buf, codec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := zw.Write(data); err != nil {
+		zw.Close()
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GenerateWaveformJSONCompressed generates JSON directly from an audio file and compresses it
+// with the given codec, which typically shrinks full-resolution peak data several times over
+func GenerateWaveformJSONCompressed(filename string, opts WaveformOptions, codec Compression) ([]byte, error) {
+	data, err := GenerateWaveformData(filename, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream the JSON straight into the encoder rather than buffering it uncompressed first
+	var buf bytes.Buffer
+	zw, err := NewCompressWriter(&buf, codec)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Float {
+		var encoded []byte
+		if encoded, err = json.MarshalIndent(data.Float(), "", "  "); err == nil {
+			_, err = zw.Write(encoded)
+		}
+	} else {
+		err = WriteJSON(zw, data)
+	}
+	if err != nil {
+		zw.Close()
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress data: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// NewDecompressReader returns a reader of the decompressed contents of r, detecting gzip
+// and Zstandard streams from their magic bytes. Uncompressed data is read through unchanged.
+func NewDecompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gzip decoder: %w", err)
+		}
+		return zr, nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+		}
+		return zr.IOReadCloser(), nil
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
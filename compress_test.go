@@ -0,0 +1,109 @@
+package gowaveform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestGenerateWaveformJSONCompressed(t *testing.T) {
+	tmpFile := "/tmp/test_compressed.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	opts := WaveformOptions{SamplesPerPixel: 64}
+	plain, err := GenerateWaveformJSON(tmpFile, opts)
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+
+	decoders := map[Compression]func([]byte) ([]byte, error){
+		CompressionGzip: func(data []byte) ([]byte, error) {
+			zr, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			return io.ReadAll(zr)
+		},
+		CompressionZstd: func(data []byte) ([]byte, error) {
+			zr, err := zstd.NewReader(nil)
+			if err != nil {
+				return nil, err
+			}
+			defer zr.Close()
+			return zr.DecodeAll(data, nil)
+		},
+	}
+
+	for codec, decode := range decoders {
+		compressed, err := GenerateWaveformJSONCompressed(tmpFile, opts, codec)
+		if err != nil {
+			t.Fatalf("GenerateWaveformJSONCompressed(%s) failed: %v", codec, err)
+		}
+
+		if len(compressed) >= len(plain) {
+			t.Errorf("%s: expected compressed size below %d bytes, got %d", codec, len(plain), len(compressed))
+		}
+
+		decompressed, err := decode(compressed)
+		if err != nil {
+			t.Fatalf("%s: failed to decompress: %v", codec, err)
+		}
+		if !bytes.Equal(decompressed, plain) {
+			t.Errorf("%s: decompressed output does not match plain JSON", codec)
+		}
+
+		var data WaveformData
+		if err := json.Unmarshal(decompressed, &data); err != nil {
+			t.Errorf("%s: decompressed output is not valid JSON: %v", codec, err)
+		}
+	}
+}
+
+func TestCompressNone(t *testing.T) {
+	data := []byte(`{"version":2}`)
+	out, err := Compress(data, CompressionNone)
+	if err != nil {
+		t.Fatalf("Compress failed: %v", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Error("Expected uncompressed data to be returned unchanged")
+	}
+
+	if _, err := Compress(data, Compression("brotli")); err == nil {
+		t.Error("Expected error for unknown codec")
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	tests := map[string]Compression{
+		"":     CompressionNone,
+		"none": CompressionNone,
+		"gzip": CompressionGzip,
+		"GZ":   CompressionGzip,
+		"zstd": CompressionZstd,
+	}
+	for name, expected := range tests {
+		codec, err := ParseCompression(name)
+		if err != nil {
+			t.Errorf("ParseCompression(%q) failed: %v", name, err)
+		}
+		if codec != expected {
+			t.Errorf("ParseCompression(%q) = %q, expected %q", name, codec, expected)
+		}
+	}
+
+	if _, err := ParseCompression("lzma"); err == nil {
+		t.Error("Expected error for unknown compression")
+	}
+
+	if CompressionZstd.Extension() != ".zst" || CompressionGzip.Extension() != ".gz" || CompressionNone.Extension() != "" {
+		t.Error("Unexpected codec extensions")
+	}
+}
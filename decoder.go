@@ -0,0 +1,86 @@
+package gowaveform
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/schollz/audiomorph"
+)
+
+// DecoderFunc decodes an audio stream into deinterleaved samples at their native bit depth
+type DecoderFunc func(io.Reader) (*audiomorph.Audio, error)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = map[string]DecoderFunc{}
+)
+
+// RegisterDecoder plugs in a decoder for files with the given extension (e.g. ".opus"),
+// used by LoadWaveform and LoadWaveformFS in place of the built-in decoders, so
+// applications can add proprietary or external formats. Registering a nil decoder
+// removes the extension again. It is safe to call concurrently with loading.
+func RegisterDecoder(ext string, fn DecoderFunc) {
+	ext = normalizeExt(ext)
+
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if fn == nil {
+		delete(decoders, ext)
+		return
+	}
+	decoders[ext] = fn
+}
+
+// registeredDecoder returns the decoder registered for the extension of filename, if any
+func registeredDecoder(filename string) (DecoderFunc, bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	fn, ok := decoders[normalizeExt(filepath.Ext(filename))]
+	return fn, ok
+}
+
+// registeredExtensions returns the extensions that have a registered decoder
+func registeredExtensions() []string {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	exts := make([]string, 0, len(decoders))
+	for ext := range decoders {
+		exts = append(exts, ext)
+	}
+	return exts
+}
+
+// normalizeExt lower-cases an extension and adds the leading dot if missing
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// decodeWithRegistered decodes r with a registered decoder and checks its output
+func decodeWithRegistered(fn DecoderFunc, r io.Reader) (*audiomorph.Audio, error) {
+	audio, err := fn(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio file: %w", err)
+	}
+	if audio == nil || audio.NumChannels <= 0 || audio.SampleRate <= 0 || len(audio.Data) != audio.NumChannels {
+		return nil, fmt.Errorf("failed to decode audio file: decoder returned invalid audio")
+	}
+	return audio, nil
+}
+
+// decodeFileWithRegistered opens filename and decodes it with a registered decoder
+func decodeFileWithRegistered(fn DecoderFunc, filename string) (*audiomorph.Audio, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+	return decodeWithRegistered(fn, f)
+}
@@ -0,0 +1,77 @@
+package gowaveform
+
+import (
+	"io"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/schollz/audiomorph"
+)
+
+// decodeFake decodes each byte of the stream as one 16-bit mono sample scaled by 100
+func decodeFake(r io.Reader) (*audiomorph.Audio, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]int, len(raw))
+	for i, b := range raw {
+		samples[i] = int(b) * 100
+	}
+	return &audiomorph.Audio{NumChannels: 1, SampleRate: 8000, BitDepth: 16, Data: [][]int{samples}}, nil
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	tmpFile := "/tmp/test_decoder.fake"
+	defer os.Remove(tmpFile)
+	if err := os.WriteFile(tmpFile, []byte{1, 2, 3, 200}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterDecoder("FAKE", decodeFake)
+	defer RegisterDecoder(".fake", nil)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if waveform.SampleRate != 8000 || waveform.Channels != 1 || waveform.Duration() != 4.0/8000 {
+		t.Errorf("Unexpected format: %d Hz, %d channels, %.6fs", waveform.SampleRate, waveform.Channels, waveform.Duration())
+	}
+	if v, _ := waveform.SampleAtTime(3.0/8000, 0); v != 20000 {
+		t.Errorf("Expected the last sample to be 20000, got %d", v)
+	}
+
+	fsys := fstest.MapFS{"sounds/a.fake": {Data: []byte{5, 6}}}
+	waveform, err = LoadWaveformFS(fsys, "sounds/a.fake")
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+	if waveform.Duration() != 2.0/8000 {
+		t.Errorf("Expected 2 frames, got %.6fs", waveform.Duration())
+	}
+
+	// Without the decoder the format is unknown again
+	RegisterDecoder(".fake", nil)
+	if _, err := LoadWaveform(tmpFile); err == nil {
+		t.Error("Expected an error after removing the decoder")
+	}
+}
+
+func TestRegisterDecoderInvalidAudio(t *testing.T) {
+	tmpFile := "/tmp/test_decoder.broken"
+	defer os.Remove(tmpFile)
+	if err := os.WriteFile(tmpFile, []byte{0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	RegisterDecoder(".broken", func(io.Reader) (*audiomorph.Audio, error) {
+		return &audiomorph.Audio{NumChannels: 2, SampleRate: 44100, Data: [][]int{{0}}}, nil
+	})
+	defer RegisterDecoder(".broken", nil)
+
+	if _, err := LoadWaveform(tmpFile); err == nil {
+		t.Error("Expected an error for audio with fewer channels of data than it declares")
+	}
+}
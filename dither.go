@@ -0,0 +1,102 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strings"
+)
+
+// Dither selects how samples deeper than 16 bits are reduced to the 16-bit samples a
+// Waveform holds
+type Dither int
+
+const (
+	// DitherNone truncates the extra bits, which adds distortion correlated with the
+	// signal to quiet passages and fades
+	DitherNone Dither = iota
+	// DitherTPDF adds triangular noise of ±1 LSB before rounding, which turns the
+	// truncation distortion into a constant, signal-independent noise floor
+	DitherTPDF
+	// DitherShaped adds TPDF dither with first-order noise shaping, moving the noise
+	// floor up in frequency where hearing is less sensitive
+	DitherShaped
+)
+
+// String returns the name of the dither as accepted by ParseDither
+func (d Dither) String() string {
+	switch d {
+	case DitherTPDF:
+		return "tpdf"
+	case DitherShaped:
+		return "shaped"
+	default:
+		return "none"
+	}
+}
+
+// ParseDither parses a dither name: none, tpdf or shaped
+func ParseDither(name string) (Dither, error) {
+	switch strings.ToLower(name) {
+	case "", "none":
+		return DitherNone, nil
+	case "tpdf":
+		return DitherTPDF, nil
+	case "shaped":
+		return DitherShaped, nil
+	default:
+		return DitherNone, fmt.Errorf("unknown dither: %s (supported: none, tpdf, shaped)", name)
+	}
+}
+
+// OptionDither dithers 24- and 32-bit audio while it is reduced to 16 bits on load,
+// instead of truncating it, so the samples returned by Samples and the views derived
+// from them carry no truncation distortion. 8- and 16-bit audio is not changed. The
+// dither noise is seeded the same way on every load, so loading a file twice gives
+// identical samples.
+func OptionDither(dither Dither) LoadOption {
+	return func(c *loadConfig) {
+		c.dither = dither
+	}
+}
+
+// ditherSeed seeds the dither noise so loads are reproducible
+const ditherSeed = 0x6477
+
+// ditherer reduces decoded samples to int16 with dither, keeping the noise shaping
+// error of each channel between calls
+type ditherer struct {
+	dither Dither
+	rng    *rand.Rand
+	errors []float64 // Quantization error of the previous sample per channel
+}
+
+// newDitherer returns a ditherer for interleaved audio with the given channels, or nil
+// when the samples are truncated as they are
+func newDitherer(dither Dither, bitDepth, channels int) *ditherer {
+	if dither == DitherNone || (bitDepth != 24 && bitDepth != 32) {
+		return nil
+	}
+	return &ditherer{
+		dither: dither,
+		rng:    rand.New(rand.NewPCG(ditherSeed, uint64(bitDepth))),
+		errors: make([]float64, channels),
+	}
+}
+
+// toInt16 converts a decoded sample of a channel to int16 like the package-level
+// toInt16, adding dither when d is not nil
+func (d *ditherer) toInt16(sample, bitDepth, channel int) int16 {
+	if d == nil {
+		return toInt16(sample, bitDepth)
+	}
+
+	// The sample in units of the 16-bit LSB
+	v := float64(sample) / float64(int(1)<<(bitDepth-16))
+	if d.dither == DitherShaped {
+		v -= d.errors[channel]
+	}
+	y := math.Round(v + d.rng.Float64() - d.rng.Float64())
+	d.errors[channel] = y - v
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, y)))
+}
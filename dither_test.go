@@ -0,0 +1,82 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+// ditherError reduces a 24-bit signal to 16 bits and returns the mean of the output in
+// 16-bit LSB and the power of the error after a moving average over 16 samples, which
+// keeps the low frequencies
+func ditherError(dither Dither, signal func(i int) float64, n int) (mean, lowPower float64) {
+	d := newDitherer(dither, 24, 1)
+	errs := make([]float64, n)
+	for i := range n {
+		in := signal(i)
+		out := d.toInt16(int(math.Round(in*256)), 24, 0)
+		mean += float64(out)
+		errs[i] = float64(out) - in
+	}
+	const avg = 16
+	for i := avg; i < n; i++ {
+		var sum float64
+		for _, e := range errs[i-avg : i] {
+			sum += e
+		}
+		lowPower += (sum / avg) * (sum / avg)
+	}
+	return mean / float64(n), lowPower / float64(n-avg)
+}
+
+func TestDither(t *testing.T) {
+	const n = 100000
+
+	// A level of a quarter LSB vanishes when truncated; dither keeps it on average
+	quarter := func(int) float64 { return 0.25 }
+	if mean, _ := ditherError(DitherNone, quarter, n); mean != 0 {
+		t.Errorf("Expected truncation to 0, got a mean of %.3f", mean)
+	}
+	for _, dither := range []Dither{DitherTPDF, DitherShaped} {
+		if mean, _ := ditherError(dither, quarter, n); math.Abs(mean-0.25) > 0.02 {
+			t.Errorf("%s: expected a mean of 0.25 LSB, got %.3f", dither, mean)
+		}
+	}
+
+	// Noise shaping moves the error away from the low frequencies
+	sine := func(i int) float64 { return 1000 * math.Sin(float64(i)*0.01) }
+	_, tpdf := ditherError(DitherTPDF, sine, n)
+	_, shaped := ditherError(DitherShaped, sine, n)
+	if shaped > tpdf/4 {
+		t.Errorf("Expected far less low-frequency error with noise shaping, got %.4f vs %.4f", shaped, tpdf)
+	}
+
+	// Loads are reproducible, and 16-bit audio is never dithered
+	a, b := newDitherer(DitherTPDF, 24, 1), newDitherer(DitherTPDF, 24, 1)
+	for i := range 1000 {
+		if a.toInt16(i*37, 24, 0) != b.toInt16(i*37, 24, 0) {
+			t.Fatal("Expected identical dither for identical input")
+		}
+	}
+	if newDitherer(DitherTPDF, 16, 1) != nil || newDitherer(DitherNone, 24, 1) != nil {
+		t.Error("Expected no ditherer for 16-bit audio or DitherNone")
+	}
+
+	// Full scale does not wrap around
+	d := newDitherer(DitherShaped, 24, 1)
+	for range 100 {
+		if v := d.toInt16(1<<23-1, 24, 0); v < 32000 {
+			t.Fatalf("Expected full scale to stay positive, got %d", v)
+		}
+	}
+}
+
+func TestParseDither(t *testing.T) {
+	for _, dither := range []Dither{DitherNone, DitherTPDF, DitherShaped} {
+		if got, err := ParseDither(dither.String()); err != nil || got != dither {
+			t.Errorf("ParseDither(%q) = %v, %v", dither.String(), got, err)
+		}
+	}
+	if _, err := ParseDither("rectangular"); err == nil {
+		t.Error("Expected an error for an unknown dither")
+	}
+}
@@ -0,0 +1,83 @@
+package gowaveform
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultDuplicateThreshold is a Similarity above which two files are taken to be the
+// same audio: lossy encodes, resampled and level-adjusted copies score above it, while
+// unrelated audio and a clip cut from a longer file score below it
+const DefaultDuplicateThreshold = 0.5
+
+// FindDuplicates scans the directory tree under root for audio files and groups those
+// whose fingerprints are at least threshold similar (see Fingerprint.Similarity), even
+// when they differ in format, sample rate or level. A file is in the group of any file
+// it is similar to, so groups can chain. Each group lists its paths in order, and the
+// groups are ordered by their first path. Files that cannot be read are skipped.
+// Scan options, e.g. OptionScanWorkers, apply to the scan.
+func FindDuplicates(ctx context.Context, root string, threshold float64, opts ...ScanOption) ([][]string, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("invalid threshold %.2f: must be above 0 and at most 1", threshold)
+	}
+	opts = append(opts[:len(opts):len(opts)], OptionScanOverviewWidth(0), OptionScanThumbnail(0, 0), OptionScanFingerprint())
+	results, err := ScanLibrary(ctx, root, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []ScanResult
+	for result := range results {
+		if result.Err == nil {
+			files = append(files, result)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Similarity is at most the ratio of the lengths, so with the files ordered by
+	// length each is only compared with those not too much longer
+	sort.Slice(files, func(i, j int) bool {
+		return len(files[i].Fingerprint.Hashes) < len(files[j].Fingerprint.Hashes)
+	})
+	parent := make([]int, len(files))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, a := range files {
+		for j := i + 1; j < len(files); j++ {
+			b := files[j]
+			if float64(len(a.Fingerprint.Hashes)) < threshold*float64(len(b.Fingerprint.Hashes)) {
+				break
+			}
+			if find(i) != find(j) && a.Fingerprint.Similarity(b.Fingerprint) >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	members := map[int][]string{}
+	for i, file := range files {
+		members[find(i)] = append(members[find(i)], file.Path)
+	}
+	var groups [][]string
+	for _, paths := range members {
+		if len(paths) > 1 {
+			sort.Strings(paths)
+			groups = append(groups, paths)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0] < groups[j][0]
+	})
+	return groups, nil
+}
@@ -0,0 +1,77 @@
+package gowaveform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"breaks", "downloads", "one-shots"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The same break three times at different rates and levels, another melody with a
+	// copy of its own, and a clip of the break, which is not a duplicate
+	writeTestWAV(t, filepath.Join(root, "breaks", "amen.wav"), 8000, 1, testMelody(8000, 1, 1, 4))
+	writeTestWAV(t, filepath.Join(root, "downloads", "amen_44k.wav"), 44100, 1, testMelody(44100, 1, 1, 4))
+	writeTestWAV(t, filepath.Join(root, "downloads", "amen (quiet).wav"), 16000, 1, testMelody(16000, 1, 0.3, 4))
+	writeTestWAV(t, filepath.Join(root, "breaks", "think.wav"), 8000, 1, testMelody(8000, 2, 1, 4))
+	writeTestWAV(t, filepath.Join(root, "downloads", "think copy.wav"), 22050, 1, testMelody(22050, 2, 1, 4))
+	writeTestWAV(t, filepath.Join(root, "one-shots", "amen_intro.wav"), 8000, 1, testMelody(8000, 1, 1, 1))
+	writeTestWAV(t, filepath.Join(root, "one-shots", "other.wav"), 8000, 1, testMelody(8000, 3, 1, 4))
+	if err := os.WriteFile(filepath.Join(root, "broken.wav"), []byte("not a wav file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := FindDuplicates(context.Background(), root, DefaultDuplicateThreshold, OptionScanWorkers(2))
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+	expected := [][]string{
+		{
+			filepath.Join(root, "breaks", "amen.wav"),
+			filepath.Join(root, "downloads", "amen (quiet).wav"),
+			filepath.Join(root, "downloads", "amen_44k.wav"),
+		},
+		{
+			filepath.Join(root, "breaks", "think.wav"),
+			filepath.Join(root, "downloads", "think copy.wav"),
+		},
+	}
+	if !reflect.DeepEqual(groups, expected) {
+		t.Errorf("Expected groups %v, got %v", expected, groups)
+	}
+
+	// Nothing is similar enough at the strictest threshold but identical files
+	groups, err = FindDuplicates(context.Background(), root, 1)
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
+	if len(groups) != 0 {
+		t.Errorf("Expected no groups, got %v", groups)
+	}
+}
+
+func TestFindDuplicatesErrors(t *testing.T) {
+	root := t.TempDir()
+	for _, threshold := range []float64{0, -0.5, 1.5} {
+		if _, err := FindDuplicates(context.Background(), root, threshold); err == nil {
+			t.Errorf("Expected an error for threshold %.1f", threshold)
+		}
+	}
+	if _, err := FindDuplicates(context.Background(), filepath.Join(root, "missing"), DefaultDuplicateThreshold); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FindDuplicates(ctx, root, DefaultDuplicateThreshold); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
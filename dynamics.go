@@ -0,0 +1,95 @@
+package gowaveform
+
+import "math"
+
+// shortTermWindow is the length in seconds of the EBU R128 short-term loudness window
+const shortTermWindow = 3.0
+
+// CrestFactor returns the ratio of the peak to the RMS level in dB in consecutive
+// windows of windowSeconds, over all channels. A sine reads 3 dB; heavily limited
+// masters sit around 6 to 8 dB and dynamic material at 15 dB and above. Silent
+// windows are NaN. It returns nil for a window that is not positive.
+func (w *Waveform) CrestFactor(windowSeconds float64) []float64 {
+	return w.crestFactor(0, w.availableFrames(), windowSeconds)
+}
+
+// crestFactor computes the crest factor in windows of windowSeconds starting at
+// startFrame; the last window may be shorter
+func (w *Waveform) crestFactor(startFrame, endFrame int, windowSeconds float64) []float64 {
+	if w.Channels == 0 || windowSeconds <= 0 || startFrame >= endFrame {
+		return nil
+	}
+	window := max(1, int(math.Round(windowSeconds*float64(w.SampleRate))))
+
+	values := make([]float64, 0, (endFrame-startFrame+window-1)/window)
+	for start := startFrame; start < endFrame; start += window {
+		end := min(start+window, endFrame)
+		var peak, energy float64
+		for _, sample := range w.audioData[start*w.Channels : end*w.Channels] {
+			v := float64(sample)
+			peak = math.Max(peak, math.Abs(v))
+			energy += v * v
+		}
+		if energy == 0 {
+			values = append(values, math.NaN())
+			continue
+		}
+		rms := math.Sqrt(energy / float64((end-start)*w.Channels))
+		values = append(values, ToDBFS(peak/rms))
+	}
+	return values
+}
+
+// ShortTermLoudness returns the loudness in LUFS of the 3 s of audio centered on each
+// consecutive step of stepSeconds, K-weighted as specified by ITU-R BS.1770 without
+// gating. This is the EBU R128 short-term loudness, centered rather than trailing so
+// it lines up with the waveform. Near the ends of the file the window is shorter.
+// Silence is -Inf. It returns nil for a step that is not positive.
+func (w *Waveform) ShortTermLoudness(stepSeconds float64) []float64 {
+	return w.shortTermLoudness(0, w.availableFrames(), stepSeconds)
+}
+
+// shortTermLoudness computes the short-term loudness of steps of stepSeconds starting
+// at startFrame; the last step may be shorter
+func (w *Waveform) shortTermLoudness(startFrame, endFrame int, stepSeconds float64) []float64 {
+	if w.Channels == 0 || stepSeconds <= 0 || startFrame >= endFrame {
+		return nil
+	}
+	step := max(1, int(math.Round(stepSeconds*float64(w.SampleRate))))
+	halfWindow := int(shortTermWindow * float64(w.SampleRate) / 2)
+
+	// Sum the weighted, K-filtered energy of all channels per 10 ms block over every
+	// frame a window reaches; windows are then sums of consecutive blocks
+	block := max(1, w.SampleRate/100)
+	lo := max(startFrame-halfWindow, 0)
+	hi := min(endFrame+halfWindow, w.availableFrames())
+	blocks := make([]float64, (hi-lo+block-1)/block)
+	for ch := 0; ch < w.Channels; ch++ {
+		weight := loudnessChannelWeight(ch, w.Channels)
+		if weight == 0 {
+			continue
+		}
+		shelf, highPass := kWeightingFilters(w.SampleRate)
+		for frame := max(lo-filterPreroll, 0); frame < hi; frame++ {
+			x := float64(w.audioData[frame*w.Channels+ch]) / 32768.0
+			y := highPass.process(shelf.process(x))
+			if frame >= lo {
+				blocks[(frame-lo)/block] += weight * y * y
+			}
+		}
+	}
+	sums := make([]float64, len(blocks)+1)
+	for i, energy := range blocks {
+		sums[i+1] = sums[i] + energy
+	}
+
+	values := make([]float64, 0, (endFrame-startFrame+step-1)/step)
+	for start := startFrame; start < endFrame; start += step {
+		center := start + min(step, endFrame-start)/2
+		first := (max(center-halfWindow, lo) - lo) / block
+		last := (min(center+halfWindow, hi) - lo + block - 1) / block
+		frames := min(last*block, hi-lo) - first*block
+		values = append(values, energyToLUFS((sums[last]-sums[first])/float64(frames)))
+	}
+	return values
+}
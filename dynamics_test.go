@@ -0,0 +1,79 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCrestFactor(t *testing.T) {
+	// 1s of a sine, 1s of a square wave and 0.5s of silence, in stereo
+	w := toneWaveform(44100, 2, 441, 0.5, 1)
+	for i := range 44100 {
+		v := int16(16384)
+		if (i/50)%2 == 1 {
+			v = -v
+		}
+		w.audioData = append(w.audioData, v, v)
+	}
+	w.audioData = append(w.audioData, make([]int16, 44100)...)
+	w.totalSamples = len(w.audioData) / 2
+
+	values := w.CrestFactor(0.5)
+	if len(values) != 5 {
+		t.Fatalf("Expected 5 windows, got %d", len(values))
+	}
+	for i, expected := range []float64{3.01, 3.01, 0, 0} {
+		if math.Abs(values[i]-expected) > 0.05 {
+			t.Errorf("Window %d: expected a crest factor of %.2f dB, got %.2f dB", i, expected, values[i])
+		}
+	}
+	if !math.IsNaN(values[4]) {
+		t.Errorf("Expected NaN for a silent window, got %.2f", values[4])
+	}
+	if w.CrestFactor(0) != nil {
+		t.Error("Expected nil for a zero window")
+	}
+}
+
+func TestShortTermLoudness(t *testing.T) {
+	// A 997 Hz sine at full scale reads -3.01 LUFS in mono as specified by BS.1770;
+	// at -20 dBFS it reads -23.01 LUFS
+	w := toneWaveform(48000, 1, 997, 0.1, 10)
+	values := w.ShortTermLoudness(1)
+	if len(values) != 10 {
+		t.Fatalf("Expected 10 steps, got %d", len(values))
+	}
+	for i, v := range values {
+		if math.Abs(v+23.01) > 0.1 {
+			t.Errorf("Step %d: expected -23.01 LUFS, got %.2f", i, v)
+		}
+	}
+
+	// Quiet and loud halves; the windows in the middle of each half see only that half
+	quiet := toneWaveform(48000, 2, 997, 0.01, 5)
+	loud := toneWaveform(48000, 2, 997, 0.5, 5)
+	quiet.audioData = append(quiet.audioData, loud.audioData...)
+	quiet.totalSamples += loud.totalSamples
+	values = quiet.ShortTermLoudness(0.5)
+	if len(values) != 20 {
+		t.Fatalf("Expected 20 steps, got %d", len(values))
+	}
+	// Stereo reads 3 dB louder than mono, so a full-scale sine is 0 LUFS
+	if math.Abs(values[4]-ToDBFS(0.01)) > 0.1 {
+		t.Errorf("Expected the quiet half at %.2f LUFS, got %.2f", ToDBFS(0.01), values[4])
+	}
+	if math.Abs(values[15]-ToDBFS(0.5)) > 0.1 {
+		t.Errorf("Expected the loud half at %.2f LUFS, got %.2f", ToDBFS(0.5), values[15])
+	}
+	if values[10] <= values[4] || values[10] >= values[15] {
+		t.Errorf("Expected the step at the change between the halves, got %.2f", values[10])
+	}
+
+	silent := &Waveform{SampleRate: 48000, Channels: 1, BitsPerSample: 16, audioData: make([]int16, 48000), totalSamples: 48000}
+	if values := silent.ShortTermLoudness(0.5); len(values) != 2 || !math.IsInf(values[0], -1) {
+		t.Errorf("Expected -Inf for silence, got %v", values)
+	}
+	if w.ShortTermLoudness(0) != nil {
+		t.Error("Expected nil for a zero step")
+	}
+}
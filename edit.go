@@ -0,0 +1,63 @@
+package gowaveform
+
+import "math"
+
+// Invert flips the polarity of every sample in place, e.g. to fix a phase-inverted
+// microphone found with ComparePolarity. The most negative sample becomes full scale.
+// See Gain for how in-place edits behave.
+func (w *Waveform) Invert() {
+	w.edit(func(audio []int16) {
+		for i, v := range audio {
+			audio[i] = clampSample(-float64(v))
+		}
+	})
+}
+
+// Reverse reverses the order of the frames in place, keeping the channels of each frame
+// together. See Gain for how in-place edits behave.
+func (w *Waveform) Reverse() {
+	w.edit(func(audio []int16) {
+		frames := len(audio) / max(w.Channels, 1)
+		for i, j := 0, frames-1; i < j; i, j = i+1, j-1 {
+			a := audio[i*w.Channels : (i+1)*w.Channels]
+			b := audio[j*w.Channels : (j+1)*w.Channels]
+			for ch := range a {
+				a[ch], b[ch] = b[ch], a[ch]
+			}
+		}
+	})
+}
+
+// Gain changes the level of every sample in place by db decibels, clipping peaks pushed
+// beyond full scale. Like Invert and Reverse it changes the waveform itself rather than
+// returning a copy: during a progressive load it waits for decoding to finish, and a
+// precomputed overview is computed again. It must not be called while other goroutines
+// read the waveform.
+func (w *Waveform) Gain(db float64) {
+	gain := math.Pow(10, db/20)
+	w.edit(func(audio []int16) {
+		for i, v := range audio {
+			audio[i] = clampSample(float64(v) * gain)
+		}
+	})
+}
+
+// edit applies fn to the samples once they are all decoded and refreshes the overview
+// at the width it was requested at, which can differ from the number of its columns
+func (w *Waveform) edit(fn func(audio []int16)) {
+	w.Wait()
+	fn(w.audioData[:w.availableFrames()*w.Channels])
+	if w.overview != nil {
+		overview, err := w.GenerateView(WaveformOptions{Width: w.overviewWidth})
+		if err != nil {
+			overview = nil
+		}
+		w.overview = overview
+		w.track()
+	}
+}
+
+// clampSample rounds a sample value to int16, clipping it to full scale
+func clampSample(v float64) int16 {
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+}
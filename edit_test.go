@@ -0,0 +1,72 @@
+package gowaveform
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestInvertReverseGain(t *testing.T) {
+	// Three stereo frames
+	w := &Waveform{SampleRate: 1000, Channels: 2, BitsPerSample: 16, audioData: []int16{4, -4, 1000, -2000, -32768, 20000}, totalSamples: 3}
+
+	w.Invert()
+	if expected := []int16{-4, 4, -1000, 2000, 32767, -20000}; !slices.Equal(w.audioData, expected) {
+		t.Errorf("Invert: expected %v, got %v", expected, w.audioData)
+	}
+
+	w.Reverse()
+	if expected := []int16{32767, -20000, -1000, 2000, -4, 4}; !slices.Equal(w.audioData, expected) {
+		t.Errorf("Reverse: expected %v, got %v", expected, w.audioData)
+	}
+
+	w.Gain(ToDBFS(0.25))
+	if expected := []int16{8192, -5000, -250, 500, -1, 1}; !slices.Equal(w.audioData, expected) {
+		t.Errorf("Gain -12 dB: expected %v, got %v", expected, w.audioData)
+	}
+
+	// Peaks beyond full scale clip
+	w.Gain(40)
+	if expected := []int16{32767, -32768, -25000, 32767, -100, 100}; !slices.Equal(w.audioData, expected) {
+		t.Errorf("Gain +40 dB: expected %v, got %v", expected, w.audioData)
+	}
+}
+
+func TestEditRefreshesOverview(t *testing.T) {
+	w := toneWaveform(44100, 1, 440, 0.5, 1)
+	overview, err := w.GenerateView(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	w.overview, w.overviewWidth = overview, 100
+
+	w.Gain(ToDBFS(0.5))
+	if w.Overview() == overview || w.Overview().Length != 100 {
+		t.Fatal("Expected a new overview of the same width")
+	}
+	if got, expected := w.Overview().Data[1], overview.Data[1]/2; got < expected-1 || got > expected+1 {
+		t.Errorf("Expected the overview at half the level, got %d instead of %d", got, expected)
+	}
+}
+
+func TestEditKeepsOverviewWidth(t *testing.T) {
+	tmpFile := "/tmp/test_edit_overview.wav"
+	defer os.Remove(tmpFile)
+
+	// 1000 frames at width 300: 3 samples per pixel and a partial last column
+	createTestWAV(t, tmpFile, 1000, 1.0)
+	w, err := LoadWaveform(tmpFile, OptionPrecomputeOverview(300))
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	length := w.Overview().Length
+	if length != 334 {
+		t.Fatalf("Expected 334 overview columns, got %d", length)
+	}
+
+	w.Gain(-6)
+	w.Invert()
+	if got := w.Overview(); got.Length != length || got.SamplesPerPixel != 3 {
+		t.Errorf("Expected the overview to keep %d columns of 3 samples, got %d of %d", length, got.Length, got.SamplesPerPixel)
+	}
+}
@@ -0,0 +1,53 @@
+package gowaveform
+
+import (
+	"math"
+	"time"
+)
+
+// FadeShape is the gain curve of a fade
+type FadeShape int
+
+const (
+	// FadeLinear ramps the gain in a straight line, which dips in loudness halfway
+	FadeLinear FadeShape = iota
+	// FadeEqualPower ramps the gain along a quarter sine, which keeps the loudness
+	// even, e.g. where a fade-out overlaps the next slice's fade-in
+	FadeEqualPower
+)
+
+// ApplyFade returns a copy of the waveform that fades in over the first in and out over
+// the last out, e.g. so slices chopped from a loop start and end at silence instead of
+// clicking. The first and last frame of a fade are silent. Fades longer than the audio
+// are cut short, and fades that overlap both apply. The waveform itself is not changed.
+func (w *Waveform) ApplyFade(in, out time.Duration, shape FadeShape) *Waveform {
+	frames := w.availableFrames()
+	audio := make([]int16, frames*w.Channels)
+	copy(audio, w.audioData)
+
+	curve := func(t float64) float64 {
+		if shape == FadeEqualPower {
+			return math.Sin(t * math.Pi / 2)
+		}
+		return t
+	}
+	fadeFrames := func(d time.Duration) int {
+		return min(max(int(d.Seconds()*float64(w.SampleRate)), 0), frames)
+	}
+	scale := func(frame int, gain float64) {
+		for i := frame * w.Channels; i < (frame+1)*w.Channels; i++ {
+			audio[i] = int16(math.Round(float64(audio[i]) * gain))
+		}
+	}
+
+	inFrames := fadeFrames(in)
+	for frame := range inFrames {
+		scale(frame, curve(float64(frame)/float64(inFrames)))
+	}
+	outFrames := fadeFrames(out)
+	for i := range outFrames {
+		scale(frames-1-i, curve(float64(i)/float64(outFrames)))
+	}
+
+	return w.withAudio(w.SampleRate, audio, frames)
+}
@@ -0,0 +1,57 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestApplyFade(t *testing.T) {
+	// 100 stereo frames at full level, 1 frame per millisecond
+	w := &Waveform{SampleRate: 1000, Channels: 2, BitsPerSample: 16, audioData: make([]int16, 200), totalSamples: 100}
+	for i := range w.audioData {
+		w.audioData[i] = 10000
+	}
+
+	faded := w.ApplyFade(10*time.Millisecond, 20*time.Millisecond, FadeLinear)
+	tests := []struct {
+		frame    int
+		expected int16
+	}{
+		{0, 0},
+		{5, 5000},
+		{10, 10000},
+		{79, 10000},
+		{89, 5000},
+		{99, 0},
+	}
+	for _, tt := range tests {
+		for ch := range 2 {
+			if got := faded.audioData[tt.frame*2+ch]; got != tt.expected {
+				t.Errorf("Frame %d channel %d: expected %d, got %d", tt.frame, ch, tt.expected, got)
+			}
+		}
+	}
+	if w.audioData[0] != 10000 {
+		t.Error("Expected the original waveform to be unchanged")
+	}
+	if faded.SampleRate != 1000 || faded.Channels != 2 || faded.totalSamples != 100 {
+		t.Errorf("Unexpected format: %d Hz, %d channels, %d frames", faded.SampleRate, faded.Channels, faded.totalSamples)
+	}
+
+	// Equal power is at -3 dB halfway
+	faded = w.ApplyFade(10*time.Millisecond, 0, FadeEqualPower)
+	if got, expected := faded.audioData[10], int16(math.Round(10000*math.Sqrt2/2)); got != expected {
+		t.Errorf("Expected %d halfway through an equal-power fade, got %d", expected, got)
+	}
+	if faded.audioData[198] != 10000 {
+		t.Error("Expected no fade-out for a zero duration")
+	}
+
+	// Fades longer than the audio are cut short to the 100 frames and overlap; frame 50
+	// is halfway into the fade-in and 49 frames from the end of the fade-out
+	faded = w.ApplyFade(time.Second, time.Second, FadeLinear)
+	if got := faded.audioData[100]; got != 2450 {
+		t.Errorf("Expected gains of 0.5 and 0.49 in the middle, got %d", got)
+	}
+}
@@ -0,0 +1,159 @@
+package gowaveform
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// FastRenderer is the name of the renderer that draws min/max columns straight into an
+// image, without a plotting library
+const FastRenderer = "fast"
+
+// fastRenderer fills each pixel column between the lowest minimum and highest maximum
+// of the peaks it covers, with the amplitude range -1 to 1 spanning the full height.
+// It draws no title, axes or markers, which makes it many times faster than gonum/plot
+// for thumbnails and sparklines. Whole pixels are filled unless Style.Antialias is set.
+type fastRenderer struct{}
+
+// DrawWaveform implements the Renderer interface
+func (fastRenderer) DrawWaveform(data *WaveformData, style Style) (image.Image, error) {
+	width, height := max(style.Width, 1), max(style.Height, 1)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	background, foreground := style.Background, style.Foreground
+	if background == nil {
+		background = color.White
+	}
+	if foreground == nil {
+		foreground = color.Black
+	}
+	bg := color.RGBAModel.Convert(background).(color.RGBA)
+	fg := color.RGBAModel.Convert(foreground).(color.RGBA)
+
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = bg.R, bg.G, bg.B, bg.A
+	}
+	if data == nil || data.Length == 0 {
+		return img, nil
+	}
+
+	// Each lane is drawn on its own part of the image, top to bottom
+	pairs := data.peakChannels()
+	lanes, channels := laneChannels(data, style.SplitChannels)
+	for lane := 0; lane < lanes; lane++ {
+		area := img.SubImage(image.Rect(0, lane*height/lanes, width, (lane+1)*height/lanes)).(*image.RGBA)
+		if area.Rect.Empty() {
+			continue
+		}
+
+		// The lowest minimum and highest maximum of the peaks each pixel column covers
+		first, last := channels(lane)
+		lows, highs := make([]int16, width), make([]int16, width)
+		for x := 0; x < width; x++ {
+			// Columns of peaks covered by this pixel column, at least one
+			firstCol := x * data.Length / width
+			lastCol := max(firstCol+1, (x+1)*data.Length/width)
+
+			lo, hi := int16(math.MaxInt16), int16(math.MinInt16)
+			for col := firstCol; col < lastCol; col++ {
+				l, h := lanePeaks(data, col, pairs, first, last)
+				lo, hi = min(lo, l), max(hi, h)
+			}
+			lows[x], highs[x] = lo, hi
+		}
+
+		switch {
+		case style.WaveStyle == WaveStyleBars:
+			dpi := style.DPI
+			if dpi <= 0 {
+				dpi = DefaultDPI
+			}
+			drawBars(area, lows, highs, fg, max(1, barWidth*dpi/DefaultDPI), max(1, barGap*dpi/DefaultDPI))
+		case style.Antialias:
+			drawAntialiased(area, lows, highs, fg)
+		default:
+			drawColumns(area, lows, highs, fg)
+		}
+	}
+	return img, nil
+}
+
+// drawColumns fills each pixel column of img between its peaks
+func drawColumns(img *image.RGBA, lows, highs []int16, fg color.RGBA) {
+	bounds := img.Bounds()
+	scale := float64(bounds.Dy()-1) / 2
+	for x := 0; x < bounds.Dx(); x++ {
+		top := int(math.Round((1 - float64(highs[x])/32768) * scale))
+		bottom := int(math.Round((1 - float64(lows[x])/32768) * scale))
+		for y := max(top, 0); y <= min(bottom, bounds.Dy()-1); y++ {
+			i := img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = fg.R, fg.G, fg.B, fg.A
+		}
+	}
+}
+
+// drawBars fills bars of bar pixels with gap pixels between them, each spanning the
+// peaks of the columns under it and its gap
+func drawBars(img *image.RGBA, lows, highs []int16, fg color.RGBA, bar, gap int) {
+	bounds := img.Bounds()
+	scale := float64(bounds.Dy()-1) / 2
+	for left := 0; left < bounds.Dx(); left += bar + gap {
+		lo, hi := int16(math.MaxInt16), int16(math.MinInt16)
+		for x := left; x < min(left+bar+gap, bounds.Dx()); x++ {
+			lo, hi = min(lo, lows[x]), max(hi, highs[x])
+		}
+		top := int(math.Round((1 - float64(hi)/32768) * scale))
+		bottom := int(math.Round((1 - float64(lo)/32768) * scale))
+		for y := max(top, 0); y <= min(bottom, bounds.Dy()-1); y++ {
+			for x := left; x < min(left+bar, bounds.Dx()); x++ {
+				i := img.PixOffset(bounds.Min.X+x, bounds.Min.Y+y)
+				img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = fg.R, fg.G, fg.B, fg.A
+			}
+		}
+	}
+}
+
+// drawAntialiased fills the outline through the peaks of each pixel column, at the
+// column centers, with a vector rasterizer that shades edge pixels by coverage. Columns
+// are at least one pixel tall so silence still draws a line.
+func drawAntialiased(img *image.RGBA, lows, highs []int16, fg color.RGBA) {
+	width, height := img.Bounds().Dx(), img.Bounds().Dy()
+	tops, bottoms := make([]float32, width), make([]float32, width)
+	for x := 0; x < width; x++ {
+		top := (1 - float32(highs[x])/32768) * float32(height) / 2
+		bottom := (1 - float32(lows[x])/32768) * float32(height) / 2
+		if pad := (1 - (bottom - top)) / 2; pad > 0 {
+			top, bottom = top-pad, bottom+pad
+		}
+		tops[x], bottoms[x] = top, bottom
+	}
+
+	// Along the maxima from left to right, then back along the minima
+	z := vector.NewRasterizer(width, height)
+	z.DrawOp = draw.Over
+	z.MoveTo(0, tops[0])
+	for x := 0; x < width; x++ {
+		z.LineTo(float32(x)+0.5, tops[x])
+	}
+	z.LineTo(float32(width), tops[width-1])
+	z.LineTo(float32(width), bottoms[width-1])
+	for x := width - 1; x >= 0; x-- {
+		z.LineTo(float32(x)+0.5, bottoms[x])
+	}
+	z.LineTo(0, bottoms[0])
+	z.ClosePath()
+	z.Draw(img, img.Bounds(), image.NewUniform(fg), image.Point{})
+}
+
+// fastRenderable reports whether a configuration asks for nothing but the waveform
+// edge to edge, so the fast renderer can draw it in place of gonum/plot: a sparkline,
+// or both axes hidden without a title, and no overlays
+func (c PlotConfig) fastRenderable() bool {
+	axisFree := c.sparkline || (c.title == "" && c.hideYAxis && (c.hideXAxis || !c.showTimestamp))
+	overlays := c.spectral || c.showRMS || c.pitchOverlay || c.phaseOverlay || c.levelOverlay || c.heatStrip != StripNone || c.overs || c.bpm > 0 || c.playhead >= 0 || len(c.markers) > 0 || len(c.regions) > 0
+	return axisFree && !overlays
+}
@@ -0,0 +1,210 @@
+package gowaveform
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestFastRenderer(t *testing.T) {
+	// Two stereo columns: a full-scale one, then a quiet positive one
+	data := &WaveformData{
+		Length: 2,
+		Data: []int16{
+			-32768, 32767, -100, 100,
+			0, 8192, 0, 16384,
+		},
+	}
+	style := Style{Width: 4, Height: 9, Background: color.White, Foreground: color.RGBA{255, 0, 0, 255}}
+
+	img, err := fastRenderer{}.DrawWaveform(data, style)
+	if err != nil {
+		t.Fatalf("DrawWaveform failed: %v", err)
+	}
+	rgba, ok := img.(*image.RGBA)
+	if !ok {
+		t.Fatalf("Expected an RGBA image, got %T", img)
+	}
+	if b := rgba.Bounds(); b.Dx() != 4 || b.Dy() != 9 {
+		t.Fatalf("Expected a 4x9 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	// Each column is drawn over two pixels; rows 0 to 8 span 1 to -1
+	red := color.RGBA{255, 0, 0, 255}
+	for x := 0; x < 4; x++ {
+		top, bottom := 0, 8
+		if x >= 2 {
+			top, bottom = 2, 4 // The loudest channel reaches 0.5
+		}
+		for y := 0; y < 9; y++ {
+			got := rgba.RGBAAt(x, y)
+			if inside := y >= top && y <= bottom; inside != (got == red) {
+				t.Errorf("Pixel (%d, %d): expected drawn=%v, got %v", x, y, inside, got)
+			}
+		}
+	}
+
+	// Without peaks the image is just the background
+	img, err = fastRenderer{}.DrawWaveform(&WaveformData{}, Style{Width: 2, Height: 2})
+	if err != nil {
+		t.Fatalf("DrawWaveform failed: %v", err)
+	}
+	if c := img.(*image.RGBA).RGBAAt(1, 1); c != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected a white background, got %v", c)
+	}
+}
+
+func TestFastRendererAntialias(t *testing.T) {
+	// A slow sine, whose sloped edges cross pixels part way at a small width
+	data := &WaveformData{Length: 1000, Data: make([]int16, 2000)}
+	for i := 0; i < data.Length; i++ {
+		v := int16(20000 * math.Sin(float64(i)/80))
+		data.Data[2*i], data.Data[2*i+1] = -v, v
+	}
+
+	// Counts pixels that are neither background nor foreground
+	partial := func(antialias bool) int {
+		img, err := fastRenderer{}.DrawWaveform(data, Style{Width: 200, Height: 40, Foreground: color.Black, Antialias: antialias})
+		if err != nil {
+			t.Fatalf("DrawWaveform failed: %v", err)
+		}
+		rgba := img.(*image.RGBA)
+		count := 0
+		for i := 0; i < len(rgba.Pix); i += 4 {
+			if v := rgba.Pix[i]; v > 0 && v < 255 {
+				count++
+			}
+		}
+		return count
+	}
+
+	if n := partial(false); n != 0 {
+		t.Errorf("Expected only whole pixels without antialiasing, got %d shaded", n)
+	}
+	if n := partial(true); n < 100 {
+		t.Errorf("Expected shaded edge pixels with antialiasing, got %d", n)
+	}
+
+	// Silence still draws a full line through the middle
+	img, err := fastRenderer{}.DrawWaveform(&WaveformData{Length: 4, Data: make([]int16, 8)}, Style{Width: 4, Height: 9, Antialias: true})
+	if err != nil {
+		t.Fatalf("DrawWaveform failed: %v", err)
+	}
+	if c := img.(*image.RGBA).RGBAAt(2, 4); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected a black center line, got %v", c)
+	}
+}
+
+func TestFastRenderable(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want bool
+	}{
+		{"default", nil, false},
+		{"sparkline", []Option{OptionSparkline(true), OptionSetTitle("Ignored")}, true},
+		{"hidden axes", []Option{OptionHideXAxis(true), OptionHideYAxis(true)}, true},
+		{"no timestamps", []Option{OptionShowTimestamp(false), OptionHideYAxis(true)}, true},
+		{"y-axis shown", []Option{OptionHideXAxis(true)}, false},
+		{"title", []Option{OptionHideXAxis(true), OptionHideYAxis(true), OptionSetTitle("Song")}, false},
+		{"RMS", []Option{OptionSparkline(true), OptionShowRMS(true)}, false},
+		{"spectral", []Option{OptionSparkline(true), OptionSpectral(true)}, false},
+		{"playhead", []Option{OptionSparkline(true), OptionPlayhead(0.5)}, false},
+		{"markers", []Option{OptionSparkline(true), OptionMarkers([]Marker{{Time: 0.5}})}, false},
+		{"beat grid", []Option{OptionSparkline(true), OptionBeatGrid(120, 0)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultPlotConfig()
+			for _, opt := range tt.opts {
+				opt(&config)
+			}
+			if got := config.fastRenderable(); got != tt.want {
+				t.Errorf("fastRenderable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An explicit renderer takes precedence
+	config := defaultPlotConfig()
+	OptionSparkline(true)(&config)
+	OptionRenderer(GonumRenderer)(&config)
+	if name := config.rendererName(); name != GonumRenderer {
+		t.Errorf("Expected the requested renderer, got %q", name)
+	}
+}
+
+func TestSavePlotFast(t *testing.T) {
+	tmpWav := "/tmp/test_plot_fast.wav"
+	tmpPlot := "/tmp/test_plot_fast.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpWav, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionSetWidth(300),
+		OptionSetHeight(60),
+		OptionSetForegroundColor("#0000FF"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// Axis-free plots are drawn edge to edge, so the waveform (0 to 0.3, rows 21 to 30)
+	// reaches the first column
+	if b := img.Bounds(); b.Dx() != 300 || b.Dy() != 60 {
+		t.Errorf("Expected a 300x60 image, got %dx%d", b.Dx(), b.Dy())
+	}
+	if c := color.RGBAModel.Convert(img.At(0, 25)).(color.RGBA); c != (color.RGBA{0, 0, 255, 255}) {
+		t.Errorf("Expected the waveform at the left edge, got %v", c)
+	}
+}
+
+func BenchmarkRenderPlotFast(b *testing.B) {
+	benchmarkRenderPlot(b, OptionRenderer(FastRenderer))
+}
+
+func BenchmarkRenderPlotGonum(b *testing.B) {
+	benchmarkRenderPlot(b, OptionRenderer(GonumRenderer))
+}
+
+func benchmarkRenderPlot(b *testing.B, renderer Option) {
+	tmpWav := "/tmp/bench_render_plot.wav"
+	defer os.Remove(tmpWav)
+
+	createTestWAV(&testing.T{}, tmpWav, 44100, 10.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		b.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RenderPlot(waveform, renderer, OptionSparkline(true)); err != nil {
+			b.Fatalf("RenderPlot failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// Defaults for FeatureOptions
+const (
+	DefaultFeatureFFTSize = 2048
+	DefaultMelBands       = 128
+)
+
+// FeatureOptions configures Chromagram and MelSpectrogram
+type FeatureOptions struct {
+	Start        float64 // Start time in seconds
+	End          float64 // End time in seconds (0 means end of file)
+	FFTSize      int     // Window size, a power of two (default 2048)
+	HopSize      int     // Frames between the starts of consecutive windows (default FFTSize/4)
+	MelBands     int     // Number of mel bands for MelSpectrogram (default 128)
+	MinFrequency float64 // Lowest frequency in Hz (default 0 for mel bands, 55 for chroma)
+	MaxFrequency float64 // Highest frequency in Hz (default Nyquist for mel bands, 5000 for chroma)
+}
+
+// Features is a matrix of audio features with one row per analysis window, e.g. as
+// input for a machine learning model
+type Features struct {
+	SampleRate int         `json:"sample_rate"`
+	FFTSize    int         `json:"fft_size"`
+	HopSize    int         `json:"hop_size"`
+	Start      float64     `json:"start"` // Time in seconds of the start of the first window
+	Rows       [][]float64 `json:"rows"`  // Rows[window][feature]
+}
+
+// Time returns the time in seconds of the center of a row's window
+func (f *Features) Time(row int) float64 {
+	return f.Start + float64(row*f.HopSize+f.FFTSize/2)/float64(f.SampleRate)
+}
+
+// Chromagram returns the energy of the twelve pitch classes (C to B, see PitchClassNames)
+// in each window, scaled so the strongest pitch class of a window is 1. Silent windows
+// are all zero. Bins are assigned to the nearest pitch class for A4 = 440 Hz; raise the
+// FFT size to separate semitones below a few hundred Hz.
+func (w *Waveform) Chromagram(opts FeatureOptions) (*Features, error) {
+	if opts.MinFrequency == 0 {
+		opts.MinFrequency = keyMinFrequency
+	}
+	if opts.MaxFrequency == 0 {
+		opts.MaxFrequency = keyMaxFrequency
+	}
+
+	return w.featureRows(opts, func(magnitudes []float64) []float64 {
+		var chroma [12]float64
+		addChroma(&chroma, magnitudes, w.SampleRate, opts.MinFrequency, opts.MaxFrequency)
+
+		var peak float64
+		for _, v := range chroma {
+			peak = math.Max(peak, v)
+		}
+		row := make([]float64, 12)
+		if peak > 0 {
+			for pc, v := range chroma {
+				row[pc] = v / peak
+			}
+		}
+		return row
+	})
+}
+
+// MelSpectrogram returns the level in dBFS of each mel band in each window, from the
+// lowest band up. Bands are triangular filters evenly spaced on the mel scale (HTK
+// formula) between the minimum and maximum frequency; a full-scale sine at the center
+// of a band reads about 0 dBFS. Levels are floored at SpectrogramFloor. Low bands that
+// are narrower than an FFT bin stay empty; raise the FFT size or lower MelBands.
+func (w *Waveform) MelSpectrogram(opts FeatureOptions) (*Features, error) {
+	if opts.MelBands == 0 {
+		opts.MelBands = DefaultMelBands
+	}
+	if opts.MelBands < 0 {
+		return nil, fmt.Errorf("invalid number of mel bands: %d", opts.MelBands)
+	}
+	nyquist := float64(w.SampleRate) / 2
+	if opts.MaxFrequency == 0 || opts.MaxFrequency > nyquist {
+		opts.MaxFrequency = nyquist
+	}
+
+	var filters [][]float64
+	return w.featureRows(opts, func(magnitudes []float64) []float64 {
+		if filters == nil {
+			filters = melFilters(opts.MelBands, len(magnitudes), w.SampleRate, opts.MinFrequency, opts.MaxFrequency)
+		}
+
+		row := make([]float64, opts.MelBands)
+		for band, weights := range filters {
+			var energy float64
+			for bin, weight := range weights {
+				energy += weight * magnitudes[bin] * magnitudes[bin]
+			}
+			level := SpectrogramFloor
+			if energy > 0 {
+				level = math.Max(SpectrogramFloor, 10*math.Log10(energy))
+			}
+			row[band] = level
+		}
+		return row
+	})
+}
+
+// featureRows runs a Hann-windowed FFT over every window of the range and collects
+// the row computed from the magnitudes of each spectrum (bins 0 to FFTSize/2, scaled
+// so a full-scale sine reads 1.0 as in GenerateSpectrogram)
+func (w *Waveform) featureRows(opts FeatureOptions, row func(magnitudes []float64) []float64) (*Features, error) {
+	if opts.FFTSize == 0 {
+		opts.FFTSize = DefaultFeatureFFTSize
+	}
+	if !isPowerOfTwo(opts.FFTSize) || opts.FFTSize < 16 {
+		return nil, fmt.Errorf("invalid FFT size %d: must be a power of two of at least 16", opts.FFTSize)
+	}
+	if opts.HopSize == 0 {
+		opts.HopSize = opts.FFTSize / 4
+	}
+	if opts.HopSize < 0 {
+		return nil, fmt.Errorf("invalid hop size: %d", opts.HopSize)
+	}
+	if opts.MinFrequency < 0 || opts.MinFrequency >= opts.MaxFrequency {
+		return nil, fmt.Errorf("invalid frequency range: %.1f to %.1f Hz", opts.MinFrequency, opts.MaxFrequency)
+	}
+
+	startSample, endSample, _, err := w.resolveRange(WaveformOptions{Start: opts.Start, End: opts.End})
+	if err != nil {
+		return nil, err
+	}
+	endSample = min(endSample, w.availableFrames())
+
+	window, windowSum := hannWindow(opts.FFTSize)
+	buf := make([]complex128, opts.FFTSize)
+	magnitudes := make([]float64, opts.FFTSize/2+1)
+	features := &Features{
+		SampleRate: w.SampleRate,
+		FFTSize:    opts.FFTSize,
+		HopSize:    opts.HopSize,
+		Start:      float64(startSample) / float64(w.SampleRate),
+	}
+
+	for frameStart := startSample; frameStart < endSample; frameStart += opts.HopSize {
+		// Zero-padded past the end of the range
+		for i := range buf {
+			var x float64
+			if frameStart+i < endSample {
+				x = w.monoSample(frameStart+i) * window[i]
+			}
+			buf[i] = complex(x, 0)
+		}
+		fft(buf)
+		for bin := range magnitudes {
+			magnitudes[bin] = 2 * cmplx.Abs(buf[bin]) / windowSum
+		}
+		features.Rows = append(features.Rows, row(magnitudes))
+	}
+
+	return features, nil
+}
+
+// melFilters returns triangular filter weights over numBins bins (0 to FFT size/2) for
+// bands evenly spaced on the mel scale between low and high Hz, each peaking at 1
+func melFilters(bands, numBins, sampleRate int, low, high float64) [][]float64 {
+	fftSize := 2 * (numBins - 1)
+	// Band edges: band b rises from edges[b] to edges[b+1] and falls to edges[b+2]
+	lowMel, highMel := hzToMel(low), hzToMel(high)
+	edges := make([]float64, bands+2)
+	for i := range edges {
+		edges[i] = melToHz(lowMel + (highMel-lowMel)*float64(i)/float64(bands+1))
+	}
+
+	filters := make([][]float64, bands)
+	for b := range filters {
+		filters[b] = make([]float64, numBins)
+		left, center, right := edges[b], edges[b+1], edges[b+2]
+		for bin := range filters[b] {
+			f := float64(bin) * float64(sampleRate) / float64(fftSize)
+			switch {
+			case f > left && f <= center:
+				filters[b][bin] = (f - left) / (center - left)
+			case f > center && f < right:
+				filters[b][bin] = (right - f) / (right - center)
+			}
+		}
+	}
+	return filters
+}
+
+// hzToMel converts a frequency in Hz to mels (HTK formula)
+func hzToMel(f float64) float64 {
+	return 2595 * math.Log10(1+f/700)
+}
+
+// melToHz converts mels to a frequency in Hz (HTK formula)
+func melToHz(mel float64) float64 {
+	return 700 * (math.Pow(10, mel/2595) - 1)
+}
@@ -0,0 +1,112 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestChromagram(t *testing.T) {
+	tmpFile := "/tmp/test_chromagram.wav"
+	defer os.Remove(tmpFile)
+
+	// 0.5s of A4 followed by 0.5s of silence
+	samples := append(sineSamples(44100, 1, 440, 0.5, 0.5), make([]int16, 22050)...)
+	writeTestWAV(t, tmpFile, 44100, 1, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	chroma, err := waveform.Chromagram(FeatureOptions{})
+	if err != nil {
+		t.Fatalf("Chromagram failed: %v", err)
+	}
+
+	// One row per 512 frames
+	if len(chroma.Rows) != (44100+511)/512 {
+		t.Errorf("Expected %d rows, got %d", (44100+511)/512, len(chroma.Rows))
+	}
+	tone := chroma.Rows[10]
+	if len(tone) != 12 || tone[9] != 1 {
+		t.Fatalf("Expected A to be the strongest pitch class, got %v", tone)
+	}
+	for pc, v := range tone {
+		if pc != 9 && v > 0.25 {
+			t.Errorf("Expected little energy in %s, got %.3f", PitchClassNames[pc], v)
+		}
+	}
+	for pc, v := range chroma.Rows[len(chroma.Rows)-1] {
+		if v != 0 {
+			t.Errorf("Expected a silent row, got %.3f in %s", v, PitchClassNames[pc])
+		}
+	}
+
+	if got := chroma.Time(1); math.Abs(got-(512+1024)/44100.0) > 1e-9 {
+		t.Errorf("Expected row 1 centered at %.6fs, got %.6fs", (512+1024)/44100.0, got)
+	}
+}
+
+func TestMelSpectrogram(t *testing.T) {
+	tmpFile := "/tmp/test_mel_spectrogram.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 1000, 0.5, 1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	mel, err := waveform.MelSpectrogram(FeatureOptions{MelBands: 40})
+	if err != nil {
+		t.Fatalf("MelSpectrogram failed: %v", err)
+	}
+
+	row := mel.Rows[10]
+	if len(row) != 40 {
+		t.Fatalf("Expected 40 bands, got %d", len(row))
+	}
+	loudest := 0
+	for band, level := range row {
+		if level > row[loudest] {
+			loudest = band
+		}
+	}
+
+	// The loudest band spans 1 kHz and reads about -6 dBFS for a half scale sine
+	lowMel, highMel := hzToMel(0), hzToMel(22050)
+	left := melToHz(lowMel + (highMel-lowMel)*float64(loudest)/41)
+	right := melToHz(lowMel + (highMel-lowMel)*float64(loudest+2)/41)
+	if left > 1000 || right < 1000 {
+		t.Errorf("Expected the loudest band to span 1000 Hz, got %.0f to %.0f Hz", left, right)
+	}
+	if row[loudest] < -10 || row[loudest] > -3 {
+		t.Errorf("Expected about -6 dBFS, got %.2f", row[loudest])
+	}
+}
+
+func TestFeatureOptionsErrors(t *testing.T) {
+	tmpFile := "/tmp/test_feature_errors.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 440, 0.5, 0.1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	tests := []FeatureOptions{
+		{FFTSize: 1000},
+		{HopSize: -1},
+		{MelBands: -1},
+		{MinFrequency: 5000, MaxFrequency: 100},
+	}
+	for _, opts := range tests {
+		if _, err := waveform.MelSpectrogram(opts); err == nil {
+			t.Errorf("Expected an error for %+v", opts)
+		}
+	}
+}
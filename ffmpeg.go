@@ -0,0 +1,107 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/schollz/audiomorph"
+)
+
+// ErrFFmpegNotFound is returned when the ffmpeg fallback decoder is enabled but
+// ffmpeg or ffprobe cannot be found
+var ErrFFmpegNotFound = errors.New("ffmpeg not found")
+
+// OptionFFmpegFallback decodes files the built-in decoders cannot handle by running
+// ffmpeg (and ffprobe for the format) from PATH, covering any format ffmpeg reads.
+// Audio decoded this way has 16-bit resolution. It is off by default so loading
+// stays pure Go unless asked for.
+func OptionFFmpegFallback() LoadOption {
+	return func(c *loadConfig) {
+		c.ffmpegFallback = true
+	}
+}
+
+// decodeFFmpeg decodes the first audio stream of a file to 16-bit PCM with ffmpeg
+func decodeFFmpeg(filename string) (*audiomorph.Audio, error) {
+	ffprobe, err := exec.LookPath("ffprobe")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFFmpegNotFound, err)
+	}
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFFmpegNotFound, err)
+	}
+
+	sampleRate, channels, err := probeAudioFormat(ffprobe, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command(ffmpeg,
+		"-loglevel", "error",
+		"-i", filename,
+		"-map", "0:a:0",
+		"-f", "s16le", "-acodec", "pcm_s16le",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	raw, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	frames := len(raw) / (2 * channels)
+	data := make([][]int, channels)
+	for ch := range data {
+		data[ch] = make([]int, frames)
+	}
+	for i := 0; i < frames*channels; i++ {
+		data[i%channels][i/channels] = int(int16(binary.LittleEndian.Uint16(raw[2*i:])))
+	}
+
+	return &audiomorph.Audio{
+		NumChannels: channels,
+		SampleRate:  sampleRate,
+		BitDepth:    16,
+		Data:        data,
+		Duration:    float64(frames) / float64(sampleRate),
+	}, nil
+}
+
+// probeAudioFormat returns the sample rate and channel count of the first audio stream
+func probeAudioFormat(ffprobe, filename string) (int, int, error) {
+	out, err := exec.Command(ffprobe,
+		"-v", "error",
+		"-select_streams", "a:0",
+		"-show_entries", "stream=sample_rate,channels",
+		"-of", "default=noprint_wrappers=1",
+		filename,
+	).Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var sampleRate, channels int
+	for _, line := range strings.Split(string(out), "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "sample_rate":
+			sampleRate, _ = strconv.Atoi(value)
+		case "channels":
+			channels, _ = strconv.Atoi(value)
+		}
+	}
+	if sampleRate <= 0 || channels <= 0 {
+		return 0, 0, fmt.Errorf("ffprobe found no audio stream in %s", filename)
+	}
+	return sampleRate, channels, nil
+}
@@ -0,0 +1,48 @@
+package gowaveform
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestFFmpegFallback(t *testing.T) {
+	// A WAV file under an extension the built-in decoders do not know
+	tmpFile := "/tmp/test_ffmpeg_fallback.audio"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 22050, 2, sineSamples(22050, 2, 440, 0.5, 0.5))
+
+	if _, err := LoadWaveform(tmpFile); err == nil {
+		t.Fatal("Expected the built-in decoders to reject the file")
+	}
+
+	waveform, err := LoadWaveform(tmpFile, OptionFFmpegFallback())
+	_, ffmpegErr := exec.LookPath("ffmpeg")
+	_, ffprobeErr := exec.LookPath("ffprobe")
+	if ffmpegErr != nil || ffprobeErr != nil {
+		if !errors.Is(err, ErrFFmpegNotFound) {
+			t.Errorf("Expected ErrFFmpegNotFound without ffmpeg, got %v", err)
+		}
+		t.Skip("ffmpeg not installed")
+	}
+	if err != nil {
+		t.Fatalf("LoadWaveform with ffmpeg fallback failed: %v", err)
+	}
+
+	if waveform.SampleRate != 22050 || waveform.Channels != 2 || waveform.BitsPerSample != 16 {
+		t.Errorf("Unexpected format: %d Hz, %d channels, %d bits", waveform.SampleRate, waveform.Channels, waveform.BitsPerSample)
+	}
+	if waveform.Duration() != 0.5 {
+		t.Errorf("Expected 0.5s, got %.6fs", waveform.Duration())
+	}
+	levels, err := waveform.ChannelLevels(0, 0)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	for ch, l := range levels {
+		if l.Peak < 0.49 || l.Peak > 0.51 {
+			t.Errorf("Expected a half scale peak in channel %d, got %.3f", ch, l.Peak)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package gowaveform
+
+import (
+	"math"
+	"math/bits"
+)
+
+// fft computes the discrete Fourier transform of x in place.
+// len(x) must be a power of two.
+func fft(x []complex128) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// Bit-reversal permutation
+	shift := 64 - uint(bits.TrailingZeros(uint(n)))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if j > i {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	// Iterative radix-2 butterflies
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		step := -2 * math.Pi / float64(size)
+		for k := 0; k < half; k++ {
+			sin, cos := math.Sincos(step * float64(k))
+			twiddle := complex(cos, sin)
+			for start := 0; start < n; start += size {
+				even := x[start+k]
+				odd := x[start+k+half] * twiddle
+				x[start+k] = even + odd
+				x[start+k+half] = even - odd
+			}
+		}
+	}
+}
+
+// hannWindow returns a Hann window of the given size and the sum of its coefficients
+func hannWindow(size int) ([]float64, float64) {
+	window := make([]float64, size)
+	var sum float64
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size))
+		sum += window[i]
+	}
+	return window, sum
+}
+
+// isPowerOfTwo reports whether n is a positive power of two
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
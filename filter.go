@@ -0,0 +1,201 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// biquad is a second-order IIR filter using the RBJ audio EQ cookbook coefficients
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+	x1, x2     float64
+	y1, y2     float64
+}
+
+// butterworthQ is the Q factor for a maximally flat (Butterworth) response
+const butterworthQ = 0.7071067811865476
+
+// filterPreroll is the number of samples fed through a filter before the
+// requested range so that the filter state has settled by the first pixel
+const filterPreroll = 2048
+
+// newLowPass creates a low-pass biquad filter with the given cutoff frequency
+func newLowPass(sampleRate int, freq, q float64) *biquad {
+	w0, alpha := biquadParams(sampleRate, freq, q)
+	cosw0 := math.Cos(w0)
+	return newBiquad(
+		(1-cosw0)/2, 1-cosw0, (1-cosw0)/2,
+		1+alpha, -2*cosw0, 1-alpha,
+	)
+}
+
+// newHighPass creates a high-pass biquad filter with the given cutoff frequency
+func newHighPass(sampleRate int, freq, q float64) *biquad {
+	w0, alpha := biquadParams(sampleRate, freq, q)
+	cosw0 := math.Cos(w0)
+	return newBiquad(
+		(1+cosw0)/2, -(1 + cosw0), (1+cosw0)/2,
+		1+alpha, -2*cosw0, 1-alpha,
+	)
+}
+
+// biquadParams returns the angular frequency and alpha term for the cookbook formulas
+func biquadParams(sampleRate int, freq, q float64) (float64, float64) {
+	// Keep the cutoff below Nyquist so the coefficients stay stable
+	nyquist := float64(sampleRate) / 2
+	if freq >= nyquist {
+		freq = nyquist * 0.99
+	}
+	if freq <= 0 {
+		freq = 1
+	}
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	return w0, math.Sin(w0) / (2 * q)
+}
+
+// newBiquad normalizes the coefficients by a0
+func newBiquad(b0, b1, b2, a0, a1, a2 float64) *biquad {
+	return &biquad{
+		b0: b0 / a0,
+		b1: b1 / a0,
+		b2: b2 / a0,
+		a1: a1 / a0,
+		a2: a2 / a0,
+	}
+}
+
+// process filters a single sample
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// bandPass limits a signal to the range between two edge frequencies.
+// A zero low edge or high edge leaves that side of the band open.
+type bandPass struct {
+	highPass *biquad
+	lowPass  *biquad
+}
+
+// newBandPass creates a band filter passing frequencies between low and high (Hz)
+func newBandPass(sampleRate int, low, high float64) *bandPass {
+	b := &bandPass{}
+	if low > 0 {
+		b.highPass = newHighPass(sampleRate, low, butterworthQ)
+	}
+	if high > 0 {
+		b.lowPass = newLowPass(sampleRate, high, butterworthQ)
+	}
+	return b
+}
+
+// process filters a single sample
+func (b *bandPass) process(x float64) float64 {
+	if b.highPass != nil {
+		x = b.highPass.process(x)
+	}
+	if b.lowPass != nil {
+		x = b.lowPass.process(x)
+	}
+	return x
+}
+
+// Filter limits a view to a frequency range before its peaks are taken, e.g. to show
+// only the energy of a kick drum or only the hiss floor. The edges are Butterworth
+// biquads, as for band peaks. Create one with HighPass, LowPass or BandPass.
+type Filter struct {
+	LowFrequency  float64 `json:"low_frequency,omitempty"`  // High-pass cutoff in Hz (0 = no high-pass)
+	HighFrequency float64 `json:"high_frequency,omitempty"` // Low-pass cutoff in Hz (0 = no low-pass)
+}
+
+// HighPass returns a filter removing the frequencies below cutoff (Hz)
+func HighPass(cutoff float64) *Filter {
+	return &Filter{LowFrequency: cutoff}
+}
+
+// LowPass returns a filter removing the frequencies above cutoff (Hz)
+func LowPass(cutoff float64) *Filter {
+	return &Filter{HighFrequency: cutoff}
+}
+
+// BandPass returns a filter keeping the frequencies between low and high (Hz)
+func BandPass(low, high float64) *Filter {
+	return &Filter{LowFrequency: low, HighFrequency: high}
+}
+
+// validate checks that the cutoffs lie between 0 and the Nyquist frequency and
+// leave a band to pass
+func (f *Filter) validate(sampleRate int) error {
+	nyquist := float64(sampleRate) / 2
+	if f.LowFrequency == 0 && f.HighFrequency == 0 {
+		return fmt.Errorf("invalid filter: no cutoff frequency")
+	}
+	for _, cutoff := range []float64{f.LowFrequency, f.HighFrequency} {
+		if cutoff < 0 || cutoff >= nyquist {
+			return fmt.Errorf("invalid filter cutoff %.1f Hz: must be between 0 and %.1f Hz", cutoff, nyquist)
+		}
+	}
+	if f.HighFrequency > 0 && f.LowFrequency >= f.HighFrequency {
+		return fmt.Errorf("invalid filter: high-pass cutoff %.1f Hz must be below low-pass cutoff %.1f Hz",
+			f.LowFrequency, f.HighFrequency)
+	}
+	return nil
+}
+
+// newChannelFilters creates one band filter per channel, warmed up on the audio just
+// before startSample
+func (w *Waveform) newChannelFilters(f *Filter, startSample int) []*bandPass {
+	filters := make([]*bandPass, w.Channels)
+	for ch := range filters {
+		filters[ch] = newBandPass(w.SampleRate, f.LowFrequency, f.HighFrequency)
+	}
+	for i := max(startSample-filterPreroll, 0); i < startSample; i++ {
+		for ch, filter := range filters {
+			filter.process(float64(w.audioData[i*w.Channels+ch]))
+		}
+	}
+	return filters
+}
+
+// filteredPeaks computes the peaks of consecutive pixels of filtered audio. The
+// filters carry their state from one pixel to the next, so pixels must be requested
+// in order.
+type filteredPeaks struct {
+	w       *Waveform
+	filters []*bandPass // One per channel
+}
+
+// peaks returns the min and max of all channels of the filtered frames, like
+// getPeaksFromRange
+func (p *filteredPeaks) peaks(startSample, sampleCount int) (int16, int16) {
+	w := p.w
+	endSample := min(startSample+sampleCount, w.availableFrames())
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := startSample; i < endSample; i++ {
+		for ch, filter := range p.filters {
+			y := filter.process(float64(w.audioData[i*w.Channels+ch]))
+			lo, hi = math.Min(lo, y), math.Max(hi, y)
+		}
+	}
+	if lo > hi {
+		return 0, 0
+	}
+	return clampInt16(lo), clampInt16(hi)
+}
+
+// pixelPeaks returns the function computing the peaks of the pixels of a view from
+// startSample on, applying opts.Filter if it is set. With a filter, pixels must be
+// requested in order.
+func (w *Waveform) pixelPeaks(opts WaveformOptions, startSample int) (func(startSample, sampleCount int) (int16, int16), error) {
+	if opts.Filter == nil {
+		return w.getPeaksFromRange, nil
+	}
+	if err := opts.Filter.validate(w.SampleRate); err != nil {
+		return nil, err
+	}
+	p := &filteredPeaks{w: w, filters: w.newChannelFilters(opts.Filter, startSample)}
+	return p.peaks, nil
+}
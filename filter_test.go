@@ -0,0 +1,146 @@
+package gowaveform
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// filterTestWaveform returns 1s of stereo audio: a 50 Hz tone at half scale plus a
+// 5 kHz tone at a tenth of full scale
+func filterTestWaveform() *Waveform {
+	const rate = 44100
+	samples := make([]int16, 2*rate)
+	for i := range rate {
+		t := float64(i) / rate
+		v := int16(math.Round(32767 * (0.5*math.Sin(2*math.Pi*50*t) + 0.1*math.Sin(2*math.Pi*5000*t))))
+		samples[2*i], samples[2*i+1] = v, v
+	}
+	return &Waveform{SampleRate: rate, Channels: 2, BitsPerSample: 16, audioData: samples, totalSamples: rate}
+}
+
+// maxPeak returns the largest magnitude of min/max pairs
+func maxPeak(data []int16) float64 {
+	var peak float64
+	for _, v := range data {
+		peak = math.Max(peak, math.Abs(float64(v)))
+	}
+	return peak / 32767
+}
+
+func TestFilteredView(t *testing.T) {
+	w := filterTestWaveform()
+
+	// Skip the first pixels, where the filters settle
+	opts := WaveformOptions{Start: 0.1, SamplesPerPixel: 441}
+	level := func(filter *Filter) float64 {
+		t.Helper()
+		opts.Filter = filter
+		data, err := w.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		return maxPeak(data.Data)
+	}
+
+	if got := level(nil); math.Abs(got-0.6) > 0.02 {
+		t.Errorf("Expected an unfiltered peak near 0.6, got %.3f", got)
+	}
+	if got := level(HighPass(1000)); math.Abs(got-0.1) > 0.02 {
+		t.Errorf("Expected the high-passed peak of the 5 kHz tone near 0.1, got %.3f", got)
+	}
+	if got := level(LowPass(500)); math.Abs(got-0.5) > 0.02 {
+		t.Errorf("Expected the low-passed peak of the 50 Hz tone near 0.5, got %.3f", got)
+	}
+	if got := level(BandPass(500, 1000)); got > 0.05 {
+		t.Errorf("Expected little between the tones, got %.3f", got)
+	}
+}
+
+func TestFilteredViewPaths(t *testing.T) {
+	w := filterTestWaveform()
+	filtered := WaveformOptions{Start: 0.25, End: 0.75, SamplesPerPixel: 100, Filter: HighPass(1000)}
+	plain := WaveformOptions{Start: 0.25, End: 0.75, SamplesPerPixel: 100}
+	coarse := WaveformOptions{Start: 0.25, End: 0.75, SamplesPerPixel: 400, Filter: HighPass(1000)}
+
+	view, err := w.GenerateView(filtered)
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	// GenerateViews computes filtered views on their own, not merged from unfiltered ones
+	views, err := w.GenerateViews([]WaveformOptions{plain, filtered, coarse})
+	if err != nil {
+		t.Fatalf("GenerateViews failed: %v", err)
+	}
+	for i, opts := range []WaveformOptions{plain, filtered, coarse} {
+		single, err := w.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		if !reflect.DeepEqual(views[i].Data, single.Data) {
+			t.Errorf("View %d: GenerateViews differs from GenerateView", i)
+		}
+	}
+
+	extended, err := w.GenerateViewExtended(filtered)
+	if err != nil {
+		t.Fatalf("GenerateViewExtended failed: %v", err)
+	}
+	if !reflect.DeepEqual(extended.Data, view.Data) {
+		t.Error("GenerateViewExtended peaks differ from GenerateView")
+	}
+
+	pixels, err := w.Pixels(filtered)
+	if err != nil {
+		t.Fatalf("Pixels failed: %v", err)
+	}
+	for range 2 {
+		var data []int16
+		for lo, hi := range pixels {
+			data = append(data, lo, hi)
+		}
+		if !reflect.DeepEqual(data, view.Data) {
+			t.Error("Pixels differ from GenerateView")
+		}
+	}
+
+	// Bands are split from the filtered audio
+	filtered.Bands = []float64{1000}
+	filtered.Metadata = true
+	banded, err := w.GenerateView(filtered)
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if low := maxPeak(banded.Bands[0].Data); low > 0.05 {
+		t.Errorf("Expected the band below the high-pass cutoff to be near silent, got %.3f", low)
+	}
+	if high := maxPeak(banded.Bands[1].Data); math.Abs(high-0.1) > 0.02 {
+		t.Errorf("Expected the band above the cutoff near 0.1, got %.3f", high)
+	}
+	if f := banded.Metadata.Options.Filter; f == nil || f.LowFrequency != 1000 {
+		t.Errorf("Expected the filter in the metadata, got %+v", f)
+	}
+}
+
+func TestFilterErrors(t *testing.T) {
+	w := filterTestWaveform()
+	for name, filter := range map[string]*Filter{
+		"no cutoff":          {},
+		"negative cutoff":    HighPass(-10),
+		"above nyquist":      LowPass(30000),
+		"empty band":         BandPass(2000, 1000),
+		"band above nyquist": BandPass(1000, 22050),
+	} {
+		opts := WaveformOptions{Width: 100, Filter: filter}
+		if _, err := w.GenerateView(opts); err == nil {
+			t.Errorf("%s: expected an error from GenerateView", name)
+		}
+		if _, err := w.GenerateViews([]WaveformOptions{opts}); err == nil {
+			t.Errorf("%s: expected an error from GenerateViews", name)
+		}
+		if _, err := w.Pixels(opts); err == nil {
+			t.Errorf("%s: expected an error from Pixels", name)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// Fingerprint parameters after Haitsma and Kalker: windows of about 186 ms every 23 ms,
+// with 33 mel bands between 300 and 2000 Hz giving 32 bits per window
+const (
+	fingerprintWindow       = 0.186 // Seconds, rounded to a power of two of frames
+	fingerprintHop          = 0.0232
+	fingerprintBands        = 33
+	fingerprintMinFrequency = 300.0
+	fingerprintMaxFrequency = 2000.0
+	fingerprintRange        = 30.0 // Levels in dB below the loudest band that count
+	fingerprintMaxOffset    = 1.0  // Largest offset Similarity searches, in seconds
+)
+
+// Fingerprint is a compact perceptual summary of audio: one 32-bit hash per 23 ms,
+// each bit telling whether the energy difference between two neighboring bands grew
+// or shrank since the previous window. The hashes depend on the spectral shape only,
+// so the same recording at another sample rate, bit depth, gain or in a lossy format
+// gives nearly the same fingerprint.
+type Fingerprint struct {
+	Duration float64  `json:"duration"` // Duration in seconds of the audio
+	Hashes   []uint32 `json:"hashes"`   // One hash per window
+}
+
+// Fingerprint computes the perceptual fingerprint of the waveform
+func (w *Waveform) Fingerprint() (*Fingerprint, error) {
+	rate := float64(w.SampleRate)
+	maxFrequency := min(fingerprintMaxFrequency, rate/2)
+	if maxFrequency <= fingerprintMinFrequency {
+		return nil, fmt.Errorf("sample rate too low to fingerprint: %d Hz", w.SampleRate)
+	}
+
+	// The power of two nearest the window length, so windows last about as long at
+	// every sample rate
+	fftSize := 16
+	for float64(fftSize)*1.5 < fingerprintWindow*rate {
+		fftSize *= 2
+	}
+	features, err := w.MelSpectrogram(FeatureOptions{
+		FFTSize:      fftSize,
+		HopSize:      max(1, int(math.Round(fingerprintHop*rate))),
+		MelBands:     fingerprintBands,
+		MinFrequency: fingerprintMinFrequency,
+		MaxFrequency: maxFrequency,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fingerprint: %w", err)
+	}
+
+	// Bands far below the loudest are treated as silent, so their noise does not
+	// decide bits
+	rows := features.Rows
+	loudest := SpectrogramFloor
+	for _, row := range rows {
+		for _, level := range row {
+			loudest = max(loudest, level)
+		}
+	}
+	floor := loudest - fingerprintRange
+	for _, row := range rows {
+		for m := range row {
+			row[m] = max(row[m], floor)
+		}
+	}
+
+	f := &Fingerprint{Duration: w.Duration()}
+	for n := 1; n < len(rows); n++ {
+		var hash uint32
+		for m := 0; m < fingerprintBands-1; m++ {
+			if rows[n][m]-rows[n][m+1]-(rows[n-1][m]-rows[n-1][m+1]) > 0 {
+				hash |= 1 << m
+			}
+		}
+		f.Hashes = append(f.Hashes, hash)
+	}
+	return f, nil
+}
+
+// Similarity compares two fingerprints, aligning them by up to a second in either
+// direction. It returns 1 for the same audio and about 0 for unrelated audio, scaled
+// down by the part of the longer fingerprint that does not overlap the shorter, so a
+// clip is not similar to a whole track containing it. Fingerprints of audio shorter
+// than about 50 ms have no hashes and are similar to nothing.
+func (f *Fingerprint) Similarity(other *Fingerprint) float64 {
+	a, b := f.Hashes, other.Hashes
+	longest := max(len(a), len(b))
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	// Bits agree by chance more often than half the time when most are clear, as in
+	// quiet passages, so agreement is measured against chance (Cohen's kappa)
+	pa, pb := setBits(a), setBits(b)
+	chance := pa*pb + (1-pa)*(1-pb)
+	if chance >= 1 {
+		return 0 // All bits alike, e.g. silence
+	}
+
+	maxLag := int(math.Round(fingerprintMaxOffset / fingerprintHop))
+	best := 0.0
+	for lag := -maxLag; lag <= maxLag; lag++ {
+		// a[i] lines up with b[i+lag]
+		first, last := max(0, -lag), min(len(a), len(b)-lag)
+		if first >= last {
+			continue
+		}
+		differing := 0
+		for i := first; i < last; i++ {
+			differing += bits.OnesCount32(a[i] ^ b[i+lag])
+		}
+		overlap := last - first
+		agreement := 1 - float64(differing)/float64(32*overlap)
+		best = max(best, (agreement-chance)/(1-chance)*float64(overlap)/float64(longest))
+	}
+	return best
+}
+
+// setBits returns the fraction of bits that are set in the hashes
+func setBits(hashes []uint32) float64 {
+	set := 0
+	for _, hash := range hashes {
+		set += bits.OnesCount32(hash)
+	}
+	return float64(set) / float64(32*len(hashes))
+}
@@ -0,0 +1,89 @@
+package gowaveform
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// testMelody synthesizes a quarter-second note sequence picked by seed, with decaying
+// notes and overtones, at the given sample rate and gain
+func testMelody(sampleRate int, seed int64, gain, seconds float64) []int16 {
+	rng := rand.New(rand.NewSource(seed))
+	var freqs []float64
+	for range int(seconds * 4) {
+		freqs = append(freqs, 110*math.Pow(2, float64(rng.Intn(36))/12))
+	}
+
+	samples := make([]int16, int(seconds*float64(sampleRate)))
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		var v float64
+		for n, freq := range freqs {
+			dt := t - float64(n)/4
+			if dt >= 0 && dt < 0.5 {
+				v += math.Exp(-8*dt) * (math.Sin(2*math.Pi*freq*dt) + 0.5*math.Sin(4*math.Pi*freq*dt))
+			}
+		}
+		samples[i] = int16(max(-32768, min(32767, 0.3*gain*v*32767)))
+	}
+	return samples
+}
+
+// fingerprintOf fingerprints mono samples
+func fingerprintOf(t *testing.T, sampleRate int, samples []int16) *Fingerprint {
+	t.Helper()
+	w := &Waveform{SampleRate: sampleRate, Channels: 1, BitsPerSample: 16, audioData: samples, totalSamples: len(samples)}
+	f, err := w.Fingerprint()
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+	return f
+}
+
+func TestFingerprint(t *testing.T) {
+	original := fingerprintOf(t, 8000, testMelody(8000, 1, 1, 4))
+	if original.Duration != 4 || len(original.Hashes) < 150 {
+		t.Fatalf("Expected a hash every 23 ms of 4 seconds, got %d", len(original.Hashes))
+	}
+	if s := original.Similarity(original); s != 1 {
+		t.Errorf("Expected a fingerprint to be identical to itself, got %.3f", s)
+	}
+
+	delayed := append(make([]int16, 2400), testMelody(8000, 1, 1, 4)...)
+	tests := []struct {
+		name     string
+		other    *Fingerprint
+		min, max float64
+	}{
+		{"resampled", fingerprintOf(t, 44100, testMelody(44100, 1, 1, 4)), 0.7, 1},
+		{"quieter", fingerprintOf(t, 16000, testMelody(16000, 1, 0.3, 4)), 0.7, 1},
+		{"delayed", fingerprintOf(t, 8000, delayed), 0.7, 1},
+		{"first half", fingerprintOf(t, 8000, testMelody(8000, 1, 1, 2)), 0, DefaultDuplicateThreshold},
+		{"other melody", fingerprintOf(t, 8000, testMelody(8000, 2, 1, 4)), 0, 0.3},
+		{"other rate and melody", fingerprintOf(t, 16000, testMelody(16000, 3, 1, 4)), 0, 0.3},
+		{"silence", fingerprintOf(t, 8000, make([]int16, 32000)), 0, 0.1},
+	}
+	for _, tt := range tests {
+		s := original.Similarity(tt.other)
+		if s < tt.min || s > tt.max {
+			t.Errorf("%s: expected a similarity between %.2f and %.2f, got %.3f", tt.name, tt.min, tt.max, s)
+		}
+		if r := tt.other.Similarity(original); math.Abs(r-s) > 1e-9 {
+			t.Errorf("%s: expected a symmetric similarity, got %.3f and %.3f", tt.name, s, r)
+		}
+	}
+}
+
+func TestFingerprintErrors(t *testing.T) {
+	w := &Waveform{SampleRate: 400, Channels: 1, audioData: make([]int16, 400), totalSamples: 400}
+	if _, err := w.Fingerprint(); err == nil {
+		t.Error("Expected an error for a sample rate below the fingerprint bands")
+	}
+
+	// Too short for a hash
+	short := fingerprintOf(t, 8000, make([]int16, 100))
+	if len(short.Hashes) != 0 || short.Similarity(short) != 0 {
+		t.Errorf("Expected no hashes and no similarity, got %d hashes", len(short.Hashes))
+	}
+}
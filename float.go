@@ -0,0 +1,63 @@
+package gowaveform
+
+// WaveformDataFloat is WaveformData with peaks normalized to -1.0..1.0,
+// the range most JS visualization libraries and ML feature pipelines expect
+type WaveformDataFloat struct {
+	Version         int             `json:"version"`
+	Channels        int             `json:"channels,omitempty"`
+	SampleRate      int             `json:"sample_rate"`
+	SamplesPerPixel int             `json:"samples_per_pixel"`
+	Bits            int             `json:"bits"`
+	Length          int             `json:"length"`
+	Data            []float64       `json:"data"`
+	Bands           []BandDataFloat `json:"bands,omitempty"`
+	TimeAxis        *TimeAxis       `json:"time_axis,omitempty"`
+	Metadata        *Metadata       `json:"metadata,omitempty"`
+}
+
+// BandDataFloat is BandData with peaks normalized to -1.0..1.0
+type BandDataFloat struct {
+	LowFrequency  float64   `json:"low_frequency"`
+	HighFrequency float64   `json:"high_frequency"`
+	Data          []float64 `json:"data"`
+}
+
+// Float converts the waveform data to normalized floats by dividing each peak by 32768
+func (d *WaveformData) Float() *WaveformDataFloat {
+	out := &WaveformDataFloat{
+		Version:         d.Version,
+		Channels:        d.Channels,
+		SampleRate:      d.SampleRate,
+		SamplesPerPixel: d.SamplesPerPixel,
+		Bits:            d.Bits,
+		Length:          d.Length,
+		Data:            normalizePeaks(d.Data),
+		TimeAxis:        d.TimeAxis,
+		Metadata:        d.Metadata,
+	}
+
+	if len(d.Bands) > 0 {
+		out.Bands = make([]BandDataFloat, len(d.Bands))
+		for i, band := range d.Bands {
+			out.Bands[i] = BandDataFloat{
+				LowFrequency:  band.LowFrequency,
+				HighFrequency: band.HighFrequency,
+				Data:          normalizePeaks(band.Data),
+			}
+		}
+	}
+
+	return out
+}
+
+// normalizePeaks scales int16 peaks to -1.0..1.0
+func normalizePeaks(peaks []int16) []float64 {
+	if peaks == nil {
+		return nil
+	}
+	out := make([]float64, len(peaks))
+	for i, v := range peaks {
+		out[i] = float64(v) / 32768.0
+	}
+	return out
+}
@@ -0,0 +1,93 @@
+package gowaveform
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestWaveformDataFloat(t *testing.T) {
+	data := &WaveformData{
+		Version:  2,
+		Channels: 1,
+		Length:   2,
+		Data:     []int16{-32768, 32767, -16384, 0},
+		Bands: []BandData{
+			{LowFrequency: 0, HighFrequency: 200, Data: []int16{-8192, 8192}},
+		},
+	}
+
+	f := data.Float()
+
+	expected := []float64{-1.0, 32767.0 / 32768.0, -0.5, 0}
+	for i, v := range expected {
+		if f.Data[i] != v {
+			t.Errorf("Data[%d]: expected %f, got %f", i, v, f.Data[i])
+		}
+	}
+	if f.Length != 2 || f.Version != 2 {
+		t.Errorf("Expected header fields to be copied, got %+v", f)
+	}
+	if len(f.Bands) != 1 || f.Bands[0].HighFrequency != 200 || f.Bands[0].Data[0] != -0.25 {
+		t.Errorf("Unexpected bands %+v", f.Bands)
+	}
+
+	for _, v := range f.Data {
+		if v < -1.0 || v > 1.0 {
+			t.Errorf("Value %f outside -1.0..1.0", v)
+		}
+	}
+}
+
+func TestGenerateWaveformJSONFloat(t *testing.T) {
+	tmpFile := "/tmp/test_float.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	opts := WaveformOptions{Width: 50, Float: true}
+	jsonData, err := GenerateWaveformJSON(tmpFile, opts)
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+
+	var decoded WaveformDataFloat
+	if err := json.Unmarshal(jsonData, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal float JSON: %v", err)
+	}
+	if decoded.Length != 50 || len(decoded.Data) != 100 {
+		t.Errorf("Expected 50 pixels, got length %d with %d values", decoded.Length, len(decoded.Data))
+	}
+
+	var hasFraction bool
+	for _, v := range decoded.Data {
+		if v < -1.0 || v > 1.0 {
+			t.Fatalf("Value %f outside -1.0..1.0", v)
+		}
+		if v != 0 && v != float64(int(v)) {
+			hasFraction = true
+		}
+	}
+	if !hasFraction {
+		t.Error("Expected normalized fractional values")
+	}
+
+	compressed, err := GenerateWaveformJSONCompressed(tmpFile, opts, CompressionGzip)
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSONCompressed failed: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Failed to open gzip stream: %v", err)
+	}
+	decompressed, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if !bytes.Equal(decompressed, jsonData) {
+		t.Error("Expected compressed float JSON to match GenerateWaveformJSON")
+	}
+}
@@ -0,0 +1,54 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Default frame dimensions for ExportFrames (720p, even sizes as most video codecs require)
+const (
+	DefaultFrameWidth  = 1280
+	DefaultFrameHeight = 720
+)
+
+// FrameFilePattern is the printf pattern used for frame filenames, e.g. frame_000000.png
+const FrameFilePattern = "frame_%06d.png"
+
+// ExportFrames writes a numbered PNG frame per video frame into dir, each showing the
+// waveform with a playhead at the frame's time. Frames cover the view window set by the
+// options (the whole file by default) and are 1280x720 unless OptionSetWidth/OptionSetHeight
+// are given. The frames can be assembled into a video with ffmpeg:
+//
+//	ffmpeg -framerate 30 -i dir/frame_%06d.png -pix_fmt yuv420p waveform.mp4
+func ExportFrames(w *Waveform, dir string, fps int, opts ...Option) error {
+	if fps <= 0 {
+		return fmt.Errorf("invalid frame rate: %d", fps)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create frame directory: %w", err)
+	}
+
+	// Video-friendly defaults go first so the caller's options override them
+	frameOpts := append([]Option{
+		OptionSetWidth(DefaultFrameWidth),
+		OptionSetHeight(DefaultFrameHeight),
+	}, opts...)
+	config, err := newPlotConfig(w, frameOpts)
+	if err != nil {
+		return err
+	}
+
+	numFrames := int(math.Ceil((config.end - config.start) * float64(fps)))
+	for i := 0; i < numFrames; i++ {
+		playhead := config.start + float64(i)/float64(fps)
+		filename := filepath.Join(dir, fmt.Sprintf(FrameFilePattern, i))
+		if err := SavePlot(w, filename, append(frameOpts, OptionPlayhead(playhead))...); err != nil {
+			return fmt.Errorf("failed to write frame %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
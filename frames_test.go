@@ -0,0 +1,68 @@
+package gowaveform
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportFrames(t *testing.T) {
+	tmpWav := "/tmp/test_frames.wav"
+	tmpDir := "/tmp/test_frames"
+	defer os.Remove(tmpWav)
+	defer os.RemoveAll(tmpDir)
+
+	createTestWAV(t, tmpWav, 44100, 0.5)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// 0.5 seconds at 10 fps gives 5 frames
+	if err := ExportFrames(waveform, tmpDir, 10, OptionSetWidth(320), OptionSetHeight(180)); err != nil {
+		t.Fatalf("ExportFrames failed: %v", err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to read frame directory: %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 frames, got %d", len(entries))
+	}
+
+	for i := 0; i < 5; i++ {
+		filename := filepath.Join(tmpDir, fmt.Sprintf(FrameFilePattern, i))
+		file, err := os.Open(filename)
+		if err != nil {
+			t.Fatalf("Failed to open frame %d: %v", i, err)
+		}
+		config, err := png.DecodeConfig(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode frame %d: %v", i, err)
+		}
+		if config.Width != 320 || config.Height != 180 {
+			t.Errorf("Frame %d: expected 320x180, got %dx%d", i, config.Width, config.Height)
+		}
+	}
+}
+
+func TestExportFramesInvalidFPS(t *testing.T) {
+	tmpWav := "/tmp/test_frames_fps.wav"
+	defer os.Remove(tmpWav)
+
+	createTestWAV(t, tmpWav, 44100, 0.5)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if err := ExportFrames(waveform, "/tmp/test_frames_fps", 0); err == nil {
+		t.Error("Expected error for zero frame rate")
+	}
+}
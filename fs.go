@@ -0,0 +1,140 @@
+package gowaveform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-audio/wav"
+	"github.com/schollz/audiomorph"
+)
+
+// LoadWaveformFS loads an audio file from a file system, such as an embed.FS,
+// a zip archive (zip.Reader) or any other virtual file system.
+// WAV files and formats with a decoder added by RegisterDecoder are decoded entirely
+// in memory. Other formats are decoded through a temporary file because their
+// decoders only read from disk; all load options apply to them, including
+// OptionFFmpegFallback.
+func LoadWaveformFS(fsys fs.FS, name string, opts ...LoadOption) (*Waveform, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+	defer f.Close()
+
+	c := newLoadConfig(opts)
+	ext := strings.ToLower(path.Ext(name))
+	decode, registered := registeredDecoder(name)
+	if registered || ext == ".wav" {
+		var audio *audiomorph.Audio
+		if registered {
+			audio, err = decodeWithRegistered(decode, f)
+		} else if audio, err = decodeWAVReader(f, c); err != nil {
+			err = fmt.Errorf("failed to decode audio file: %w", err)
+		}
+		if err == nil {
+			if err = checkAudioLimits(audio, c); err != nil {
+				err = fmt.Errorf("failed to decode audio file: %w", err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		waveform := newWaveform(audio, c)
+		waveform.source = name
+		if err := waveform.finishLoad(c); err != nil {
+			return nil, err
+		}
+		return waveform, nil
+	}
+
+	// Spool to a temporary file with the same extension so the decoder picks the right format
+	tmp, err := os.CreateTemp("", "gowaveform-*"+ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, f); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to copy audio file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	waveform, err := loadWaveform(tmp.Name(), c)
+	if err != nil {
+		return nil, err
+	}
+	waveform.source = name
+	return waveform, nil
+}
+
+// decodeWAVReader decodes a WAV stream into deinterleaved audio, the same way
+// audiomorph decodes WAV files from disk, after checking its header against the
+// load limits
+func decodeWAVReader(r io.Reader, c loadConfig) (*audiomorph.Audio, error) {
+	rs, ok := r.(io.ReadSeeker)
+	if !ok {
+		// The WAV decoder needs to seek between chunks, so buffer the stream
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read WAV data: %w", err)
+		}
+		rs = bytes.NewReader(data)
+	}
+
+	size, err := rs.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV data: %w", err)
+	}
+	if _, err := rs.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read WAV data: %w", err)
+	}
+	if err := checkWAVLimits(rs, size, c, false); err != nil {
+		return nil, err
+	}
+
+	decoder := wav.NewDecoder(rs)
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file")
+	}
+
+	if err := decoder.FwdToPCM(); err != nil {
+		return nil, fmt.Errorf("failed to forward to PCM data: %w", err)
+	}
+
+	format := decoder.Format()
+	if format.NumChannels <= 0 {
+		return nil, fmt.Errorf("invalid WAV file: no channels")
+	}
+
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PCM buffer: %w", err)
+	}
+
+	// Deinterlace PCM data from []int to [][]int
+	numChannels := format.NumChannels
+	numSamples := len(buf.Data) / numChannels
+	data := make([][]int, numChannels)
+	for ch := range data {
+		data[ch] = make([]int, numSamples)
+	}
+	for i := 0; i < numSamples*numChannels; i++ {
+		data[i%numChannels][i/numChannels] = buf.Data[i]
+	}
+
+	return &audiomorph.Audio{
+		NumChannels: numChannels,
+		SampleRate:  format.SampleRate,
+		BitDepth:    int(decoder.BitDepth),
+		Data:        data,
+		Duration:    float64(numSamples) / float64(format.SampleRate),
+	}, nil
+}
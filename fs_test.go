@@ -0,0 +1,134 @@
+package gowaveform
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadWaveformFS(t *testing.T) {
+	tmpFile := "/tmp/test_fs_source.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read test WAV: %v", err)
+	}
+
+	fsys := fstest.MapFS{
+		"audio/test.wav": &fstest.MapFile{Data: data},
+	}
+
+	waveform, err := LoadWaveformFS(fsys, "audio/test.wav")
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+
+	// The result should match loading the same file from disk
+	expected, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if waveform.SampleRate != expected.SampleRate || waveform.Channels != expected.Channels || waveform.BitsPerSample != expected.BitsPerSample {
+		t.Errorf("Expected %d Hz/%d ch/%d bit, got %d Hz/%d ch/%d bit",
+			expected.SampleRate, expected.Channels, expected.BitsPerSample,
+			waveform.SampleRate, waveform.Channels, waveform.BitsPerSample)
+	}
+	if waveform.totalSamples != expected.totalSamples {
+		t.Errorf("Expected %d samples, got %d", expected.totalSamples, waveform.totalSamples)
+	}
+	for i := range expected.audioData {
+		if waveform.audioData[i] != expected.audioData[i] {
+			t.Fatalf("Sample %d differs: expected %d, got %d", i, expected.audioData[i], waveform.audioData[i])
+		}
+	}
+}
+
+func TestLoadWaveformFSZip(t *testing.T) {
+	tmpFile := "/tmp/test_fs_zip.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 22050, 0.5)
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read test WAV: %v", err)
+	}
+
+	// Zip files do not support seeking, so this exercises the buffered path
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	fw, err := zw.Create("sounds/clip.wav")
+	if err != nil {
+		t.Fatalf("Failed to create zip entry: %v", err)
+	}
+	fw.Write(data)
+	zw.Close()
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+
+	waveform, err := LoadWaveformFS(zr, "sounds/clip.wav")
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+
+	if waveform.SampleRate != 22050 {
+		t.Errorf("Expected sample rate 22050, got %d", waveform.SampleRate)
+	}
+	if waveform.totalSamples != 11025 {
+		t.Errorf("Expected 11025 samples, got %d", waveform.totalSamples)
+	}
+}
+
+func TestLoadWaveformFSErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.wav": &fstest.MapFile{Data: []byte("not a wav file")},
+	}
+
+	if _, err := LoadWaveformFS(fsys, "missing.wav"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if _, err := LoadWaveformFS(fsys, "bad.wav"); err == nil {
+		t.Error("Expected error for invalid WAV data")
+	}
+}
+
+func TestLoadWaveformFSOptions(t *testing.T) {
+	// A WAV file under an extension the built-in decoders do not know goes through a
+	// temporary file, with every load option
+	tmpFile := "/tmp/test_fs_options.wav"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 22050, 2, sineSamples(22050, 2, 440, 0.5, 0.5))
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read test WAV: %v", err)
+	}
+	fsys := fstest.MapFS{"song.audio": &fstest.MapFile{Data: data}}
+
+	waveform, err := LoadWaveformFS(fsys, "song.audio", OptionFFmpegFallback(), OptionPrecomputeOverview(50))
+	_, ffmpegErr := exec.LookPath("ffmpeg")
+	_, ffprobeErr := exec.LookPath("ffprobe")
+	if ffmpegErr != nil || ffprobeErr != nil {
+		if !errors.Is(err, ErrFFmpegNotFound) {
+			t.Errorf("Expected the fallback to look for ffmpeg, got %v", err)
+		}
+		t.Skip("ffmpeg not installed")
+	}
+	if err != nil {
+		t.Fatalf("LoadWaveformFS with ffmpeg fallback failed: %v", err)
+	}
+	if waveform.SampleRate != 22050 || waveform.Channels != 2 || waveform.source != "song.audio" {
+		t.Errorf("Unexpected waveform: %d Hz, %d channels from %q", waveform.SampleRate, waveform.Channels, waveform.source)
+	}
+	if waveform.Overview() == nil || waveform.overviewWidth != 50 {
+		t.Error("Expected the overview to be precomputed")
+	}
+}
@@ -3,7 +3,11 @@ module github.com/schollz/gowaveform
 go 1.25
 
 require (
+	github.com/go-audio/audio v1.0.0
+	github.com/go-audio/wav v1.1.0
+	github.com/klauspost/compress v1.20.1
 	github.com/schollz/audiomorph v1.0.1
+	golang.org/x/image v0.32.0
 	gonum.org/v1/plot v0.16.0
 )
 
@@ -16,9 +20,7 @@ require (
 	github.com/braheezy/shine-mp3 v0.1.0 // indirect
 	github.com/faiface/beep v1.1.0 // indirect
 	github.com/go-audio/aiff v1.1.0 // indirect
-	github.com/go-audio/audio v1.0.0 // indirect
 	github.com/go-audio/riff v1.0.0 // indirect
-	github.com/go-audio/wav v1.1.0 // indirect
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/hajimehoshi/go-mp3 v0.3.4 // indirect
 	github.com/icza/bitio v1.1.0 // indirect
@@ -30,6 +32,5 @@ require (
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/schollz/goflac v0.1.0 // indirect
 	github.com/schollz/govorbis v0.0.0-20251109153616-1f3f82bece61 // indirect
-	golang.org/x/image v0.32.0 // indirect
 	golang.org/x/text v0.30.0 // indirect
 )
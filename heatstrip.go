@@ -0,0 +1,119 @@
+package gowaveform
+
+import (
+	"image/color"
+	"math"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// StripMetric selects what the heat strip under a plot shows
+type StripMetric int
+
+const (
+	// StripNone draws no heat strip
+	StripNone StripMetric = iota
+	// StripCrestFactor colors each window by its crest factor (see CrestFactor), red
+	// where the peaks stand 6 dB or less above the RMS level and green from 18 dB, so
+	// over-compressed sections of a master stand out
+	StripCrestFactor
+	// StripLoudness colors each window by its short-term loudness (see
+	// ShortTermLoudness), green at -30 LUFS and below and red from -6 LUFS
+	StripLoudness
+)
+
+// Ranges of the heat strip colors, from green to red
+const (
+	stripCrestHot     = 6.0   // Crest factor in dB at and below which the strip is red
+	stripCrestCool    = 18.0  // Crest factor in dB at and above which the strip is green
+	stripLoudnessHot  = -6.0  // Loudness in LUFS at and above which the strip is red
+	stripLoudnessCool = -30.0 // Loudness in LUFS at and below which the strip is green
+	stripMinWindow    = 0.05  // Shortest window of the strip in seconds
+)
+
+// The heat strip sits under the waveform, inside the plot on the amplitude scale
+const (
+	stripTop    = -1.08
+	stripBottom = -1.2
+)
+
+// OptionHeatStrip draws a thin strip under the waveform colored by the given metric in
+// windows of about four pixels, from green to yellow to red. Windows without a value,
+// such as silence, are left blank. StripNone removes the strip.
+func OptionHeatStrip(metric StripMetric) Option {
+	return func(c *PlotConfig) {
+		c.heatStrip = metric
+	}
+}
+
+// stripColors computes the color of each window of the heat strip for the frames from
+// startFrame to endFrame, nil where a window has no value
+func (w *Waveform) stripColors(metric StripMetric, startFrame, endFrame int, window float64) []color.Color {
+	var values []float64
+	var hot, cool float64
+	switch metric {
+	case StripCrestFactor:
+		values = w.crestFactor(startFrame, endFrame, window)
+		hot, cool = stripCrestHot, stripCrestCool
+	case StripLoudness:
+		values = w.shortTermLoudness(startFrame, endFrame, window)
+		hot, cool = stripLoudnessHot, stripLoudnessCool
+	default:
+		return nil
+	}
+
+	colors := make([]color.Color, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		colors[i] = heatColor((v - cool) / (hot - cool))
+	}
+	return colors
+}
+
+// heatColor maps t from 0 to 1 onto green, yellow and red
+func heatColor(t float64) color.Color {
+	t = math.Max(0, math.Min(1, t))
+	green := [3]float64{0, 170, 80}
+	yellow := [3]float64{240, 200, 0}
+	red := [3]float64{220, 30, 30}
+
+	from, to, f := green, yellow, 2*t
+	if t > 0.5 {
+		from, to, f = yellow, red, 2*t-1
+	}
+	mix := func(i int) uint8 {
+		return uint8(math.Round(from[i] + (to[i]-from[i])*f))
+	}
+	return color.RGBA{R: mix(0), G: mix(1), B: mix(2), A: 255}
+}
+
+// heatStrip draws one colored cell per window under the waveform
+type heatStrip struct {
+	colors []color.Color
+	start  float64 // Time in seconds of the start of the first window
+	end    float64 // Time in seconds of the end of the last window
+	window float64 // Length of a window in seconds
+}
+
+// Plot implements the plot.Plotter interface
+func (hs *heatStrip) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	top, bottom := trY(stripTop), trY(stripBottom)
+	for i, col := range hs.colors {
+		if col == nil {
+			continue
+		}
+		x0 := trX(hs.start + float64(i)*hs.window)
+		x1 := trX(math.Min(hs.start+float64(i+1)*hs.window, hs.end))
+		c.FillPolygon(col, c.ClipPolygonXY([]vg.Point{
+			{X: x0, Y: bottom},
+			{X: x1, Y: bottom},
+			{X: x1, Y: top},
+			{X: x0, Y: top},
+		}))
+	}
+}
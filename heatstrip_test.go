@@ -0,0 +1,76 @@
+package gowaveform
+
+import (
+	"image/color"
+	"os"
+	"testing"
+)
+
+func TestHeatColor(t *testing.T) {
+	tests := []struct {
+		t        float64
+		expected color.RGBA
+	}{
+		{-1, color.RGBA{R: 0, G: 170, B: 80, A: 255}},
+		{0, color.RGBA{R: 0, G: 170, B: 80, A: 255}},
+		{0.5, color.RGBA{R: 240, G: 200, B: 0, A: 255}},
+		{1, color.RGBA{R: 220, G: 30, B: 30, A: 255}},
+		{2, color.RGBA{R: 220, G: 30, B: 30, A: 255}},
+	}
+	for _, tt := range tests {
+		if got := heatColor(tt.t); got != tt.expected {
+			t.Errorf("heatColor(%.1f) = %v, expected %v", tt.t, got, tt.expected)
+		}
+	}
+}
+
+func TestStripColors(t *testing.T) {
+	// 1s of a sine (crest factor 3 dB, red) followed by 1s of silence (blank)
+	w := toneWaveform(44100, 1, 441, 0.5, 1)
+	w.audioData = append(w.audioData, make([]int16, 44100)...)
+	w.totalSamples *= 2
+
+	colors := w.stripColors(StripCrestFactor, 0, w.totalSamples, 0.5)
+	if len(colors) != 4 {
+		t.Fatalf("Expected 4 windows, got %d", len(colors))
+	}
+	red := heatColor(1)
+	if colors[0] != red || colors[1] != red {
+		t.Errorf("Expected red for a sine, got %v and %v", colors[0], colors[1])
+	}
+	if colors[2] != nil || colors[3] != nil {
+		t.Errorf("Expected no color for silence, got %v and %v", colors[2], colors[3])
+	}
+
+	if w.stripColors(StripNone, 0, w.totalSamples, 0.5) != nil {
+		t.Error("Expected no colors without a metric")
+	}
+}
+
+func TestSavePlotHeatStrip(t *testing.T) {
+	tmpWav := "/tmp/test_plot_heatstrip.wav"
+	defer os.Remove(tmpWav)
+
+	// A tone that is quiet for the first half
+	tone := sineSamples(44100, 2, 440, 0.5, 2.0)
+	for i := range tone[:len(tone)/2] {
+		tone[i] /= 100
+	}
+	writeTestWAV(t, tmpWav, 44100, 2, tone)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	for name, metric := range map[string]StripMetric{"crest": StripCrestFactor, "loudness": StripLoudness} {
+		tmpPlot := "/tmp/test_plot_heatstrip_" + name + ".png"
+		defer os.Remove(tmpPlot)
+
+		err = SavePlot(waveform, tmpPlot, OptionHeatStrip(metric), OptionSetStart(0.5), OptionSetEnd(1.5))
+		if err != nil {
+			t.Fatalf("SavePlot with the %s strip failed: %v", name, err)
+		}
+		verifyImageFile(t, tmpPlot)
+	}
+}
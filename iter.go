@@ -0,0 +1,47 @@
+package gowaveform
+
+import "iter"
+
+// Frames returns an iterator over the decoded frames that yields the frame index and
+// the sample of every channel, so analysis code can stream through the audio without
+// copying it. The slice is reused for every frame; copy it to keep it. During a
+// progressive load iteration ends at the last frame decoded so far.
+func (w *Waveform) Frames() iter.Seq2[int, []int16] {
+	return func(yield func(int, []int16) bool) {
+		samples := make([]int16, w.Channels)
+		for frame := 0; frame < w.availableFrames(); frame++ {
+			copy(samples, w.audioData[frame*w.Channels:])
+			if !yield(frame, samples) {
+				return
+			}
+		}
+	}
+}
+
+// Pixels returns an iterator over the min/max pairs of a view, computed one pixel at a
+// time as the loop asks for them. The pairs match GenerateView for the same options,
+// including Symmetric and Filter; Bands, Metadata and the output format options do
+// not apply.
+func (w *Waveform) Pixels(opts WaveformOptions) (iter.Seq2[int16, int16], error) {
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.pixelPeaks(opts, startSample); err != nil {
+		return nil, err
+	}
+
+	return func(yield func(int16, int16) bool) {
+		// Every iteration starts with fresh filter state
+		peaks, _ := w.pixelPeaks(opts, startSample)
+		for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+			lo, hi := peaks(pixelStart, min(samplesPerPixel, endSample-pixelStart))
+			if opts.Symmetric {
+				lo, hi = mirrorPair(lo, hi)
+			}
+			if !yield(lo, hi) {
+				return
+			}
+		}
+	}, nil
+}
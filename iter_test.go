@@ -0,0 +1,89 @@
+package gowaveform
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFrames(t *testing.T) {
+	tmpFile := "/tmp/test_frames_iter.wav"
+	defer os.Remove(tmpFile)
+
+	samples := []int16{1, -1, 2, -2, 3, -3, 4, -4}
+	writeTestWAV(t, tmpFile, 44100, 2, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	count := 0
+	for frame, values := range waveform.Frames() {
+		if frame != count {
+			t.Errorf("Expected frame %d, got %d", count, frame)
+		}
+		if len(values) != 2 || values[0] != samples[frame*2] || values[1] != samples[frame*2+1] {
+			t.Errorf("Frame %d: expected %v, got %v", frame, samples[frame*2:frame*2+2], values)
+		}
+		count++
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 frames, got %d", count)
+	}
+
+	// Breaking out of the loop stops the iteration
+	count = 0
+	for frame := range waveform.Frames() {
+		if frame == 1 {
+			break
+		}
+		count++
+	}
+	if count != 1 {
+		t.Errorf("Expected to stop after 1 frame, got %d", count)
+	}
+}
+
+func TestPixels(t *testing.T) {
+	tmpFile := "/tmp/test_pixels_iter.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	for _, opts := range []WaveformOptions{
+		{Width: 300},
+		{Start: 0.25, End: 0.75, SamplesPerPixel: 100},
+		{Width: 50, Symmetric: true},
+	} {
+		view, err := waveform.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		pixels, err := waveform.Pixels(opts)
+		if err != nil {
+			t.Fatalf("Pixels failed: %v", err)
+		}
+
+		var data []int16
+		for lo, hi := range pixels {
+			data = append(data, lo, hi)
+		}
+		if len(data) != len(view.Data) {
+			t.Fatalf("%+v: expected %d values, got %d", opts, len(view.Data), len(data))
+		}
+		for i := range data {
+			if data[i] != view.Data[i] {
+				t.Fatalf("%+v: value %d: expected %d, got %d", opts, i, view.Data[i], data[i])
+			}
+		}
+	}
+
+	if _, err := waveform.Pixels(WaveformOptions{Start: 2}); err == nil {
+		t.Error("Expected an error for a range past the end")
+	}
+}
@@ -0,0 +1,205 @@
+package gowaveform
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WriteJSON streams waveform data to w as indented JSON.
+// The output is byte-for-byte identical to GenerateJSON, but the data array is
+// written incrementally instead of being marshaled into memory first, so very
+// large views are not buffered twice.
+func WriteJSON(w io.Writer, data *WaveformData) error {
+	return writeJSON(w, data, true)
+}
+
+// WriteJSONCompact streams waveform data to w as compact JSON without
+// indentation or newlines, matching json.Marshal
+func WriteJSONCompact(w io.Writer, data *WaveformData) error {
+	return writeJSON(w, data, false)
+}
+
+func writeJSON(w io.Writer, data *WaveformData, indent bool) error {
+	if data == nil {
+		return fmt.Errorf("failed to write JSON: no waveform data")
+	}
+
+	jw := getJSONStreamWriter(w, indent)
+	defer putJSONStreamWriter(jw)
+
+	jw.raw("{")
+	jw.intField(1, "version", data.Version, false)
+	if data.Channels != 0 {
+		jw.intField(1, "channels", data.Channels, true)
+	}
+	jw.intField(1, "sample_rate", data.SampleRate, true)
+	jw.intField(1, "samples_per_pixel", data.SamplesPerPixel, true)
+	jw.intField(1, "bits", data.Bits, true)
+	jw.intField(1, "length", data.Length, true)
+	jw.key(1, "data", true)
+	jw.int16Array(1, data.Data)
+
+	if len(data.Bands) > 0 {
+		jw.key(1, "bands", true)
+		jw.raw("[")
+		for i, band := range data.Bands {
+			if i > 0 {
+				jw.raw(",")
+			}
+			jw.newline(2)
+			jw.raw("{")
+			jw.floatField(3, "low_frequency", band.LowFrequency, false)
+			jw.floatField(3, "high_frequency", band.HighFrequency, true)
+			jw.key(3, "data", true)
+			jw.int16Array(3, band.Data)
+			jw.newline(2)
+			jw.raw("}")
+		}
+		jw.newline(1)
+		jw.raw("]")
+	}
+
+	if data.TimeAxis != nil {
+		jw.key(1, "time_axis", true)
+		jw.value(1, data.TimeAxis)
+	}
+
+	if data.Metadata != nil {
+		jw.key(1, "metadata", true)
+		jw.value(1, data.Metadata)
+	}
+
+	jw.newline(0)
+	jw.raw("}")
+
+	if jw.err != nil {
+		return fmt.Errorf("failed to write JSON: %w", jw.err)
+	}
+	if err := jw.w.Flush(); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// jsonStreamWriter writes JSON tokens in the layout used by json.MarshalIndent
+// with two-space indentation (or json.Marshal when indent is false).
+// The first write error is kept and later writes are skipped.
+type jsonStreamWriter struct {
+	w      *bufio.Writer
+	indent bool
+	buf    []byte
+	err    error
+}
+
+// jsonWriterPool holds stream writers, with their buffers, reused across writeJSON calls
+var jsonWriterPool sync.Pool
+
+// getJSONStreamWriter returns a stream writer for w from jsonWriterPool
+func getJSONStreamWriter(w io.Writer, indent bool) *jsonStreamWriter {
+	jw, ok := jsonWriterPool.Get().(*jsonStreamWriter)
+	if !ok {
+		return &jsonStreamWriter{w: bufio.NewWriter(w), indent: indent}
+	}
+	jw.w.Reset(w)
+	jw.indent, jw.buf, jw.err = indent, jw.buf[:0], nil
+	return jw
+}
+
+// putJSONStreamWriter returns jw to jsonWriterPool without keeping its destination
+func putJSONStreamWriter(jw *jsonStreamWriter) {
+	jw.w.Reset(nil)
+	jsonWriterPool.Put(jw)
+}
+
+func (jw *jsonStreamWriter) raw(s string) {
+	if jw.err != nil {
+		return
+	}
+	_, jw.err = jw.w.WriteString(s)
+}
+
+func (jw *jsonStreamWriter) newline(depth int) {
+	if jw.indent {
+		jw.raw("\n" + strings.Repeat("  ", depth))
+	}
+}
+
+// key writes an object key at the given depth, preceded by a comma unless it is the first field
+func (jw *jsonStreamWriter) key(depth int, name string, comma bool) {
+	if comma {
+		jw.raw(",")
+	}
+	jw.newline(depth)
+	jw.raw(strconv.Quote(name))
+	if jw.indent {
+		jw.raw(": ")
+	} else {
+		jw.raw(":")
+	}
+}
+
+func (jw *jsonStreamWriter) intField(depth int, name string, value int, comma bool) {
+	jw.key(depth, name, comma)
+	jw.raw(strconv.Itoa(value))
+}
+
+func (jw *jsonStreamWriter) floatField(depth int, name string, value float64, comma bool) {
+	jw.key(depth, name, comma)
+	// Use encoding/json for floats so the number format matches GenerateJSON exactly
+	jw.value(depth, value)
+}
+
+// value writes a small value with encoding/json, indented to continue at the given depth
+func (jw *jsonStreamWriter) value(depth int, v interface{}) {
+	var encoded []byte
+	var err error
+	if jw.indent {
+		encoded, err = json.MarshalIndent(v, strings.Repeat("  ", depth), "  ")
+	} else {
+		encoded, err = json.Marshal(v)
+	}
+	if err != nil {
+		if jw.err == nil {
+			jw.err = err
+		}
+		return
+	}
+	jw.raw(string(encoded))
+}
+
+// int16Array writes an array whose opening bracket follows a key at the given depth
+func (jw *jsonStreamWriter) int16Array(depth int, values []int16) {
+	if values == nil {
+		jw.raw("null")
+		return
+	}
+	if len(values) == 0 {
+		jw.raw("[]")
+		return
+	}
+
+	jw.raw("[")
+	prefix := ""
+	if jw.indent {
+		prefix = "\n" + strings.Repeat("  ", depth+1)
+	}
+	for i, v := range values {
+		if jw.err != nil {
+			return
+		}
+		jw.buf = jw.buf[:0]
+		if i > 0 {
+			jw.buf = append(jw.buf, ',')
+		}
+		jw.buf = append(jw.buf, prefix...)
+		jw.buf = strconv.AppendInt(jw.buf, int64(v), 10)
+		_, jw.err = jw.w.Write(jw.buf)
+	}
+	jw.newline(depth)
+	jw.raw("]")
+}
@@ -0,0 +1,93 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestWriteJSONMatchesGenerateJSON(t *testing.T) {
+	tmpFile := "/tmp/test_writejson.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	views := map[string]WaveformOptions{
+		"plain":    {Width: 100},
+		"bands":    {Width: 50, Bands: []float64{200, 2000}},
+		"metadata": {Width: 20, Bands: []float64{500}, Metadata: true},
+		"version1": {Width: 20, FormatVersion: 1},
+		"timeaxis": {Start: 0.25, Width: 10, TimeAxis: true, Metadata: true},
+	}
+
+	for name, opts := range views {
+		data, err := waveform.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("%s: GenerateView failed: %v", name, err)
+		}
+
+		expected, err := GenerateJSON(data)
+		if err != nil {
+			t.Fatalf("%s: GenerateJSON failed: %v", name, err)
+		}
+		var buf bytes.Buffer
+		if err := WriteJSON(&buf, data); err != nil {
+			t.Fatalf("%s: WriteJSON failed: %v", name, err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("%s: WriteJSON output differs from GenerateJSON", name)
+		}
+
+		expectedCompact, err := json.Marshal(data)
+		if err != nil {
+			t.Fatalf("%s: json.Marshal failed: %v", name, err)
+		}
+		buf.Reset()
+		if err := WriteJSONCompact(&buf, data); err != nil {
+			t.Fatalf("%s: WriteJSONCompact failed: %v", name, err)
+		}
+		if !bytes.Equal(buf.Bytes(), expectedCompact) {
+			t.Errorf("%s: WriteJSONCompact output differs from json.Marshal:\n%s\n%s", name, buf.Bytes(), expectedCompact)
+		}
+	}
+}
+
+func TestWriteJSONEmptyData(t *testing.T) {
+	for _, data := range []*WaveformData{
+		{Version: 2, Data: []int16{}},
+		{Version: 2},
+	} {
+		expected, _ := json.MarshalIndent(data, "", "  ")
+		var buf bytes.Buffer
+		if err := WriteJSON(&buf, data); err != nil {
+			t.Fatalf("WriteJSON failed: %v", err)
+		}
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("Expected %s, got %s", expected, buf.Bytes())
+		}
+	}
+
+	if err := WriteJSON(&bytes.Buffer{}, nil); err == nil {
+		t.Error("Expected error for nil data")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteJSONWriteError(t *testing.T) {
+	data := &WaveformData{Version: 2, Data: make([]int16, 100000)}
+	if err := WriteJSON(failingWriter{}, data); err == nil {
+		t.Error("Expected write error to be returned")
+	}
+}
@@ -0,0 +1,143 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// PitchClassNames are the names of the twelve pitch classes, starting at C
+var PitchClassNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// Key is a musical key
+type Key struct {
+	Tonic int  // Pitch class of the tonic, from 0 (C) to 11 (B)
+	Minor bool // Minor mode; major otherwise
+}
+
+// String returns the name of the key, e.g. "F# minor"
+func (k Key) String() string {
+	mode := "major"
+	if k.Minor {
+		mode = "minor"
+	}
+	return PitchClassNames[k.Tonic] + " " + mode
+}
+
+// Krumhansl-Kessler key profiles: the perceived fit of each pitch class, starting at the tonic
+var (
+	majorProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// Settings of the chromagram used by DetectKey
+const (
+	keyFFTSize      = 8192 // About 5 Hz per bin at 44.1 kHz, enough to separate semitones from A1 up
+	keyMinFrequency = 55.0
+	keyMaxFrequency = 5000.0
+	maxKeyFrames    = 1000 // Longer files are subsampled
+)
+
+// DetectKey estimates the key of the audio by summing a chromagram of the whole file
+// and correlating it with the Krumhansl-Kessler profile of every major and minor key.
+// The confidence is the correlation of the best key, from 0 to 1; relative keys (such
+// as C major and A minor) share their notes, so a low margin between them is common.
+func DetectKey(w *Waveform) (key Key, confidence float64, err error) {
+	frames := w.availableFrames()
+	if frames == 0 {
+		return Key{}, 0, fmt.Errorf("no audio to detect a key")
+	}
+
+	hop := keyFFTSize / 2
+	step := max(1, (frames+hop-1)/hop/maxKeyFrames) * hop
+	window, _ := hannWindow(keyFFTSize)
+	buf := make([]complex128, keyFFTSize)
+	magnitudes := make([]float64, keyFFTSize/2+1)
+
+	var chroma [12]float64
+	for start := 0; start < frames; start += step {
+		for i := range buf {
+			var x float64
+			if start+i < frames {
+				x = w.monoSample(start+i) * window[i]
+			}
+			buf[i] = complex(x, 0)
+		}
+		fft(buf)
+		for bin := range magnitudes {
+			magnitudes[bin] = cmplx.Abs(buf[bin])
+		}
+		addChroma(&chroma, magnitudes, w.SampleRate, keyMinFrequency, keyMaxFrequency)
+	}
+
+	var total float64
+	for _, v := range chroma {
+		total += v
+	}
+	if total == 0 {
+		return Key{}, 0, fmt.Errorf("no tonal content to detect a key")
+	}
+
+	best := math.Inf(-1)
+	for tonic := 0; tonic < 12; tonic++ {
+		for _, minor := range []bool{false, true} {
+			profile := majorProfile
+			if minor {
+				profile = minorProfile
+			}
+			var rotated [12]float64
+			for pc := range rotated {
+				rotated[pc] = profile[(pc-tonic+12)%12]
+			}
+			if r := pearson(chroma[:], rotated[:]); r > best {
+				best = r
+				key = Key{Tonic: tonic, Minor: minor}
+			}
+		}
+	}
+
+	return key, math.Max(best, 0), nil
+}
+
+// addChroma adds the magnitudes of the spectrum bins (0 to FFT size/2) between low and
+// high Hz to the pitch class nearest to each bin's frequency
+func addChroma(chroma *[12]float64, magnitudes []float64, sampleRate int, low, high float64) {
+	fftSize := 2 * (len(magnitudes) - 1)
+	for bin := 1; bin < len(magnitudes); bin++ {
+		f := float64(bin) * float64(sampleRate) / float64(fftSize)
+		if f < low || f > high {
+			continue
+		}
+		chroma[pitchClass(f)] += magnitudes[bin]
+	}
+}
+
+// pitchClass returns the pitch class (0 = C) nearest to a frequency in Hz, tuned to A4 = 440 Hz
+func pitchClass(frequency float64) int {
+	semitones := int(math.Round(12*math.Log2(frequency/440))) + 9
+	return (semitones%12 + 12) % 12
+}
+
+// pearson returns the correlation coefficient of two equally long series, or 0 if
+// either is constant
+func pearson(a, b []float64) float64 {
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(len(a))
+	meanB /= float64(len(b))
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
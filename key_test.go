@@ -0,0 +1,83 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+// chordSamples returns mono samples of equal-level sines at the given frequencies
+func chordSamples(sampleRate int, seconds float64, frequencies ...float64) []int16 {
+	samples := make([]int16, int(seconds*float64(sampleRate)))
+	for i := range samples {
+		var v float64
+		for _, f := range frequencies {
+			v += math.Sin(2 * math.Pi * f * float64(i) / float64(sampleRate))
+		}
+		samples[i] = int16(math.Round(8000 * v))
+	}
+	return samples
+}
+
+func TestDetectKey(t *testing.T) {
+	tmpFile := "/tmp/test_detect_key.wav"
+	defer os.Remove(tmpFile)
+
+	tests := []struct {
+		name        string
+		frequencies []float64
+		expected    string
+	}{
+		{"C major triad", []float64{261.63, 329.63, 392.00}, "C major"},
+		{"A minor triad", []float64{220.00, 261.63, 329.63}, "A minor"},
+		{"F# minor triad", []float64{185.00, 220.00, 277.18}, "F# minor"},
+	}
+
+	for _, tt := range tests {
+		writeTestWAV(t, tmpFile, 44100, 1, chordSamples(44100, 2, tt.frequencies...))
+		waveform, err := LoadWaveform(tmpFile)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+
+		key, confidence, err := DetectKey(waveform)
+		if err != nil {
+			t.Fatalf("%s: DetectKey failed: %v", tt.name, err)
+		}
+		if key.String() != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, key)
+		}
+		if confidence < 0.5 || confidence > 1 {
+			t.Errorf("%s: expected a confidence between 0.5 and 1, got %.3f", tt.name, confidence)
+		}
+	}
+}
+
+func TestDetectKeySilence(t *testing.T) {
+	tmpFile := "/tmp/test_detect_key_silence.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, make([]int16, 44100))
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if _, _, err := DetectKey(waveform); err == nil {
+		t.Error("Expected an error for silent audio")
+	}
+}
+
+func TestPitchClass(t *testing.T) {
+	tests := []struct {
+		frequency float64
+		expected  int
+	}{
+		{440, 9}, {261.63, 0}, {55, 9}, {466.16, 10}, {258, 0}, {4186, 0},
+	}
+	for _, tt := range tests {
+		if pc := pitchClass(tt.frequency); pc != tt.expected {
+			t.Errorf("pitchClass(%.2f) = %d, expected %d", tt.frequency, pc, tt.expected)
+		}
+	}
+}
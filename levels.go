@@ -0,0 +1,141 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// ChannelLevel holds the peak and RMS level of one channel, normalized to 0.0..1.0
+type ChannelLevel struct {
+	Peak float64 `json:"peak"`
+	RMS  float64 `json:"rms"`
+}
+
+// PeakDBFS returns the peak level in dBFS (-Inf for silence)
+func (l ChannelLevel) PeakDBFS() float64 {
+	return ToDBFS(l.Peak)
+}
+
+// RMSDBFS returns the RMS level in dBFS (-Inf for silence)
+func (l ChannelLevel) RMSDBFS() float64 {
+	return ToDBFS(l.RMS)
+}
+
+// ToDBFS converts a linear level (1.0 = full scale) to dBFS, returning -Inf for 0
+func ToDBFS(level float64) float64 {
+	if level <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(level)
+}
+
+// ChannelLevels computes the peak and RMS level of each channel between start and end
+// in seconds (end 0 means end of file)
+func (w *Waveform) ChannelLevels(start, end float64) ([]ChannelLevel, error) {
+	startSample, endSample, _, err := w.resolveRange(WaveformOptions{Start: start, End: end})
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make([]ChannelLevel, w.Channels)
+	count := endSample - startSample
+	if count == 0 {
+		return levels, nil
+	}
+
+	for ch := range levels {
+		var peak int
+		var sum float64
+		for frame := startSample; frame < endSample; frame++ {
+			v := int(w.audioData[frame*w.Channels+ch])
+			if v < 0 {
+				v = -v
+			}
+			if v > peak {
+				peak = v
+			}
+			sum += float64(v) * float64(v)
+		}
+		levels[ch] = ChannelLevel{
+			Peak: float64(peak) / 32768.0,
+			RMS:  math.Sqrt(sum/float64(count)) / 32768.0,
+		}
+	}
+
+	return levels, nil
+}
+
+// RangeStats holds the levels of a selection of the audio
+type RangeStats struct {
+	Start    float64        `json:"start"`    // Start of the selection in seconds
+	End      float64        `json:"end"`      // End of the selection in seconds
+	Duration float64        `json:"duration"` // Length of the selection in seconds
+	Peak     float64        `json:"peak"`     // Highest sample peak of any channel, 0.0..1.0
+	RMS      float64        `json:"rms"`      // RMS level across all channels, 0.0..1.0
+	Levels   []ChannelLevel `json:"levels"`   // Peak and RMS level per channel
+}
+
+// PeakDBFS returns the peak level in dBFS (-Inf for silence)
+func (s RangeStats) PeakDBFS() float64 {
+	return ToDBFS(s.Peak)
+}
+
+// RMSDBFS returns the RMS level in dBFS (-Inf for silence)
+func (s RangeStats) RMSDBFS() float64 {
+	return ToDBFS(s.RMS)
+}
+
+// StatsRange computes the duration, peak and RMS level of a selection between start and
+// end in seconds (end 0 means end of file), e.g. for the status bar of an editor
+func (w *Waveform) StatsRange(start, end float64) (*RangeStats, error) {
+	startSample, endSample, _, err := w.resolveRange(WaveformOptions{Start: start, End: end})
+	if err != nil {
+		return nil, err
+	}
+	levels, err := w.ChannelLevels(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &RangeStats{
+		Start:  float64(startSample) / float64(w.SampleRate),
+		End:    float64(endSample) / float64(w.SampleRate),
+		Levels: levels,
+	}
+	stats.Duration = stats.End - stats.Start
+	var energy float64
+	for _, l := range levels {
+		stats.Peak = math.Max(stats.Peak, l.Peak)
+		energy += l.RMS * l.RMS
+	}
+	if len(levels) > 0 {
+		stats.RMS = math.Sqrt(energy / float64(len(levels)))
+	}
+	return stats, nil
+}
+
+// Samples returns a copy of the decoded samples of one channel, e.g. for analysis code
+// that needs the raw audio. Changing the copy does not change the waveform. During a
+// progressive load it holds the frames decoded so far. Returns nil for an invalid channel.
+func (w *Waveform) Samples(channel int) []int16 {
+	if channel < 0 || channel >= w.Channels {
+		return nil
+	}
+	samples := make([]int16, w.availableFrames())
+	for frame := range samples {
+		samples[frame] = w.audioData[frame*w.Channels+channel]
+	}
+	return samples
+}
+
+// SampleAtTime returns the sample value of a channel at a time in seconds
+func (w *Waveform) SampleAtTime(t float64, channel int) (int16, error) {
+	if channel < 0 || channel >= w.Channels {
+		return 0, fmt.Errorf("invalid channel %d: audio has %d channels", channel, w.Channels)
+	}
+	frame := int(t * float64(w.SampleRate))
+	if t < 0 || frame >= w.availableFrames() {
+		return 0, fmt.Errorf("time %.3fs is outside the audio", t)
+	}
+	return w.audioData[frame*w.Channels+channel], nil
+}
@@ -0,0 +1,140 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestChannelLevels(t *testing.T) {
+	tmpFile := "/tmp/test_levels.wav"
+	defer os.Remove(tmpFile)
+
+	// Left: constant 16384 (-6 dBFS), right: silence
+	samples := make([]int16, 2*4410)
+	for i := 0; i < len(samples); i += 2 {
+		samples[i] = 16384
+	}
+	writeTestWAV(t, tmpFile, 44100, 2, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	levels, err := waveform.ChannelLevels(0, 0)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	if len(levels) != 2 {
+		t.Fatalf("Expected 2 channels, got %d", len(levels))
+	}
+
+	if levels[0].Peak != 0.5 || levels[0].RMS != 0.5 {
+		t.Errorf("Expected left peak and RMS of 0.5, got %+v", levels[0])
+	}
+	if math.Abs(levels[0].PeakDBFS()-(-6.02)) > 0.01 {
+		t.Errorf("Expected -6.02 dBFS, got %f", levels[0].PeakDBFS())
+	}
+	if !math.IsInf(levels[1].PeakDBFS(), -1) || !math.IsInf(levels[1].RMSDBFS(), -1) {
+		t.Errorf("Expected silent right channel at -Inf dBFS, got %+v", levels[1])
+	}
+
+	if _, err := waveform.ChannelLevels(0.2, 0.1); err == nil {
+		t.Error("Expected error for inverted range")
+	}
+}
+
+func TestStatsRange(t *testing.T) {
+	// Stereo, 0.1s of a constant 16384 in the left channel followed by 0.1s of silence
+	w := &Waveform{SampleRate: 1000, Channels: 2, BitsPerSample: 16, audioData: make([]int16, 400), totalSamples: 200}
+	for i := 0; i < 200; i += 2 {
+		w.audioData[i] = 16384
+	}
+
+	stats, err := w.StatsRange(0.05, 0.15)
+	if err != nil {
+		t.Fatalf("StatsRange failed: %v", err)
+	}
+	if math.Abs(stats.Start-0.05) > 1e-9 || math.Abs(stats.End-0.15) > 1e-9 || math.Abs(stats.Duration-0.1) > 1e-9 {
+		t.Errorf("Expected the selection from 0.05s to 0.15s, got %+v", stats)
+	}
+	if stats.Peak != 0.5 || math.Abs(stats.PeakDBFS()+6.02) > 0.01 {
+		t.Errorf("Expected a peak of 0.5, got %f", stats.Peak)
+	}
+	// Half of the left channel at 0.5 and a silent right channel
+	if math.Abs(stats.RMS-0.25) > 1e-9 {
+		t.Errorf("Expected an RMS of 0.25, got %f", stats.RMS)
+	}
+	if len(stats.Levels) != 2 || stats.Levels[1].Peak != 0 {
+		t.Errorf("Expected levels per channel, got %+v", stats.Levels)
+	}
+
+	// End 0 selects to the end of the file
+	if stats, err := w.StatsRange(0.1, 0); err != nil || stats.Peak != 0 || math.Abs(stats.End-0.2) > 1e-9 {
+		t.Errorf("Expected a silent selection to the end, got %+v, %v", stats, err)
+	}
+	if _, err := w.StatsRange(0.2, 0.1); err == nil {
+		t.Error("Expected error for inverted range")
+	}
+}
+
+func TestSampleAtTime(t *testing.T) {
+	tmpFile := "/tmp/test_sample_at_time.wav"
+	defer os.Remove(tmpFile)
+
+	samples := []int16{1, -1, 2, -2, 3, -3, 4, -4}
+	writeTestWAV(t, tmpFile, 4, 2, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if v, err := waveform.SampleAtTime(0.5, 0); err != nil || v != 3 {
+		t.Errorf("Expected 3 at 0.5s on the left channel, got %d (%v)", v, err)
+	}
+	if v, err := waveform.SampleAtTime(0.25, 1); err != nil || v != -2 {
+		t.Errorf("Expected -2 at 0.25s on the right channel, got %d (%v)", v, err)
+	}
+	if _, err := waveform.SampleAtTime(1.0, 0); err == nil {
+		t.Error("Expected error past the end")
+	}
+	if _, err := waveform.SampleAtTime(0, 2); err == nil {
+		t.Error("Expected error for invalid channel")
+	}
+}
+
+func TestSamples(t *testing.T) {
+	tmpFile := "/tmp/test_samples.wav"
+	defer os.Remove(tmpFile)
+
+	samples := []int16{1, -1, 2, -2, 3, -3, 4, -4}
+	writeTestWAV(t, tmpFile, 4, 2, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	right := waveform.Samples(1)
+	expected := []int16{-1, -2, -3, -4}
+	if len(right) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, right)
+	}
+	for i := range expected {
+		if right[i] != expected[i] {
+			t.Errorf("Sample %d: expected %d, got %d", i, expected[i], right[i])
+		}
+	}
+
+	// The result is a copy
+	right[0] = 100
+	if v, _ := waveform.SampleAtTime(0, 1); v != -1 {
+		t.Errorf("Expected the waveform to be unchanged, got %d", v)
+	}
+
+	if waveform.Samples(2) != nil || waveform.Samples(-1) != nil {
+		t.Error("Expected nil for an invalid channel")
+	}
+}
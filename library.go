@@ -0,0 +1,283 @@
+package gowaveform
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// DefaultLibraryExtensions are the files ScanLibrary picks up unless
+// OptionScanExtensions is given, in addition to those with a registered decoder
+var DefaultLibraryExtensions = []string{".wav", ".mp3", ".flac", ".ogg", ".aif", ".aiff"}
+
+// ScanResult describes one audio file found by ScanLibrary
+type ScanResult struct {
+	Path          string        // Path of the file, starting with the scanned root
+	Size          int64         // Size of the file in bytes
+	ModTime       time.Time     // Modification time of the file
+	SampleRate    int           // Sample rate in Hz
+	Channels      int           // Number of channels
+	BitsPerSample int           // Bit depth of the source
+	Duration      float64       // Duration in seconds
+	Overview      *WaveformData // Full-file view, nil with OptionScanOverviewWidth(0)
+	Thumbnail     image.Image   // Sparkline image, only set with OptionScanThumbnail
+	Fingerprint   *Fingerprint  // Perceptual fingerprint, only set with OptionScanFingerprint
+	Err           error         // Why the file could not be read; the other fields may be partial
+
+	Done  int // Files finished so far, including this one
+	Total int // Files found in the library
+}
+
+// ScanOption is the type all library scan options need to adhere to
+type ScanOption func(*scanConfig)
+
+// scanConfig holds the settings of a library scan
+type scanConfig struct {
+	workers         int
+	overviewWidth   int
+	thumbnailWidth  int
+	thumbnailHeight int
+	thumbnailOpts   []Option
+	fingerprint     bool
+	loadOpts        []LoadOption
+	extensions      []string
+}
+
+// OptionScanWorkers sets how many files are read at once (default: the number of CPUs)
+func OptionScanWorkers(n int) ScanOption {
+	return func(c *scanConfig) {
+		c.workers = n
+	}
+}
+
+// OptionScanOverviewWidth sets the width in pixels of the overview of each file
+// (default: 1000). With 0 and no thumbnail or fingerprint, WAV files are only probed from their
+// header instead of decoded, which makes listing a large library fast.
+func OptionScanOverviewWidth(width int) ScanOption {
+	return func(c *scanConfig) {
+		c.overviewWidth = width
+	}
+}
+
+// OptionScanThumbnail renders a sparkline thumbnail of each file with the given size in
+// pixels. Further plot options, such as colors, are applied on top.
+func OptionScanThumbnail(width, height int, opts ...Option) ScanOption {
+	return func(c *scanConfig) {
+		c.thumbnailWidth = width
+		c.thumbnailHeight = height
+		c.thumbnailOpts = opts
+	}
+}
+
+// OptionScanFingerprint computes the perceptual fingerprint of each file
+func OptionScanFingerprint() ScanOption {
+	return func(c *scanConfig) {
+		c.fingerprint = true
+	}
+}
+
+// OptionScanLoad adds options for loading each file, e.g. OptionFFmpegFallback
+func OptionScanLoad(opts ...LoadOption) ScanOption {
+	return func(c *scanConfig) {
+		c.loadOpts = append(c.loadOpts, opts...)
+	}
+}
+
+// OptionScanExtensions sets the extensions of the files to scan, replacing
+// DefaultLibraryExtensions. Extensions with a registered decoder are always scanned.
+func OptionScanExtensions(exts ...string) ScanOption {
+	return func(c *scanConfig) {
+		c.extensions = exts
+	}
+}
+
+// ScanLibrary walks the directory tree under root and reads every audio file in it
+// with a pool of workers, streaming a result per file over the returned channel as
+// soon as it is ready, in no particular order. Each result carries the progress of
+// the scan. Files that cannot be read, and directories that cannot be listed, give a
+// result with Err set. The channel is closed when the scan is done or ctx is canceled.
+func ScanLibrary(ctx context.Context, root string, opts ...ScanOption) (<-chan ScanResult, error) {
+	c := scanConfig{
+		workers:       runtime.NumCPU(),
+		overviewWidth: 1000,
+		extensions:    DefaultLibraryExtensions,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.workers <= 0:
+		return nil, fmt.Errorf("invalid number of workers: %d", c.workers)
+	case c.overviewWidth < 0:
+		return nil, fmt.Errorf("invalid overview width: %d", c.overviewWidth)
+	case c.thumbnailWidth < 0 || c.thumbnailHeight < 0 || (c.thumbnailWidth > 0) != (c.thumbnailHeight > 0):
+		return nil, fmt.Errorf("invalid thumbnail size: %dx%d", c.thumbnailWidth, c.thumbnailHeight)
+	}
+	if info, err := os.Stat(root); err != nil {
+		return nil, fmt.Errorf("failed to scan library: %w", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("failed to scan library: %s is not a directory", root)
+	}
+
+	extensions := map[string]bool{}
+	for _, ext := range c.extensions {
+		extensions[normalizeExt(ext)] = true
+	}
+	for _, ext := range registeredExtensions() {
+		extensions[ext] = true
+	}
+
+	results := make(chan ScanResult)
+	go func() {
+		defer close(results)
+
+		// Find all files first, so every result can report the total
+		var files, failed []ScanResult
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if ctx.Err() != nil {
+				return filepath.SkipAll
+			}
+			if err != nil {
+				failed = append(failed, ScanResult{Path: path, Err: fmt.Errorf("failed to scan library: %w", err)})
+				return nil // WalkDir skips the directory
+			}
+			if d.IsDir() || !extensions[normalizeExt(filepath.Ext(path))] {
+				return nil
+			}
+			files = append(files, ScanResult{Path: path})
+			return nil
+		})
+
+		var mu sync.Mutex // Serializes sends so Done increases along the channel
+		done, total := 0, len(files)+len(failed)
+		send := func(result ScanResult) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			result.Done, result.Total = done, total
+			select {
+			case results <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, result := range failed {
+			if !send(result) {
+				return
+			}
+		}
+
+		jobs := make(chan ScanResult)
+		var wg sync.WaitGroup
+		for range min(c.workers, max(len(files), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for job := range jobs {
+					if !send(c.scanFile(job)) {
+						return
+					}
+				}
+			}()
+		}
+	feed:
+		for _, job := range files {
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+		close(jobs)
+		wg.Wait()
+	}()
+	return results, nil
+}
+
+// scanFile reads the file of a result and fills in the rest of it
+func (c scanConfig) scanFile(result ScanResult) ScanResult {
+	info, err := os.Stat(result.Path)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to scan library: %w", err)
+		return result
+	}
+	result.Size, result.ModTime = info.Size(), info.ModTime()
+
+	if c.overviewWidth == 0 && c.thumbnailWidth == 0 && !c.fingerprint && isWAVFile(result.Path) {
+		if _, registered := registeredDecoder(result.Path); !registered {
+			if err := probeWAV(&result); err == nil {
+				return result
+			}
+			// Leave it to the decoder to report what is wrong
+		}
+	}
+
+	opts := c.loadOpts
+	if c.overviewWidth > 0 {
+		opts = append(opts[:len(opts):len(opts)], OptionPrecomputeOverview(c.overviewWidth))
+	}
+	w, err := LoadWaveform(result.Path, opts...)
+	if err == nil {
+		err = w.Wait() // With OptionProgressive
+	}
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer w.releaseAudio()
+	result.SampleRate, result.Channels, result.BitsPerSample = w.SampleRate, w.Channels, w.BitsPerSample
+	result.Duration = w.Duration()
+	result.Overview = w.Overview()
+
+	if c.thumbnailWidth > 0 {
+		plotOpts := append([]Option{
+			OptionSparkline(true),
+			OptionSetWidth(c.thumbnailWidth),
+			OptionSetHeight(c.thumbnailHeight),
+		}, c.thumbnailOpts...)
+		thumbnail, err := RenderPlot(w, plotOpts...)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to render thumbnail: %w", err)
+			return result
+		}
+		result.Thumbnail = thumbnail.Image
+	}
+	if c.fingerprint {
+		if result.Fingerprint, err = w.Fingerprint(); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	return result
+}
+
+// probeWAV fills in the format and duration of a WAV file from its header, without
+// decoding the audio
+func probeWAV(result *ScanResult) error {
+	f, err := os.Open(result.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	header, err := ReadWAVHeader(f)
+	if err != nil {
+		return err
+	}
+
+	// Files cut short while recording hold less than their header declares
+	dataSize := min(int64(header.DataSize), max(result.Size-header.DataOffset, 0))
+	frameSize := int64(header.Channels) * ((int64(header.BitsPerSample) + 7) / 8)
+	result.SampleRate = int(header.SampleRate)
+	result.Channels = int(header.Channels)
+	result.BitsPerSample = int(header.BitsPerSample)
+	result.Duration = float64(dataSize/frameSize) / float64(header.SampleRate)
+	return nil
+}
@@ -0,0 +1,160 @@
+package gowaveform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// testLibrary writes a directory tree with two WAV files, a broken one and a file that
+// is not audio, and returns its root
+func testLibrary(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "album"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestWAV(t, filepath.Join(root, "song.wav"), 8000, 1, make([]int16, 8000))
+	writeTestWAV(t, filepath.Join(root, "album", "stereo.WAV"), 4000, 2, make([]int16, 2*2000))
+	for name, data := range map[string]string{"broken.wav": "not a wav file", "cover.jpg": "not audio"} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// collectScan drains a scan, checking the progress, and returns the results by file name
+func collectScan(t *testing.T, results <-chan ScanResult) map[string]ScanResult {
+	t.Helper()
+	byName := map[string]ScanResult{}
+	done := 0
+	for result := range results {
+		done++
+		if result.Done != done || result.Total != 3 {
+			t.Errorf("Expected progress %d of 3, got %d of %d", done, result.Done, result.Total)
+		}
+		byName[filepath.Base(result.Path)] = result
+	}
+	return byName
+}
+
+func TestScanLibrary(t *testing.T) {
+	root := testLibrary(t)
+	results, err := ScanLibrary(context.Background(), root, OptionScanWorkers(2), OptionScanThumbnail(40, 10))
+	if err != nil {
+		t.Fatalf("ScanLibrary failed: %v", err)
+	}
+	byName := collectScan(t, results)
+
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) != 3 || names[0] != "broken.wav" || names[1] != "song.wav" || names[2] != "stereo.WAV" {
+		t.Fatalf("Expected the three WAV files, got %v", names)
+	}
+
+	if byName["broken.wav"].Err == nil {
+		t.Error("Expected an error for the broken file")
+	}
+	song := byName["song.wav"]
+	if song.Err != nil {
+		t.Fatalf("Unexpected error: %v", song.Err)
+	}
+	if song.Path != filepath.Join(root, "song.wav") || song.Size != 44+16000 || song.ModTime.IsZero() {
+		t.Errorf("Unexpected file info: %s, %d bytes, %v", song.Path, song.Size, song.ModTime)
+	}
+	if song.SampleRate != 8000 || song.Channels != 1 || song.BitsPerSample != 16 || song.Duration != 1 {
+		t.Errorf("Unexpected format: %+v", song)
+	}
+	if song.Overview == nil || song.Overview.Length != 1000 {
+		t.Errorf("Expected an overview 1000 pixels wide, got %+v", song.Overview)
+	}
+	if song.Thumbnail == nil || song.Thumbnail.Bounds().Dx() != 40 || song.Thumbnail.Bounds().Dy() != 10 {
+		t.Errorf("Expected a 40x10 thumbnail, got %v", song.Thumbnail)
+	}
+	if stereo := byName["stereo.WAV"]; stereo.Channels != 2 || stereo.Duration != 0.5 {
+		t.Errorf("Unexpected format: %+v", stereo)
+	}
+}
+
+func TestScanLibraryProbe(t *testing.T) {
+	root := testLibrary(t)
+	results, err := ScanLibrary(context.Background(), root, OptionScanOverviewWidth(0))
+	if err != nil {
+		t.Fatalf("ScanLibrary failed: %v", err)
+	}
+	byName := collectScan(t, results)
+
+	// The same format as decoding, without an overview
+	song := byName["song.wav"]
+	if song.Err != nil || song.SampleRate != 8000 || song.Channels != 1 || song.Duration != 1 || song.Overview != nil {
+		t.Errorf("Unexpected probe result: %+v", song)
+	}
+	if stereo := byName["stereo.WAV"]; stereo.Channels != 2 || stereo.Duration != 0.5 {
+		t.Errorf("Unexpected probe result: %+v", stereo)
+	}
+	if byName["broken.wav"].Err == nil {
+		t.Error("Expected an error for the broken file")
+	}
+}
+
+func TestScanLibraryExtensions(t *testing.T) {
+	root := testLibrary(t)
+	if err := os.WriteFile(filepath.Join(root, "clip.fake"), []byte{1, 2, 3, 4}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	RegisterDecoder(".fake", decodeFake)
+	defer RegisterDecoder(".fake", nil)
+
+	// Registered decoders are always scanned
+	results, err := ScanLibrary(context.Background(), root, OptionScanExtensions(".jpg"), OptionScanOverviewWidth(0))
+	if err != nil {
+		t.Fatalf("ScanLibrary failed: %v", err)
+	}
+	var found []string
+	for result := range results {
+		found = append(found, filepath.Base(result.Path))
+	}
+	sort.Strings(found)
+	if len(found) != 2 || found[0] != "clip.fake" || found[1] != "cover.jpg" {
+		t.Errorf("Expected clip.fake and cover.jpg, got %v", found)
+	}
+}
+
+func TestScanLibraryCancel(t *testing.T) {
+	root := testLibrary(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	results, err := ScanLibrary(ctx, root, OptionScanWorkers(1))
+	if err != nil {
+		t.Fatalf("ScanLibrary failed: %v", err)
+	}
+	<-results
+	cancel()
+	// The channel is closed without reading the rest
+	for range results {
+	}
+}
+
+func TestScanLibraryErrors(t *testing.T) {
+	root := testLibrary(t)
+	for _, opts := range [][]ScanOption{
+		{OptionScanWorkers(0)},
+		{OptionScanOverviewWidth(-1)},
+		{OptionScanThumbnail(40, 0)},
+	} {
+		if _, err := ScanLibrary(context.Background(), root, opts...); err == nil {
+			t.Error("Expected an error for invalid options")
+		}
+	}
+	if _, err := ScanLibrary(context.Background(), filepath.Join(root, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+	if _, err := ScanLibrary(context.Background(), filepath.Join(root, "song.wav")); err == nil {
+		t.Error("Expected an error for a file")
+	}
+}
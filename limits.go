@@ -0,0 +1,128 @@
+package gowaveform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/schollz/audiomorph"
+)
+
+// Limits applied while loading unless changed with OptionMaxChannels and
+// OptionMaxSamples
+const (
+	DefaultMaxChannels = 64
+	DefaultMaxSamples  = 1 << 30 // Samples over all channels, about 3.4 hours of 44.1 kHz stereo
+)
+
+// unknownDataSize is the data chunk size written by streaming encoders that do not
+// know the length in advance; the data then runs to the end of the file
+const unknownDataSize = 0xFFFFFFFF
+
+// Errors returned, wrapped with details, when a file is over a load limit or its
+// header cannot be trusted, instead of attempting a huge allocation
+var (
+	ErrTooManyChannels  = errors.New("too many channels")
+	ErrTooManySamples   = errors.New("too many samples")
+	ErrDataSizeMismatch = errors.New("declared data size exceeds file size")
+)
+
+// OptionMaxChannels sets the largest number of channels a file may have (default:
+// DefaultMaxChannels); loading a file with more fails with ErrTooManyChannels.
+// 0 removes the limit.
+func OptionMaxChannels(n int) LoadOption {
+	return func(c *loadConfig) {
+		c.maxChannels = n
+	}
+}
+
+// OptionMaxSamples sets the largest number of samples, over all channels, a file may
+// have (default: DefaultMaxSamples); loading a longer file fails with
+// ErrTooManySamples. For WAV files this is checked from the header before decoding.
+// 0 removes the limit.
+func OptionMaxSamples(n int64) LoadOption {
+	return func(c *loadConfig) {
+		c.maxSamples = n
+	}
+}
+
+// checkWAVLimits reads the header of a WAV file of fileSize bytes from r and checks
+// it against the load limits. Decoders that allocate for the declared data size set
+// exact, making a data chunk that runs past the end of the file an error; others read
+// only the data present. r is left at the position it started from.
+func checkWAVLimits(r io.ReadSeeker, fileSize int64, c loadConfig, exact bool) error {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	header, err := ReadWAVHeader(r)
+	if _, seekErr := r.Seek(start, io.SeekStart); seekErr != nil {
+		return seekErr
+	}
+	if errors.Is(err, ErrDataSizeMismatch) {
+		return err
+	} else if err != nil {
+		return nil // Not for the limits to judge; the decoder reports what is wrong
+	}
+
+	if err := c.checkChannels(int(header.Channels)); err != nil {
+		return err
+	}
+	available := max(fileSize-start-header.DataOffset, 0)
+	dataSize := int64(header.DataSize)
+	if header.DataSize == unknownDataSize {
+		dataSize = available
+	}
+	if dataSize > available {
+		if exact {
+			return fmt.Errorf("%w: the header declares %d bytes of audio, the file holds %d",
+				ErrDataSizeMismatch, dataSize, available)
+		}
+		dataSize = available
+	}
+	bytesPerSample := (int64(header.BitsPerSample) + 7) / 8
+	return c.checkSamples(dataSize / bytesPerSample)
+}
+
+// checkWAVFileLimits checks the header of a WAV file on disk against the load limits
+func checkWAVFileLimits(filename string, c loadConfig, exact bool) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil // Reported by the decoder
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil
+	}
+	return checkWAVLimits(f, info.Size(), c, exact)
+}
+
+// checkAudioLimits checks decoded audio against the load limits before it is converted
+func checkAudioLimits(audio *audiomorph.Audio, c loadConfig) error {
+	if err := c.checkChannels(audio.NumChannels); err != nil {
+		return err
+	}
+	samples := int64(0)
+	for _, channel := range audio.Data {
+		samples += int64(len(channel))
+	}
+	return c.checkSamples(samples)
+}
+
+// checkChannels returns ErrTooManyChannels if channels is over the limit
+func (c loadConfig) checkChannels(channels int) error {
+	if c.maxChannels > 0 && channels > c.maxChannels {
+		return fmt.Errorf("%w: %d, the limit is %d", ErrTooManyChannels, channels, c.maxChannels)
+	}
+	return nil
+}
+
+// checkSamples returns ErrTooManySamples if samples is over the limit
+func (c loadConfig) checkSamples(samples int64) error {
+	if c.maxSamples > 0 && samples > c.maxSamples {
+		return fmt.Errorf("%w: %d, the limit is %d", ErrTooManySamples, samples, c.maxSamples)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+// withChunk inserts a chunk with a declared size before the data chunk of a WAV file
+// made by testWAVBytes
+func withChunk(wav []byte, id string, size uint32, body []byte) []byte {
+	out := append([]byte{}, wav[:36]...)
+	out = append(out, id...)
+	out = binary.LittleEndian.AppendUint32(out, size)
+	out = append(out, body...)
+	return append(out, wav[36:]...)
+}
+
+// withDataSize replaces the declared size of the data chunk of a WAV file made by
+// testWAVBytes
+func withDataSize(wav []byte, size uint32) []byte {
+	out := append([]byte{}, wav...)
+	binary.LittleEndian.PutUint32(out[40:44], size)
+	return out
+}
+
+func TestReadWAVHeaderChunks(t *testing.T) {
+	wav := testWAVBytes(8000, 2, make([]int16, 200))
+
+	// Odd-sized chunks are padded
+	header, err := ReadWAVHeader(bytes.NewReader(withChunk(wav, "LIST", 5, []byte("abcde\x00"))))
+	if err != nil {
+		t.Fatalf("ReadWAVHeader failed: %v", err)
+	}
+	if header.Channels != 2 || header.SampleRate != 8000 || header.DataSize != 400 || header.DataOffset != 58 {
+		t.Errorf("Unexpected header %+v", header)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not RIFF", append([]byte("RIFX"), wav[4:]...)},
+		{"truncated header", wav[:8]},
+		{"missing data chunk", wav[:36]},
+		{"no channels", append(append(append([]byte{}, wav[:22]...), 0, 0), wav[24:]...)},
+		{"data before fmt", append(append([]byte{}, wav[:12]...), wav[36:]...)},
+	}
+	for _, tt := range tests {
+		if _, err := ReadWAVHeader(bytes.NewReader(tt.data)); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	// A chunk claiming gigabytes is not passed on to the decoder
+	_, err = ReadWAVHeader(bytes.NewReader(withChunk(wav, "LIST", 0x7FFFFFF0, []byte("abcd"))))
+	if !errors.Is(err, ErrDataSizeMismatch) {
+		t.Errorf("Expected ErrDataSizeMismatch, got %v", err)
+	}
+}
+
+func TestLoadLimits(t *testing.T) {
+	tmpFile := "/tmp/test_limits.wav"
+	defer os.Remove(tmpFile)
+
+	// One second of four channels
+	data := testWAVBytes(8000, 4, make([]int16, 4*8000))
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		t.Fatalf("Failed to write test WAV file: %v", err)
+	}
+	fsys := fstest.MapFS{"test.wav": {Data: data}}
+
+	tests := []struct {
+		name string
+		opts []LoadOption
+		err  error
+	}{
+		{"defaults", nil, nil},
+		{"too many channels", []LoadOption{OptionMaxChannels(2)}, ErrTooManyChannels},
+		{"no channel limit", []LoadOption{OptionMaxChannels(0)}, nil},
+		{"too many samples", []LoadOption{OptionMaxSamples(1000)}, ErrTooManySamples},
+		{"too many samples progressively", []LoadOption{OptionMaxSamples(1000), OptionProgressive()}, ErrTooManySamples},
+		{"samples at the limit", []LoadOption{OptionMaxSamples(4 * 8000)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := LoadWaveform(tmpFile, tt.opts...)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("LoadWaveform: expected %v, got %v", tt.err, err)
+			}
+			if err == nil {
+				w.Wait()
+			}
+			if _, err := LoadWaveformFS(fsys, "test.wav", tt.opts...); !errors.Is(err, tt.err) {
+				t.Errorf("LoadWaveformFS: expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestLoadDataSizeMismatch(t *testing.T) {
+	tmpFile := "/tmp/test_limits_mismatch.wav"
+	defer os.Remove(tmpFile)
+
+	// The header claims 2 GB of audio in a file of a few hundred bytes
+	wav := withDataSize(testWAVBytes(8000, 1, make([]int16, 200)), 0x7FFFFFF0)
+	if err := os.WriteFile(tmpFile, wav, 0644); err != nil {
+		t.Fatalf("Failed to write test WAV file: %v", err)
+	}
+
+	// The progressive decoder would allocate for the declared size
+	if _, err := LoadWaveform(tmpFile, OptionProgressive()); !errors.Is(err, ErrDataSizeMismatch) {
+		t.Errorf("Expected ErrDataSizeMismatch when loading progressively, got %v", err)
+	}
+
+	// The others read the data that is there, as for files cut short while recording
+	w, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if w.totalSamples != 200 {
+		t.Errorf("Expected 200 samples, got %d", w.totalSamples)
+	}
+
+	// A chunk before the data claiming gigabytes fails on every path
+	wav = withChunk(testWAVBytes(8000, 1, make([]int16, 200)), "LIST", 0x7FFFFFF0, []byte("INFO"))
+	if err := os.WriteFile(tmpFile, wav, 0644); err != nil {
+		t.Fatalf("Failed to write test WAV file: %v", err)
+	}
+	if _, err := LoadWaveform(tmpFile); !errors.Is(err, ErrDataSizeMismatch) {
+		t.Errorf("Expected ErrDataSizeMismatch, got %v", err)
+	}
+	if _, err := LoadWaveformFS(fstest.MapFS{"test.wav": {Data: wav}}, "test.wav"); !errors.Is(err, ErrDataSizeMismatch) {
+		t.Errorf("Expected ErrDataSizeMismatch from LoadWaveformFS, got %v", err)
+	}
+}
+
+// FuzzReadWAVHeader checks that arbitrary input never panics and that a header is
+// only returned for data it lies within
+func FuzzReadWAVHeader(f *testing.F) {
+	wav := testWAVBytes(8000, 2, make([]int16, 64))
+	f.Add(wav)
+	f.Add(wav[:40])
+	f.Add(withDataSize(wav, 0xFFFFFFFF))
+	f.Add(withChunk(wav, "LIST", 0x7FFFFFF0, []byte("INFO")))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		header, err := ReadWAVHeader(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if header.Channels == 0 || header.DataOffset > int64(len(data)) {
+			t.Errorf("Invalid header %+v for %d bytes", header, len(data))
+		}
+	})
+}
+
+// FuzzLoadWAV checks that loading arbitrary WAV data never panics and stays within
+// the load limits
+func FuzzLoadWAV(f *testing.F) {
+	wav := testWAVBytes(8000, 2, make([]int16, 64))
+	f.Add(wav)
+	f.Add(wav[:50])
+	f.Add(withDataSize(wav, 0x7FFFFFF0))
+	f.Add(withChunk(wav, "LIST", 0x7FFFFFF0, []byte("INFO")))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		fsys := fstest.MapFS{"fuzz.wav": {Data: data}}
+		w, err := LoadWaveformFS(fsys, "fuzz.wav", OptionMaxChannels(8), OptionMaxSamples(1<<16))
+		if err != nil {
+			return
+		}
+		if w.Channels > 8 || len(w.audioData) > 1<<16 {
+			t.Errorf("Loaded %d channels and %d samples past the limits", w.Channels, len(w.audioData))
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package gowaveform
+
+import "fmt"
+
+// LoadOption is the type all load options need to adhere to
+type LoadOption func(*loadConfig)
+
+// loadConfig holds the settings applied while loading audio
+type loadConfig struct {
+	overviewWidth  int    // Width of the precomputed overview in pixels (0 = none)
+	progressive    bool   // Decode WAV files in the background after the first chunk
+	ffmpegFallback bool   // Decode with ffmpeg when the built-in decoders fail
+	maxChannels    int    // Most channels a file may have (0 = no limit)
+	maxSamples     int64  // Most samples over all channels a file may have (0 = no limit)
+	dither         Dither // Dither applied when reducing deeper audio to 16 bits
+	maxSampleRate  int    // Highest sample rate kept after decoding (0 = no limit)
+	mono           bool   // Mix the channels down to one while decoding
+}
+
+// OptionPrecomputeOverview builds a full-file view with the given width while loading,
+// available from Waveform.Overview, so UIs can paint immediately while detailed
+// views are computed on demand
+func OptionPrecomputeOverview(width int) LoadOption {
+	return func(c *loadConfig) {
+		c.overviewWidth = width
+	}
+}
+
+// OptionMaxSampleRate resamples audio above rate Hz down to rate right after decoding,
+// e.g. 48000 to cut the memory used by 96 or 192 kHz masters 2 to 4 times when they
+// are only visualized. The full-rate audio is still decoded first, so this lowers the
+// memory held once loading finishes, not its peak. Files that need resampling are
+// decoded fully even with OptionProgressive. 0 keeps every rate (default).
+func OptionMaxSampleRate(rate int) LoadOption {
+	return func(c *loadConfig) {
+		c.maxSampleRate = rate
+	}
+}
+
+func newLoadConfig(opts []LoadOption) loadConfig {
+	c := loadConfig{
+		maxChannels: DefaultMaxChannels,
+		maxSamples:  DefaultMaxSamples,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// finishLoad applies the load options that work on the decoded waveform
+func (w *Waveform) finishLoad(c loadConfig) error {
+	if c.overviewWidth < 0 {
+		return fmt.Errorf("invalid overview width: %d", c.overviewWidth)
+	}
+	if c.maxSampleRate < 0 {
+		return fmt.Errorf("invalid maximum sample rate: %d", c.maxSampleRate)
+	}
+	if c.maxSampleRate > 0 && w.SampleRate > c.maxSampleRate {
+		resampled, err := w.Resample(c.maxSampleRate)
+		if err != nil {
+			return fmt.Errorf("failed to resample audio: %w", err)
+		}
+		w.SampleRate = resampled.SampleRate
+		w.audioData = resampled.audioData
+		w.totalSamples = resampled.totalSamples
+	}
+	if c.overviewWidth > 0 {
+		overview, err := w.GenerateView(WaveformOptions{Width: c.overviewWidth})
+		if err != nil {
+			return fmt.Errorf("failed to precompute overview: %w", err)
+		}
+		w.overview, w.overviewWidth = overview, c.overviewWidth
+	}
+	w.track()
+	return nil
+}
+
+// Overview returns the full-file view built during load with OptionPrecomputeOverview,
+// or nil if none was requested. With OptionProgressive it is nil until loading finishes.
+func (w *Waveform) Overview() *WaveformData {
+	if w.Loading() {
+		return nil
+	}
+	return w.overview
+}
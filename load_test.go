@@ -0,0 +1,98 @@
+package gowaveform
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestOptionPrecomputeOverview(t *testing.T) {
+	tmpFile := "/tmp/test_overview.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile, OptionPrecomputeOverview(200))
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	overview := waveform.Overview()
+	if overview == nil {
+		t.Fatal("Expected a precomputed overview")
+	}
+
+	expected, err := waveform.GenerateView(WaveformOptions{Width: 200})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if !reflect.DeepEqual(overview, expected) {
+		t.Error("Expected overview to match a full-file view of the same width")
+	}
+
+	fsWaveform, err := LoadWaveformFS(os.DirFS("/tmp"), "test_overview.wav", OptionPrecomputeOverview(50))
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+	if fsWaveform.Overview() == nil || fsWaveform.Overview().Length != 50 {
+		t.Error("Expected LoadWaveformFS to precompute a 50 pixel overview")
+	}
+}
+
+func TestLoadWithoutOverview(t *testing.T) {
+	tmpFile := "/tmp/test_no_overview.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 0.5)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if waveform.Overview() != nil {
+		t.Error("Expected no overview when not requested")
+	}
+
+	if _, err := LoadWaveform(tmpFile, OptionPrecomputeOverview(-1)); err == nil {
+		t.Error("Expected error for negative overview width")
+	}
+}
+
+func TestOptionMaxSampleRate(t *testing.T) {
+	tmpFile := "/tmp/test_max_rate.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 96000, 1.0)
+
+	for _, opts := range [][]LoadOption{
+		{OptionMaxSampleRate(48000), OptionPrecomputeOverview(100)},
+		{OptionMaxSampleRate(48000), OptionPrecomputeOverview(100), OptionProgressive()},
+	} {
+		waveform, err := LoadWaveform(tmpFile, opts...)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+		if waveform.Loading() {
+			t.Error("Expected a resampled load to be finished")
+		}
+		if waveform.SampleRate != 48000 || waveform.totalSamples != 48000 || len(waveform.audioData) != 48000*waveform.Channels {
+			t.Errorf("Expected 48000 frames at 48 kHz, got %d at %d Hz", waveform.totalSamples, waveform.SampleRate)
+		}
+		if waveform.Overview() == nil || waveform.Overview().SampleRate != 48000 {
+			t.Error("Expected the overview to be built from the resampled audio")
+		}
+	}
+
+	// Rates at or below the limit are kept
+	waveform, err := LoadWaveform(tmpFile, OptionMaxSampleRate(96000))
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if waveform.SampleRate != 96000 {
+		t.Errorf("Expected 96 kHz to be kept, got %d Hz", waveform.SampleRate)
+	}
+
+	if _, err := LoadWaveform(tmpFile, OptionMaxSampleRate(-1)); err == nil {
+		t.Error("Expected error for a negative maximum sample rate")
+	}
+}
@@ -0,0 +1,106 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Limits of the FindLoop search; longer files and wider tolerances are subsampled
+const (
+	loopMatchWindow = 0.01 // Seconds compared on each side of the loop boundary
+	maxLoopStarts   = 1000 // Zero crossings tried as loop starts
+	maxLoopEnds     = 200  // Zero crossings tried as loop ends per start
+)
+
+// FindLoop searches for loop points about targetLength seconds apart (within ±tolerance)
+// that join seamlessly, e.g. to set up a sustain loop in a sampler. Candidates are rising
+// zero crossings of the mono mix, so the jump back has no step; each pair is scored by
+// cross-correlating the audio around the end with the audio around the start, scaled
+// so that differing levels lower the score. The score runs from 1 (the end continues
+// exactly like the start) to -1. Start and end are in seconds; the loop plays from start
+// up to, but not including, end.
+func FindLoop(w *Waveform, targetLength float64, tolerance float64) (start, end float64, score float64, err error) {
+	if targetLength <= 0 {
+		return 0, 0, 0, fmt.Errorf("loop length must be positive")
+	}
+	if tolerance < 0 {
+		return 0, 0, 0, fmt.Errorf("tolerance must not be negative")
+	}
+
+	frames := w.availableFrames()
+	minLength := max(1, int(math.Ceil((targetLength-tolerance)*float64(w.SampleRate))))
+	maxLength := int((targetLength + tolerance) * float64(w.SampleRate))
+	if minLength > maxLength || minLength >= frames {
+		return 0, 0, 0, fmt.Errorf("audio is too short for a %.3fs loop", targetLength)
+	}
+
+	mix := w.monoMix()
+	crossings := risingZeroCrossings(mix)
+	if len(crossings) < 2 {
+		return 0, 0, 0, fmt.Errorf("no zero crossings to loop between")
+	}
+
+	window := max(1, int(loopMatchWindow*float64(w.SampleRate)))
+	bestStart, bestEnd := -1, -1
+	bestScore := math.Inf(-1)
+
+	// Starts must leave room for the shortest loop before the last crossing
+	last := sort.SearchInts(crossings, crossings[len(crossings)-1]-minLength+1)
+	startStep := max(1, last/maxLoopStarts)
+	for i := 0; i < last; i += startStep {
+		s := crossings[i]
+		lo := sort.SearchInts(crossings, s+minLength)
+		hi := sort.SearchInts(crossings, s+maxLength+1)
+		endStep := max(1, (hi-lo)/maxLoopEnds)
+		for j := lo; j < hi; j += endStep {
+			e := crossings[j]
+			similarity := loopSimilarity(mix, s, e, window)
+			if similarity > bestScore || (similarity == bestScore && closerLength(e-s, bestEnd-bestStart, targetLength*float64(w.SampleRate))) {
+				bestStart, bestEnd, bestScore = s, e, similarity
+			}
+		}
+	}
+	if bestStart < 0 {
+		return 0, 0, 0, fmt.Errorf("no zero crossings %.3fs ±%.3fs apart", targetLength, tolerance)
+	}
+
+	rate := float64(w.SampleRate)
+	return float64(bestStart) / rate, float64(bestEnd) / rate, bestScore, nil
+}
+
+// risingZeroCrossings returns the frames at which the signal goes from negative to
+// zero or positive
+func risingZeroCrossings(x []float64) []int {
+	var crossings []int
+	for i := 1; i < len(x); i++ {
+		if x[i-1] < 0 && x[i] >= 0 {
+			crossings = append(crossings, i)
+		}
+	}
+	return crossings
+}
+
+// loopSimilarity compares window frames on each side of end with those around start,
+// as 2·Σab / (Σa² + Σb²): 1 for identical audio, lower for other shapes or levels.
+// Two silent windows match perfectly.
+func loopSimilarity(x []float64, start, end, window int) float64 {
+	before := min(window, start)
+	after := min(window, len(x)-end)
+	var dot, energy float64
+	for k := -before; k < after; k++ {
+		a, b := x[start+k], x[end+k]
+		dot += a * b
+		energy += a*a + b*b
+	}
+	if energy == 0 {
+		return 1
+	}
+	return 2 * dot / energy
+}
+
+// closerLength reports whether a loop of length frames is closer to target than one of
+// best frames
+func closerLength(length, best int, target float64) bool {
+	return math.Abs(float64(length)-target) < math.Abs(float64(best)-target)
+}
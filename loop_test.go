@@ -0,0 +1,88 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestFindLoop(t *testing.T) {
+	tmpFile := "/tmp/test_find_loop.wav"
+	defer os.Remove(tmpFile)
+
+	// A 100 Hz tone has a period of exactly 441 frames at 44100 Hz
+	writeTestWAV(t, tmpFile, 44100, 2, sineSamples(44100, 2, 100, 0.5, 2))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	start, end, score, err := FindLoop(waveform, 0.5, 0.02)
+	if err != nil {
+		t.Fatalf("FindLoop failed: %v", err)
+	}
+	if length := end - start; math.Abs(length-0.5) > 0.02 {
+		t.Errorf("Expected a loop of 0.5s ±0.02s, got %.4fs (%.4f to %.4f)", length, start, end)
+	}
+	periods := (end - start) * 100
+	if math.Abs(periods-math.Round(periods)) > 0.01 {
+		t.Errorf("Expected a whole number of periods, got %.3f", periods)
+	}
+	if score < 0.99 {
+		t.Errorf("Expected a near perfect score, got %.4f", score)
+	}
+}
+
+func TestFindLoopLevelChange(t *testing.T) {
+	tmpFile := "/tmp/test_find_loop_level.wav"
+	defer os.Remove(tmpFile)
+
+	// The same tone at full level for 1s, then at a quarter level for 1s: loops that
+	// cross the level change score lower than loops within either half
+	loud := sineSamples(44100, 1, 100, 0.8, 1)
+	quiet := sineSamples(44100, 1, 100, 0.2, 1)
+	writeTestWAV(t, tmpFile, 44100, 1, append(loud, quiet...))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	start, end, score, err := FindLoop(waveform, 0.5, 0)
+	if err != nil {
+		t.Fatalf("FindLoop failed: %v", err)
+	}
+	if (start < 1) != (end <= 1) {
+		t.Errorf("Expected the loop within one level, got %.4f to %.4f", start, end)
+	}
+	if score < 0.99 {
+		t.Errorf("Expected a near perfect score, got %.4f", score)
+	}
+}
+
+func TestFindLoopErrors(t *testing.T) {
+	tmpFile := "/tmp/test_find_loop_errors.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 100, 0.5, 1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	tests := []struct {
+		name              string
+		length, tolerance float64
+	}{
+		{"zero length", 0, 0.1},
+		{"negative tolerance", 0.5, -0.1},
+		{"longer than audio", 2, 0.1},
+	}
+	for _, tt := range tests {
+		if _, _, _, err := FindLoop(waveform, tt.length, tt.tolerance); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package gowaveform
+
+import "math"
+
+// Loudness gating as specified by ITU-R BS.1770-4
+const (
+	loudnessBlock         = 0.4   // Gating block length in seconds
+	loudnessStep          = 0.1   // Gating block step in seconds (75% overlap)
+	loudnessAbsoluteGate  = -70.0 // Blocks quieter than this in LUFS are ignored
+	loudnessRelativeGate  = -10.0 // Blocks this far below the ungated loudness are ignored
+	loudnessOffset        = -0.691
+	loudnessSurroundGain  = 1.41 // Channel weight of the surround channels of 5.1 audio
+	kWeightShelfFrequency = 1681.974450955533
+	kWeightShelfGain      = 3.999843853973347
+	kWeightShelfQ         = 0.7071752369554196
+	kWeightHighPass       = 38.13547087602444
+	kWeightHighPassQ      = 0.5003270373238773
+)
+
+// kWeightingFilters returns the two stages of the BS.1770 K-weighting filter
+// (a high shelf modelling the head and a high-pass), derived for any sample rate
+func kWeightingFilters(sampleRate int) (shelf, highPass *biquad) {
+	fs := float64(sampleRate)
+
+	k := math.Tan(math.Pi * kWeightShelfFrequency / fs)
+	vh := math.Pow(10, kWeightShelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	shelf = newBiquad(
+		vh+vb*k/kWeightShelfQ+k*k, 2*(k*k-vh), vh-vb*k/kWeightShelfQ+k*k,
+		1+k/kWeightShelfQ+k*k, 2*(k*k-1), 1-k/kWeightShelfQ+k*k,
+	)
+
+	// The high-pass keeps a unity numerator; only the feedback terms are normalized
+	k = math.Tan(math.Pi * kWeightHighPass / fs)
+	a0 := 1 + k/kWeightHighPassQ + k*k
+	highPass = newBiquad(a0, -2*a0, a0, a0, 2*(k*k-1), 1-k/kWeightHighPassQ+k*k)
+
+	return shelf, highPass
+}
+
+// loudnessChannelWeight returns the BS.1770 weight of a channel: the LFE of 5.1 audio
+// is excluded and its surround channels are boosted
+func loudnessChannelWeight(channel, channels int) float64 {
+	if channels == 6 {
+		return [6]float64{1, 1, 1, 0, loudnessSurroundGain, loudnessSurroundGain}[channel]
+	}
+	return 1
+}
+
+// IntegratedLoudness returns the integrated loudness of the whole file in LUFS, measured
+// as specified by ITU-R BS.1770-4 (K-weighting with absolute and relative gating).
+// It returns -Inf for silence or audio shorter than one 400 ms gating block.
+func (w *Waveform) IntegratedLoudness() float64 {
+	frames := w.availableFrames()
+	blockFrames := int(loudnessBlock * float64(w.SampleRate))
+	stepFrames := int(loudnessStep * float64(w.SampleRate))
+	if w.Channels == 0 || blockFrames == 0 || stepFrames == 0 || frames < blockFrames {
+		return math.Inf(-1)
+	}
+
+	// Sum the weighted, K-filtered energy of all channels per 100 ms step; every
+	// gating block is then four consecutive steps
+	steps := make([]float64, frames/stepFrames)
+	for ch := 0; ch < w.Channels; ch++ {
+		weight := loudnessChannelWeight(ch, w.Channels)
+		if weight == 0 {
+			continue
+		}
+		shelf, highPass := kWeightingFilters(w.SampleRate)
+		for frame := 0; frame < len(steps)*stepFrames; frame++ {
+			x := float64(w.audioData[frame*w.Channels+ch]) / 32768.0
+			y := highPass.process(shelf.process(x))
+			steps[frame/stepFrames] += weight * y * y
+		}
+	}
+
+	stepsPerBlock := blockFrames / stepFrames
+	var blocks []float64
+	for i := 0; i+stepsPerBlock <= len(steps); i++ {
+		var energy float64
+		for _, s := range steps[i : i+stepsPerBlock] {
+			energy += s
+		}
+		blocks = append(blocks, energy/float64(stepsPerBlock*stepFrames))
+	}
+
+	// Absolute gate, then a relative gate 10 LU below the loudness of the remaining blocks
+	gated := gateBlocks(blocks, loudnessAbsoluteGate)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+	relative := energyToLUFS(meanEnergy(gated)) + loudnessRelativeGate
+	gated = gateBlocks(gated, relative)
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+
+	return energyToLUFS(meanEnergy(gated))
+}
+
+// gateBlocks returns the blocks louder than the gate in LUFS
+func gateBlocks(blocks []float64, gate float64) []float64 {
+	var kept []float64
+	for _, b := range blocks {
+		if energyToLUFS(b) > gate {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+func meanEnergy(blocks []float64) float64 {
+	var sum float64
+	for _, b := range blocks {
+		sum += b
+	}
+	return sum / float64(len(blocks))
+}
+
+// energyToLUFS converts a weighted mean square to LUFS
+func energyToLUFS(energy float64) float64 {
+	if energy <= 0 {
+		return math.Inf(-1)
+	}
+	return loudnessOffset + 10*math.Log10(energy)
+}
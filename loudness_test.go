@@ -0,0 +1,87 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+// sineSamples returns interleaved samples of a sine at the given amplitude (1.0 = full scale)
+// on every channel
+func sineSamples(sampleRate, channels int, frequency, amplitude, seconds float64) []int16 {
+	frames := int(seconds * float64(sampleRate))
+	samples := make([]int16, frames*channels)
+	for i := 0; i < frames; i++ {
+		v := int16(math.Round(amplitude * 32767 * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate))))
+		for ch := 0; ch < channels; ch++ {
+			samples[i*channels+ch] = v
+		}
+	}
+	return samples
+}
+
+func TestIntegratedLoudness(t *testing.T) {
+	tmpFile := "/tmp/test_loudness.wav"
+	defer os.Remove(tmpFile)
+
+	// BS.1770: a 0 dBFS 1 kHz sine on one channel measures -3.01 LUFS; stereo adds 3 dB
+	tests := []struct {
+		sampleRate int
+		channels   int
+		amplitude  float64
+		expected   float64
+	}{
+		{48000, 1, 1.0, -3.01},
+		{48000, 1, 0.1, -23.01},
+		{44100, 1, 0.1, -23.01},
+		{44100, 2, 0.1, -20.0},
+	}
+
+	for _, tt := range tests {
+		samples := sineSamples(tt.sampleRate, tt.channels, 1000, tt.amplitude, 3)
+		writeTestWAV(t, tmpFile, uint32(tt.sampleRate), uint16(tt.channels), samples)
+
+		waveform, err := LoadWaveform(tmpFile)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+		if got := waveform.IntegratedLoudness(); math.Abs(got-tt.expected) > 0.1 {
+			t.Errorf("%d Hz, %d channels, amplitude %g: expected %.2f LUFS, got %.2f", tt.sampleRate, tt.channels, tt.amplitude, tt.expected, got)
+		}
+	}
+}
+
+func TestIntegratedLoudnessGating(t *testing.T) {
+	tmpFile := "/tmp/test_loudness_gating.wav"
+	defer os.Remove(tmpFile)
+
+	// Silence is gated out, so adding it barely lowers the loudness of the tone. Without
+	// gating, 2s of tone in 6s would measure 4.8 dB lower; the blocks overlapping the
+	// end of the tone pass the gates and account for the small difference.
+	samples := append(sineSamples(48000, 1, 1000, 0.1, 2), make([]int16, 48000*4)...)
+	writeTestWAV(t, tmpFile, 48000, 1, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if got := waveform.IntegratedLoudness(); math.Abs(got+23.01) > 0.5 {
+		t.Errorf("Expected silence to be gated out (-23.01 LUFS), got %.2f", got)
+	}
+}
+
+func TestIntegratedLoudnessSilence(t *testing.T) {
+	tmpFile := "/tmp/test_loudness_silence.wav"
+	defer os.Remove(tmpFile)
+
+	for _, samples := range [][]int16{make([]int16, 48000), sineSamples(48000, 1, 1000, 0.5, 0.2)} {
+		writeTestWAV(t, tmpFile, 48000, 1, samples)
+		waveform, err := LoadWaveform(tmpFile)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+		if got := waveform.IntegratedLoudness(); !math.IsInf(got, -1) {
+			t.Errorf("Expected -Inf for silence or audio shorter than a block, got %.2f", got)
+		}
+	}
+}
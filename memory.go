@@ -0,0 +1,50 @@
+package gowaveform
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// liveMemory is the number of bytes counted by track for the waveforms the garbage
+// collector has not freed yet
+var liveMemory atomic.Int64
+
+// MemoryUsage returns the number of bytes held by the samples and the precomputed
+// overview of the waveform, which make up nearly all of its memory. A file is held
+// with 2 bytes per sample and channel, whatever its bit depth; OptionLoadMono and
+// OptionMaxSampleRate reduce it. During a progressive load the whole sample buffer is
+// counted from the start.
+func (w *Waveform) MemoryUsage() int64 {
+	n := int64(cap(w.audioData)) * 2
+	if o := w.overview; o != nil {
+		n += int64(cap(o.Data)) * 2
+		for _, band := range o.Bands {
+			n += int64(cap(band.Data)) * 2
+		}
+	}
+	return n
+}
+
+// LiveMemoryUsage returns the total MemoryUsage of the waveforms this package has
+// loaded or created that the garbage collector has not freed yet, e.g. to keep a server
+// within a memory budget. Freed waveforms are subtracted once the garbage collector
+// has run, so the total lags behind dropped references. Waveforms are counted from
+// every load function and every function or method returning a new *Waveform.
+func LiveMemoryUsage() int64 {
+	return liveMemory.Load()
+}
+
+// track counts the current MemoryUsage of w in LiveMemoryUsage until w is garbage
+// collected and returns w. It is called again whenever the samples or the overview of
+// w are replaced.
+func (w *Waveform) track() *Waveform {
+	if w.memory == nil {
+		w.memory = new(atomic.Int64)
+		runtime.AddCleanup(w, func(memory *atomic.Int64) {
+			liveMemory.Add(-memory.Load())
+		}, w.memory)
+	}
+	n := w.MemoryUsage()
+	liveMemory.Add(n - w.memory.Swap(n))
+	return w
+}
@@ -0,0 +1,58 @@
+package gowaveform
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMemoryUsage(t *testing.T) {
+	w := toneWaveform(44100, 2, 440, 0.5, 1)
+	if got := w.MemoryUsage(); got != 44100*2*2 {
+		t.Errorf("Expected %d bytes of samples, got %d", 44100*2*2, got)
+	}
+
+	overview, err := w.GenerateView(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	w.overview = overview
+	if got, expected := w.MemoryUsage(), int64(44100*2*2+cap(overview.Data)*2); got != expected {
+		t.Errorf("Expected %d bytes with the overview, got %d", expected, got)
+	}
+}
+
+func TestLiveMemoryUsage(t *testing.T) {
+	mono := toneWaveform(44100, 2, 440, 0.5, 1).MixToMono()
+	if mono.memory == nil || mono.memory.Load() != mono.MemoryUsage() {
+		t.Fatal("Expected a new waveform to be counted")
+	}
+
+	// Replacing the overview is counted as well
+	overview, err := mono.GenerateView(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	mono.overview = overview
+	mono.Gain(-6)
+	if got := mono.memory.Load(); got != mono.MemoryUsage() {
+		t.Errorf("Expected %d bytes counted after an edit, got %d", mono.MemoryUsage(), got)
+	}
+
+	// The bytes are released once the waveform is garbage collected; waveforms of
+	// earlier tests may be released meanwhile, so only an upper bound is checked
+	size := mono.MemoryUsage()
+	counted := LiveMemoryUsage()
+	if counted < size {
+		t.Fatalf("Expected at least %d live bytes, got %d", size, counted)
+	}
+	mono = nil
+	for range 50 {
+		runtime.GC()
+		if LiveMemoryUsage() <= counted-size {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("Expected at most %d live bytes, got %d", counted-size, LiveMemoryUsage())
+}
@@ -0,0 +1,72 @@
+package gowaveform
+
+import (
+	"runtime/debug"
+	"time"
+)
+
+// modulePath is the import path used to look up the library version in the build info
+const modulePath = "github.com/schollz/gowaveform"
+
+// Metadata records where a waveform view came from, so downstream caches can
+// validate provenance. It is only added when WaveformOptions.Metadata is set.
+type Metadata struct {
+	Source    string          `json:"source,omitempty"` // File the audio was loaded from (empty if unknown)
+	Duration  float64         `json:"duration"`         // Duration of the source audio in seconds
+	Generated time.Time       `json:"generated"`        // Time the view was generated (UTC)
+	Tool      string          `json:"tool"`             // Generator name and version (e.g. "gowaveform v1.2.0")
+	Options   MetadataOptions `json:"options"`          // Options the view was generated with
+}
+
+// MetadataOptions mirrors the WaveformOptions that produced a view
+type MetadataOptions struct {
+	Start           float64   `json:"start"`
+	End             float64   `json:"end"`
+	SamplesPerPixel int       `json:"samples_per_pixel"`
+	Width           int       `json:"width"`
+	Bands           []float64 `json:"bands,omitempty"`
+	Symmetric       bool      `json:"symmetric,omitempty"`
+	TimeAxis        bool      `json:"time_axis,omitempty"`
+	Filter          *Filter   `json:"filter,omitempty"`
+}
+
+// newMetadata builds the metadata block for a view generated with opts
+func (w *Waveform) newMetadata(opts WaveformOptions) *Metadata {
+	return &Metadata{
+		Source:    w.source,
+		Duration:  w.Duration(),
+		Generated: time.Now().UTC(),
+		Tool:      "gowaveform " + moduleVersion(),
+		Options: MetadataOptions{
+			Start:           opts.Start,
+			End:             opts.End,
+			SamplesPerPixel: opts.SamplesPerPixel,
+			Width:           opts.Width,
+			Bands:           opts.Bands,
+			Symmetric:       opts.Symmetric,
+			TimeAxis:        opts.TimeAxis,
+			Filter:          opts.Filter,
+		},
+	}
+}
+
+// moduleVersion returns the version of this module from the build info,
+// or "devel" when built from a local checkout
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+	if info.Main.Path == modulePath && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == modulePath {
+			if dep.Replace != nil {
+				return "devel"
+			}
+			return dep.Version
+		}
+	}
+	return "devel"
+}
@@ -0,0 +1,94 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetadata(t *testing.T) {
+	tmpFile := "/tmp/test_metadata.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	before := time.Now().UTC().Add(-time.Second)
+	data, err := waveform.GenerateView(WaveformOptions{Start: 0.5, Width: 100, Metadata: true})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	meta := data.Metadata
+	if meta == nil {
+		t.Fatal("Expected metadata to be present")
+	}
+	if meta.Source != tmpFile {
+		t.Errorf("Expected source %s, got %s", tmpFile, meta.Source)
+	}
+	if meta.Duration < 1.99 || meta.Duration > 2.01 {
+		t.Errorf("Expected duration ~2.0, got %f", meta.Duration)
+	}
+	if meta.Generated.Before(before) {
+		t.Errorf("Unexpected generation time %v", meta.Generated)
+	}
+	if !strings.HasPrefix(meta.Tool, "gowaveform ") {
+		t.Errorf("Unexpected tool %q", meta.Tool)
+	}
+	if meta.Options.Start != 0.5 || meta.Options.Width != 100 {
+		t.Errorf("Unexpected options %+v", meta.Options)
+	}
+
+	jsonData, err := GenerateJSON(data)
+	if err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+	var decoded WaveformData
+	if err := json.Unmarshal(jsonData, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	if decoded.Metadata == nil || decoded.Metadata.Source != tmpFile {
+		t.Error("Expected metadata to round-trip through JSON")
+	}
+}
+
+func TestMetadataDisabled(t *testing.T) {
+	tmpFile := "/tmp/test_metadata_disabled.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	jsonData, err := GenerateWaveformJSON(tmpFile, WaveformOptions{Width: 10})
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+	if bytes.Contains(jsonData, []byte("metadata")) {
+		t.Error("Expected no metadata block when disabled")
+	}
+}
+
+func TestMetadataFS(t *testing.T) {
+	tmpFile := "/tmp/test_metadata_fs.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 0.5)
+
+	waveform, err := LoadWaveformFS(os.DirFS("/tmp"), "test_metadata_fs.wav")
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+	data, err := waveform.GenerateView(WaveformOptions{Width: 10, Metadata: true})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if data.Metadata.Source != "test_metadata_fs.wav" {
+		t.Errorf("Expected source to be the name within the file system, got %s", data.Metadata.Source)
+	}
+}
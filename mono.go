@@ -0,0 +1,40 @@
+package gowaveform
+
+// OptionLoadMono mixes the channels down to one while loading when mono is true, so a
+// multichannel recording such as an 8-channel field recording holds one channel of
+// samples instead of eight when only a combined waveform is wanted. The channels are
+// averaged the same way as MixToMono. Load limits still apply to the channels of the
+// file.
+func OptionLoadMono(mono bool) LoadOption {
+	return func(c *loadConfig) {
+		c.mono = mono
+	}
+}
+
+// channelMixer averages the channels of interleaved samples into one, keeping the
+// sum of a frame split between calls
+type channelMixer struct {
+	channels int
+	sum      int
+}
+
+// newChannelMixer returns a mixer for audio with the given channels, or nil when the
+// channels are kept as they are
+func newChannelMixer(mono bool, channels int) *channelMixer {
+	if !mono || channels <= 1 {
+		return nil
+	}
+	return &channelMixer{channels: channels}
+}
+
+// mix adds the sample of channel ch to the current frame and returns the mixed sample
+// once the last channel of the frame is added
+func (m *channelMixer) mix(ch int, sample int16) (int16, bool) {
+	m.sum += int(sample)
+	if ch < m.channels-1 {
+		return 0, false
+	}
+	mixed := int16(m.sum / m.channels)
+	m.sum = 0
+	return mixed, true
+}
@@ -0,0 +1,75 @@
+package gowaveform
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestOptionLoadMono(t *testing.T) {
+	tmpFile := "/tmp/test_load_mono.wav"
+	defer os.Remove(tmpFile)
+
+	// Four channels over more than one progressive decode chunk
+	const channels, frames = 4, progressiveChunkFrames + 1000
+	samples := make([]int16, channels*frames)
+	for i := range samples {
+		samples[i] = int16((i/channels)%200*(i%channels+1) - 300)
+	}
+	writeTestWAV(t, tmpFile, 44100, channels, samples)
+
+	full, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	expected := full.MixToMono()
+
+	for _, opts := range [][]LoadOption{
+		{OptionLoadMono(true)},
+		{OptionLoadMono(true), OptionProgressive()},
+	} {
+		waveform, err := LoadWaveform(tmpFile, opts...)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+		if err := waveform.Wait(); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+		if waveform.Channels != 1 || waveform.totalSamples != frames || len(waveform.audioData) != frames {
+			t.Errorf("Expected %d mono frames, got %d frames of %d channels", frames, waveform.totalSamples, waveform.Channels)
+		}
+		if !slices.Equal(waveform.audioData, expected.audioData) {
+			t.Error("Expected the same samples as MixToMono")
+		}
+	}
+
+	fsWaveform, err := LoadWaveformFS(os.DirFS("/tmp"), "test_load_mono.wav", OptionLoadMono(true))
+	if err != nil {
+		t.Fatalf("LoadWaveformFS failed: %v", err)
+	}
+	if fsWaveform.Channels != 1 || !slices.Equal(fsWaveform.audioData, expected.audioData) {
+		t.Error("Expected LoadWaveformFS to mix down to the same samples")
+	}
+
+	if kept, err := LoadWaveform(tmpFile, OptionLoadMono(false)); err != nil || kept.Channels != channels {
+		t.Errorf("Expected OptionLoadMono(false) to keep %d channels", channels)
+	}
+}
+
+func TestChannelMixer(t *testing.T) {
+	if newChannelMixer(false, 2) != nil || newChannelMixer(true, 1) != nil {
+		t.Error("Expected no mixer when the channels are kept")
+	}
+
+	m := newChannelMixer(true, 3)
+	var mixed []int16
+	for i, v := range []int16{3, 4, 5, -1, -1, -3} {
+		if out, ok := m.mix(i%3, v); ok {
+			mixed = append(mixed, out)
+		}
+	}
+	// Averages round toward zero like MixToMono
+	if expected := []int16{4, -1}; !slices.Equal(mixed, expected) {
+		t.Errorf("Expected %v, got %v", expected, mixed)
+	}
+}
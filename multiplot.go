@@ -0,0 +1,103 @@
+package gowaveform
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// DefaultMultiPlotPanelHeight is the height in pixels of each panel of SaveMultiPlot
+// when no height is set
+const DefaultMultiPlotPanelHeight = 150
+
+// SaveMultiPlot saves waveforms stacked as panels sharing one time axis to an image
+// file, e.g. to show the stems of a track together. The panels are aligned so a time
+// is at the same x position in each, and only the bottom one has time labels. The
+// time range defaults to the longest waveform; shorter ones end early in their panel.
+// The options apply to every panel, except that the title is only drawn above the top
+// one and the height is that of the whole image (150 pixels per panel by default).
+// Each panel's amplitude axis is labeled with the name of its file. The full gonum/plot
+// pipeline is always used, whatever the renderer options.
+func SaveMultiPlot(ws []*Waveform, filename string, opts ...Option) error {
+	if len(ws) == 0 {
+		return fmt.Errorf("no waveforms to plot")
+	}
+	longest := ws[0]
+	for i, w := range ws {
+		if w == nil {
+			return fmt.Errorf("waveform %d is nil", i)
+		}
+		if w.Duration() > longest.Duration() {
+			longest = w
+		}
+	}
+
+	// A height for all panels goes first so the caller's options override it
+	config, err := newPlotConfig(longest, append([]Option{
+		OptionSetHeight(DefaultMultiPlotPanelHeight * len(ws)),
+	}, opts...))
+	if err != nil {
+		return err
+	}
+
+	plots := make([][]*plot.Plot, len(ws))
+	for i, w := range ws {
+		panel := config
+		if i > 0 {
+			panel.title = ""
+		}
+		if i < len(ws)-1 {
+			panel.hideXAxis = true
+		}
+
+		p, err := multiPlotPanel(w, panel)
+		if err != nil {
+			return fmt.Errorf("failed to plot waveform %d: %w", i, err)
+		}
+		plots[i] = []*plot.Plot{p}
+	}
+
+	c := config.newCanvas()
+	canvases := plot.Align(plots, draw.Tiles{Rows: len(ws), Cols: 1}, draw.New(c))
+	for i := range plots {
+		plots[i][0].Draw(canvases[i][0])
+	}
+	return saveImage(c.Image(), config, filename)
+}
+
+// multiPlotPanel builds the plot of one waveform over the shared time range of a
+// multi-panel plot
+func multiPlotPanel(w *Waveform, config PlotConfig) (*plot.Plot, error) {
+	// Generate as many columns per second as the other panels, over the part of the
+	// range this waveform covers, and compute the overlays for the same part. Markers,
+	// regions and the time axis still span the whole range.
+	source, rangeEnd := w, config.end
+	end := min(config.end, w.Duration())
+	data := &WaveformData{Length: 1, Data: []int16{0, 0}, SampleRate: w.SampleRate, SamplesPerPixel: 1, Start: config.start}
+	if config.start < end {
+		config.width = max(1, int(float64(config.width)*(end-config.start)/(rangeEnd-config.start)+0.5))
+		config.end = end
+
+		var err error
+		if data, err = plotWaveformData(w, config); err != nil {
+			return nil, err
+		}
+	} else {
+		source = nil // Past its end the panel stays silent
+	}
+	data.End = rangeEnd
+
+	p, err := plotView(source, data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.hideYAxis && !config.sparkline && w.source != "" {
+		name := filepath.Base(w.source)
+		p.Y.Label.Text = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return p, nil
+}
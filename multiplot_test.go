@@ -0,0 +1,81 @@
+package gowaveform
+
+import (
+	"image/png"
+	"os"
+	"testing"
+)
+
+func TestSaveMultiPlot(t *testing.T) {
+	tmpLong := "/tmp/test_multiplot_long.wav"
+	tmpShort := "/tmp/test_multiplot_short.wav"
+	tmpPlot := "/tmp/test_multiplot.png"
+	defer os.Remove(tmpLong)
+	defer os.Remove(tmpShort)
+	defer os.Remove(tmpPlot)
+
+	createToneWAV(t, tmpLong, 44100, 2.0, 220)
+	createToneWAV(t, tmpShort, 44100, 1.0, 220)
+
+	long, err := LoadWaveform(tmpLong)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	short, err := LoadWaveform(tmpShort)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SaveMultiPlot([]*Waveform{long, short}, tmpPlot,
+		OptionSetWidth(400),
+		OptionSetForegroundColor("#000000"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SaveMultiPlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// Two panels of the default height, one above the other
+	bounds := img.Bounds()
+	if bounds.Dx() != 400 || bounds.Dy() != 2*DefaultMultiPlotPanelHeight {
+		t.Fatalf("Expected a 400x%d image, got %dx%d", 2*DefaultMultiPlotPanelHeight, bounds.Dx(), bounds.Dy())
+	}
+
+	// Both files are drawn in the first half; the shorter one ends halfway along the
+	// shared time axis
+	dark := func(x, y int) bool {
+		r, _, _, _ := img.At(x, y).RGBA()
+		return r>>8 < 128
+	}
+	top, bottom := DefaultMultiPlotPanelHeight/2, DefaultMultiPlotPanelHeight*3/2
+	if !dark(100, top) || !dark(100, bottom) {
+		t.Error("Expected both waveforms at a quarter of the width")
+	}
+	if !dark(300, top) {
+		t.Error("Expected the longer waveform at three quarters of the width")
+	}
+	if dark(300, bottom) {
+		t.Error("Expected the shorter waveform to end halfway")
+	}
+}
+
+func TestSaveMultiPlotErrors(t *testing.T) {
+	if err := SaveMultiPlot(nil, "/tmp/test_multiplot_error.png"); err == nil {
+		t.Error("Expected an error without waveforms")
+	}
+	if err := SaveMultiPlot([]*Waveform{nil}, "/tmp/test_multiplot_error.png"); err == nil {
+		t.Error("Expected an error for a nil waveform")
+	}
+}
@@ -0,0 +1,68 @@
+package gowaveform
+
+import "math"
+
+// NormalizePeak returns a copy of the waveform scaled so its highest sample peak of
+// any channel reaches targetDBFS, e.g. to level-match the slices of a chopped kit.
+// Silent audio is returned unchanged. The waveform itself is not changed.
+func (w *Waveform) NormalizePeak(targetDBFS float64) *Waveform {
+	var peak float64
+	for _, v := range w.audioData[:w.availableFrames()*w.Channels] {
+		peak = math.Max(peak, math.Abs(float64(v))/32768.0)
+	}
+	if peak == 0 {
+		return w.withGain(1)
+	}
+	return w.withGain(math.Pow(10, targetDBFS/20) / peak)
+}
+
+// NormalizeLoudness returns a copy of the waveform scaled to targetLUFS. Audio of at
+// least one 400 ms gating block is measured by its integrated loudness; shorter audio,
+// such as a drum hit, by its ungated K-weighted loudness, as BS.1770 does within one
+// block. Peaks pushed beyond full scale are clipped, so follow with NormalizePeak or
+// check TruePeak for loud targets. Silent audio is returned unchanged. The waveform
+// itself is not changed.
+func (w *Waveform) NormalizeLoudness(targetLUFS float64) *Waveform {
+	loudness := w.IntegratedLoudness()
+	if math.IsInf(loudness, -1) && w.availableFrames() < int(loudnessBlock*float64(w.SampleRate)) {
+		loudness = w.ungatedLoudness()
+	}
+	if math.IsInf(loudness, -1) {
+		return w.withGain(1)
+	}
+	return w.withGain(math.Pow(10, (targetLUFS-loudness)/20))
+}
+
+// ungatedLoudness returns the K-weighted loudness in LUFS of the whole audio without
+// gating, or -Inf for silence
+func (w *Waveform) ungatedLoudness() float64 {
+	frames := w.availableFrames()
+	if w.Channels == 0 || frames == 0 {
+		return math.Inf(-1)
+	}
+	var energy float64
+	for ch := 0; ch < w.Channels; ch++ {
+		weight := loudnessChannelWeight(ch, w.Channels)
+		if weight == 0 {
+			continue
+		}
+		shelf, highPass := kWeightingFilters(w.SampleRate)
+		for frame := range frames {
+			x := float64(w.audioData[frame*w.Channels+ch]) / 32768.0
+			y := highPass.process(shelf.process(x))
+			energy += weight * y * y
+		}
+	}
+	return energyToLUFS(energy / float64(frames))
+}
+
+// withGain returns a copy of the waveform with every sample scaled by gain, clipped
+// to full scale
+func (w *Waveform) withGain(gain float64) *Waveform {
+	frames := w.availableFrames()
+	audio := make([]int16, frames*w.Channels)
+	for i, v := range w.audioData[:len(audio)] {
+		audio[i] = clampSample(float64(v) * gain)
+	}
+	return w.withAudio(w.SampleRate, audio, frames)
+}
@@ -0,0 +1,58 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizePeak(t *testing.T) {
+	w := toneWaveform(44100, 2, 440, 0.25, 0.5)
+	normalized := w.NormalizePeak(ToDBFS(0.5))
+
+	levels, err := normalized.ChannelLevels(0, 0)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	for ch, l := range levels {
+		if math.Abs(l.Peak-0.5) > 0.001 {
+			t.Errorf("Channel %d: expected a peak of 0.5, got %.4f", ch, l.Peak)
+		}
+	}
+	if original, _ := w.ChannelLevels(0, 0); math.Abs(original[0].Peak-0.25) > 0.001 {
+		t.Error("Expected the original waveform to be unchanged")
+	}
+
+	silent := &Waveform{SampleRate: 44100, Channels: 1, BitsPerSample: 16, audioData: make([]int16, 100), totalSamples: 100}
+	if s := silent.NormalizePeak(0); s.totalSamples != 100 || s.audioData[0] != 0 {
+		t.Error("Expected silence to stay silent")
+	}
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	// Long enough for integrated loudness
+	normalized := toneWaveform(48000, 2, 997, 0.05, 2).NormalizeLoudness(-14)
+	if got := normalized.IntegratedLoudness(); math.Abs(got+14) > 0.1 {
+		t.Errorf("Expected -14 LUFS, got %.2f", got)
+	}
+
+	// A 200 ms hit is measured without gating; a full-scale sine reads -3 LUFS in mono
+	hit := toneWaveform(48000, 1, 997, 0.1, 0.2)
+	normalized = hit.NormalizeLoudness(-9.02)
+	if got := normalized.ungatedLoudness(); math.Abs(got+9.02) > 0.2 {
+		t.Errorf("Expected -9.02 LUFS, got %.2f", got)
+	}
+	if levels, _ := normalized.ChannelLevels(0, 0); math.Abs(levels[0].Peak-0.5) > 0.02 {
+		t.Errorf("Expected a peak of about 0.5, got %.4f", levels[0].Peak)
+	}
+
+	// Targets beyond full scale clip instead of wrapping around
+	loud := hit.NormalizeLoudness(10)
+	if levels, _ := loud.ChannelLevels(0, 0); levels[0].Peak < 0.99 {
+		t.Errorf("Expected clipping at full scale, got a peak of %.4f", levels[0].Peak)
+	}
+	for i, v := range loud.audioData {
+		if (v > 0) != (hit.audioData[i] > 0) && hit.audioData[i] != 0 {
+			t.Fatalf("Sample %d changed sign: %d from %d", i, v, hit.audioData[i])
+		}
+	}
+}
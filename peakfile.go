@@ -0,0 +1,307 @@
+package gowaveform
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// binaryFlag8Bit marks 8-bit peaks in the flags field of the audiowaveform binary format
+const binaryFlag8Bit = 1
+
+// IsBinaryPeakFile reports whether a filename selects the audiowaveform binary format (.dat)
+func IsBinaryPeakFile(filename string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".dat"
+}
+
+// LoadWaveformData reads peaks saved by gowaveform or audiowaveform, in the binary format
+// for .dat files and as JSON otherwise. JSON may be gzip or zstd compressed.
+func LoadWaveformData(filename string) (*WaveformData, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open peak file: %w", err)
+	}
+	defer f.Close()
+
+	if IsBinaryPeakFile(filename) {
+		return ReadBinary(f)
+	}
+	return ReadJSON(f)
+}
+
+// ReadJSON reads peaks in the JSON format written by GenerateJSON or audiowaveform,
+// decompressing gzip or zstd input. Peaks written with WaveformOptions.Float are not supported.
+// Unknown fields are ignored; ReadJSONStrict rejects them and other schema violations.
+func ReadJSON(r io.Reader) (*WaveformData, error) {
+	zr, err := NewDecompressReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var data WaveformData
+	if err := json.NewDecoder(zr).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to read JSON peaks: %w", err)
+	}
+	if err := data.validate(); err != nil {
+		return nil, err
+	}
+	data.restoreRange()
+	return &data, nil
+}
+
+// binaryHeader is the header of the audiowaveform binary format, version 1 and 2.
+// Version 1 ends before Channels.
+type binaryHeader struct {
+	Version         int32
+	Flags           uint32
+	SampleRate      int32
+	SamplesPerPixel int32
+	Length          uint32
+	Channels        int32
+}
+
+// binaryReadChunk is the number of peak values ReadBinary reads at a time
+const binaryReadChunk = 1 << 16
+
+// validate rejects headers that describe no peaks or more than DefaultMaxSamples peak
+// values, so a corrupt or hostile file cannot request a huge allocation
+func (h binaryHeader) validate() error {
+	switch {
+	case h.Channels <= 0 || h.Channels > DefaultMaxChannels:
+		return fmt.Errorf("invalid channel count in binary peaks: %d", h.Channels)
+	case h.SampleRate <= 0:
+		return fmt.Errorf("invalid sample rate in binary peaks: %d", h.SampleRate)
+	case h.SamplesPerPixel <= 0:
+		return fmt.Errorf("invalid samples per pixel in binary peaks: %d", h.SamplesPerPixel)
+	case h.Length == 0 || int64(h.Length)*int64(h.Channels)*2 > DefaultMaxSamples:
+		return fmt.Errorf("invalid length in binary peaks: %d pixels of %d channels", h.Length, h.Channels)
+	}
+	return nil
+}
+
+// ReadBinary reads peaks in the audiowaveform binary format. 8-bit peaks are scaled to
+// the 16-bit range used by WaveformData. Headers with no peaks, a non-positive sample
+// rate or samples per pixel, more than DefaultMaxChannels channels or more than
+// DefaultMaxSamples peak values are rejected, as are files shorter than their header
+// declares.
+func ReadBinary(r io.Reader) (*WaveformData, error) {
+	var h binaryHeader
+	for _, field := range []interface{}{&h.Version, &h.Flags, &h.SampleRate, &h.SamplesPerPixel, &h.Length} {
+		if err := binary.Read(r, binary.LittleEndian, field); err != nil {
+			return nil, fmt.Errorf("failed to read binary peak header: %w", err)
+		}
+	}
+
+	switch h.Version {
+	case 1:
+		h.Channels = 1
+	case 2:
+		if err := binary.Read(r, binary.LittleEndian, &h.Channels); err != nil {
+			return nil, fmt.Errorf("failed to read binary peak header: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported binary peak version: %d (supported: 1, 2)", h.Version)
+	}
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+
+	data := &WaveformData{
+		Version:         int(h.Version),
+		SampleRate:      int(h.SampleRate),
+		SamplesPerPixel: int(h.SamplesPerPixel),
+		Bits:            16,
+		Length:          int(h.Length),
+	}
+	if h.Version == 2 {
+		data.Channels = int(h.Channels)
+	}
+	if h.Flags&binaryFlag8Bit != 0 {
+		data.Bits = 8
+	}
+
+	// The header cannot be trusted, so peaks are read in chunks and a truncated file
+	// fails before the declared size is allocated
+	total := int(h.Length) * int(h.Channels) * 2
+	data.Data = make([]int16, 0, min(total, binaryReadChunk))
+	chunk8 := make([]int8, min(total, binaryReadChunk))
+	chunk16 := make([]int16, min(total, binaryReadChunk))
+	for len(data.Data) < total {
+		n := min(total-len(data.Data), binaryReadChunk)
+		if data.Bits == 8 {
+			if err := binary.Read(r, binary.LittleEndian, chunk8[:n]); err != nil {
+				return nil, fmt.Errorf("failed to read binary peaks: %w", err)
+			}
+			for _, v := range chunk8[:n] {
+				data.Data = append(data.Data, int16(v)<<8)
+			}
+		} else {
+			if err := binary.Read(r, binary.LittleEndian, chunk16[:n]); err != nil {
+				return nil, fmt.Errorf("failed to read binary peaks: %w", err)
+			}
+			data.Data = append(data.Data, chunk16[:n]...)
+		}
+	}
+
+	data.restoreRange()
+	return data, nil
+}
+
+// WriteBinary writes peaks in the audiowaveform binary format with 16-bit resolution.
+// The format has no room for band peaks or metadata, which are left out. The channel
+// count written is that of the peak data, which holds one min/max pair per pixel for
+// views generated by gowaveform.
+func WriteBinary(w io.Writer, data *WaveformData) error {
+	if data == nil {
+		return fmt.Errorf("failed to write binary peaks: no waveform data")
+	}
+	if err := data.validate(); err != nil {
+		return err
+	}
+
+	h := binaryHeader{
+		Version:         int32(data.Version),
+		SampleRate:      int32(data.SampleRate),
+		SamplesPerPixel: int32(data.SamplesPerPixel),
+		Length:          uint32(data.Length),
+		Channels:        int32(data.peakChannels()),
+	}
+	if h.Version == 0 {
+		h.Version = 2
+	}
+	if h.Version == 1 && h.Channels != 1 {
+		return fmt.Errorf("binary peak version 1 holds a single channel, data has %d", h.Channels)
+	}
+
+	fields := []interface{}{h.Version, h.Flags, h.SampleRate, h.SamplesPerPixel, h.Length}
+	if h.Version == 2 {
+		fields = append(fields, h.Channels)
+	}
+	for _, field := range fields {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("failed to write binary peaks: %w", err)
+		}
+	}
+
+	// Encode the peaks into a pooled buffer rather than letting binary.Write allocate one
+	buf := getBytes()
+	defer bytePool.Put(buf)
+	for _, v := range data.Data {
+		*buf = binary.LittleEndian.AppendUint16(*buf, uint16(v))
+	}
+	if _, err := w.Write(*buf); err != nil {
+		return fmt.Errorf("failed to write binary peaks: %w", err)
+	}
+	return nil
+}
+
+// Rescale merges whole pixels into a coarser view with the given samples per pixel, which
+// must be a multiple of the current value, so peaks can be resized without the audio.
+// Band peaks are merged the same way and the time axis keeps the time of every merged
+// pixel's first source pixel; metadata is kept unchanged.
+func (d *WaveformData) Rescale(samplesPerPixel int) (*WaveformData, error) {
+	if d.SamplesPerPixel <= 0 || samplesPerPixel <= 0 || samplesPerPixel%d.SamplesPerPixel != 0 {
+		return nil, fmt.Errorf("cannot rescale from %d to %d samples per pixel: must be a multiple", d.SamplesPerPixel, samplesPerPixel)
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	factor := samplesPerPixel / d.SamplesPerPixel
+	channels := d.peakChannels()
+
+	out := *d
+	out.SamplesPerPixel = samplesPerPixel
+	out.Length = (d.Length + factor - 1) / factor
+	out.Data = mergePixels(d.Data, channels, factor)
+	if len(d.Bands) > 0 {
+		out.Bands = make([]BandData, len(d.Bands))
+		for i, band := range d.Bands {
+			out.Bands[i] = band
+			out.Bands[i].Data = mergePixels(band.Data, pairsPerPixel(band.Data, d.Length), factor)
+		}
+	}
+	if d.TimeAxis != nil {
+		axis := *d.TimeAxis
+		axis.Times = make([]float64, 0, out.Length)
+		for i := 0; i < len(d.TimeAxis.Times); i += factor {
+			axis.Times = append(axis.Times, d.TimeAxis.Times[i])
+		}
+		out.TimeAxis = &axis
+	}
+
+	return &out, nil
+}
+
+// RescaleToWidth rescales to at most width pixels with the smallest whole merge factor
+func (d *WaveformData) RescaleToWidth(width int) (*WaveformData, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid width: %d", width)
+	}
+	factor := (d.Length + width - 1) / width
+	if factor < 1 {
+		factor = 1
+	}
+	return d.Rescale(d.SamplesPerPixel * factor)
+}
+
+// mergePixels combines every factor pixels of interleaved per-channel min/max pairs into one
+func mergePixels(peaks []int16, channels, factor int) []int16 {
+	stride := channels * 2
+	pixels := len(peaks) / stride
+	out := make([]int16, 0, (pixels+factor-1)/factor*stride)
+
+	for first := 0; first < pixels; first += factor {
+		last := min(first+factor, pixels)
+		for ch := 0; ch < channels; ch++ {
+			lo, hi := peaks[first*stride+ch*2], peaks[first*stride+ch*2+1]
+			for p := first + 1; p < last; p++ {
+				lo = min(lo, peaks[p*stride+ch*2])
+				hi = max(hi, peaks[p*stride+ch*2+1])
+			}
+			out = append(out, lo, hi)
+		}
+	}
+	return out
+}
+
+// peakChannels returns the number of min/max pairs stored per pixel
+func (d *WaveformData) peakChannels() int {
+	return pairsPerPixel(d.Data, d.Length)
+}
+
+// pairsPerPixel returns the number of min/max pairs per pixel in length pixels of peaks
+func pairsPerPixel(peaks []int16, length int) int {
+	if length > 0 && len(peaks) >= 2*length {
+		return len(peaks) / (2 * length)
+	}
+	return 1
+}
+
+// restoreRange sets the range of loaded peaks from the time axis and metadata when
+// present, and otherwise assumes the view starts at 0 and covers the whole source
+func (d *WaveformData) restoreRange() {
+	if d.SampleRate > 0 {
+		d.End = float64(d.Length*d.SamplesPerPixel) / float64(d.SampleRate)
+	}
+	if d.TimeAxis != nil {
+		d.Start, d.End = d.TimeAxis.Start, d.TimeAxis.End
+	}
+	d.SourceDuration = d.End
+	if d.Metadata != nil {
+		d.SourceDuration = d.Metadata.Duration
+	}
+}
+
+// validate checks that the peak data holds whole pixels for every channel
+func (d *WaveformData) validate() error {
+	if d.Length < 0 || (d.Length == 0 && len(d.Data) > 0) || (d.Length > 0 && len(d.Data)%(2*d.Length) != 0) {
+		return fmt.Errorf("invalid peak data: %d values for length %d", len(d.Data), d.Length)
+	}
+	return nil
+}
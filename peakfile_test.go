@@ -0,0 +1,169 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tmpFile := "/tmp/test_peakfile.wav"
+	defer os.Remove(tmpFile)
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	data, err := GenerateWaveformData(tmpFile, WaveformOptions{SamplesPerPixel: 256})
+	if err != nil {
+		t.Fatalf("GenerateWaveformData failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteBinary(&buf, data); err != nil {
+		t.Fatalf("WriteBinary failed: %v", err)
+	}
+	// 24 byte version 2 header followed by 16-bit peaks
+	if buf.Len() != 24+2*len(data.Data) {
+		t.Errorf("Expected %d bytes, got %d", 24+2*len(data.Data), buf.Len())
+	}
+
+	read, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatalf("ReadBinary failed: %v", err)
+	}
+	if read.SampleRate != data.SampleRate || read.SamplesPerPixel != 256 || read.Length != data.Length || read.Channels != 1 {
+		t.Errorf("Unexpected header: %+v", read)
+	}
+	if !reflect.DeepEqual(read.Data, data.Data) {
+		t.Error("Peaks changed in the round trip")
+	}
+}
+
+func TestReadBinary8Bit(t *testing.T) {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []int32{1, binaryFlag8Bit, 8000, 64})
+	binary.Write(&buf, binary.LittleEndian, uint32(2))
+	binary.Write(&buf, binary.LittleEndian, []int8{-10, 20, -128, 127})
+
+	data, err := ReadBinary(&buf)
+	if err != nil {
+		t.Fatalf("ReadBinary failed: %v", err)
+	}
+	if data.Version != 1 || data.Bits != 8 || data.Length != 2 || data.Channels != 0 {
+		t.Errorf("Unexpected header: %+v", data)
+	}
+	expected := []int16{-10 << 8, 20 << 8, -128 << 8, 127 << 8}
+	if !reflect.DeepEqual(data.Data, expected) {
+		t.Errorf("Expected %v, got %v", expected, data.Data)
+	}
+
+	buf.Reset()
+	binary.Write(&buf, binary.LittleEndian, []int32{3, 0, 8000, 64, 0})
+	if _, err := ReadBinary(&buf); err == nil {
+		t.Error("Expected an error for an unsupported version")
+	}
+}
+
+func TestReadBinaryHostileHeader(t *testing.T) {
+	header := func(version, flags, rate, spp int32, length uint32, channels int32) *bytes.Buffer {
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, []int32{version, flags, rate, spp})
+		binary.Write(&buf, binary.LittleEndian, length)
+		binary.Write(&buf, binary.LittleEndian, channels)
+		return &buf
+	}
+	for name, buf := range map[string]*bytes.Buffer{
+		"huge length and channels": header(2, 0, 44100, 256, 0xFFFFFFFF, 0x7FFFFFFF),
+		"huge length":              header(2, 0, 44100, 256, 0xFFFFFFFF, 2),
+		"too many channels":        header(2, 0, 44100, 256, 10, DefaultMaxChannels+1),
+		"no channels":              header(2, 0, 44100, 256, 10, 0),
+		"zero length":              header(2, 0, 44100, 256, 0, 1),
+		"zero sample rate":         header(2, 0, 0, 256, 10, 1),
+		"negative samples/pixel":   header(2, 0, 44100, -1, 10, 1),
+		"truncated 16-bit peaks":   header(2, 0, 44100, 256, 1<<20, 2),
+		"truncated 8-bit peaks":    header(2, binaryFlag8Bit, 44100, 256, 1<<20, 2),
+		"truncated header":         bytes.NewBuffer([]byte{2, 0, 0, 0, 0}),
+	} {
+		if _, err := ReadBinary(buf); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// Peaks spanning several read chunks are read completely
+	length := binaryReadChunk/2 + 3
+	buf := header(2, 0, 44100, 256, uint32(length), 1)
+	peaks := make([]int16, 2*length)
+	for i := range peaks {
+		peaks[i] = int16(i)
+	}
+	binary.Write(buf, binary.LittleEndian, peaks)
+	data, err := ReadBinary(buf)
+	if err != nil {
+		t.Fatalf("ReadBinary failed: %v", err)
+	}
+	if !reflect.DeepEqual(data.Data, peaks) {
+		t.Error("Peaks spanning several chunks changed")
+	}
+}
+
+func TestReadJSONCompressed(t *testing.T) {
+	tmpFile := "/tmp/test_peakfile_json.wav"
+	defer os.Remove(tmpFile)
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	for _, codec := range []Compression{CompressionNone, CompressionGzip, CompressionZstd} {
+		encoded, err := GenerateWaveformJSONCompressed(tmpFile, WaveformOptions{SamplesPerPixel: 512}, codec)
+		if err != nil {
+			t.Fatalf("GenerateWaveformJSONCompressed failed: %v", err)
+		}
+		data, err := ReadJSON(bytes.NewReader(encoded))
+		if err != nil {
+			t.Fatalf("ReadJSON failed for %q: %v", codec, err)
+		}
+		if data.SamplesPerPixel != 512 || len(data.Data) != 2*data.Length {
+			t.Errorf("Unexpected data for %q: spp %d, length %d, %d values", codec, data.SamplesPerPixel, data.Length, len(data.Data))
+		}
+	}
+}
+
+func TestRescale(t *testing.T) {
+	data := &WaveformData{
+		Version:         2,
+		Channels:        1,
+		SampleRate:      44100,
+		SamplesPerPixel: 100,
+		Bits:            16,
+		Length:          5,
+		Data:            []int16{-1, 1, -5, 2, -2, 7, -3, 3, -9, 0},
+		Bands:           []BandData{{HighFrequency: 22050, Data: []int16{-1, 1, -2, 2, -3, 3, -4, 4, -5, 5}}},
+	}
+
+	out, err := data.Rescale(200)
+	if err != nil {
+		t.Fatalf("Rescale failed: %v", err)
+	}
+	if out.SamplesPerPixel != 200 || out.Length != 3 {
+		t.Errorf("Expected 3 pixels of 200 samples, got %d of %d", out.Length, out.SamplesPerPixel)
+	}
+	if expected := []int16{-5, 2, -3, 7, -9, 0}; !reflect.DeepEqual(out.Data, expected) {
+		t.Errorf("Expected %v, got %v", expected, out.Data)
+	}
+	if expected := []int16{-2, 2, -4, 4, -5, 5}; !reflect.DeepEqual(out.Bands[0].Data, expected) {
+		t.Errorf("Expected band peaks %v, got %v", expected, out.Bands[0].Data)
+	}
+	if data.Length != 5 || data.Bands[0].Data[0] != -1 {
+		t.Error("Rescale modified its input")
+	}
+
+	out, err = data.RescaleToWidth(2)
+	if err != nil {
+		t.Fatalf("RescaleToWidth failed: %v", err)
+	}
+	if out.Length != 2 || out.SamplesPerPixel != 300 {
+		t.Errorf("Expected 2 pixels of 300 samples, got %d of %d", out.Length, out.SamplesPerPixel)
+	}
+
+	if _, err := data.Rescale(150); err == nil {
+		t.Error("Expected an error for a samples per pixel that is not a multiple")
+	}
+}
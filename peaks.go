@@ -0,0 +1,72 @@
+package gowaveform
+
+// peakScan extends lo and hi with the minimum and maximum of samples.
+// The main loop takes 16 samples per iteration into four independent
+// accumulator pairs, which removes bounds checks and breaks the dependency
+// chain of a single running min/max so the CPU can overlap comparisons.
+func peakScan(samples []int16, lo, hi int16) (int16, int16) {
+	lo0, lo1, lo2, lo3 := lo, lo, lo, lo
+	hi0, hi1, hi2, hi3 := hi, hi, hi, hi
+
+	for len(samples) >= 16 {
+		s := samples[:16:16]
+		for i := 0; i < 16; i += 4 {
+			a, b, c, d := s[i], s[i+1], s[i+2], s[i+3]
+			if a < lo0 {
+				lo0 = a
+			}
+			if a > hi0 {
+				hi0 = a
+			}
+			if b < lo1 {
+				lo1 = b
+			}
+			if b > hi1 {
+				hi1 = b
+			}
+			if c < lo2 {
+				lo2 = c
+			}
+			if c > hi2 {
+				hi2 = c
+			}
+			if d < lo3 {
+				lo3 = d
+			}
+			if d > hi3 {
+				hi3 = d
+			}
+		}
+		samples = samples[16:]
+	}
+
+	for _, sample := range samples {
+		if sample < lo0 {
+			lo0 = sample
+		}
+		if sample > hi0 {
+			hi0 = sample
+		}
+	}
+
+	if lo1 < lo0 {
+		lo0 = lo1
+	}
+	if lo3 < lo2 {
+		lo2 = lo3
+	}
+	if lo2 < lo0 {
+		lo0 = lo2
+	}
+	if hi1 > hi0 {
+		hi0 = hi1
+	}
+	if hi3 > hi2 {
+		hi2 = hi3
+	}
+	if hi2 > hi0 {
+		hi0 = hi2
+	}
+
+	return lo0, hi0
+}
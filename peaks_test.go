@@ -0,0 +1,76 @@
+package gowaveform
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestPeakScanMatchesScalar(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for n := 0; n < 100; n++ {
+		samples := make([]int16, n)
+		for i := range samples {
+			samples[i] = int16(rng.Intn(65536) - 32768)
+		}
+
+		lo, hi := peakScan(samples, math.MaxInt16, math.MinInt16)
+		expectedLo, expectedHi := peakScanScalar(samples, math.MaxInt16, math.MinInt16)
+		if lo != expectedLo || hi != expectedHi {
+			t.Errorf("n=%d: expected (%d, %d), got (%d, %d)", n, expectedLo, expectedHi, lo, hi)
+		}
+	}
+
+	// Extremes in every lane position
+	for pos := 0; pos < 20; pos++ {
+		samples := make([]int16, 20)
+		samples[pos] = math.MinInt16
+		samples[19-pos] = math.MaxInt16
+		lo, hi := peakScan(samples, 0, 0)
+		if lo != math.MinInt16 || hi != math.MaxInt16 {
+			t.Errorf("pos=%d: expected full range, got (%d, %d)", pos, lo, hi)
+		}
+	}
+}
+
+func benchmarkPeakScan(b *testing.B, scan func([]int16, int16, int16) (int16, int16)) {
+	const amenFile = "data/amen_170.wav"
+
+	if _, err := os.Stat(amenFile); os.IsNotExist(err) {
+		b.Skip("Skipping benchmark: data/amen_170.wav not found")
+	}
+
+	waveform, err := LoadWaveform(amenFile)
+	if err != nil {
+		b.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	b.SetBytes(int64(len(waveform.audioData) * 2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		scan(waveform.audioData, math.MaxInt16, math.MinInt16)
+	}
+}
+
+func BenchmarkPeakScan_AmenBreak(b *testing.B) {
+	benchmarkPeakScan(b, peakScan)
+}
+
+func BenchmarkPeakScanScalar_AmenBreak(b *testing.B) {
+	benchmarkPeakScan(b, peakScanScalar)
+}
+
+// peakScanScalar is the reference one-sample-at-a-time scan that peakScan is checked and benchmarked against
+func peakScanScalar(samples []int16, lo, hi int16) (int16, int16) {
+	for _, sample := range samples {
+		if sample < lo {
+			lo = sample
+		}
+		if sample > hi {
+			hi = sample
+		}
+	}
+	return lo, hi
+}
@@ -0,0 +1,102 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// PhaseCorrelation returns the correlation of the left and right channels in consecutive
+// windows of windowSeconds, from +1 (identical, mono) over 0 (unrelated) to -1 (opposite
+// polarity). Stretches near or below zero lose level when the track is summed to mono.
+// Silent windows are 0. It returns nil for mono audio or a window that is not positive.
+func (w *Waveform) PhaseCorrelation(windowSeconds float64) []float64 {
+	return w.phaseCorrelation(0, w.availableFrames(), windowSeconds)
+}
+
+// phaseCorrelation computes the correlation of the first two channels in windows of
+// windowSeconds starting at startFrame; the last window may be shorter
+func (w *Waveform) phaseCorrelation(startFrame, endFrame int, windowSeconds float64) []float64 {
+	if w.Channels < 2 || windowSeconds <= 0 || startFrame >= endFrame {
+		return nil
+	}
+	window := int(math.Round(windowSeconds * float64(w.SampleRate)))
+	if window < 1 {
+		window = 1
+	}
+
+	values := make([]float64, 0, (endFrame-startFrame+window-1)/window)
+	for start := startFrame; start < endFrame; start += window {
+		end := min(start+window, endFrame)
+		var dot, energyL, energyR float64
+		for frame := start; frame < end; frame++ {
+			l := float64(w.audioData[frame*w.Channels])
+			r := float64(w.audioData[frame*w.Channels+1])
+			dot += l * r
+			energyL += l * l
+			energyR += r * r
+		}
+		if energyL == 0 || energyR == 0 {
+			values = append(values, 0)
+			continue
+		}
+		values = append(values, dot/math.Sqrt(energyL*energyR))
+	}
+	return values
+}
+
+// ComparePolarity reports whether b is phase-inverted relative to a, e.g. to check the
+// sources of a multi-microphone capture before summing them. b is aligned to a within
+// DefaultMaxOffset by the magnitude of their cross-correlation, so inverted audio aligns
+// as well, and the correlation of the aligned mono mixes is returned: near +1 for the
+// same polarity, near -1 for inverted polarity and near 0 for unrelated audio. It
+// returns 0 if the sample rates differ or either file is empty or silent.
+func ComparePolarity(a, b *Waveform) float64 {
+	if a.SampleRate != b.SampleRate {
+		return 0
+	}
+	monoA, monoB := a.monoMix(), b.monoMix()
+	if len(monoA) == 0 || len(monoB) == 0 {
+		return 0
+	}
+
+	lag := alignmentLag(monoA, monoB, int(DefaultMaxOffset*float64(a.SampleRate)), true)
+	var dot, energyA, energyB float64
+	for i := range monoA {
+		if j := i + lag; j >= 0 && j < len(monoB) {
+			dot += monoA[i] * monoB[j]
+			energyA += monoA[i] * monoA[i]
+			energyB += monoB[j] * monoB[j]
+		}
+	}
+	if energyA == 0 || energyB == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(energyA*energyB)
+}
+
+// EstimateInterchannelDelay returns the number of frames the right channel lags the
+// left (negative when it leads), found by cross-correlating the two channels within
+// DefaultMaxOffset, e.g. to detect a stereo pair captured by two unsynchronized
+// recorders. The magnitude of the correlation is used, so a channel with inverted
+// polarity aligns as well. Coincident stereo and mono mixes read 0; spaced microphones
+// read the difference in distance to the dominant source. It returns an error for
+// audio with fewer than two channels or a silent channel.
+func EstimateInterchannelDelay(w *Waveform) (samples int, err error) {
+	if w.Channels < 2 {
+		return 0, fmt.Errorf("interchannel delay needs two channels, audio has %d", w.Channels)
+	}
+	frames := w.availableFrames()
+	left, right := make([]float64, frames), make([]float64, frames)
+	var energyLeft, energyRight float64
+	for frame := range frames {
+		left[frame] = float64(w.audioData[frame*w.Channels]) / 32768.0
+		right[frame] = float64(w.audioData[frame*w.Channels+1]) / 32768.0
+		energyLeft += left[frame] * left[frame]
+		energyRight += right[frame] * right[frame]
+	}
+	if energyLeft == 0 || energyRight == 0 {
+		return 0, fmt.Errorf("cannot estimate the delay of a silent channel")
+	}
+
+	return alignmentLag(left, right, int(DefaultMaxOffset*float64(w.SampleRate)), true), nil
+}
@@ -0,0 +1,145 @@
+package gowaveform
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+)
+
+func TestPhaseCorrelation(t *testing.T) {
+	tmpFile := "/tmp/test_phase.wav"
+	defer os.Remove(tmpFile)
+
+	// 0.5s in phase, 0.5s with the right channel inverted, 0.5s of silence
+	tone := sineSamples(44100, 1, 440, 0.5, 0.5)
+	samples := make([]int16, 0, 3*len(tone)*2)
+	for _, v := range tone {
+		samples = append(samples, v, v)
+	}
+	for _, v := range tone {
+		samples = append(samples, v, -v)
+	}
+	samples = append(samples, make([]int16, 2*len(tone))...)
+	writeTestWAV(t, tmpFile, 44100, 2, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	values := waveform.PhaseCorrelation(0.25)
+	expected := []float64{1, 1, -1, -1, 0, 0}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %d windows, got %d", len(expected), len(values))
+	}
+	for i, v := range values {
+		if math.Abs(v-expected[i]) > 1e-6 {
+			t.Errorf("Window %d: expected %.1f, got %.4f", i, expected[i], v)
+		}
+	}
+
+	if waveform.PhaseCorrelation(0) != nil {
+		t.Error("Expected nil for a zero window")
+	}
+}
+
+func TestPhaseCorrelationMono(t *testing.T) {
+	tmpFile := "/tmp/test_phase_mono.wav"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 440, 0.5, 0.5))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if values := waveform.PhaseCorrelation(0.1); values != nil {
+		t.Errorf("Expected nil for mono audio, got %d values", len(values))
+	}
+}
+
+func TestComparePolarity(t *testing.T) {
+	// b is a inverted at half gain and delayed by 300 frames, in stereo
+	rng := rand.New(rand.NewSource(3190))
+	samplesA := make([]int16, 22050)
+	for i := range samplesA {
+		samplesA[i] = int16(rng.Intn(20000) - 10000)
+	}
+	samplesB := make([]int16, 2*300, 2*(300+len(samplesA)))
+	for _, v := range samplesA {
+		samplesB = append(samplesB, -v/2, -v/2)
+	}
+	unrelated := make([]int16, len(samplesA))
+	for i := range unrelated {
+		unrelated[i] = int16(rng.Intn(20000) - 10000)
+	}
+	a := &Waveform{SampleRate: 44100, Channels: 1, BitsPerSample: 16, audioData: samplesA, totalSamples: len(samplesA)}
+	b := &Waveform{SampleRate: 44100, Channels: 2, BitsPerSample: 16, audioData: samplesB, totalSamples: len(samplesB) / 2}
+	c := &Waveform{SampleRate: 44100, Channels: 1, BitsPerSample: 16, audioData: unrelated, totalSamples: len(unrelated)}
+
+	if p := ComparePolarity(a, b); p > -0.999 {
+		t.Errorf("Expected inverted polarity near -1, got %.4f", p)
+	}
+	if p := ComparePolarity(b, a); p > -0.999 {
+		t.Errorf("Expected inverted polarity near -1 in the other direction, got %.4f", p)
+	}
+	if p := ComparePolarity(a, a); math.Abs(p-1) > 1e-9 {
+		t.Errorf("Expected the same polarity to give 1, got %.4f", p)
+	}
+	if p := ComparePolarity(a, c); math.Abs(p) > 0.1 {
+		t.Errorf("Expected unrelated audio near 0, got %.4f", p)
+	}
+
+	silent := &Waveform{SampleRate: 44100, Channels: 1, BitsPerSample: 16, audioData: make([]int16, 1000), totalSamples: 1000}
+	resampled := &Waveform{SampleRate: 48000, Channels: 1, BitsPerSample: 16, audioData: samplesA, totalSamples: len(samplesA)}
+	if ComparePolarity(a, silent) != 0 || ComparePolarity(a, resampled) != 0 {
+		t.Error("Expected 0 for silent audio and differing sample rates")
+	}
+}
+
+func TestEstimateInterchannelDelay(t *testing.T) {
+	rng := rand.New(rand.NewSource(3199))
+	noise := make([]int16, 44100)
+	for i := range noise {
+		noise[i] = int16(rng.Intn(20000) - 10000)
+	}
+	// stereo returns noise in the left channel and a copy shifted by delay frames,
+	// scaled by gain, in the right
+	stereo := func(delay int, gain int16) *Waveform {
+		samples := make([]int16, 2*len(noise))
+		for i, v := range noise {
+			samples[2*i] = v
+			if j := i - delay; j >= 0 && j < len(noise) {
+				samples[2*i+1] = noise[j] * gain
+			}
+		}
+		return &Waveform{SampleRate: 44100, Channels: 2, BitsPerSample: 16, audioData: samples, totalSamples: len(noise)}
+	}
+
+	tests := []struct {
+		delay int
+		gain  int16
+	}{
+		{0, 1},
+		{37, 1},
+		{-12, 1},
+		{2000, -1}, // Inverted polarity
+	}
+	for _, tt := range tests {
+		got, err := EstimateInterchannelDelay(stereo(tt.delay, tt.gain))
+		if err != nil {
+			t.Fatalf("EstimateInterchannelDelay failed: %v", err)
+		}
+		if got != tt.delay {
+			t.Errorf("Expected a delay of %d frames, got %d", tt.delay, got)
+		}
+	}
+
+	mono := &Waveform{SampleRate: 44100, Channels: 1, BitsPerSample: 16, audioData: noise, totalSamples: len(noise)}
+	if _, err := EstimateInterchannelDelay(mono); err == nil {
+		t.Error("Expected an error for mono audio")
+	}
+	if _, err := EstimateInterchannelDelay(stereo(0, 0)); err == nil {
+		t.Error("Expected an error for a silent channel")
+	}
+}
@@ -0,0 +1,147 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// PitchPoint is a single pitch estimate
+type PitchPoint struct {
+	Time       float64 `json:"time"`       // Center of the analysis window in seconds
+	Frequency  float64 `json:"frequency"`  // Estimated fundamental frequency in Hz (0 if unvoiced)
+	Confidence float64 `json:"confidence"` // Confidence of the estimate from 0.0 to 1.0
+}
+
+// PitchOptions defines parameters for pitch tracking
+type PitchOptions struct {
+	Start        float64 // Start time in seconds
+	End          float64 // End time in seconds (0 means end of file)
+	MinFrequency float64 // Lowest detectable frequency in Hz (default 60)
+	MaxFrequency float64 // Highest detectable frequency in Hz (default 1000)
+	WindowSize   int     // Analysis window size in samples (default 2048)
+	HopSize      int     // Distance between analysis windows in samples (default 512)
+	Threshold    float64 // YIN absolute threshold; lower is stricter (default 0.15)
+}
+
+// TrackPitch estimates the fundamental frequency over time using the YIN algorithm.
+// Channels are mixed to mono before analysis. Windows without a clear periodicity
+// are returned with a Frequency of 0.
+func TrackPitch(w *Waveform, opts PitchOptions) ([]PitchPoint, error) {
+	if opts.MinFrequency <= 0 {
+		opts.MinFrequency = 60
+	}
+	if opts.MaxFrequency <= 0 {
+		opts.MaxFrequency = 1000
+	}
+	if opts.WindowSize <= 0 {
+		opts.WindowSize = 2048
+	}
+	if opts.HopSize <= 0 {
+		opts.HopSize = 512
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = 0.15
+	}
+	if opts.MinFrequency >= opts.MaxFrequency {
+		return nil, fmt.Errorf("invalid frequency range: min must be below max")
+	}
+
+	startSample, endSample, _, err := w.resolveRange(WaveformOptions{Start: opts.Start, End: opts.End})
+	if err != nil {
+		return nil, err
+	}
+
+	// The integration window is half the analysis window; the other half holds the lags
+	integration := opts.WindowSize / 2
+	tauMin := int(float64(w.SampleRate) / opts.MaxFrequency)
+	tauMax := int(float64(w.SampleRate) / opts.MinFrequency)
+	if tauMax > integration {
+		tauMax = integration
+	}
+	if tauMin < 2 {
+		tauMin = 2
+	}
+	if tauMin >= tauMax {
+		return nil, fmt.Errorf("window size %d is too small for a minimum frequency of %.1f Hz", opts.WindowSize, opts.MinFrequency)
+	}
+
+	frame := make([]float64, opts.WindowSize)
+	diff := make([]float64, tauMax+1)
+	points := []PitchPoint{}
+
+	for frameStart := startSample; frameStart+opts.WindowSize <= endSample; frameStart += opts.HopSize {
+		for i := range frame {
+			frame[i] = w.monoSample(frameStart + i)
+		}
+
+		frequency, confidence := yinEstimate(frame, diff, integration, tauMin, tauMax, w.SampleRate, opts.Threshold)
+		points = append(points, PitchPoint{
+			Time:       float64(frameStart+opts.WindowSize/2) / float64(w.SampleRate),
+			Frequency:  frequency,
+			Confidence: confidence,
+		})
+	}
+
+	return points, nil
+}
+
+// yinEstimate runs the YIN difference function over one frame and returns
+// the estimated frequency (0 if unvoiced) and its confidence
+func yinEstimate(frame, diff []float64, integration, tauMin, tauMax, sampleRate int, threshold float64) (float64, float64) {
+	// Difference function
+	for tau := 1; tau <= tauMax; tau++ {
+		var sum float64
+		for j := 0; j < integration; j++ {
+			d := frame[j] - frame[j+tau]
+			sum += d * d
+		}
+		diff[tau] = sum
+	}
+
+	// Cumulative mean normalized difference
+	diff[0] = 1
+	var running float64
+	for tau := 1; tau <= tauMax; tau++ {
+		running += diff[tau]
+		if running == 0 {
+			diff[tau] = 1
+		} else {
+			diff[tau] = diff[tau] * float64(tau) / running
+		}
+	}
+
+	// First dip below the threshold, followed down to its local minimum
+	bestTau := -1
+	for tau := tauMin; tau <= tauMax; tau++ {
+		if diff[tau] < threshold {
+			for tau+1 <= tauMax && diff[tau+1] < diff[tau] {
+				tau++
+			}
+			bestTau = tau
+			break
+		}
+	}
+
+	if bestTau == -1 {
+		// No clear period; report how close the best candidate came
+		best := 1.0
+		for tau := tauMin; tau <= tauMax; tau++ {
+			if diff[tau] < best {
+				best = diff[tau]
+			}
+		}
+		return 0, math.Max(0, 1-best)
+	}
+
+	// Parabolic interpolation for sub-sample accuracy
+	refined := float64(bestTau)
+	if bestTau > 1 && bestTau < tauMax {
+		s0, s1, s2 := diff[bestTau-1], diff[bestTau], diff[bestTau+1]
+		denom := s0 - 2*s1 + s2
+		if denom != 0 {
+			refined += (s0 - s2) / (2 * denom)
+		}
+	}
+
+	return float64(sampleRate) / refined, math.Min(1, math.Max(0, 1-diff[bestTau]))
+}
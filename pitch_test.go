@@ -0,0 +1,108 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestTrackPitchSineTone(t *testing.T) {
+	tmpFile := "/tmp/test_pitch_tone.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 220)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	points, err := TrackPitch(waveform, PitchOptions{})
+	if err != nil {
+		t.Fatalf("TrackPitch failed: %v", err)
+	}
+
+	if len(points) == 0 {
+		t.Fatal("Expected pitch points")
+	}
+
+	for _, pt := range points {
+		if math.Abs(pt.Frequency-220) > 2 {
+			t.Errorf("At %.3fs: expected ~220 Hz, got %.2f Hz", pt.Time, pt.Frequency)
+		}
+		if pt.Confidence < 0.9 {
+			t.Errorf("At %.3fs: expected high confidence, got %.2f", pt.Time, pt.Confidence)
+		}
+	}
+
+	// Points should be evenly spaced by the hop size
+	hop := 512.0 / 44100.0
+	if math.Abs(points[1].Time-points[0].Time-hop) > 1e-9 {
+		t.Errorf("Expected points spaced %.5fs apart, got %.5fs", hop, points[1].Time-points[0].Time)
+	}
+}
+
+func TestTrackPitchSilence(t *testing.T) {
+	tmpFile := "/tmp/test_pitch_silence.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, make([]int16, 44100))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	points, err := TrackPitch(waveform, PitchOptions{})
+	if err != nil {
+		t.Fatalf("TrackPitch failed: %v", err)
+	}
+
+	for _, pt := range points {
+		if pt.Frequency != 0 {
+			t.Fatalf("At %.3fs: expected unvoiced silence, got %.2f Hz", pt.Time, pt.Frequency)
+		}
+	}
+}
+
+func TestTrackPitchRange(t *testing.T) {
+	tmpFile := "/tmp/test_pitch_range.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 2.0, 440)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	points, err := TrackPitch(waveform, PitchOptions{Start: 0.5, End: 1.0})
+	if err != nil {
+		t.Fatalf("TrackPitch failed: %v", err)
+	}
+
+	for _, pt := range points {
+		if pt.Time < 0.5 || pt.Time > 1.0 {
+			t.Errorf("Point at %.3fs is outside the requested range", pt.Time)
+		}
+	}
+}
+
+func TestTrackPitchInvalidOptions(t *testing.T) {
+	tmpFile := "/tmp/test_pitch_invalid.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if _, err := TrackPitch(waveform, PitchOptions{MinFrequency: 500, MaxFrequency: 100}); err == nil {
+		t.Error("Expected error when min frequency is above max frequency")
+	}
+	if _, err := TrackPitch(waveform, PitchOptions{MinFrequency: 20, WindowSize: 64}); err == nil {
+		t.Error("Expected error when window is too small for the minimum frequency")
+	}
+}
@@ -2,28 +2,81 @@ package gowaveform
 
 import (
 	"fmt"
+	"image"
 	"image/color"
+	"image/jpeg"
+	"io"
+	"math"
+	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	"golang.org/x/image/font/opentype"
 	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
+	"gonum.org/v1/plot/font/liberation"
 	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/text"
 	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"gonum.org/v1/plot/vg/vgimg"
 )
 
 // PlotConfig holds the configuration for plotting a waveform
 type PlotConfig struct {
 	width           int
 	height          int
+	dpi             int     // Dots per inch, which sets the size of text and lines
+	physicalWidth   float64 // Width in inches (0 = use width in pixels)
+	physicalHeight  float64 // Height in inches (0 = use height in pixels)
 	backgroundColor color.Color
 	foregroundColor color.Color
 	showTimestamp   bool
 	hideYAxis       bool
 	hideXAxis       bool
 	title           string
-	start           float64 // Start time in seconds (0 = beginning)
-	end             float64 // End time in seconds (0 = use full duration)
-	resolution      float64 // Resolution multiplier (1.0 = full resolution, 0.5 = half resolution)
+	window          window               // Requested view range, resolved into start and end
+	start           float64              // Start time in seconds of the resolved view
+	end             float64              // End time in seconds of the resolved view
+	resolution      float64              // Resolution multiplier (1.0 = full resolution, 0.5 = half resolution)
+	spectral        bool                 // Color each column by its dominant frequency band
+	pitchOverlay    bool                 // Draw the pitch contour over the waveform
+	pitchColor      color.Color          // Color of the pitch contour
+	bpm             float64              // Tempo for the beat grid (0 = no beat grid)
+	beatOffset      float64              // Time in seconds of the first downbeat
+	timeFormat      TimeFormat           // Format of the time axis labels
+	frameRate       float64              // Frame rate for SMPTE timecode labels
+	xTickInterval   float64              // Spacing of x-axis ticks in seconds (0 = automatic)
+	xTickFormatter  func(float64) string // Custom x-axis tick label formatter (nil = use time format)
+	showRMS         bool                 // Draw the RMS body inside the peak envelope
+	rmsColor        color.Color          // Color of the RMS body
+	playhead        float64              // Time in seconds of the playhead line (negative = no playhead)
+	playheadColor   color.Color          // Color of the playhead line
+	markers         []Marker             // Labeled marker lines drawn over the waveform
+	markerColor     color.Color          // Color of the marker lines and labels
+	regions         []Region             // Labeled time ranges shaded behind the waveform
+	regionColor     color.Color          // Color of regions without their own color
+	phaseOverlay    bool                 // Draw the stereo phase correlation over the waveform
+	phaseColor      color.Color          // Color of the phase correlation line
+	levelOverlay    bool                 // Draw the weighted level timeline over the waveform
+	levelWeighting  Weighting            // Frequency weighting of the level timeline
+	levelColor      color.Color          // Color of the level timeline
+	heatStrip       StripMetric          // Metric of the heat strip under the waveform (StripNone = no strip)
+	overs           bool                 // Flag inter-sample overs above and below the waveform
+	overColor       color.Color          // Color of the inter-sample over flags
+	symmetric       bool                 // Mirror the absolute peak of each pixel around zero
+	filter          *Filter              // Filter applied to the audio before the peaks are taken
+	deterministic   bool                 // Render byte-identical output for identical inputs and options
+	fontData        []byte               // TrueType or OpenType font for all text (nil = default font)
+	fontSize        float64              // Title and axis label size in points (0 = 12)
+	labelColor      color.Color          // Color of the title and axis labels (nil = black)
+	tickColor       color.Color          // Color of the tick labels, tick marks and axis lines (nil = black)
+	sparkline       bool                 // Fill the whole canvas with the waveform, without axes or title
+	antialias       bool                 // Shade edge pixels by coverage in the fast renderer
+	renderer        string               // Name of a registered Renderer (empty = full gonum/plot pipeline)
+	waveStyle       WaveStyle            // Filled or bars
+	splitChannels   bool                 // Draw each channel in its own lane
 }
 
 // Option is the type all plot options need to adhere to
@@ -43,6 +96,30 @@ func OptionSetHeight(height int) Option {
 	}
 }
 
+// OptionSetDPI sets the resolution in dots per inch (default 96). The image keeps the
+// configured size in pixels while text and lines scale with the DPI, so 192 gives a
+// sharp 2x image for retina displays and 300 suits print.
+func OptionSetDPI(dpi int) Option {
+	return func(c *PlotConfig) {
+		c.dpi = dpi
+	}
+}
+
+// OptionSetSizeInches sets the physical size of the plot, overriding the size in
+// pixels with the size times the DPI, rounded to whole pixels
+func OptionSetSizeInches(width, height float64) Option {
+	return func(c *PlotConfig) {
+		c.physicalWidth = width
+		c.physicalHeight = height
+	}
+}
+
+// OptionSetSizeCentimeters sets the physical size of the plot in centimeters, see
+// OptionSetSizeInches
+func OptionSetSizeCentimeters(width, height float64) Option {
+	return OptionSetSizeInches(width/2.54, height/2.54)
+}
+
 // OptionSetBackgroundColor sets the background color using a hex color code
 func OptionSetBackgroundColor(hexColor string) Option {
 	return func(c *PlotConfig) {
@@ -85,47 +162,272 @@ func OptionSetTitle(title string) Option {
 	}
 }
 
-// OptionSetStart sets the start time in seconds for the waveform view
-func OptionSetStart(start float64) Option {
+// OptionSetResolution sets the resolution multiplier for waveform generation
+// 1.0 = full resolution (1 pixel per width unit)
+// 0.5 = half resolution (generate with half the width)
+// 2.0 = double resolution (generate with double the width)
+func OptionSetResolution(resolution float64) Option {
 	return func(c *PlotConfig) {
-		c.start = start
+		if resolution > 0 {
+			c.resolution = resolution
+		}
 	}
 }
 
-// OptionSetEnd sets the end time in seconds for the waveform view
-func OptionSetEnd(end float64) Option {
+// OptionSpectral enables or disables frequency-colored rendering, where each column
+// is colored by its dominant frequency band (low = red, mid = green, high = blue)
+func OptionSpectral(spectral bool) Option {
 	return func(c *PlotConfig) {
-		c.end = end
+		c.spectral = spectral
 	}
 }
 
-// OptionSetZoom sets the duration (in seconds) to display, centered around the midpoint
-// If start is set, zoom from start; if end is set, zoom backwards from end
-func OptionSetZoom(duration float64) Option {
+// OptionPitchOverlay enables or disables drawing the pitch contour over the waveform.
+// The contour is tracked with TrackPitch and drawn on a logarithmic frequency scale
+// spanning the full plot height.
+func OptionPitchOverlay(show bool) Option {
 	return func(c *PlotConfig) {
-		// This will be handled specially in SavePlot based on start/end values
-		// For now, we'll store it as the end value and process it later
-		if c.start > 0 {
-			c.end = c.start + duration
-		} else {
-			// Will be calculated in SavePlot when we know total duration
-			c.end = -duration // Negative indicates zoom duration
+		c.pitchOverlay = show
+	}
+}
+
+// OptionSetPitchColor sets the color of the pitch contour using a hex color code
+func OptionSetPitchColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.pitchColor = hexToColor(hexColor)
+	}
+}
+
+// OptionBeatGrid draws a beat grid for the given tempo, with the first downbeat at
+// offset seconds, and labels the x-axis in bars and beats instead of seconds
+func OptionBeatGrid(bpm float64, offset float64) Option {
+	return func(c *PlotConfig) {
+		c.bpm = bpm
+		c.beatOffset = offset
+	}
+}
+
+// OptionTimeFormat sets the format of the time axis labels
+// (seconds, mm:ss.mmm, SMPTE timecode or sample indices)
+func OptionTimeFormat(format TimeFormat) Option {
+	return func(c *PlotConfig) {
+		c.timeFormat = format
+	}
+}
+
+// OptionSetFrameRate sets the frame rate used for SMPTE timecode labels (default: 30)
+func OptionSetFrameRate(fps float64) Option {
+	return func(c *PlotConfig) {
+		if fps > 0 {
+			c.frameRate = fps
 		}
 	}
 }
 
-// OptionSetResolution sets the resolution multiplier for waveform generation
-// 1.0 = full resolution (1 pixel per width unit)
-// 0.5 = half resolution (generate with half the width)
-// 2.0 = double resolution (generate with double the width)
-func OptionSetResolution(resolution float64) Option {
+// OptionXTickInterval places an x-axis tick every given number of seconds
+// instead of letting the plotting library choose tick positions
+func OptionXTickInterval(seconds float64) Option {
 	return func(c *PlotConfig) {
-		if resolution > 0 {
-			c.resolution = resolution
+		if seconds > 0 {
+			c.xTickInterval = seconds
 		}
 	}
 }
 
+// OptionXTickFormatter sets a function that formats x-axis tick labels from a time in seconds,
+// overriding the time format (e.g. to print ticks as "0:05")
+func OptionXTickFormatter(formatter func(float64) string) Option {
+	return func(c *PlotConfig) {
+		c.xTickFormatter = formatter
+	}
+}
+
+// OptionShowRMS enables or disables drawing the RMS level of each column inside
+// the min/max envelope, the way most DAWs render tracks
+func OptionShowRMS(show bool) Option {
+	return func(c *PlotConfig) {
+		c.showRMS = show
+	}
+}
+
+// OptionSetRMSColor sets the color of the RMS body using a hex color code
+func OptionSetRMSColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.rmsColor = hexToColor(hexColor)
+	}
+}
+
+// OptionPlayhead draws a vertical playhead line at the given time in seconds
+func OptionPlayhead(seconds float64) Option {
+	return func(c *PlotConfig) {
+		c.playhead = seconds
+	}
+}
+
+// OptionSetPlayheadColor sets the color of the playhead line using a hex color code
+func OptionSetPlayheadColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.playheadColor = hexToColor(hexColor)
+	}
+}
+
+// Marker is a labeled position in the audio
+type Marker struct {
+	Time  float64 // Position in seconds
+	Label string  // Optional label drawn next to the top of the marker line
+}
+
+// OptionMarkers draws a vertical line at each marker, labeled with its label if set
+func OptionMarkers(markers []Marker) Option {
+	return func(c *PlotConfig) {
+		c.markers = markers
+	}
+}
+
+// OptionSetMarkerColor sets the color of the marker lines and labels using a hex color code
+func OptionSetMarkerColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.markerColor = hexToColor(hexColor)
+	}
+}
+
+// OptionPhaseCorrelation enables or disables drawing the correlation of the left and
+// right channels over the waveform, on the amplitude scale from -1 to +1, to spot
+// stretches that cancel when summed to mono. Mono audio draws no line.
+func OptionPhaseCorrelation(show bool) Option {
+	return func(c *PlotConfig) {
+		c.phaseOverlay = show
+	}
+}
+
+// OptionSetPhaseColor sets the color of the phase correlation line using a hex color code
+func OptionSetPhaseColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.phaseColor = hexToColor(hexColor)
+	}
+}
+
+// OptionLevelOverlay enables or disables drawing the weighted short-term level (see
+// LevelTimeline) over the waveform, for noise monitoring. The level is drawn on the
+// amplitude scale with -60 dBFS at the bottom and 0 dBFS at the top, in windows of
+// DefaultLevelWindow or about four pixels, whichever is longer.
+func OptionLevelOverlay(show bool) Option {
+	return func(c *PlotConfig) {
+		c.levelOverlay = show
+	}
+}
+
+// OptionSetLevelWeighting sets the frequency weighting of the level overlay
+// (default WeightingA)
+func OptionSetLevelWeighting(weighting Weighting) Option {
+	return func(c *PlotConfig) {
+		c.levelWeighting = weighting
+	}
+}
+
+// OptionSetLevelColor sets the color of the level overlay using a hex color code
+func OptionSetLevelColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.levelColor = hexToColor(hexColor)
+	}
+}
+
+// OptionTruePeakOvers enables or disables flagging inter-sample overs (see TruePeak)
+// with short marks at the top and bottom edges of the waveform
+func OptionTruePeakOvers(show bool) Option {
+	return func(c *PlotConfig) {
+		c.overs = show
+	}
+}
+
+// OptionSetOverColor sets the color of the inter-sample over flags using a hex color code
+func OptionSetOverColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.overColor = hexToColor(hexColor)
+	}
+}
+
+// OptionSymmetric enables or disables symmetric rendering, which mirrors the larger of
+// the minimum and maximum magnitude of each pixel around zero (see WaveformOptions.Symmetric)
+func OptionSymmetric(symmetric bool) Option {
+	return func(c *PlotConfig) {
+		c.symmetric = symmetric
+	}
+}
+
+// OptionFilter draws the audio passed through a high-pass, low-pass or band-pass filter,
+// e.g. OptionFilter(HighPass(120)) (see WaveformOptions.Filter)
+func OptionFilter(filter *Filter) Option {
+	return func(c *PlotConfig) {
+		c.filter = filter
+	}
+}
+
+// OptionDeterministic enables or disables deterministic rendering, which guarantees
+// byte-identical images for identical audio and options, e.g. to content-address cached
+// images. Fonts are pinned to the bundled Liberation Sans regardless of the gonum/plot
+// defaults, progressively loaded audio is decoded completely before drawing, and time
+// axis labels are rounded to the nanosecond before formatting. Plots never embed the
+// time they were generated.
+func OptionDeterministic(deterministic bool) Option {
+	return func(c *PlotConfig) {
+		c.deterministic = deterministic
+	}
+}
+
+// OptionSparkline enables or disables the sparkline preset, which removes the title,
+// axes, labels and all padding so the waveform fills the canvas edge to edge, e.g. for
+// thumbnails in a track list. Unlike hiding both axes, no whitespace is left around
+// the data area.
+func OptionSparkline(sparkline bool) Option {
+	return func(c *PlotConfig) {
+		c.sparkline = sparkline
+	}
+}
+
+// OptionAntialias enables or disables anti-aliased drawing for plots made by the fast
+// renderer, such as sparklines and other axis-free thumbnails. The outline through the
+// peaks is filled with a vector rasterizer that shades edge pixels by coverage, so
+// narrow images have smooth instead of stair-stepped edges. Plots drawn with gonum/plot
+// are always anti-aliased.
+func OptionAntialias(antialias bool) Option {
+	return func(c *PlotConfig) {
+		c.antialias = antialias
+	}
+}
+
+// OptionSetFont sets the TrueType or OpenType font used for the title, axis labels,
+// tick labels and marker labels, e.g. the contents of a .ttf file. SavePlot returns
+// an error if the font cannot be parsed.
+func OptionSetFont(ttf []byte) Option {
+	return func(c *PlotConfig) {
+		c.fontData = ttf
+	}
+}
+
+// OptionSetFontSize sets the size in points of the title and axis labels (default 12);
+// tick and marker labels are drawn at 10/12 of it
+func OptionSetFontSize(size float64) Option {
+	return func(c *PlotConfig) {
+		c.fontSize = size
+	}
+}
+
+// OptionSetLabelColor sets the color of the title and axis labels using a hex color code
+func OptionSetLabelColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.labelColor = hexToColor(hexColor)
+	}
+}
+
+// OptionSetTickColor sets the color of the tick labels, tick marks and axis lines
+// using a hex color code
+func OptionSetTickColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.tickColor = hexToColor(hexColor)
+	}
+}
+
 // hexToColor converts a hex color string to color.Color
 // Supports formats: #RGB, #RRGGBB, RGB, RRGGBB
 func hexToColor(hex string) color.Color {
@@ -147,92 +449,222 @@ func hexToColor(hex string) color.Color {
 	return color.RGBA{R: r, G: g, B: b, A: 255}
 }
 
-// SavePlot saves the waveform visualization to an image file
-// The file format (PNG or JPEG) is determined by the filename extension
-func SavePlot(w *Waveform, filename string, opts ...Option) error {
-	// Default configuration
-	config := PlotConfig{
+// defaultPlotConfig returns the configuration used when no options are given
+func defaultPlotConfig() PlotConfig {
+	return PlotConfig{
 		width:           800,
 		height:          400,
+		dpi:             DefaultDPI,
 		backgroundColor: color.White,
 		foregroundColor: color.RGBA{R: 0, G: 100, B: 200, A: 255}, // Blue
 		showTimestamp:   true,
 		hideYAxis:       false,
 		hideXAxis:       false,
 		title:           "",
-		start:           0,
-		end:             0,
 		resolution:      1.0,
+		spectral:        false,
+		pitchOverlay:    false,
+		pitchColor:      color.RGBA{R: 255, G: 140, B: 0, A: 255}, // Orange
+		timeFormat:      TimeFormatSeconds,
+		frameRate:       DefaultFrameRate,
+		showRMS:         false,
+		rmsColor:        color.RGBA{R: 90, G: 170, B: 255, A: 255}, // Light blue
+		playhead:        -1,
+		playheadColor:   color.RGBA{R: 220, G: 30, B: 30, A: 255},  // Red
+		markerColor:     color.RGBA{R: 0, G: 160, B: 60, A: 255},   // Green
+		regionColor:     color.RGBA{R: 0, G: 110, B: 220, A: 255},  // Blue
+		phaseColor:      color.RGBA{R: 160, G: 40, B: 200, A: 255}, // Purple
+		levelWeighting:  WeightingA,
+		levelColor:      color.RGBA{R: 200, G: 160, B: 0, A: 255}, // Amber
+		overColor:       color.RGBA{R: 230, G: 0, B: 0, A: 255},   // Red
 	}
+}
+
+// newPlotConfig applies the options to the default configuration and resolves
+// the view window against the waveform duration
+func newPlotConfig(w *Waveform, opts []Option) (PlotConfig, error) {
+	config := defaultPlotConfig()
 
 	// Apply options
 	for _, opt := range opts {
 		opt(&config)
 	}
 
-	// Get total duration
-	totalDuration := w.Duration()
+	// Resolve the physical size once the DPI is known, whatever the order of options
+	if config.dpi <= 0 {
+		config.dpi = DefaultDPI
+	}
+	if config.physicalWidth > 0 && config.physicalHeight > 0 {
+		config.width = max(1, int(math.Round(config.physicalWidth*float64(config.dpi))))
+		config.height = max(1, int(math.Round(config.physicalHeight*float64(config.dpi))))
+	}
 
-	// Handle zoom (negative end indicates zoom duration was set)
-	if config.end < 0 {
-		zoomDuration := -config.end
-		if config.start > 0 {
-			// Zoom from start
-			config.end = config.start + zoomDuration
-		} else {
-			// Center zoom around midpoint
-			center := totalDuration / 2.0
-			config.start = center - zoomDuration/2.0
-			config.end = center + zoomDuration/2.0
+	start, end, err := config.window.resolve(w.Duration())
+	if err != nil {
+		return config, err
+	}
+	config.start, config.end = start, end
+
+	return config, nil
+}
+
+// SavePlot saves the waveform visualization to an image file
+// The file format (PNG or JPEG) is determined by the filename extension
+func SavePlot(w *Waveform, filename string, opts ...Option) error {
+	config, err := newPlotConfig(w, opts)
+	if err != nil {
+		return err
+	}
+
+	if name := config.rendererName(); name != "" {
+		img, err := renderWithRenderer(w, config, name)
+		if err != nil {
+			return err
 		}
+		return saveImage(img, config, filename)
+	}
+
+	p, err := newPlot(w, config)
+	if err != nil {
+		return err
+	}
+
+	return savePlotImage(p, config, filename)
+}
+
+// savePlotImage saves a plot with the configured size to an image file
+// The file format (PNG or JPEG) is determined by the filename extension
+func savePlotImage(p *plot.Plot, config PlotConfig, filename string) error {
+	c := config.newCanvas()
+	p.Draw(draw.New(c))
+	return saveImage(c.Image(), config, filename)
+}
+
+// saveImage encodes an image to a PNG or JPEG file, chosen by the filename extension
+func saveImage(img image.Image, config PlotConfig, filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	var encode func(io.Writer, image.Image) error
+	switch ext {
+	case ".png":
+		encode = func(out io.Writer, img image.Image) error {
+			return encodePNG(out, img, config.dpi)
+		}
+	case ".jpg", ".jpeg":
+		encode = func(out io.Writer, img image.Image) error {
+			return jpeg.Encode(out, img, nil)
+		}
+	default:
+		return fmt.Errorf("unsupported file format: %s (supported: .png, .jpg, .jpeg)", ext)
 	}
 
-	// Clamp start and end to valid range
-	if config.start < 0 {
-		config.start = 0
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create image file: %w", err)
 	}
-	if config.end > totalDuration || config.end == 0 {
-		config.end = totalDuration
+	if err := encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to save %s: %w", strings.ToUpper(strings.TrimPrefix(ext, ".")), err)
 	}
-	if config.start >= config.end {
-		config.start = 0
-		config.end = totalDuration
+	return f.Close()
+}
+
+// size returns the plot dimensions as vg lengths at the configured DPI
+func (c PlotConfig) size() (vg.Length, vg.Length) {
+	dpi := vg.Length(c.dpi)
+	return vg.Length(c.width) * vg.Inch / dpi, vg.Length(c.height) * vg.Inch / dpi
+}
+
+// newCanvas returns an image canvas of the configured size in pixels and DPI
+func (c PlotConfig) newCanvas() *vgimg.Canvas {
+	width, height := c.size()
+	return vgimg.NewWith(vgimg.UseWH(width, height), vgimg.UseDPI(c.dpi))
+}
+
+// newPlot builds the plot of the waveform for a resolved configuration
+func newPlot(w *Waveform, config PlotConfig) (*plot.Plot, error) {
+	waveformData, err := plotWaveformData(w, config)
+	if err != nil {
+		return nil, err
 	}
+	return plotView(w, waveformData, config)
+}
+
+// effectiveWidth returns the number of peak columns, based on the width and resolution
+func (c PlotConfig) effectiveWidth() int {
+	return max(1, int(float64(c.width)*c.resolution))
+}
 
-	// Calculate effective width based on resolution
-	effectiveWidth := int(float64(config.width) * config.resolution)
-	if effectiveWidth < 1 {
-		effectiveWidth = 1
+// plotWaveformData generates the peaks drawn for a resolved configuration
+func plotWaveformData(w *Waveform, config PlotConfig) (*WaveformData, error) {
+	// A partly decoded file would draw differently depending on timing
+	if config.deterministic {
+		if err := w.Wait(); err != nil {
+			return nil, fmt.Errorf("failed to load audio: %w", err)
+		}
 	}
 
-	// Generate waveform data
-	waveformData, err := w.GenerateView(WaveformOptions{
-		Start: config.start,
-		End:   config.end,
-		Width: effectiveWidth,
-	})
+	opts := WaveformOptions{
+		Start:     config.start,
+		End:       config.end,
+		Width:     config.effectiveWidth(),
+		Symmetric: config.symmetric,
+		Filter:    config.filter,
+	}
+	generate := w.GenerateView
+	if config.splitChannels && w.Channels > 1 && !config.spectral && !config.showRMS {
+		generate = w.splitView
+	}
+	waveformData, err := generate(opts)
 	if err != nil {
-		return fmt.Errorf("failed to generate waveform view: %w", err)
+		return nil, fmt.Errorf("failed to generate waveform view: %w", err)
 	}
+	return waveformData, nil
+}
+
+// plotView builds the plot of generated peaks. The waveform may be nil when only the
+// peaks are available, in which case the overlays computed from the audio (spectral
+// colors, RMS, pitch and phase correlation) are left out.
+func plotView(w *Waveform, waveformData *WaveformData, config PlotConfig) (*plot.Plot, error) {
+	effectiveWidth := config.effectiveWidth()
 
 	// Create a new plot
 	p := plot.New()
+	if err := styleText(p, config); err != nil {
+		return nil, err
+	}
 
 	// Set background color
 	p.BackgroundColor = config.backgroundColor
 
 	// Set title
 	p.Title.Text = config.title
-	
+
 	// Set labels
 	if config.showTimestamp {
 		p.X.Label.Text = "Time (seconds)"
 	}
-	
+
 	if !config.hideYAxis {
 		p.Y.Label.Text = "Amplitude"
 	}
 
+	// Use custom tick positions or labels when requested
+	if config.timeFormat != TimeFormatSeconds {
+		p.X.Label.Text = timeAxisLabel(config.timeFormat, config.frameRate)
+	}
+	if config.timeFormat != TimeFormatSeconds || config.xTickInterval > 0 || config.xTickFormatter != nil || config.deterministic {
+		p.X.Tick.Marker = timeTicker(config, waveformData.SampleRate)
+	}
+
+	// Label the x-axis in bars and beats when a beat grid is set
+	if config.bpm > 0 {
+		p.X.Label.Text = fmt.Sprintf("Bar.Beat (%g BPM)", config.bpm)
+		p.X.Tick.Marker = plot.TickerFunc(func(min, max float64) []plot.Tick {
+			return beatTicks(BeatGrid(min, max, config.bpm, config.beatOffset))
+		})
+	}
+
 	// Hide labels if timestamp is disabled
 	if !config.showTimestamp {
 		p.X.Label.Text = ""
@@ -257,6 +689,188 @@ func SavePlot(w *Waveform, filename string, opts ...Option) error {
 		p.Y.LineStyle.Width = 0
 	}
 
+	// A sparkline gives the whole canvas to the data area
+	if config.sparkline {
+		p.Title.Text = ""
+		p.X.Label.Text = ""
+		p.Y.Label.Text = ""
+		p.HideAxes()
+		p.X.Padding = 0
+		p.Y.Padding = 0
+	}
+
+	// Shade regions behind the waveform
+	if len(config.regions) > 0 {
+		p.Add(&regionShading{regions: config.regions, start: waveformData.Start, end: waveformData.End, color: config.regionColor})
+	}
+
+	// Draw the waveform as frequency-colored columns, bars or lanes, or a single filled polygon
+	if config.spectral && w != nil {
+		energies, err := w.GenerateBandEnergies(WaveformOptions{
+			Start: config.start,
+			End:   config.end,
+			Width: effectiveWidth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate band energies: %w", err)
+		}
+		p.Add(&spectralColumns{data: waveformData, energies: energies})
+	} else if config.waveStyle != WaveStyleFilled || config.splitChannels {
+		p.Add(&waveShape{
+			data:  waveformData,
+			style: config.waveStyle,
+			split: config.splitChannels,
+			color: config.foregroundColor,
+			pixel: vg.Inch / vg.Length(config.dpi),
+		})
+	} else {
+		poly, err := waveformPolygon(waveformData)
+		if err != nil {
+			return nil, err
+		}
+		poly.Color = config.foregroundColor
+		poly.LineStyle.Width = vg.Points(0) // No outline
+
+		p.Add(poly)
+	}
+
+	// Draw the RMS body inside the envelope
+	if config.showRMS && w != nil {
+		levels, err := w.GenerateRMS(WaveformOptions{
+			Start: config.start,
+			End:   config.end,
+			Width: effectiveWidth,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate RMS: %w", err)
+		}
+		rmsPoly, err := rmsPolygon(waveformData, levels)
+		if err != nil {
+			return nil, err
+		}
+		rmsPoly.Color = config.rmsColor
+		rmsPoly.LineStyle.Width = vg.Points(0) // No outline
+
+		p.Add(rmsPoly)
+	}
+
+	// Draw the pitch contour on top of the waveform
+	if config.pitchOverlay && w != nil {
+		points, err := TrackPitch(w, PitchOptions{Start: config.start, End: config.end})
+		if err != nil {
+			return nil, fmt.Errorf("failed to track pitch: %w", err)
+		}
+		p.Add(&pitchContour{
+			points:       points,
+			color:        config.pitchColor,
+			minFrequency: 60,
+			maxFrequency: 1000,
+		})
+	}
+
+	// Draw the phase correlation with about one value per four pixels
+	if config.phaseOverlay && w != nil {
+		window := math.Max(phaseMinWindow, (config.end-config.start)*4/float64(config.width))
+		startFrame := int(config.start * float64(w.SampleRate))
+		endFrame := min(int(config.end*float64(w.SampleRate)), w.availableFrames())
+		if values := w.phaseCorrelation(startFrame, endFrame, window); len(values) > 0 {
+			p.Add(&windowLine{
+				values: values,
+				start:  float64(startFrame) / float64(w.SampleRate),
+				window: window,
+				color:  config.phaseColor,
+			})
+		}
+	}
+
+	// Draw the weighted level in windows of at least four pixels
+	if config.levelOverlay && w != nil {
+		windowMs := max(DefaultLevelWindow, int(math.Ceil((config.end-config.start)*4000/float64(config.width))))
+		startFrame := int(config.start * float64(w.SampleRate))
+		endFrame := min(int(config.end*float64(w.SampleRate)), w.availableFrames())
+		if levels := w.levelTimeline(startFrame, endFrame, config.levelWeighting, windowMs); len(levels) > 0 {
+			values := make([]float64, len(levels))
+			for i, level := range levels {
+				values[i] = -1 + 2*(math.Max(level, levelOverlayFloor)-levelOverlayFloor)/-levelOverlayFloor
+			}
+			p.Add(&windowLine{
+				values: values,
+				start:  float64(startFrame) / float64(w.SampleRate),
+				window: float64(windowMs*w.SampleRate/1000) / float64(w.SampleRate),
+				color:  config.levelColor,
+			})
+		}
+	}
+
+	// Flag inter-sample overs at the edges of the waveform
+	if config.overs && w != nil {
+		startFrame := int(config.start * float64(w.SampleRate))
+		endFrame := min(int(config.end*float64(w.SampleRate)), w.availableFrames())
+		if _, positions := w.truePeak(startFrame, endFrame); len(positions) > 0 {
+			p.Add(&overMarks{times: positions, color: config.overColor})
+		}
+	}
+
+	// Draw the heat strip under the waveform in windows of at least four pixels
+	stripShown := false
+	if config.heatStrip != StripNone && w != nil {
+		window := math.Max(stripMinWindow, (config.end-config.start)*4/float64(config.width))
+		startFrame := int(config.start * float64(w.SampleRate))
+		endFrame := min(int(config.end*float64(w.SampleRate)), w.availableFrames())
+		if colors := w.stripColors(config.heatStrip, startFrame, endFrame, window); len(colors) > 0 {
+			p.Add(&heatStrip{
+				colors: colors,
+				start:  float64(startFrame) / float64(w.SampleRate),
+				end:    float64(endFrame) / float64(w.SampleRate),
+				window: math.Max(1, math.Round(window*float64(w.SampleRate))) / float64(w.SampleRate),
+			})
+			stripShown = true
+		}
+	}
+
+	// Draw the beat grid over the waveform so lines stay visible
+	if config.bpm > 0 {
+		p.Add(&beatGridLines{beats: BeatGrid(waveformData.Start, waveformData.End, config.bpm, config.beatOffset)})
+	}
+
+	// Draw markers over the waveform and beat grid
+	if len(config.markers) > 0 {
+		p.Add(&markerLines{markers: config.markers, start: waveformData.Start, end: waveformData.End, color: config.markerColor})
+	}
+
+	// Draw region labels over the waveform so they stay readable
+	if len(config.regions) > 0 {
+		p.Add(&regionLabels{regions: config.regions, start: waveformData.Start, end: waveformData.End, color: config.regionColor})
+	}
+
+	// Draw the playhead on top of everything else
+	if config.playhead >= waveformData.Start && config.playhead <= waveformData.End {
+		p.Add(&playheadLine{time: config.playhead, color: config.playheadColor})
+	}
+
+	// Set X axis range to match the view
+	p.X.Min = waveformData.Start
+	p.X.Max = waveformData.End
+
+	// Set Y axis range
+	p.Y.Min = -1.0
+	p.Y.Max = 1.0
+	if stripShown {
+		// Make room for the strip but keep the ticks on the amplitude range
+		p.Y.Min = stripBottom
+		if !config.hideYAxis {
+			p.Y.Tick.Marker = plot.TickerFunc(func(min, max float64) []plot.Tick {
+				return plot.DefaultTicks{}.Ticks(-1, 1)
+			})
+		}
+	}
+
+	return p, nil
+}
+
+// waveformPolygon builds a filled polygon tracing the max values left to right
+// and the min values right to left
+func waveformPolygon(waveformData *WaveformData) (*plotter.Polygon, error) {
 	// Create XY points from waveform data
 	// We'll use a polygon to create the filled waveform visualization
 	points := make(plotter.XYs, 0, len(waveformData.Data))
@@ -269,7 +883,7 @@ func SavePlot(w *Waveform, filename string, opts ...Option) error {
 
 		// Calculate time position for this pixel relative to the view start
 		samplePos := float64(i * samplesPerPixel)
-		timePos := config.start + (samplePos / float64(w.SampleRate))
+		timePos := waveformData.Start + (samplePos / float64(waveformData.SampleRate))
 
 		// Normalize amplitude to -1.0 to 1.0 range
 		maxNorm := float64(maxVal) / 32768.0
@@ -283,7 +897,7 @@ func SavePlot(w *Waveform, filename string, opts ...Option) error {
 		minVal := waveformData.Data[i*2]
 
 		samplePos := float64(i * samplesPerPixel)
-		timePos := config.start + (samplePos / float64(w.SampleRate))
+		timePos := waveformData.Start + (samplePos / float64(waveformData.SampleRate))
 		minNormVal := float64(minVal) / 32768.0
 
 		points = append(points, plotter.XY{X: timePos, Y: minNormVal})
@@ -292,41 +906,350 @@ func SavePlot(w *Waveform, filename string, opts ...Option) error {
 	// Create a polygon for filled waveform
 	poly, err := plotter.NewPolygon(points)
 	if err != nil {
-		return fmt.Errorf("failed to create polygon: %w", err)
+		return nil, fmt.Errorf("failed to create polygon: %w", err)
 	}
-	poly.Color = config.foregroundColor
-	poly.LineStyle.Width = vg.Points(0) // No outline
+	return poly, nil
+}
 
-	p.Add(poly)
+// rmsPolygon builds a filled polygon spanning +RMS to -RMS for each column,
+// limited to the min/max envelope so it never pokes outside the peaks
+func rmsPolygon(waveformData *WaveformData, levels []float64) (*plotter.Polygon, error) {
+	n := waveformData.Length
+	if len(levels) < n {
+		n = len(levels)
+	}
+	start := waveformData.Start
+	pixelDuration := float64(waveformData.SamplesPerPixel) / float64(waveformData.SampleRate)
 
-	// Set X axis range to match the view
-	p.X.Min = config.start
-	p.X.Max = config.end
+	points := make(plotter.XYs, 0, n*2)
+	for i := 0; i < n; i++ {
+		top := math.Min(levels[i], float64(waveformData.Data[i*2+1])/32768.0)
+		points = append(points, plotter.XY{X: start + float64(i)*pixelDuration, Y: top})
+	}
+	for i := n - 1; i >= 0; i-- {
+		bottom := math.Max(-levels[i], float64(waveformData.Data[i*2])/32768.0)
+		points = append(points, plotter.XY{X: start + float64(i)*pixelDuration, Y: bottom})
+	}
 
-	// Set Y axis range
-	p.Y.Min = -1.0
-	p.Y.Max = 1.0
+	poly, err := plotter.NewPolygon(points)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create RMS polygon: %w", err)
+	}
+	return poly, nil
+}
 
-	// Determine file format from extension
-	ext := strings.ToLower(filepath.Ext(filename))
-	
-	// Convert pixels to vg.Length (assuming 96 DPI)
-	width := vg.Length(config.width) * vg.Inch / 96
-	height := vg.Length(config.height) * vg.Inch / 96
+// spectralColumns draws each min/max column of a view filled with the color of its band energies
+type spectralColumns struct {
+	data     *WaveformData
+	energies []BandEnergy
+}
 
-	// Save the plot
-	switch ext {
-	case ".png":
-		if err := p.Save(width, height, filename); err != nil {
-			return fmt.Errorf("failed to save PNG: %w", err)
+// Plot implements the plot.Plotter interface
+func (s *spectralColumns) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	pixelDuration := float64(s.data.SamplesPerPixel) / float64(s.data.SampleRate)
+
+	for i := 0; i < s.data.Length && i < len(s.energies); i++ {
+		x0 := trX(s.data.Start + float64(i)*pixelDuration)
+		x1 := trX(s.data.Start + float64(i+1)*pixelDuration)
+		yMin := trY(float64(s.data.Data[i*2]) / 32768.0)
+		yMax := trY(float64(s.data.Data[i*2+1]) / 32768.0)
+
+		column := []vg.Point{
+			{X: x0, Y: yMin},
+			{X: x1, Y: yMin},
+			{X: x1, Y: yMax},
+			{X: x0, Y: yMax},
 		}
-	case ".jpg", ".jpeg":
-		if err := p.Save(width, height, filename); err != nil {
-			return fmt.Errorf("failed to save JPEG: %w", err)
+		c.FillPolygon(s.energies[i].Color(), c.ClipPolygonXY(column))
+	}
+}
+
+// pitchContour draws pitch estimates as line segments on a logarithmic frequency
+// scale mapped onto the amplitude range; unvoiced points break the line
+type pitchContour struct {
+	points       []PitchPoint
+	color        color.Color
+	minFrequency float64
+	maxFrequency float64
+}
+
+// Plot implements the plot.Plotter interface
+func (pc *pitchContour) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	logMin := math.Log(pc.minFrequency)
+	logRange := math.Log(pc.maxFrequency) - logMin
+
+	style := draw.LineStyle{Color: pc.color, Width: vg.Points(1.5)}
+	var segment []vg.Point
+	flush := func() {
+		if len(segment) > 1 {
+			c.StrokeLines(style, c.ClipLinesXY(segment)...)
 		}
-	default:
-		return fmt.Errorf("unsupported file format: %s (supported: .png, .jpg, .jpeg)", ext)
+		segment = segment[:0]
+	}
+
+	for _, pt := range pc.points {
+		if pt.Frequency <= 0 {
+			flush()
+			continue
+		}
+		y := -1 + 2*(math.Log(pt.Frequency)-logMin)/logRange
+		segment = append(segment, vg.Point{X: trX(pt.Time), Y: trY(y)})
+	}
+	flush()
+}
+
+// phaseMinWindow is the shortest window in seconds of the phase correlation overlay
+const phaseMinWindow = 0.01
+
+// levelOverlayFloor is the level in dBFS at the bottom of the level overlay
+const levelOverlayFloor = -60.0
+
+// windowLine draws values on the amplitude scale, such as phase correlations, as a
+// line with one point in the middle of each window
+type windowLine struct {
+	values []float64
+	start  float64
+	window float64
+	color  color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (pl *windowLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	points := make([]vg.Point, len(pl.values))
+	for i, v := range pl.values {
+		points[i] = vg.Point{X: trX(pl.start + (float64(i)+0.5)*pl.window), Y: trY(v)}
+	}
+	if len(points) == 1 {
+		points = append(points, points[0])
+	}
+	c.StrokeLines(draw.LineStyle{Color: pl.color, Width: vg.Points(1.5)}, c.ClipLinesXY(points)...)
+}
+
+// overMarkLength is the length of the inter-sample over flags on the amplitude scale
+const overMarkLength = 0.15
+
+// overMarks flags inter-sample overs with short lines down from the top and up from
+// the bottom of the amplitude range
+type overMarks struct {
+	times []float64
+	color color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (om *overMarks) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, trY := plt.Transforms(&c)
+	style := draw.LineStyle{Color: om.color, Width: vg.Points(1.5)}
+	for _, t := range om.times {
+		x := trX(t)
+		c.StrokeLines(style, c.ClipLinesXY([]vg.Point{{X: x, Y: trY(1)}, {X: x, Y: trY(1 - overMarkLength)}})...)
+		c.StrokeLines(style, c.ClipLinesXY([]vg.Point{{X: x, Y: trY(-1)}, {X: x, Y: trY(-1 + overMarkLength)}})...)
+	}
+}
+
+// maxIntervalTicks limits the number of ticks generated for a fixed tick interval
+const maxIntervalTicks = 1000
+
+// timeTicker returns an x-axis ticker that places ticks at the configured interval
+// (or the library's default positions) and labels them with the configured formatter
+// or time format
+func timeTicker(config PlotConfig, sampleRate int) plot.Ticker {
+	return plot.TickerFunc(func(min, max float64) []plot.Tick {
+		var ticks []plot.Tick
+		if config.xTickInterval > 0 {
+			first := math.Ceil(min/config.xTickInterval - 1e-9)
+			for n := first; n*config.xTickInterval <= max+1e-9 && len(ticks) < maxIntervalTicks; n++ {
+				// Round away floating point noise from the multiplication
+				value := math.Round(n*config.xTickInterval*1e9) / 1e9
+				if value == 0 {
+					value = 0 // Avoid labeling negative zero
+				}
+				ticks = append(ticks, plot.Tick{Value: value, Label: strconv.FormatFloat(value, 'f', -1, 64)})
+			}
+		} else {
+			ticks = plot.DefaultTicks{}.Ticks(min, max)
+			if config.deterministic {
+				for i := range ticks {
+					ticks[i].Value = math.Round(ticks[i].Value*1e9) / 1e9
+					if ticks[i].Label != "" {
+						ticks[i].Label = strconv.FormatFloat(ticks[i].Value, 'f', -1, 64)
+					}
+				}
+			}
+		}
+
+		for i := range ticks {
+			if ticks[i].Label == "" {
+				continue // Minor tick
+			}
+			if config.xTickFormatter != nil {
+				ticks[i].Label = config.xTickFormatter(ticks[i].Value)
+			} else if config.timeFormat != TimeFormatSeconds {
+				ticks[i].Label = FormatTime(ticks[i].Value, config.timeFormat, sampleRate, config.frameRate)
+			}
+		}
+		return ticks
+	})
+}
+
+// pinnedFonts holds the bundled fonts used by deterministic plots, independent of the
+// gonum/plot default font cache that applications may change
+var pinnedFonts = font.NewCache(liberation.Collection())
+
+// customTypeface names the font given with OptionSetFont in its private cache
+const customTypeface = "gowaveform-custom"
+
+// styleText applies the configured font, size and colors to every text style of a
+// plot. Without a custom font, deterministic plots use Liberation Sans from
+// pinnedFonts and others the gonum/plot default font. Tick labels keep the gonum/plot
+// proportion of 10/12 of the title and axis label size.
+func styleText(p *plot.Plot, config PlotConfig) error {
+	fnt := plot.DefaultFont
+	handler := p.TextHandler
+	switch {
+	case config.fontData != nil:
+		face, err := opentype.Parse(config.fontData)
+		if err != nil {
+			return fmt.Errorf("failed to parse font: %w", err)
+		}
+		fnt = font.Font{Typeface: customTypeface}
+		handler = text.Plain{Fonts: font.NewCache(font.Collection{{Font: fnt, Face: face}})}
+	case config.deterministic:
+		fnt = font.Font{Typeface: "Liberation", Variant: "Sans"}
+		handler = text.Plain{Fonts: pinnedFonts}
 	}
 
+	labelSize := vg.Points(12)
+	if config.fontSize > 0 {
+		labelSize = vg.Points(config.fontSize)
+	}
+	tickSize := labelSize * 10 / 12
+
+	p.TextHandler = handler
+	for _, style := range []*text.Style{&p.Title.TextStyle, &p.Legend.TextStyle, &p.X.Label.TextStyle, &p.Y.Label.TextStyle} {
+		style.Handler = handler
+		style.Font = font.From(fnt, labelSize)
+		if config.labelColor != nil {
+			style.Color = config.labelColor
+		}
+	}
+	for _, axis := range []*plot.Axis{&p.X, &p.Y} {
+		axis.Tick.Label.Handler = handler
+		axis.Tick.Label.Font = font.From(fnt, tickSize)
+		if config.tickColor != nil {
+			axis.Tick.Label.Color = config.tickColor
+			axis.Tick.LineStyle.Color = config.tickColor
+			axis.LineStyle.Color = config.tickColor
+		}
+	}
 	return nil
 }
+
+// timeAxisLabel returns the x-axis label for a time format
+func timeAxisLabel(format TimeFormat, frameRate float64) string {
+	switch format {
+	case TimeFormatMinutes:
+		return "Time (mm:ss.mmm)"
+	case TimeFormatTimecode:
+		return fmt.Sprintf("Timecode (%g fps)", frameRate)
+	case TimeFormatSamples:
+		return "Time (samples)"
+	default:
+		return "Time (seconds)"
+	}
+}
+
+// maxBeatLabels is the most tick labels shown on a beat-grid x-axis
+const maxBeatLabels = 16
+
+// beatTicks converts beats to axis ticks, labeling every beat when there is room,
+// otherwise only every nth bar; unlabeled beats become minor ticks
+func beatTicks(beats []Beat) []plot.Tick {
+	ticks := make([]plot.Tick, 0, len(beats))
+
+	barStep := 0 // 0 means every beat is labeled
+	if len(beats) > maxBeatLabels {
+		bars := len(beats)/BeatsPerBar + 1
+		barStep = (bars + maxBeatLabels - 1) / maxBeatLabels
+	}
+
+	for _, b := range beats {
+		tick := plot.Tick{Value: b.Time}
+		if barStep == 0 {
+			tick.Label = b.Label()
+		} else if b.IsDownbeat() && (b.Bar-1)%barStep == 0 {
+			tick.Label = b.Label()
+		}
+		ticks = append(ticks, tick)
+	}
+
+	return ticks
+}
+
+// beatGridLines draws a vertical line at every beat, with stronger lines on downbeats
+type beatGridLines struct {
+	beats []Beat
+}
+
+// Plot implements the plot.Plotter interface
+func (g *beatGridLines) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+
+	// Semi-transparent gray stays visible on both light and dark backgrounds
+	barStyle := draw.LineStyle{Color: color.NRGBA{R: 128, G: 128, B: 128, A: 220}, Width: vg.Points(1)}
+	beatStyle := draw.LineStyle{Color: color.NRGBA{R: 128, G: 128, B: 128, A: 110}, Width: vg.Points(0.5)}
+
+	for _, b := range g.beats {
+		x := trX(b.Time)
+		style := beatStyle
+		if b.IsDownbeat() {
+			style = barStyle
+		}
+		c.StrokeLine2(style, x, c.Min.Y, x, c.Max.Y)
+	}
+}
+
+// playheadLine draws a vertical line marking the playback position
+type playheadLine struct {
+	time  float64
+	color color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (ph *playheadLine) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+	x := trX(ph.time)
+	c.StrokeLine2(draw.LineStyle{Color: ph.color, Width: vg.Points(2)}, x, c.Min.Y, x, c.Max.Y)
+}
+
+// markerLines draws a vertical line with an optional label for each marker in view
+type markerLines struct {
+	markers    []Marker
+	start, end float64
+	color      color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (ml *markerLines) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+	lineStyle := draw.LineStyle{Color: ml.color, Width: vg.Points(1.5)}
+
+	// Label with the tick label font so the size follows the rest of the plot
+	labelStyle := plt.X.Tick.Label
+	labelStyle.Color = ml.color
+	labelStyle.XAlign = draw.XLeft
+	labelStyle.YAlign = draw.YTop
+
+	for _, m := range ml.markers {
+		if m.Time < ml.start || m.Time > ml.end {
+			continue
+		}
+		x := trX(m.Time)
+		c.StrokeLine2(lineStyle, x, c.Min.Y, x, c.Max.Y)
+		if m.Label != "" {
+			c.FillText(labelStyle, vg.Point{X: x + vg.Points(2), Y: c.Max.Y - vg.Points(2)}, m.Label)
+		}
+	}
+}
@@ -1,12 +1,18 @@
 package gowaveform
 
 import (
+	"bytes"
+	"fmt"
 	"image"
 	"image/color"
 	"image/jpeg"
 	"image/png"
 	"os"
 	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/font"
 )
 
 // Helper function to verify an image file exists and can be opened
@@ -121,6 +127,50 @@ func TestSavePlotWithWidth(t *testing.T) {
 	}
 }
 
+func TestSavePlotDPI(t *testing.T) {
+	tmpWav := "/tmp/test_plot_dpi.wav"
+	tmpPlot := "/tmp/test_plot_dpi.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpWav, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          []Option
+		width, height int
+	}{
+		{"retina", []Option{OptionSetWidth(1600), OptionSetHeight(800), OptionSetDPI(192)}, 1600, 800},
+		{"inches", []Option{OptionSetSizeInches(2, 1), OptionSetDPI(300)}, 600, 300},
+		{"centimeters", []Option{OptionSetDPI(300), OptionSetSizeCentimeters(5.08, 2.54)}, 600, 300},
+	}
+
+	for _, tt := range tests {
+		if err := SavePlot(waveform, tmpPlot, tt.opts...); err != nil {
+			t.Fatalf("%s: SavePlot failed: %v", tt.name, err)
+		}
+		data, err := os.ReadFile(tmpPlot)
+		if err != nil {
+			t.Fatalf("%s: failed to read image: %v", tt.name, err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: failed to decode PNG: %v", tt.name, err)
+		}
+		if b := img.Bounds(); b.Dx() != tt.width || b.Dy() != tt.height {
+			t.Errorf("%s: expected exactly %dx%d, got %dx%d", tt.name, tt.width, tt.height, b.Dx(), b.Dy())
+		}
+		if !bytes.Contains(data, []byte("pHYs")) {
+			t.Errorf("%s: expected a pHYs chunk with the DPI", tt.name)
+		}
+	}
+}
+
 func TestSavePlotWithHeight(t *testing.T) {
 	tmpWav := "/tmp/test_plot_height.wav"
 	tmpPlot := "/tmp/test_plot_height.png"
@@ -482,3 +532,569 @@ func TestSavePlotWithTitleAndHiddenYAxis(t *testing.T) {
 	// Verify the file was created
 	verifyImageFile(t, tmpPlot)
 }
+
+func TestSavePlotSpectral(t *testing.T) {
+	tmpWav := "/tmp/test_plot_spectral.wav"
+	tmpPlot := "/tmp/test_plot_spectral.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a low tone so the columns are drawn red
+	createToneWAV(t, tmpWav, 44100, 1.0, 60)
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// Save with frequency coloring
+	err = SavePlot(waveform, tmpPlot, OptionSpectral(true), OptionHideXAxis(true), OptionHideYAxis(true))
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// The center row crosses the waveform, so it should contain red pixels
+	bounds := img.Bounds()
+	y := (bounds.Min.Y + bounds.Max.Y) / 2
+	foundRed := false
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, g, b, _ := img.At(x, y).RGBA()
+		if r>>8 > 200 && g>>8 < 80 && b>>8 < 80 {
+			foundRed = true
+			break
+		}
+	}
+	if !foundRed {
+		t.Error("Expected red columns for a low-frequency tone")
+	}
+}
+
+func TestSavePlotPitchOverlay(t *testing.T) {
+	tmpWav := "/tmp/test_plot_pitch.wav"
+	tmpPlot := "/tmp/test_plot_pitch.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a tone with a stable pitch
+	createToneWAV(t, tmpWav, 44100, 1.0, 220)
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// Save with the pitch contour overlaid
+	err = SavePlot(waveform, tmpPlot, OptionPitchOverlay(true), OptionSetPitchColor("#FF0000"))
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	// Verify the file was created
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotPhaseCorrelation(t *testing.T) {
+	tmpWav := "/tmp/test_plot_phase.wav"
+	tmpPlot := "/tmp/test_plot_phase.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Stereo with the right channel inverted in the second half
+	tone := sineSamples(44100, 2, 220, 0.5, 1.0)
+	for i := len(tone) / 2; i < len(tone); i += 2 {
+		tone[i+1] = -tone[i+1]
+	}
+	writeTestWAV(t, tmpWav, 44100, 2, tone)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot, OptionPhaseCorrelation(true), OptionSetPhaseColor("#FF0000"))
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotLevelOverlay(t *testing.T) {
+	tmpWav := "/tmp/test_plot_level.wav"
+	tmpPlot := "/tmp/test_plot_level.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// A tone that is quiet for the first half
+	tone := sineSamples(44100, 1, 440, 0.5, 2.0)
+	for i := range tone[:len(tone)/2] {
+		tone[i] /= 100
+	}
+	writeTestWAV(t, tmpWav, 44100, 1, tone)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionLevelOverlay(true),
+		OptionSetLevelWeighting(WeightingC),
+		OptionSetLevelColor("#FF0000"),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotTruePeakOvers(t *testing.T) {
+	tmpWav := "/tmp/test_plot_overs.wav"
+	tmpPlot := "/tmp/test_plot_overs.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Silence with a short burst whose peaks lie above full scale between the samples
+	samples := make([]int16, 44100)
+	for i, v := range hotSamples(40, 1.2) {
+		samples[22050+i] = int16(v * 32768)
+	}
+	writeTestWAV(t, tmpWav, 44100, 1, samples)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionTruePeakOvers(true),
+		OptionSetOverColor("#FF00FF"),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotSymmetric(t *testing.T) {
+	tmpFile := "/tmp/test_plot_symmetric.wav"
+	tmpPlot := "/tmp/test_plot_symmetric.png"
+	defer os.Remove(tmpFile)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if err := SavePlot(waveform, tmpPlot, OptionSymmetric(true)); err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotDeterministic(t *testing.T) {
+	tmpFile := "/tmp/test_plot_deterministic.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	render := func(filename string, opts ...LoadOption) []byte {
+		t.Helper()
+		defer os.Remove(filename)
+
+		waveform, err := LoadWaveform(tmpFile, opts...)
+		if err != nil {
+			t.Fatalf("LoadWaveform failed: %v", err)
+		}
+		if err := SavePlot(waveform, filename, OptionDeterministic(true), OptionSetTitle("Test")); err != nil {
+			t.Fatalf("SavePlot failed: %v", err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatalf("Failed to read plot: %v", err)
+		}
+		return data
+	}
+
+	first := render("/tmp/test_plot_deterministic_1.png")
+
+	// Neither a progressive load nor a different default font changes the output
+	defaultFont := plot.DefaultFont
+	plot.DefaultFont = font.Font{Typeface: "Liberation", Variant: "Serif"}
+	defer func() { plot.DefaultFont = defaultFont }()
+	second := render("/tmp/test_plot_deterministic_2.png", OptionProgressive())
+
+	if !bytes.Equal(first, second) {
+		t.Error("Expected byte-identical PNG output in deterministic mode")
+	}
+}
+
+func TestSavePlotFontAndColors(t *testing.T) {
+	tmpFile := "/tmp/test_plot_font.wav"
+	tmpPlot := "/tmp/test_plot_font.png"
+	defer os.Remove(tmpFile)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionSetTitle("Brand"),
+		OptionSetFont(goregular.TTF),
+		OptionSetFontSize(16),
+		OptionSetLabelColor("#00FF00"),
+		OptionSetTickColor("#FF00FF"),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open image: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// The thin axis lines are antialiased onto the white background, so look for magenta tints
+	found := false
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y && !found; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, g, b, _ := img.At(x, y).RGBA(); r > 0xF000 && b > 0xF000 && g < 0xA000 {
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		t.Error("Expected axis lines in the tick color")
+	}
+
+	if err := SavePlot(waveform, tmpPlot, OptionSetFont([]byte("not a font"))); err == nil {
+		t.Error("Expected an error for an invalid font")
+	}
+}
+
+func TestSavePlotBeatGrid(t *testing.T) {
+	const amenFile = "data/amen_170.wav"
+	tmpPlot := "/tmp/test_plot_beat_grid.png"
+	defer os.Remove(tmpPlot)
+
+	// Check if file exists, skip if not
+	if _, err := os.Stat(amenFile); os.IsNotExist(err) {
+		t.Skip("Skipping test: data/amen_170.wav not found")
+	}
+
+	waveform, err := LoadWaveform(amenFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// Save with a 170 BPM beat grid
+	err = SavePlot(waveform, tmpPlot, OptionBeatGrid(170, 0))
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	// Verify the file was created
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestBeatTicks(t *testing.T) {
+	// A short range labels every beat
+	ticks := beatTicks(BeatGrid(0, 2, 120, 0))
+	for _, tick := range ticks {
+		if tick.Label == "" {
+			t.Errorf("Expected all beats labeled in short range, tick at %.2f is not", tick.Value)
+		}
+	}
+
+	// A long range only labels some bars and leaves other beats as minor ticks
+	ticks = beatTicks(BeatGrid(0, 120, 120, 0))
+	labeled := 0
+	for _, tick := range ticks {
+		if tick.Label != "" {
+			labeled++
+		}
+	}
+	if labeled == 0 || labeled > maxBeatLabels {
+		t.Errorf("Expected between 1 and %d labels, got %d", maxBeatLabels, labeled)
+	}
+}
+
+func TestSavePlotTimeFormats(t *testing.T) {
+	tmpWav := "/tmp/test_plot_time_format.wav"
+	tmpPlot := "/tmp/test_plot_time_format.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a test WAV file
+	createTestWAV(t, tmpWav, 44100, 2.0)
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	for _, format := range []TimeFormat{TimeFormatMinutes, TimeFormatTimecode, TimeFormatSamples} {
+		err = SavePlot(waveform, tmpPlot, OptionTimeFormat(format), OptionSetFrameRate(25))
+		if err != nil {
+			t.Fatalf("SavePlot with %s format failed: %v", format, err)
+		}
+
+		// Verify the file was created
+		verifyImageFile(t, tmpPlot)
+	}
+}
+
+func TestTimeTickerInterval(t *testing.T) {
+	config := PlotConfig{
+		xTickInterval: 5,
+		xTickFormatter: func(seconds float64) string {
+			return fmt.Sprintf("%d:%02d", int(seconds)/60, int(seconds)%60)
+		},
+	}
+
+	ticks := timeTicker(config, 44100).Ticks(2, 21)
+	expected := []string{"0:05", "0:10", "0:15", "0:20"}
+	if len(ticks) != len(expected) {
+		t.Fatalf("Expected %d ticks, got %d", len(expected), len(ticks))
+	}
+	for i, label := range expected {
+		if ticks[i].Label != label {
+			t.Errorf("Tick %d: expected label %q, got %q", i, label, ticks[i].Label)
+		}
+	}
+}
+
+func TestTimeTickerIntervalWithoutFormatter(t *testing.T) {
+	config := PlotConfig{xTickInterval: 0.1}
+
+	ticks := timeTicker(config, 44100).Ticks(0, 0.35)
+	expected := []string{"0", "0.1", "0.2", "0.3"}
+	if len(ticks) != len(expected) {
+		t.Fatalf("Expected %d ticks, got %d", len(expected), len(ticks))
+	}
+	for i, label := range expected {
+		if ticks[i].Label != label {
+			t.Errorf("Tick %d: expected label %q, got %q", i, label, ticks[i].Label)
+		}
+	}
+}
+
+func TestSavePlotTickIntervalAndFormatter(t *testing.T) {
+	tmpWav := "/tmp/test_plot_ticks.wav"
+	tmpPlot := "/tmp/test_plot_ticks.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a test WAV file
+	createTestWAV(t, tmpWav, 44100, 2.0)
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	called := false
+	err = SavePlot(waveform, tmpPlot,
+		OptionXTickInterval(0.25),
+		OptionXTickFormatter(func(seconds float64) string {
+			called = true
+			return fmt.Sprintf("%.0fms", seconds*1000)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	if !called {
+		t.Error("Expected the tick formatter to be used")
+	}
+
+	// Verify the file was created
+	verifyImageFile(t, tmpPlot)
+}
+
+func TestSavePlotRMSOverlay(t *testing.T) {
+	tmpWav := "/tmp/test_plot_rms.wav"
+	tmpPlot := "/tmp/test_plot_rms.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a tone with a steady RMS level
+	createToneWAV(t, tmpWav, 44100, 1.0, 441)
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// Save with a red RMS body inside a black envelope
+	err = SavePlot(waveform, tmpPlot,
+		OptionShowRMS(true),
+		OptionSetForegroundColor("#000000"),
+		OptionSetRMSColor("#FF0000"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// The center of the plot is inside the RMS body
+	bounds := img.Bounds()
+	r, g, b, _ := img.At((bounds.Min.X+bounds.Max.X)/2, (bounds.Min.Y+bounds.Max.Y)/2).RGBA()
+	if r>>8 < 200 || g>>8 > 80 || b>>8 > 80 {
+		t.Errorf("Expected RMS color at the center, got (%d, %d, %d)", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestSavePlotPlayhead(t *testing.T) {
+	tmpWav := "/tmp/test_plot_playhead.wav"
+	tmpPlot := "/tmp/test_plot_playhead.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a silent file so only the playhead is drawn
+	writeTestWAV(t, tmpWav, 44100, 1, make([]int16, 44100))
+
+	// Load the waveform
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionPlayhead(0.5),
+		OptionSetPlayheadColor("#FF0000"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// Look for the red playhead along the upper part of the plot, away from the waveform
+	bounds := img.Bounds()
+	y := bounds.Min.Y + bounds.Dy()/4
+	foundRed := false
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, g, b, _ := img.At(x, y).RGBA()
+		if r>>8 > 200 && g>>8 < 80 && b>>8 < 80 {
+			foundRed = true
+			break
+		}
+	}
+	if !foundRed {
+		t.Error("Expected a red playhead line")
+	}
+}
+
+func TestSavePlotMarkers(t *testing.T) {
+	tmpWav := "/tmp/test_plot_markers.wav"
+	tmpPlot := "/tmp/test_plot_markers.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a silent file so only the markers are drawn
+	writeTestWAV(t, tmpWav, 44100, 1, make([]int16, 44100))
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionSetStart(0.2),
+		OptionSetEnd(0.8),
+		OptionMarkers([]Marker{{Time: 0.1}, {Time: 0.25, Label: "kick"}, {Time: 0.75}}),
+		OptionSetMarkerColor("#00FF00"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// Count the green lines crossing the lower part of the plot, below the labels.
+	// The marker at 0.1s is outside the view and must not be drawn.
+	bounds := img.Bounds()
+	y := bounds.Min.Y + bounds.Dy()*3/4
+	lines := 0
+	inLine := false
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, g, b, _ := img.At(x, y).RGBA()
+		green := g>>8 > 200 && r>>8 < 80 && b>>8 < 80
+		if green && !inLine {
+			lines++
+		}
+		inLine = green
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 marker lines in view, found %d", lines)
+	}
+}
@@ -0,0 +1,64 @@
+package gowaveform
+
+import "sync"
+
+// Pools of buffers reused across views and loads, so a server or a library scan
+// generating many views does not spend its time collecting per-request slices
+var (
+	peakPool   sync.Pool // *[]int16 peak data of released views
+	samplePool sync.Pool // *[]int16 sample buffers of released waveforms
+	bytePool   sync.Pool // *[]byte scratch space of the encoders
+)
+
+// getInt16s returns an empty slice with room for at least n values from pool, or a new
+// one if the pooled slice is too small
+func getInt16s(pool *sync.Pool, n int) []int16 {
+	if p, ok := pool.Get().(*[]int16); ok && cap(*p) >= n {
+		return (*p)[:0]
+	}
+	return make([]int16, 0, n)
+}
+
+// putInt16s returns s to pool for reuse; s must not be used afterwards
+func putInt16s(pool *sync.Pool, s []int16) {
+	if cap(s) > 0 {
+		pool.Put(&s)
+	}
+}
+
+// getBytes returns an empty scratch buffer from bytePool
+func getBytes() *[]byte {
+	if p, ok := bytePool.Get().(*[]byte); ok {
+		*p = (*p)[:0]
+		return p
+	}
+	b := make([]byte, 0, 4096)
+	return &b
+}
+
+// Release returns the peak buffers of the view to a pool shared by GenerateView, so
+// the next view reuses them instead of allocating. Call it once the view is encoded
+// and no longer needed, e.g. after WriteJSON in a request handler. The view must not
+// be used afterwards; its Data and band peaks are set to nil.
+func (d *WaveformData) Release() {
+	putInt16s(&peakPool, d.Data)
+	d.Data = nil
+	for i := range d.Bands {
+		putInt16s(&peakPool, d.Bands[i].Data)
+		d.Bands[i].Data = nil
+	}
+}
+
+// releaseAudio returns the sample buffer of a waveform that is no longer used to a
+// pool shared by loads, e.g. once a library scan has taken its overview. It must only
+// be called on waveforms never handed to callers.
+func (w *Waveform) releaseAudio() {
+	if w.Loading() {
+		return
+	}
+	putInt16s(&samplePool, w.audioData)
+	w.audioData = nil
+	w.totalSamples = 0
+	w.overview = nil
+	w.track()
+}
@@ -0,0 +1,84 @@
+package gowaveform
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestReleaseReusesPeaks(t *testing.T) {
+	w := toneWaveform(44100, 2, 440, 0.5, 1)
+	opts := WaveformOptions{Width: 200, Bands: []float64{1000}}
+
+	first, err := w.GenerateView(opts)
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	expected := slices.Clone(first.Data)
+	first.Release()
+	if first.Data != nil || first.Bands[0].Data != nil {
+		t.Error("Expected Release to clear the peaks")
+	}
+
+	// Views built from reused buffers hold no stale peaks
+	for range 3 {
+		view, err := w.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("GenerateView failed: %v", err)
+		}
+		if !slices.Equal(view.Data, expected) || len(view.Bands[0].Data) != len(expected) {
+			t.Fatal("Expected the same peaks from a reused buffer")
+		}
+		view.Release()
+	}
+}
+
+func TestWriteJSONPooledWriters(t *testing.T) {
+	data := &WaveformData{Version: 2, Channels: 1, SampleRate: 44100, SamplesPerPixel: 256, Bits: 16, Length: 2, Data: []int16{-5, 5, -10, 10}}
+	expected, err := GenerateJSON(data)
+	if err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+
+	// A failed write must not leave its error or buffered output to the next writer
+	WriteJSON(failingWriter{}, &WaveformData{Version: 2, Data: make([]int16, 100000)})
+	for range 3 {
+		var compact, indented bytes.Buffer
+		if err := WriteJSONCompact(&compact, data); err != nil {
+			t.Fatalf("WriteJSONCompact failed: %v", err)
+		}
+		if err := WriteJSON(&indented, data); err != nil {
+			t.Fatalf("WriteJSON failed: %v", err)
+		}
+		if !bytes.Equal(indented.Bytes(), expected) {
+			t.Fatalf("Expected output identical to GenerateJSON, got %s", indented.String())
+		}
+		if compact.String() != `{"version":2,"channels":1,"sample_rate":44100,"samples_per_pixel":256,"bits":16,"length":2,"data":[-5,5,-10,10]}` {
+			t.Fatalf("Unexpected compact output %s", compact.String())
+		}
+	}
+}
+
+func TestReleaseAudio(t *testing.T) {
+	w := toneWaveform(44100, 1, 440, 0.5, 1).track()
+	w.releaseAudio()
+	if w.audioData != nil || w.totalSamples != 0 || w.memory.Load() != 0 {
+		t.Error("Expected the samples to be released and no longer counted")
+	}
+}
+
+func BenchmarkGenerateViewJSONPooled(b *testing.B) {
+	w := toneWaveform(44100, 2, 440, 0.5, 10)
+	b.ReportAllocs()
+	for b.Loop() {
+		data, err := w.GenerateView(WaveformOptions{Width: 2000})
+		if err != nil {
+			b.Fatalf("GenerateView failed: %v", err)
+		}
+		if err := WriteJSON(io.Discard, data); err != nil {
+			b.Fatalf("WriteJSON failed: %v", err)
+		}
+		data.Release()
+	}
+}
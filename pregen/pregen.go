@@ -0,0 +1,503 @@
+// Package pregen generates peak files for audio in the background: an overview and a
+// set of standard zoom levels per file, written by a pool of workers. Completed work
+// is recorded in a state file in the output directory, so files that have not changed
+// are skipped after a restart, and failed files are retried with a backoff.
+//
+//	q, err := pregen.New("peaks", pregen.OptionZoomLevels(256, 1024, 4096))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer q.Close()
+//	go q.Watch(ctx, "audio", 10*time.Second)
+package pregen
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// Defaults used unless options are given
+const (
+	DefaultOverviewWidth = 1000        // Width of the overview in pixels
+	DefaultRetries       = 3           // Retries after a failed attempt
+	DefaultRetryDelay    = time.Second // Wait before the first retry, doubled for each one
+)
+
+// StateFile is the name of the file in the output directory recording completed work
+const StateFile = "pregen.json"
+
+// ErrClosed is returned when adding files to a closed queue
+var ErrClosed = errors.New("queue closed")
+
+// audioExtensions are the files Watch picks up
+var audioExtensions = map[string]bool{
+	".wav": true, ".mp3": true, ".flac": true, ".ogg": true, ".aif": true, ".aiff": true,
+}
+
+// config holds the configuration of a queue
+type config struct {
+	workers       int
+	overviewWidth int
+	zoomLevels    []int // Samples per pixel of the zoom levels
+	format        string
+	retries       int
+	retryDelay    time.Duration
+	loadOpts      []gowaveform.LoadOption
+	onDone        func(file string, err error)
+}
+
+// Option is the type all queue options need to adhere to
+type Option func(*config)
+
+// OptionWorkers sets how many files are generated at once (default: the number of CPUs)
+func OptionWorkers(n int) Option {
+	return func(c *config) {
+		c.workers = n
+	}
+}
+
+// OptionOverviewWidth sets the width in pixels of the overview of each file (default:
+// 1000); 0 skips the overview
+func OptionOverviewWidth(width int) Option {
+	return func(c *config) {
+		c.overviewWidth = width
+	}
+}
+
+// OptionZoomLevels sets the zoom levels generated for each file, in samples per pixel
+// (default: 256, 1024 and 4096)
+func OptionZoomLevels(samplesPerPixel ...int) Option {
+	return func(c *config) {
+		c.zoomLevels = append([]int{}, samplesPerPixel...)
+	}
+}
+
+// OptionFormat sets the format of the peak files by extension: ".dat" for
+// audiowaveform binary (default) or ".json"
+func OptionFormat(ext string) Option {
+	return func(c *config) {
+		c.format = ext
+	}
+}
+
+// OptionRetries sets how often a failed file is retried (default: 3) and the wait
+// before the first retry (default: one second), which doubles for each further one
+func OptionRetries(n int, delay time.Duration) Option {
+	return func(c *config) {
+		c.retries = n
+		c.retryDelay = delay
+	}
+}
+
+// OptionLoad adds options for loading the audio, e.g. gowaveform.OptionFFmpegFallback
+func OptionLoad(opts ...gowaveform.LoadOption) Option {
+	return func(c *config) {
+		c.loadOpts = append(c.loadOpts, opts...)
+	}
+}
+
+// OptionOnDone sets a function called from the worker whenever a file is finished,
+// with nil or the error of its last attempt
+func OptionOnDone(fn func(file string, err error)) Option {
+	return func(c *config) {
+		c.onDone = fn
+	}
+}
+
+// Status is the recorded state of a file
+type Status struct {
+	Size     int64     `json:"size"`               // Size of the file when it was generated
+	ModTime  time.Time `json:"mod_time"`           // Modification time of the file when it was generated
+	Done     bool      `json:"done"`               // All peak files were written
+	Attempts int       `json:"attempts,omitempty"` // Attempts made for this version of the file
+	Error    string    `json:"error,omitempty"`    // Error of the last failed attempt
+}
+
+// Queue generates peak files for the files added to it with a pool of workers
+type Queue struct {
+	outDir string
+	config config
+
+	mu      sync.Mutex
+	cond    *sync.Cond        // Signaled when jobs are queued or finished
+	pending []string          // Files waiting for a worker
+	queued  map[string]bool   // Files pending or in progress
+	active  int               // Files in progress
+	state   map[string]Status // Recorded state by absolute path
+	closed  bool
+	done    chan struct{} // Closed by Close to stop retries
+	workers sync.WaitGroup
+}
+
+// New starts a queue writing peak files to outDir, which is created if needed, and
+// reads the work recorded there by earlier runs
+func New(outDir string, opts ...Option) (*Queue, error) {
+	c := config{
+		workers:       runtime.NumCPU(),
+		overviewWidth: DefaultOverviewWidth,
+		zoomLevels:    []int{256, 1024, 4096},
+		format:        ".dat",
+		retries:       DefaultRetries,
+		retryDelay:    DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	switch {
+	case c.workers <= 0:
+		return nil, fmt.Errorf("invalid number of workers: %d", c.workers)
+	case c.overviewWidth < 0:
+		return nil, fmt.Errorf("invalid overview width: %d", c.overviewWidth)
+	case c.format != ".dat" && c.format != ".json":
+		return nil, fmt.Errorf("unsupported format %q: use .dat or .json", c.format)
+	case c.retries < 0 || c.retryDelay < 0:
+		return nil, fmt.Errorf("invalid retries: %d after %s", c.retries, c.retryDelay)
+	case c.overviewWidth == 0 && len(c.zoomLevels) == 0:
+		return nil, fmt.Errorf("nothing to generate: no overview and no zoom levels")
+	}
+	for _, spp := range c.zoomLevels {
+		if spp <= 0 {
+			return nil, fmt.Errorf("invalid zoom level: %d samples per pixel", spp)
+		}
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	q := &Queue{
+		outDir: outDir,
+		config: c,
+		queued: map[string]bool{},
+		state:  map[string]Status{},
+		done:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	data, err := os.ReadFile(filepath.Join(outDir, StateFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read state: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &q.state); err != nil {
+			return nil, fmt.Errorf("failed to read state: %w", err)
+		}
+	}
+
+	for range c.workers {
+		q.workers.Add(1)
+		go q.work()
+	}
+	return q, nil
+}
+
+// Add queues files for generation. Files already queued, generated and not changed
+// since, or failed on every attempt and not changed since, are skipped.
+func (q *Queue) Add(files ...string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return ErrClosed
+	}
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", file, err)
+		}
+		if q.queued[abs] || q.finished(abs) {
+			continue
+		}
+		q.queued[abs] = true
+		q.pending = append(q.pending, abs)
+	}
+	q.cond.Broadcast()
+	return nil
+}
+
+// finished reports whether the current version of a file was processed: its peak
+// files were generated and are still there, or it failed on every attempt. q.mu must
+// be held.
+func (q *Queue) finished(file string) bool {
+	status, ok := q.state[file]
+	if !ok {
+		return false
+	}
+	info, err := os.Stat(file)
+	if err != nil || info.Size() != status.Size || !info.ModTime().Equal(status.ModTime) {
+		return false
+	}
+	if !status.Done {
+		return status.Attempts > q.config.retries
+	}
+	for _, output := range q.outputs(file) {
+		if _, err := os.Stat(output); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch adds the audio files in dir and its subdirectories, and any that are created
+// or changed later, checking every interval until ctx is done. Files are added once
+// their size and modification time are the same on two checks in a row, so files
+// still being written are left alone.
+func (q *Queue) Watch(ctx context.Context, dir string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid interval: %s", interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	seen := map[string]Status{}
+	for {
+		current := map[string]Status{}
+		var stable []string
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() || !audioExtensions[strings.ToLower(filepath.Ext(path))] {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil // Removed since the directory was read
+			}
+			version := Status{Size: info.Size(), ModTime: info.ModTime()}
+			if previous, ok := seen[path]; ok && previous.Size == version.Size && previous.ModTime.Equal(version.ModTime) {
+				stable = append(stable, path)
+			}
+			current[path] = version
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", dir, err)
+		}
+		seen = current
+		if err := q.Add(stable...); err != nil {
+			return err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Status returns the recorded state of a file, and false if it has not been processed
+func (q *Queue) Status(file string) (Status, bool) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return Status{}, false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	status, ok := q.state[abs]
+	return status, ok
+}
+
+// Output returns the path of the peak file of a file for a zoom level in samples per
+// pixel, or of its overview for 0. Files are named after the audio file and a hash of
+// its absolute path, so files with the same name in different directories do not clash.
+func (q *Queue) Output(file string, samplesPerPixel int) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		abs = file
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs)) + "-" + hex.EncodeToString(sum[:4])
+	level := "overview"
+	if samplesPerPixel > 0 {
+		level = strconv.Itoa(samplesPerPixel)
+	}
+	return filepath.Join(q.outDir, name+"."+level+q.config.format)
+}
+
+// outputs returns the paths of all peak files of a file
+func (q *Queue) outputs(file string) []string {
+	var paths []string
+	if q.config.overviewWidth > 0 {
+		paths = append(paths, q.Output(file, 0))
+	}
+	for _, spp := range q.config.zoomLevels {
+		paths = append(paths, q.Output(file, spp))
+	}
+	return paths
+}
+
+// Wait blocks until all queued files are finished
+func (q *Queue) Wait() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for (len(q.pending) > 0 && !q.closed) || q.active > 0 {
+		q.cond.Wait()
+	}
+}
+
+// Close stops the workers once the files in progress are finished. Files still
+// waiting are dropped; they are queued again when added after a restart.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.workers.Wait()
+	return nil
+}
+
+// work runs jobs until the queue is closed
+func (q *Queue) work() {
+	defer q.workers.Done()
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		file := q.pending[0]
+		q.pending = q.pending[1:]
+		q.active++
+		q.mu.Unlock()
+
+		err := q.process(file)
+
+		q.mu.Lock()
+		q.active--
+		delete(q.queued, file)
+		q.cond.Broadcast()
+		q.mu.Unlock()
+
+		if q.config.onDone != nil && !errors.Is(err, ErrClosed) {
+			q.config.onDone(file, err)
+		}
+	}
+}
+
+// process generates the peak files of a file, retrying with a growing delay
+func (q *Queue) process(file string) error {
+	delay := q.config.retryDelay
+	for attempt := 0; ; attempt++ {
+		info, err := os.Stat(file)
+		if err == nil {
+			err = q.generate(file)
+		}
+
+		status := Status{Done: err == nil}
+		if info != nil {
+			status.Size, status.ModTime = info.Size(), info.ModTime()
+		}
+		q.mu.Lock()
+		if previous, ok := q.state[file]; ok && !previous.Done && previous.Size == status.Size && previous.ModTime.Equal(status.ModTime) {
+			status.Attempts = previous.Attempts
+		}
+		if err != nil {
+			status.Attempts++
+			status.Error = err.Error()
+		}
+		q.state[file] = status
+		saveErr := q.save()
+		q.mu.Unlock()
+
+		if err == nil {
+			return saveErr
+		}
+		if attempt >= q.config.retries {
+			return err
+		}
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-q.done:
+			return ErrClosed
+		}
+	}
+}
+
+// generate loads a file and writes its overview and zoom levels
+func (q *Queue) generate(file string) error {
+	w, err := gowaveform.LoadWaveform(file, q.config.loadOpts...)
+	if err != nil {
+		return err
+	}
+
+	var opts []gowaveform.WaveformOptions
+	if q.config.overviewWidth > 0 {
+		opts = append(opts, gowaveform.WaveformOptions{Width: q.config.overviewWidth})
+	}
+	for _, spp := range q.config.zoomLevels {
+		opts = append(opts, gowaveform.WaveformOptions{SamplesPerPixel: spp})
+	}
+	views, err := w.GenerateViews(opts)
+	if err != nil {
+		return fmt.Errorf("failed to generate views: %w", err)
+	}
+
+	for i, output := range q.outputs(file) {
+		if err := writeAtomic(output, func(f *os.File) error {
+			if q.config.format == ".json" {
+				return gowaveform.WriteJSON(f, views[i])
+			}
+			return gowaveform.WriteBinary(f, views[i])
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save writes the state file. q.mu must be held.
+func (q *Queue) save() error {
+	data, err := json.MarshalIndent(q.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return writeAtomic(filepath.Join(q.outDir, StateFile), func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// writeAtomic writes a file through a temporary file in the same directory, so
+// readers never see a partly written file
+func writeAtomic(filename string, write func(*os.File) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".pregen-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+package pregen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// testWAV returns one second of 16-bit mono audio with a quiet ramp, from the WAV
+// fixture shared with the other packages
+func testWAV() []byte {
+	data, err := os.ReadFile("../testdata/ramp.wav")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// writeFile writes data to name in dir and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// doneCounter counts the files finished by a queue
+type doneCounter struct {
+	mu    sync.Mutex
+	files map[string]int
+}
+
+func (d *doneCounter) onDone(file string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.files == nil {
+		d.files = map[string]int{}
+	}
+	d.files[file]++
+}
+
+func (d *doneCounter) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	n := 0
+	for _, c := range d.files {
+		n += c
+	}
+	return n
+}
+
+func TestQueueGenerates(t *testing.T) {
+	audio, out := t.TempDir(), t.TempDir()
+	song := writeFile(t, audio, "song.wav", testWAV())
+	// Same name in another directory
+	stem := writeFile(t, audio, "stems/song.wav", testWAV())
+
+	q, err := New(out, OptionWorkers(2), OptionOverviewWidth(100), OptionZoomLevels(80, 400))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+	if err := q.Add(song, stem, song); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	q.Wait()
+
+	if q.Output(song, 0) == q.Output(stem, 0) {
+		t.Errorf("Expected different outputs for files with the same name, got %s", q.Output(song, 0))
+	}
+	for _, file := range []string{song, stem} {
+		status, ok := q.Status(file)
+		if !ok || !status.Done || status.Error != "" {
+			t.Errorf("Expected %s to be done, got %+v", file, status)
+		}
+		for spp, length := range map[int]int{0: 100, 80: 100, 400: 20} {
+			f, err := os.Open(q.Output(file, spp))
+			if err != nil {
+				t.Fatalf("Missing output: %v", err)
+			}
+			data, err := gowaveform.ReadBinary(f)
+			f.Close()
+			if err != nil {
+				t.Fatalf("ReadBinary failed: %v", err)
+			}
+			if data.Length != length {
+				t.Errorf("Level %d: expected length %d, got %d", spp, length, data.Length)
+			}
+		}
+	}
+}
+
+func TestQueueFormat(t *testing.T) {
+	song := writeFile(t, t.TempDir(), "song.wav", testWAV())
+	q, err := New(t.TempDir(), OptionFormat(".json"), OptionZoomLevels())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+	q.Add(song)
+	q.Wait()
+
+	output := q.Output(song, 0)
+	if filepath.Ext(output) != ".json" {
+		t.Errorf("Expected a .json output, got %s", output)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Missing output: %v", err)
+	}
+
+	for _, opts := range [][]Option{
+		{OptionWorkers(0)},
+		{OptionFormat(".png")},
+		{OptionZoomLevels(0)},
+		{OptionOverviewWidth(0), OptionZoomLevels()},
+		{OptionRetries(-1, 0)},
+	} {
+		if _, err := New(t.TempDir(), opts...); err == nil {
+			t.Error("Expected an error for an invalid configuration")
+		}
+	}
+}
+
+func TestQueuePersistence(t *testing.T) {
+	audio, out := t.TempDir(), t.TempDir()
+	song := writeFile(t, audio, "song.wav", testWAV())
+
+	var done doneCounter
+	q, err := New(out, OptionOnDone(done.onDone))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	q.Add(song)
+	q.Wait()
+	q.Close()
+	if done.count() != 1 {
+		t.Fatalf("Expected one file done, got %d", done.count())
+	}
+
+	// Unchanged files are skipped after a restart
+	q, err = New(out, OptionOnDone(done.onDone))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+	if status, ok := q.Status(song); !ok || !status.Done {
+		t.Errorf("Expected the state to be restored, got %+v", status)
+	}
+	q.Add(song)
+	q.Wait()
+	if done.count() != 1 {
+		t.Errorf("Expected the unchanged file to be skipped, got %d done", done.count())
+	}
+
+	// Missing outputs and changed files are generated again
+	os.Remove(q.Output(song, 1024))
+	q.Add(song)
+	q.Wait()
+	if done.count() != 2 {
+		t.Errorf("Expected the file with a missing output to be generated, got %d done", done.count())
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(song, later, later)
+	q.Add(song)
+	q.Wait()
+	if done.count() != 3 {
+		t.Errorf("Expected the changed file to be generated, got %d done", done.count())
+	}
+}
+
+func TestQueueRetries(t *testing.T) {
+	audio, out := t.TempDir(), t.TempDir()
+	broken := writeFile(t, audio, "broken.wav", []byte("not a wav file"))
+
+	var done doneCounter
+	q, err := New(out, OptionRetries(2, time.Millisecond), OptionOnDone(done.onDone))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+	q.Add(broken)
+	q.Wait()
+
+	status, _ := q.Status(broken)
+	if status.Done || status.Attempts != 3 || status.Error == "" {
+		t.Errorf("Expected three failed attempts, got %+v", status)
+	}
+
+	// Given up on until the file changes
+	q.Add(broken)
+	q.Wait()
+	if done.count() != 1 {
+		t.Errorf("Expected the failed file to be skipped, got %d done", done.count())
+	}
+	writeFile(t, audio, "broken.wav", testWAV())
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(broken, later, later)
+	q.Add(broken)
+	q.Wait()
+	if status, _ := q.Status(broken); !status.Done || status.Attempts != 0 {
+		t.Errorf("Expected the fixed file to be done, got %+v", status)
+	}
+}
+
+func TestQueueWatch(t *testing.T) {
+	audio, out := t.TempDir(), t.TempDir()
+	song := writeFile(t, audio, "song.wav", testWAV())
+	writeFile(t, audio, "notes.txt", []byte("not audio"))
+
+	finished := make(chan string, 10)
+	q, err := New(out, OptionOnDone(func(file string, err error) {
+		finished <- filepath.Base(file)
+	}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer q.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	watched := make(chan error)
+	go func() {
+		watched <- q.Watch(ctx, audio, 10*time.Millisecond)
+	}()
+
+	expect := func(name string) {
+		t.Helper()
+		select {
+		case file := <-finished:
+			if file != name {
+				t.Errorf("Expected %s, got %s", name, file)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %s", name)
+		}
+	}
+	expect("song.wav")
+	writeFile(t, audio, "stems/bass.wav", testWAV())
+	expect("bass.wav")
+
+	cancel()
+	if err := <-watched; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if status, ok := q.Status(song); !ok || !status.Done {
+		t.Errorf("Expected %s to be done, got %+v", song, status)
+	}
+	select {
+	case file := <-finished:
+		t.Errorf("Unexpected file %s", file)
+	default:
+	}
+}
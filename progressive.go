@@ -0,0 +1,191 @@
+package gowaveform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+)
+
+// progressiveChunkFrames is the number of frames decoded per step when loading progressively
+const progressiveChunkFrames = 65536
+
+// OptionProgressive makes LoadWaveform return as soon as the header and the first chunk
+// of a WAV file are decoded and decode the rest in a background goroutine. Views and
+// analyses cover only the part loaded so far; use LoadedFraction to report progress and
+// Wait to block until decoding finishes. Other formats are always decoded fully.
+func OptionProgressive() LoadOption {
+	return func(c *loadConfig) {
+		c.progressive = true
+	}
+}
+
+// loadWAVProgressive decodes the header and first chunk of a WAV file and
+// continues decoding the rest in the background
+func loadWAVProgressive(filename string, c loadConfig) (*Waveform, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audio file: %w", err)
+	}
+
+	decoder := wav.NewDecoder(f)
+	if !decoder.IsValidFile() {
+		f.Close()
+		return nil, fmt.Errorf("failed to decode audio file: invalid WAV file")
+	}
+	if err := decoder.FwdToPCM(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to forward to PCM data: %w", err)
+	}
+
+	format := decoder.Format()
+	bitDepth := int(decoder.BitDepth)
+	if format.NumChannels <= 0 || bitDepth <= 0 {
+		f.Close()
+		return nil, fmt.Errorf("failed to decode audio file: invalid WAV format")
+	}
+	bytesPerFrame := format.NumChannels * ((bitDepth + 7) / 8)
+	totalFrames := int(decoder.PCMLen() / int64(bytesPerFrame))
+
+	// The samples are allocated up front, so never trust the header beyond the file size
+	if info, err := f.Stat(); err != nil || decoder.PCMLen() > info.Size() {
+		f.Close()
+		return nil, fmt.Errorf("failed to decode audio file: %w", ErrDataSizeMismatch)
+	}
+	if err := c.checkSamples(int64(totalFrames) * int64(format.NumChannels)); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to decode audio file: %w", err)
+	}
+
+	m := newChannelMixer(c.mono, format.NumChannels)
+	channels := format.NumChannels
+	if m != nil {
+		channels = 1
+	}
+	w := &Waveform{
+		SampleRate:    format.SampleRate,
+		Channels:      channels,
+		BitsPerSample: bitDepth,
+		audioData:     make([]int16, totalFrames*channels),
+		totalSamples:  totalFrames,
+		source:        filename,
+		loadDone:      make(chan struct{}),
+	}
+	w.track()
+
+	buf := &audio.IntBuffer{Format: format, Data: make([]int, progressiveChunkFrames*format.NumChannels)}
+	d := newDitherer(c.dither, bitDepth, format.NumChannels)
+	pos, done, err := w.decodeChunk(decoder, buf, d, m, bitDepth, 0)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read PCM data: %w", err)
+	}
+
+	decodeRest := func() error {
+		for !done {
+			if pos, done, err = w.decodeChunk(decoder, buf, d, m, bitDepth, pos); err != nil {
+				return fmt.Errorf("failed to read PCM data: %w", err)
+			}
+		}
+		if pos < totalFrames*format.NumChannels {
+			return fmt.Errorf("failed to read PCM data: file ends after %d of %d frames", pos/format.NumChannels, w.totalSamples)
+		}
+		return w.finishLoad(c)
+	}
+
+	// Resampling replaces the sample buffer, so it cannot happen while views read it
+	if c.maxSampleRate > 0 && w.SampleRate > c.maxSampleRate {
+		defer f.Close()
+		w.loadDone = nil
+		if err := decodeRest(); err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+
+	go func() {
+		defer close(w.loadDone)
+		defer f.Close()
+		w.loadErr = decodeRest()
+	}()
+
+	return w, nil
+}
+
+// decodeChunk decodes the next chunk of PCM data into the sample buffer starting at
+// sample pos of the file, dithered by d and mixed down by m unless they are nil, and
+// publishes the new number of loaded frames
+func (w *Waveform) decodeChunk(decoder *wav.Decoder, buf *audio.IntBuffer, d *ditherer, m *channelMixer, bitDepth, pos int) (int, bool, error) {
+	n, err := decoder.PCMBuffer(buf)
+	if err != nil {
+		return pos, true, err
+	}
+	channels := w.Channels
+	if m != nil {
+		channels = m.channels
+	}
+	end := w.totalSamples * channels
+	for _, sample := range buf.Data[:n] {
+		if pos >= end {
+			break
+		}
+		v := d.toInt16(sample, bitDepth, pos%channels)
+		if m == nil {
+			w.audioData[pos] = v
+		} else if mixed, ok := m.mix(pos%channels, v); ok {
+			w.audioData[pos/channels] = mixed
+		}
+		pos++
+	}
+	w.loadedFrames.Store(int64(pos / channels))
+	return pos, n == 0 || pos >= end, nil
+}
+
+// isWAVFile reports whether the file name has a WAV extension
+func isWAVFile(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), ".wav")
+}
+
+// availableFrames returns the number of frames that can be read, which is
+// less than the total while a progressive load is still decoding
+func (w *Waveform) availableFrames() int {
+	if w.loadDone == nil {
+		return w.totalSamples
+	}
+	return int(w.loadedFrames.Load())
+}
+
+// Loading reports whether a progressive load is still decoding in the background
+func (w *Waveform) Loading() bool {
+	if w.loadDone == nil {
+		return false
+	}
+	select {
+	case <-w.loadDone:
+		return false
+	default:
+		return true
+	}
+}
+
+// LoadedFraction returns the fraction of the audio decoded so far, from 0.0 to 1.0.
+// It is always 1.0 unless the waveform was loaded with OptionProgressive.
+func (w *Waveform) LoadedFraction() float64 {
+	if w.totalSamples == 0 {
+		return 1
+	}
+	return float64(w.availableFrames()) / float64(w.totalSamples)
+}
+
+// Wait blocks until a progressive load has finished and returns any error
+// from decoding the rest of the file. It returns nil immediately otherwise.
+func (w *Waveform) Wait() error {
+	if w.loadDone == nil {
+		return nil
+	}
+	<-w.loadDone
+	return w.loadErr
+}
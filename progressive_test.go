@@ -0,0 +1,119 @@
+package gowaveform
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestOptionProgressive(t *testing.T) {
+	tmpFile := "/tmp/test_progressive.wav"
+	defer os.Remove(tmpFile)
+
+	// Long enough to span several decode chunks
+	createTestWAV(t, tmpFile, 44100, 5.0)
+
+	waveform, err := LoadWaveform(tmpFile, OptionProgressive(), OptionPrecomputeOverview(100))
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if waveform.Duration() < 4.99 || waveform.Duration() > 5.01 {
+		t.Errorf("Expected full duration from the header, got %f", waveform.Duration())
+	}
+
+	// Views are served while decoding continues
+	if _, err := waveform.GenerateView(WaveformOptions{Width: 100}); err != nil {
+		t.Errorf("GenerateView during load failed: %v", err)
+	}
+
+	if err := waveform.Wait(); err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if waveform.Loading() {
+		t.Error("Expected loading to be finished after Wait")
+	}
+	if waveform.LoadedFraction() != 1 {
+		t.Errorf("Expected fully loaded, got %f", waveform.LoadedFraction())
+	}
+
+	full, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if !reflect.DeepEqual(waveform.audioData, full.audioData) {
+		t.Error("Expected progressive load to decode the same samples as a full load")
+	}
+
+	expected, err := full.GenerateView(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if !reflect.DeepEqual(waveform.Overview(), expected) {
+		t.Error("Expected overview to be built once loading finished")
+	}
+}
+
+func TestProgressivePartialView(t *testing.T) {
+	tmpFile := "/tmp/test_progressive_partial.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	full, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// Simulate a load that has decoded the first quarter
+	partial := &Waveform{
+		SampleRate:    full.SampleRate,
+		Channels:      full.Channels,
+		BitsPerSample: full.BitsPerSample,
+		audioData:     full.audioData,
+		totalSamples:  full.totalSamples,
+		loadDone:      make(chan struct{}),
+	}
+	partial.loadedFrames.Store(int64(full.totalSamples / 4))
+
+	if f := partial.LoadedFraction(); f < 0.24 || f > 0.26 {
+		t.Errorf("Expected loaded fraction ~0.25, got %f", f)
+	}
+	if !partial.Loading() {
+		t.Error("Expected partial load to report loading")
+	}
+	if partial.Overview() != nil {
+		t.Error("Expected no overview while loading")
+	}
+
+	view, err := partial.GenerateView(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	expected, _ := full.GenerateView(WaveformOptions{Width: 100})
+	if view.SamplesPerPixel != expected.SamplesPerPixel {
+		t.Errorf("Expected the full-range pixel layout (%d samples per pixel), got %d", expected.SamplesPerPixel, view.SamplesPerPixel)
+	}
+	if view.Length < 24 || view.Length > 26 {
+		t.Errorf("Expected ~25 loaded pixels, got %d", view.Length)
+	}
+	if !reflect.DeepEqual(view.Data, expected.Data[:len(view.Data)]) {
+		t.Error("Expected loaded pixels to match the full view")
+	}
+
+	// Ranges past the loaded part are empty rather than an error
+	view, err = partial.GenerateView(WaveformOptions{Start: 0.5, Width: 10})
+	if err != nil {
+		t.Fatalf("GenerateView past loaded range failed: %v", err)
+	}
+	if view.Length != 0 {
+		t.Errorf("Expected empty view past the loaded part, got %d pixels", view.Length)
+	}
+}
+
+func TestProgressiveFallback(t *testing.T) {
+	waveform := &Waveform{totalSamples: 10}
+	if waveform.LoadedFraction() != 1 || waveform.Wait() != nil {
+		t.Error("Expected waveforms loaded at once to report fully loaded")
+	}
+}
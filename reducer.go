@@ -0,0 +1,86 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// PeakReducer reduces a stream of interleaved int16 samples to min/max pairs
+// incrementally, so peaks can be pushed to clients as audio is ingested
+// instead of regenerating a view over the whole buffer.
+// Pixels use the same layout as GenerateView with a start time of 0.
+type PeakReducer struct {
+	channels        int
+	samplesPerPixel int
+	pending         int // Samples (across all channels) in the current pixel
+	min, max        int16
+	pixels          int
+}
+
+// NewPeakReducer creates a reducer for audio with the given number of channels
+// that emits one min/max pair per samplesPerPixel frames
+func NewPeakReducer(channels, samplesPerPixel int) (*PeakReducer, error) {
+	if channels <= 0 {
+		return nil, fmt.Errorf("invalid channel count: %d", channels)
+	}
+	if samplesPerPixel <= 0 {
+		return nil, fmt.Errorf("invalid samples per pixel: %d", samplesPerPixel)
+	}
+	return &PeakReducer{
+		channels:        channels,
+		samplesPerPixel: samplesPerPixel,
+		min:             math.MaxInt16,
+		max:             math.MinInt16,
+	}, nil
+}
+
+// Write adds interleaved samples and returns the min/max pairs of the pixels they completed.
+// Chunks do not need to be aligned to frames or pixels.
+func (r *PeakReducer) Write(samples []int16) []int16 {
+	var peaks []int16
+	pixelSize := r.samplesPerPixel * r.channels
+
+	for len(samples) > 0 {
+		n := pixelSize - r.pending
+		if n > len(samples) {
+			n = len(samples)
+		}
+		r.min, r.max = peakScan(samples[:n], r.min, r.max)
+		samples = samples[n:]
+		r.pending += n
+
+		if r.pending == pixelSize {
+			peaks = append(peaks, r.min, r.max)
+			r.reset()
+		}
+	}
+
+	return peaks
+}
+
+// Flush returns the min/max pair of the partial pixel at the end of the stream,
+// or nil if there is none. The reducer can keep accepting samples afterwards.
+func (r *PeakReducer) Flush() []int16 {
+	if r.pending == 0 {
+		return nil
+	}
+	peaks := []int16{r.min, r.max}
+	r.reset()
+	return peaks
+}
+
+// Pixels returns the number of min/max pairs emitted so far
+func (r *PeakReducer) Pixels() int {
+	return r.pixels
+}
+
+// SamplesPerPixel returns the number of frames reduced into each pixel
+func (r *PeakReducer) SamplesPerPixel() int {
+	return r.samplesPerPixel
+}
+
+func (r *PeakReducer) reset() {
+	r.pending = 0
+	r.min, r.max = math.MaxInt16, math.MinInt16
+	r.pixels++
+}
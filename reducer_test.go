@@ -0,0 +1,76 @@
+package gowaveform
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPeakReducerMatchesGenerateView(t *testing.T) {
+	tmpFile := "/tmp/test_reducer.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	expected, err := waveform.GenerateView(WaveformOptions{SamplesPerPixel: 300})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	reducer, err := NewPeakReducer(waveform.Channels, 300)
+	if err != nil {
+		t.Fatalf("NewPeakReducer failed: %v", err)
+	}
+
+	// Feed odd-sized chunks that split frames and pixels
+	var peaks []int16
+	samples := waveform.audioData
+	for chunk := 1; len(samples) > 0; chunk = chunk*3 + 1 {
+		if chunk > len(samples) {
+			chunk = len(samples)
+		}
+		peaks = append(peaks, reducer.Write(samples[:chunk])...)
+		samples = samples[chunk:]
+	}
+	peaks = append(peaks, reducer.Flush()...)
+
+	if !reflect.DeepEqual(peaks, expected.Data) {
+		t.Errorf("Expected %d peaks matching GenerateView, got %d", len(expected.Data), len(peaks))
+	}
+	if reducer.Pixels() != expected.Length {
+		t.Errorf("Expected %d pixels, got %d", expected.Length, reducer.Pixels())
+	}
+}
+
+func TestPeakReducer(t *testing.T) {
+	reducer, err := NewPeakReducer(2, 2)
+	if err != nil {
+		t.Fatalf("NewPeakReducer failed: %v", err)
+	}
+
+	if peaks := reducer.Write([]int16{1, -1, 5}); peaks != nil {
+		t.Errorf("Expected no complete pixel, got %v", peaks)
+	}
+	peaks := reducer.Write([]int16{-7, 2, 3})
+	if !reflect.DeepEqual(peaks, []int16{-7, 5}) {
+		t.Errorf("Expected [-7 5], got %v", peaks)
+	}
+	if peaks := reducer.Flush(); !reflect.DeepEqual(peaks, []int16{2, 3}) {
+		t.Errorf("Expected partial pixel [2 3], got %v", peaks)
+	}
+	if peaks := reducer.Flush(); peaks != nil {
+		t.Errorf("Expected nothing to flush, got %v", peaks)
+	}
+
+	if _, err := NewPeakReducer(0, 256); err == nil {
+		t.Error("Expected error for zero channels")
+	}
+	if _, err := NewPeakReducer(1, 0); err == nil {
+		t.Error("Expected error for zero samples per pixel")
+	}
+}
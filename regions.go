@@ -0,0 +1,153 @@
+package gowaveform
+
+import (
+	"image/color"
+	"sort"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// regionAlpha is the opacity of region shading, so the waveform shows through
+const regionAlpha = 0.25
+
+// Region is a labeled time range in the audio, such as a podcast chapter or a song
+// section
+type Region struct {
+	Start float64     // Start in seconds
+	End   float64     // End in seconds
+	Label string      // Optional label drawn inside the top of the region
+	Color color.Color // Shading and label color (nil = the region color option)
+}
+
+// OptionRegions shades each region behind the waveform and draws its label inside the
+// top of the region. Labels that would overlap an earlier label move down a row, so
+// adjacent short regions such as verse and chorus stay readable.
+func OptionRegions(regions []Region) Option {
+	return func(c *PlotConfig) {
+		c.regions = regions
+	}
+}
+
+// OptionSetRegionColor sets the color of regions without their own color using a hex
+// color code. The shading is drawn at 25% opacity and labels at full opacity.
+func OptionSetRegionColor(hexColor string) Option {
+	return func(c *PlotConfig) {
+		c.regionColor = hexToColor(hexColor)
+	}
+}
+
+// regionShading fills the part of each region in view behind the waveform
+type regionShading struct {
+	regions    []Region
+	start, end float64
+	color      color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (rs *regionShading) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+	for _, r := range rs.regions {
+		start, end, ok := clipRegion(r, rs.start, rs.end)
+		if !ok {
+			continue
+		}
+		x0, x1 := trX(start), trX(end)
+		c.FillPolygon(withAlpha(regionColor(r, rs.color), regionAlpha), []vg.Point{
+			{X: x0, Y: c.Min.Y},
+			{X: x1, Y: c.Min.Y},
+			{X: x1, Y: c.Max.Y},
+			{X: x0, Y: c.Max.Y},
+		})
+	}
+}
+
+// regionLabels draws the label of each region in view over the waveform, in rows
+// from the top so that no two labels overlap
+type regionLabels struct {
+	regions    []Region
+	start, end float64
+	color      color.Color
+}
+
+// Plot implements the plot.Plotter interface
+func (rl *regionLabels) Plot(c draw.Canvas, plt *plot.Plot) {
+	trX, _ := plt.Transforms(&c)
+
+	// Label with the tick label font so the size follows the rest of the plot
+	labelStyle := plt.X.Tick.Label
+	labelStyle.XAlign = draw.XLeft
+	labelStyle.YAlign = draw.YTop
+	pad := vg.Points(2)
+	rowHeight := labelStyle.Height("M") + pad
+
+	// Place labels from left to right, each in the first row where it fits
+	regions := make([]Region, 0, len(rl.regions))
+	for _, r := range rl.regions {
+		if _, _, ok := clipRegion(r, rl.start, rl.end); ok && r.Label != "" {
+			regions = append(regions, r)
+		}
+	}
+	sort.SliceStable(regions, func(i, j int) bool {
+		return min(regions[i].Start, regions[i].End) < min(regions[j].Start, regions[j].End)
+	})
+
+	lefts, widths := make([]vg.Length, len(regions)), make([]vg.Length, len(regions))
+	for i, r := range regions {
+		start, _, _ := clipRegion(r, rl.start, rl.end)
+		widths[i] = labelStyle.Width(r.Label)
+		lefts[i] = max(min(trX(start)+pad, c.Max.X-widths[i]-pad), c.Min.X) // Keep labels at the right edge in view
+	}
+
+	for i, row := range labelRows(lefts, widths, 2*pad) {
+		y := c.Max.Y - pad - vg.Length(row)*rowHeight
+		if y-rowHeight < c.Min.Y {
+			continue // No room for this row
+		}
+		labelStyle.Color = withAlpha(regionColor(regions[i], rl.color), 1)
+		c.FillText(labelStyle, vg.Point{X: lefts[i], Y: y}, regions[i].Label)
+	}
+}
+
+// labelRows assigns each label, given by its left edge and width in order of the left
+// edges, to the first row in which it starts at least gap after the previous label
+func labelRows(lefts, widths []vg.Length, gap vg.Length) []int {
+	rows := make([]int, len(lefts))
+	var rowEnds []vg.Length // Right edge of the last label in each row
+	for i, left := range lefts {
+		row := 0
+		for row < len(rowEnds) && rowEnds[row]+gap > left {
+			row++
+		}
+		if row == len(rowEnds) {
+			rowEnds = append(rowEnds, 0)
+		}
+		rowEnds[row] = left + widths[i]
+		rows[i] = row
+	}
+	return rows
+}
+
+// clipRegion returns the part of a region inside the view, in either order of its
+// start and end, and whether any of it is in view
+func clipRegion(r Region, viewStart, viewEnd float64) (start, end float64, ok bool) {
+	start, end = min(r.Start, r.End), max(r.Start, r.End)
+	start, end = max(start, viewStart), min(end, viewEnd)
+	return start, end, start < end
+}
+
+// regionColor returns the color of a region, or the fallback if it has none
+func regionColor(r Region, fallback color.Color) color.Color {
+	if r.Color != nil {
+		return r.Color
+	}
+	return fallback
+}
+
+// withAlpha returns c with its opacity set to alpha, from 0 to 1
+func withAlpha(c color.Color, alpha float64) color.Color {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	n.A = uint8(alpha*255 + 0.5)
+	return n
+}
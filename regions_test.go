@@ -0,0 +1,101 @@
+package gowaveform
+
+import (
+	"image/color"
+	"image/png"
+	"os"
+	"slices"
+	"testing"
+
+	"gonum.org/v1/plot/vg"
+)
+
+func TestSavePlotRegions(t *testing.T) {
+	tmpWav := "/tmp/test_plot_regions.wav"
+	tmpPlot := "/tmp/test_plot_regions.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Create a silent file so only the regions are drawn
+	writeTestWAV(t, tmpWav, 44100, 1, make([]int16, 44100))
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionSetWidth(400),
+		OptionSetHeight(100),
+		OptionRegions([]Region{
+			{Start: 0.0, End: 0.25, Label: "Intro"},
+			{Start: 0.5, End: 0.75, Label: "Chorus", Color: color.RGBA{R: 255, A: 255}},
+		}),
+		OptionSetRegionColor("#0000FF"),
+		OptionHideXAxis(true),
+		OptionHideYAxis(true),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open plot: %v", err)
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+
+	// Sample below the labels and away from the silent waveform in the middle
+	bounds := img.Bounds()
+	y := bounds.Min.Y + bounds.Dy()*3/4
+	tint := func(x int) (r, g, b uint32) {
+		r, g, b, _ = img.At(bounds.Min.X+bounds.Dx()*x/8, y).RGBA()
+		return r >> 8, g >> 8, b >> 8
+	}
+
+	if r, g, b := tint(1); b < 240 || r > 220 || g > 220 {
+		t.Errorf("Expected light blue shading in the first region, got (%d, %d, %d)", r, g, b)
+	}
+	if r, g, b := tint(3); r < 240 || g < 240 || b < 240 {
+		t.Errorf("Expected white between the regions, got (%d, %d, %d)", r, g, b)
+	}
+	if r, g, b := tint(5); r < 240 || g > 220 || b > 220 {
+		t.Errorf("Expected light red shading in the second region, got (%d, %d, %d)", r, g, b)
+	}
+}
+
+func TestLabelRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		lefts  []vg.Length
+		widths []vg.Length
+		want   []int
+	}{
+		{"apart", []vg.Length{0, 50, 100}, []vg.Length{40, 40, 40}, []int{0, 0, 0}},
+		{"overlapping", []vg.Length{0, 20, 45}, []vg.Length{40, 40, 40}, []int{0, 1, 0}},
+		{"stacked", []vg.Length{0, 5, 10}, []vg.Length{40, 40, 40}, []int{0, 1, 2}},
+		{"within the gap", []vg.Length{0, 42}, []vg.Length{40, 40}, []int{0, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := labelRows(tt.lefts, tt.widths, 4); !slices.Equal(got, tt.want) {
+				t.Errorf("labelRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClipRegion(t *testing.T) {
+	if start, end, ok := clipRegion(Region{Start: 3, End: 1}, 2, 10); !ok || start != 2 || end != 3 {
+		t.Errorf("Expected a reversed region clipped to 2-3, got %v-%v (%v)", start, end, ok)
+	}
+	if _, _, ok := clipRegion(Region{Start: 0, End: 1}, 2, 10); ok {
+		t.Error("Expected a region before the view to be out of view")
+	}
+}
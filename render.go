@@ -0,0 +1,119 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	stddraw "image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// DefaultDPI is the resolution of plots unless OptionSetDPI is given
+const DefaultDPI = 96
+
+// PlotImage is a plot rendered in memory, with the pixel area its time axis spans
+type PlotImage struct {
+	Image    *image.RGBA
+	Start    float64         // Time in seconds at the left edge of DataArea
+	End      float64         // Time in seconds at the right edge of DataArea
+	DataArea image.Rectangle // Pixel area of the waveform, inside the axes and title
+}
+
+// TimeToX returns the pixel column of a time in seconds
+func (pi *PlotImage) TimeToX(t float64) int {
+	if pi.End <= pi.Start {
+		return pi.DataArea.Min.X
+	}
+	frac := (t - pi.Start) / (pi.End - pi.Start)
+	return pi.DataArea.Min.X + int(frac*float64(pi.DataArea.Dx()-1)+0.5)
+}
+
+// RenderPlot renders the waveform visualization to an image in memory, using the same
+// options as SavePlot. The data area lets callers draw on top of the waveform,
+// e.g. a playhead for each frame of a video. With OptionRenderer the data area is
+// the whole image, since other renderers do not report their layout.
+func RenderPlot(w *Waveform, opts ...Option) (*PlotImage, error) {
+	config, err := newPlotConfig(w, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if name := config.rendererName(); name != "" {
+		img, err := renderWithRenderer(w, config, name)
+		if err != nil {
+			return nil, err
+		}
+		rgba := image.NewRGBA(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy()))
+		stddraw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, stddraw.Src)
+		return &PlotImage{Image: rgba, Start: config.start, End: config.end, DataArea: rgba.Bounds()}, nil
+	}
+
+	p, err := newPlot(w, config)
+	if err != nil {
+		return nil, err
+	}
+
+	c := config.newCanvas()
+	dc := draw.New(c)
+	p.Draw(dc)
+
+	img, ok := c.Image().(*image.RGBA)
+	if !ok {
+		return nil, fmt.Errorf("failed to render plot: unexpected image type %T", c.Image())
+	}
+
+	// Convert the data canvas from vg units (origin bottom left) to pixels (origin top left)
+	da := p.DataCanvas(dc).Rectangle
+	dpi := c.DPI()
+	bounds := img.Bounds()
+	area := image.Rect(
+		int(da.Min.X.Dots(dpi)+0.5),
+		bounds.Dy()-int(da.Max.Y.Dots(dpi)+0.5),
+		int(da.Max.X.Dots(dpi)+0.5),
+		bounds.Dy()-int(da.Min.Y.Dots(dpi)+0.5),
+	).Intersect(bounds)
+
+	return &PlotImage{
+		Image:    img,
+		Start:    config.start,
+		End:      config.end,
+		DataArea: area,
+	}, nil
+}
+
+// encodePNG writes img as PNG with a pHYs chunk recording the DPI, so print software
+// lays the image out at its physical size
+func encodePNG(w io.Writer, img image.Image, dpi int) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	encoded := buf.Bytes()
+
+	// The chunk goes right after the 8-byte signature and the 25-byte IHDR chunk
+	const ihdrEnd = 8 + 25
+	if len(encoded) < ihdrEnd {
+		return fmt.Errorf("unexpected PNG encoding of %d bytes", len(encoded))
+	}
+	pixelsPerMeter := uint32(math.Round(float64(dpi) / 0.0254))
+	chunk := make([]byte, 0, 4+4+9+4)
+	chunk = binary.BigEndian.AppendUint32(chunk, 9)
+	chunk = append(chunk, "pHYs"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, pixelsPerMeter)
+	chunk = binary.BigEndian.AppendUint32(chunk, pixelsPerMeter)
+	chunk = append(chunk, 1) // Unit: meter
+	chunk = binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+
+	for _, part := range [][]byte{encoded[:ihdrEnd], chunk, encoded[ihdrEnd:]} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
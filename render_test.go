@@ -0,0 +1,90 @@
+package gowaveform
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRenderPlot(t *testing.T) {
+	tmpWav := "/tmp/test_render_plot.wav"
+	defer os.Remove(tmpWav)
+
+	// Create a silent file so only the playhead is drawn
+	writeTestWAV(t, tmpWav, 44100, 1, make([]int16, 44100))
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	img, err := RenderPlot(waveform,
+		OptionSetWidth(640),
+		OptionSetHeight(240),
+		OptionSetStart(0.25),
+		OptionPlayhead(0.5),
+		OptionSetPlayheadColor("#FF0000"),
+	)
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+
+	bounds := img.Image.Bounds()
+	if bounds.Dx() != 640 || bounds.Dy() != 240 {
+		t.Errorf("Expected a 640x240 image, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if img.Start != 0.25 || img.End != 1.0 {
+		t.Errorf("Expected a 0.25-1.0s time range, got %v-%v", img.Start, img.End)
+	}
+	if img.DataArea.Empty() || !img.DataArea.In(bounds) || img.DataArea.Eq(bounds) {
+		t.Errorf("Expected the data area inside the axes, got %v in %v", img.DataArea, bounds)
+	}
+
+	// The red playhead must be where TimeToX places its time
+	y := img.DataArea.Min.Y + img.DataArea.Dy()/4
+	playheadX := -1
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		c := img.Image.RGBAAt(x, y)
+		if c.R > 200 && c.G < 80 && c.B < 80 {
+			playheadX = x
+			break
+		}
+	}
+	if playheadX < 0 {
+		t.Fatal("Expected a red playhead line")
+	}
+	if x := img.TimeToX(0.5); x < playheadX-1 || x > playheadX+2 {
+		t.Errorf("TimeToX(0.5) = %d, playhead drawn at %d", x, playheadX)
+	}
+	if x := img.TimeToX(0.25); x != img.DataArea.Min.X {
+		t.Errorf("TimeToX(start) = %d, want %d", x, img.DataArea.Min.X)
+	}
+	if x := img.TimeToX(1.0); x != img.DataArea.Max.X-1 {
+		t.Errorf("TimeToX(end) = %d, want %d", x, img.DataArea.Max.X-1)
+	}
+}
+
+func TestRenderPlotSparkline(t *testing.T) {
+	tmpWav := "/tmp/test_render_sparkline.wav"
+	defer os.Remove(tmpWav)
+
+	createTestWAV(t, tmpWav, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	img, err := RenderPlot(waveform,
+		OptionSetWidth(300),
+		OptionSetHeight(40),
+		OptionSetTitle("Hidden"),
+		OptionSparkline(true),
+	)
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+
+	if bounds := img.Image.Bounds(); !img.DataArea.Eq(bounds) {
+		t.Errorf("Expected the data area to fill the image, got %v in %v", img.DataArea, bounds)
+	}
+}
@@ -0,0 +1,170 @@
+package gowaveform
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"sync"
+
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// GonumRenderer is the name of the built-in renderer, which draws with gonum/plot
+const GonumRenderer = "gonum"
+
+// Style is the look of a waveform image, as passed to a Renderer
+type Style struct {
+	Width         int         // Image width in pixels
+	Height        int         // Image height in pixels
+	DPI           int         // Dots per inch that text and line widths scale with
+	Background    color.Color // Background color
+	Foreground    color.Color // Waveform color
+	Title         string      // Title above the waveform (empty = none)
+	Sparkline     bool        // Fill the whole image with the waveform, without axes or title
+	Markers       []Marker    // Labeled marker lines
+	Regions       []Region    // Labeled, shaded time ranges
+	Antialias     bool        // Shade edge pixels by coverage instead of filling whole pixels
+	WaveStyle     WaveStyle   // Filled or bars
+	SplitChannels bool        // Each channel in its own lane; the peaks hold a min/max pair per channel per pixel
+}
+
+// Renderer draws waveform peaks to an image. Implementations can offer styling that
+// gonum/plot has no primitives for, such as gradients, rounded bars or glow. The
+// time range of the peaks is in data.Start and data.End.
+//
+// The peaks hold a column per data.SamplesPerPixel samples, generated for
+// Style.Width pixels scaled by OptionSetResolution, so data.Length need not equal
+// Style.Width: a range that does not divide evenly into the width ends with an extra,
+// partial column, and a range of fewer samples than pixels has a column per sample.
+// Renderers spread data.Length columns across the image width, as the built-in
+// renderers do.
+type Renderer interface {
+	DrawWaveform(data *WaveformData, style Style) (image.Image, error)
+}
+
+// RendererFunc adapts a function to the Renderer interface
+type RendererFunc func(data *WaveformData, style Style) (image.Image, error)
+
+// DrawWaveform calls f(data, style)
+func (f RendererFunc) DrawWaveform(data *WaveformData, style Style) (image.Image, error) {
+	return f(data, style)
+}
+
+var (
+	renderersMu sync.RWMutex
+	renderers   = map[string]Renderer{GonumRenderer: gonumRenderer{}, FastRenderer: fastRenderer{}}
+)
+
+// RegisterRenderer makes a renderer available under a name for OptionRenderer.
+// Registering nil removes the name again. It is safe to call concurrently with
+// rendering.
+func RegisterRenderer(name string, r Renderer) {
+	renderersMu.Lock()
+	defer renderersMu.Unlock()
+	if r == nil {
+		delete(renderers, name)
+		return
+	}
+	renderers[name] = r
+}
+
+// OptionRenderer draws the plot with the renderer registered under name instead of
+// the full gonum/plot pipeline. Renderers receive the peaks and the options that make
+// up a Style; options for overlays computed from the audio (spectral colors, RMS,
+// pitch, phase, beat grid and playhead) only apply to the default pipeline.
+func OptionRenderer(name string) Option {
+	return func(c *PlotConfig) {
+		c.renderer = name
+	}
+}
+
+// style returns the parts of the configuration passed to renderers
+func (c PlotConfig) style() Style {
+	return Style{
+		Width:         c.width,
+		Height:        c.height,
+		DPI:           c.dpi,
+		Background:    c.backgroundColor,
+		Foreground:    c.foregroundColor,
+		Title:         c.title,
+		Sparkline:     c.sparkline,
+		Markers:       c.markers,
+		Regions:       c.regions,
+		Antialias:     c.antialias,
+		WaveStyle:     c.waveStyle,
+		SplitChannels: c.splitChannels,
+	}
+}
+
+// rendererName returns the renderer a resolved configuration draws with: the one set
+// with OptionRenderer, the fast renderer when nothing but the waveform is asked for, or
+// empty for the full gonum/plot pipeline
+func (c PlotConfig) rendererName() string {
+	if c.renderer == "" && c.fastRenderable() {
+		return FastRenderer
+	}
+	return c.renderer
+}
+
+// renderWithRenderer generates the peaks for a resolved configuration and draws them
+// with the named renderer
+func renderWithRenderer(w *Waveform, config PlotConfig, name string) (image.Image, error) {
+	renderersMu.RLock()
+	r, ok := renderers[name]
+	renderersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown renderer: %q", name)
+	}
+
+	data, err := plotWaveformData(w, config)
+	if err != nil {
+		return nil, err
+	}
+	img, err := r.DrawWaveform(data, config.style())
+	if err != nil {
+		return nil, fmt.Errorf("renderer %q failed: %w", name, err)
+	}
+	if img == nil {
+		return nil, fmt.Errorf("renderer %q returned no image", name)
+	}
+	return img, nil
+}
+
+// gonumRenderer draws peaks with gonum/plot, like SavePlot without the overlays that
+// need the audio
+type gonumRenderer struct{}
+
+// DrawWaveform implements the Renderer interface
+func (gonumRenderer) DrawWaveform(data *WaveformData, style Style) (image.Image, error) {
+	config := defaultPlotConfig()
+	config.start, config.end = data.Start, data.End
+	config.title = style.Title
+	config.sparkline = style.Sparkline
+	config.markers = style.Markers
+	config.regions = style.Regions
+	config.waveStyle = style.WaveStyle
+	config.splitChannels = style.SplitChannels
+	if style.Width > 0 {
+		config.width = style.Width
+	}
+	if style.Height > 0 {
+		config.height = style.Height
+	}
+	if style.DPI > 0 {
+		config.dpi = style.DPI
+	}
+	if style.Background != nil {
+		config.backgroundColor = style.Background
+	}
+	if style.Foreground != nil {
+		config.foregroundColor = style.Foreground
+	}
+
+	p, err := plotView(nil, data, config)
+	if err != nil {
+		return nil, err
+	}
+	c := config.newCanvas()
+	p.Draw(draw.New(c))
+	return c.Image(), nil
+}
@@ -0,0 +1,118 @@
+package gowaveform
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"testing"
+)
+
+func TestRegisterRenderer(t *testing.T) {
+	tmpWav := "/tmp/test_renderer.wav"
+	tmpPlot := "/tmp/test_renderer.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpWav, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// A renderer that fills the image with the foreground color
+	var gotData *WaveformData
+	var gotStyle Style
+	RegisterRenderer("solid", RendererFunc(func(data *WaveformData, style Style) (image.Image, error) {
+		gotData, gotStyle = data, style
+		img := image.NewRGBA(image.Rect(0, 0, style.Width, style.Height))
+		for i := 0; i < len(img.Pix); i += 4 {
+			r, g, b, _ := style.Foreground.RGBA()
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = uint8(r>>8), uint8(g>>8), uint8(b>>8), 255
+		}
+		return img, nil
+	}))
+	defer RegisterRenderer("solid", nil)
+
+	err = SavePlot(waveform, tmpPlot,
+		OptionRenderer("solid"),
+		OptionSetWidth(200),
+		OptionSetHeight(50),
+		OptionSetStart(0.5),
+		OptionSetEnd(1.5),
+		OptionSetForegroundColor("#FF8000"),
+		OptionSetTitle("Custom"),
+	)
+	if err != nil {
+		t.Fatalf("SavePlot failed: %v", err)
+	}
+
+	// 44100 samples in 200 pixels: 220 samples per column and a partial last column
+	if gotData == nil || gotData.Start != 0.5 || gotData.End != 1.5 {
+		t.Fatalf("Expected peaks from 0.5s to 1.5s, got %+v", gotData)
+	}
+	if gotData.Length < 200 || gotData.Length*gotData.SamplesPerPixel < 44100 || (gotData.Length-1)*gotData.SamplesPerPixel >= 44100 {
+		t.Errorf("Expected at least 200 columns covering the range, got %d of %d samples", gotData.Length, gotData.SamplesPerPixel)
+	}
+	if gotStyle.Title != "Custom" || gotStyle.DPI != DefaultDPI {
+		t.Errorf("Expected the title and default DPI in the style, got %+v", gotStyle)
+	}
+
+	file, err := os.Open(tmpPlot)
+	if err != nil {
+		t.Fatalf("Failed to open image: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("Expected a 200x50 image, got %dx%d", b.Dx(), b.Dy())
+	}
+	if c := color.RGBAModel.Convert(img.At(10, 10)).(color.RGBA); c != (color.RGBA{255, 128, 0, 255}) {
+		t.Errorf("Expected the renderer's output, got %v", c)
+	}
+
+	rendered, err := RenderPlot(waveform, OptionRenderer("solid"), OptionSetWidth(100), OptionSetHeight(20))
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+	if !rendered.DataArea.Eq(rendered.Image.Bounds()) {
+		t.Errorf("Expected the data area to be the whole image, got %v", rendered.DataArea)
+	}
+
+	if err := SavePlot(waveform, tmpPlot, OptionRenderer("missing")); err == nil {
+		t.Error("Expected an error for an unknown renderer")
+	}
+}
+
+func TestGonumRenderer(t *testing.T) {
+	tmpWav := "/tmp/test_gonum_renderer.wav"
+	defer os.Remove(tmpWav)
+
+	createTestWAV(t, tmpWav, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// The built-in renderer draws loaded or generated peaks without the audio
+	data, err := waveform.GenerateView(WaveformOptions{Width: 320})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	img, err := gonumRenderer{}.DrawWaveform(data, Style{Width: 320, Height: 120})
+	if err != nil {
+		t.Fatalf("DrawWaveform failed: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 320 || b.Dy() != 120 {
+		t.Errorf("Expected a 320x120 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	if _, err := RenderPlot(waveform, OptionRenderer(GonumRenderer), OptionShowRMS(true)); err != nil {
+		t.Errorf("RenderPlot with the gonum renderer failed: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Package rendertest compares rendered waveforms against golden files, so applications
+// embedding RenderPlot, SavePlot or a terminal renderer can write regression tests that
+// tolerate antialiasing and font differences between machines.
+//
+// Golden files are rewritten instead of compared when the environment variable named
+// by UpdateEnv is set, e.g. GOWAVEFORM_UPDATE_GOLDEN=1 go test ./...
+package rendertest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv names the environment variable that rewrites golden files
+const UpdateEnv = "GOWAVEFORM_UPDATE_GOLDEN"
+
+// DefaultThreshold is the perceptual distance above which two pixels differ
+const DefaultThreshold = 0.1
+
+// config holds the tolerances of an image comparison
+type config struct {
+	threshold    float64 // Perceptual distance from 0 to 1 above which pixels differ
+	maxDiffRatio float64 // Fraction of pixels that may differ
+}
+
+// Option configures AssertImage
+type Option func(*config)
+
+// OptionThreshold sets the perceptual distance, from 0 (identical) to 1 (black and
+// white), above which two pixels count as different (default 0.1)
+func OptionThreshold(threshold float64) Option {
+	return func(c *config) {
+		c.threshold = threshold
+	}
+}
+
+// OptionMaxDiffRatio sets the fraction of pixels, from 0 to 1, that may differ before
+// the images no longer match (default 0)
+func OptionMaxDiffRatio(ratio float64) Option {
+	return func(c *config) {
+		c.maxDiffRatio = ratio
+	}
+}
+
+// Diff is the result of comparing two images of the same size
+type Diff struct {
+	Pixels int         // Number of pixels that differ
+	Total  int         // Number of pixels compared
+	Image  *image.RGBA // The expected image faded, with differing pixels in red
+}
+
+// Ratio returns the fraction of pixels that differ
+func (d *Diff) Ratio() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Pixels) / float64(d.Total)
+}
+
+// CompareImages counts the pixels whose perceptual distance is above threshold. The
+// distance is measured in the YIQ color space, which weights brightness over hue the
+// way the eye does, after blending any transparency onto white.
+func CompareImages(want, got image.Image, threshold float64) (*Diff, error) {
+	wb, gb := want.Bounds(), got.Bounds()
+	if wb.Dx() != gb.Dx() || wb.Dy() != gb.Dy() {
+		return nil, fmt.Errorf("image sizes differ: want %dx%d, got %dx%d", wb.Dx(), wb.Dy(), gb.Dx(), gb.Dy())
+	}
+
+	diff := &Diff{
+		Total: wb.Dx() * wb.Dy(),
+		Image: image.NewRGBA(image.Rect(0, 0, wb.Dx(), wb.Dy())),
+	}
+	for y := 0; y < wb.Dy(); y++ {
+		for x := 0; x < wb.Dx(); x++ {
+			a := want.At(wb.Min.X+x, wb.Min.Y+y)
+			b := got.At(gb.Min.X+x, gb.Min.Y+y)
+			if Distance(a, b) > threshold {
+				diff.Pixels++
+				diff.Image.Set(x, y, color.RGBA{R: 255, A: 255})
+				continue
+			}
+			// Fade matching pixels toward white so the differences stand out
+			r, g, bl := blendWhite(a)
+			lum := 0.29889531*r + 0.58662247*g + 0.11448223*bl
+			v := uint8(255 - (255-lum)*0.25)
+			diff.Image.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return diff, nil
+}
+
+// maxYIQDelta is the weighted YIQ distance between black and white
+const maxYIQDelta = 0.5053 * 255 * 255
+
+// Distance returns the perceptual distance of two colors from 0 (identical) to 1
+// (black and white)
+func Distance(a, b color.Color) float64 {
+	r1, g1, b1 := blendWhite(a)
+	r2, g2, b2 := blendWhite(b)
+	dr, dg, db := r1-r2, g1-g2, b1-b2
+
+	y := 0.29889531*dr + 0.58662247*dg + 0.11448223*db
+	i := 0.59597799*dr - 0.27417610*dg - 0.32180189*db
+	q := 0.21147017*dr - 0.52261711*dg + 0.31114694*db
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	return math.Min(1, math.Sqrt(delta/maxYIQDelta))
+}
+
+// blendWhite returns the 8-bit components of a color drawn over white
+func blendWhite(c color.Color) (r, g, b float64) {
+	cr, cg, cb, ca := c.RGBA() // Premultiplied, 0 to 65535
+	white := 65535 - float64(ca)
+	return (float64(cr) + white) / 257, (float64(cg) + white) / 257, (float64(cb) + white) / 257
+}
+
+// AssertImage compares got with the PNG at golden and fails the test if more pixels
+// differ than allowed. On failure it writes the image it got and a diff image next to
+// the golden file (name.actual.png and name.diff.png) for inspection.
+func AssertImage(t testing.TB, golden string, got image.Image, opts ...Option) {
+	t.Helper()
+
+	cfg := config{threshold: DefaultThreshold}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if os.Getenv(UpdateEnv) != "" {
+		if err := writePNG(golden, got); err != nil {
+			t.Fatalf("failed to update golden image: %v", err)
+		}
+		return
+	}
+
+	want, err := readPNG(golden)
+	if err != nil {
+		t.Fatalf("failed to read golden image (set %s=1 to create it): %v", UpdateEnv, err)
+		return
+	}
+
+	diff, err := CompareImages(want, got, cfg.threshold)
+	if err != nil {
+		t.Errorf("%s: %v", golden, err)
+		writeArtifact(t, golden, ".actual.png", got)
+		return
+	}
+	if diff.Ratio() > cfg.maxDiffRatio {
+		t.Errorf("%s: %d of %d pixels differ (%.3f%%, %.3f%% allowed)",
+			golden, diff.Pixels, diff.Total, diff.Ratio()*100, cfg.maxDiffRatio*100)
+		writeArtifact(t, golden, ".actual.png", got)
+		writeArtifact(t, golden, ".diff.png", diff.Image)
+	}
+}
+
+// AssertSnapshot compares terminal output with the text file at golden, ignoring the
+// difference between CRLF and LF line endings, and fails the test at the first line
+// that differs. On failure it writes the output it got to name.actual next to the
+// golden file. Use StripANSI first to compare text without colors.
+func AssertSnapshot(t testing.TB, golden string, got string) {
+	t.Helper()
+
+	got = strings.ReplaceAll(got, "\r\n", "\n")
+	if os.Getenv(UpdateEnv) != "" {
+		if err := writeFile(golden, []byte(got)); err != nil {
+			t.Fatalf("failed to update snapshot: %v", err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read snapshot (set %s=1 to create it): %v", UpdateEnv, err)
+		return
+	}
+	want := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if got == want {
+		return
+	}
+
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	line := 0
+	for line < len(wantLines) && line < len(gotLines) && wantLines[line] == gotLines[line] {
+		line++
+	}
+	t.Errorf("%s: line %d differs\nwant: %q\ngot:  %q", golden, line+1, lineAt(wantLines, line), lineAt(gotLines, line))
+	if err := writeFile(golden+".actual", []byte(got)); err != nil {
+		t.Logf("failed to write actual output: %v", err)
+	}
+}
+
+// ansiEscape matches ANSI CSI escape sequences such as colors and cursor movement
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// StripANSI removes ANSI escape sequences from terminal output
+func StripANSI(s string) string {
+	return ansiEscape.ReplaceAllString(s, "")
+}
+
+// lineAt returns line i, or a placeholder past the end
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return "<end of output>"
+}
+
+// writeArtifact writes an image next to golden with the given suffix, logging failures
+func writeArtifact(t testing.TB, golden, suffix string, img image.Image) {
+	t.Helper()
+	name := strings.TrimSuffix(golden, filepath.Ext(golden)) + suffix
+	if err := writePNG(name, img); err != nil {
+		t.Logf("failed to write %s: %v", name, err)
+		return
+	}
+	t.Logf("wrote %s", name)
+}
+
+// readPNG decodes the PNG file at filename
+func readPNG(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// writePNG encodes img to filename as PNG, creating its directory
+func writePNG(filename string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	// Encode an RGBA copy so paletted or premultiplied sources round-trip the same way
+	rgba := image.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	if err := png.Encode(f, rgba); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeFile writes data to filename, creating its directory
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0o644)
+}
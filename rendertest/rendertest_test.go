@@ -0,0 +1,140 @@
+package rendertest
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recorder is a testing.TB that records failures instead of stopping the test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper()                         {}
+func (r *recorder) Logf(format string, args ...any) {}
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+// stripes returns a 20x10 image of black and white vertical stripes
+func stripes() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 20, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 20; x++ {
+			c := color.RGBA{255, 255, 255, 255}
+			if x%4 < 2 {
+				c = color.RGBA{0, 0, 0, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestDistance(t *testing.T) {
+	black := color.RGBA{0, 0, 0, 255}
+	white := color.RGBA{255, 255, 255, 255}
+
+	if d := Distance(black, white); d < 0.99 {
+		t.Errorf("Expected black and white to be about 1 apart, got %.3f", d)
+	}
+	if d := Distance(black, black); d != 0 {
+		t.Errorf("Expected identical colors to be 0 apart, got %.3f", d)
+	}
+	if d := Distance(color.RGBA{100, 100, 100, 255}, color.RGBA{102, 101, 100, 255}); d > DefaultThreshold {
+		t.Errorf("Expected a slight shade to be within the default threshold, got %.3f", d)
+	}
+	// Transparent pixels are compared as drawn over white
+	if d := Distance(color.RGBA{}, white); d != 0 {
+		t.Errorf("Expected transparent and white to match, got %.3f", d)
+	}
+}
+
+func TestCompareImages(t *testing.T) {
+	want := stripes()
+	got := stripes()
+	got.Set(3, 3, color.RGBA{0, 0, 0, 255})
+	got.Set(0, 0, color.RGBA{10, 10, 10, 255}) // Within the threshold
+
+	diff, err := CompareImages(want, got, DefaultThreshold)
+	if err != nil {
+		t.Fatalf("CompareImages failed: %v", err)
+	}
+	if diff.Pixels != 1 || diff.Total != 200 {
+		t.Errorf("Expected 1 of 200 pixels to differ, got %d of %d", diff.Pixels, diff.Total)
+	}
+	if c := diff.Image.RGBAAt(3, 3); c != (color.RGBA{255, 0, 0, 255}) {
+		t.Errorf("Expected the differing pixel in red, got %v", c)
+	}
+
+	if _, err := CompareImages(want, image.NewRGBA(image.Rect(0, 0, 10, 10)), DefaultThreshold); err == nil {
+		t.Error("Expected an error for images of different sizes")
+	}
+}
+
+func TestAssertImage(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "testdata", "stripes.png")
+
+	t.Setenv(UpdateEnv, "1")
+	AssertImage(t, golden, stripes())
+	if _, err := os.Stat(golden); err != nil {
+		t.Fatalf("Expected the golden image to be written: %v", err)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	AssertImage(t, golden, stripes())
+
+	changed := stripes()
+	changed.Set(3, 3, color.RGBA{0, 0, 0, 255})
+	r := &recorder{}
+	AssertImage(r, golden, changed)
+	if len(r.failures) != 1 {
+		t.Fatalf("Expected one failure, got %v", r.failures)
+	}
+	for _, suffix := range []string{".actual.png", ".diff.png"} {
+		if _, err := os.Stat(filepath.Join(filepath.Dir(golden), "stripes"+suffix)); err != nil {
+			t.Errorf("Expected %s to be written: %v", suffix, err)
+		}
+	}
+
+	r = &recorder{}
+	AssertImage(r, golden, changed, OptionMaxDiffRatio(0.01))
+	if len(r.failures) != 0 {
+		t.Errorf("Expected one pixel in 200 to be tolerated, got %v", r.failures)
+	}
+}
+
+func TestAssertSnapshot(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "view.txt")
+	output := "\x1b[33m▄▆█\x1b[0m\r\n0s   1s\n"
+
+	t.Setenv(UpdateEnv, "1")
+	AssertSnapshot(t, golden, StripANSI(output))
+	data, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Expected the snapshot to be written: %v", err)
+	}
+	if string(data) != "▄▆█\n0s   1s\n" {
+		t.Errorf("Expected colors and CRLF to be removed, got %q", data)
+	}
+
+	t.Setenv(UpdateEnv, "")
+	AssertSnapshot(t, golden, StripANSI(output))
+
+	r := &recorder{}
+	AssertSnapshot(r, golden, "▄▆█\n0s   2s\n")
+	if len(r.failures) != 1 {
+		t.Fatalf("Expected one failure, got %v", r.failures)
+	}
+	if _, err := os.Stat(golden + ".actual"); err != nil {
+		t.Errorf("Expected the actual output to be written: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+)
+
+// resampleZeroCrossings is the number of sinc zero crossings on each side of an output
+// sample used by Resample; more is sharper but slower
+const resampleZeroCrossings = 16
+
+// Resample returns a copy of the audio converted to targetRate Hz, e.g. to bring files
+// of mixed rates to a common rate before comparing, mixing or concatenating them. It
+// uses a Blackman-windowed sinc interpolator that also low-pass filters below the new
+// Nyquist frequency when downsampling. The duration and channels are kept.
+func (w *Waveform) Resample(targetRate int) (*Waveform, error) {
+	if targetRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate: %d", targetRate)
+	}
+	if w.SampleRate <= 0 {
+		return nil, fmt.Errorf("invalid source sample rate: %d", w.SampleRate)
+	}
+
+	frames := w.availableFrames()
+	if targetRate == w.SampleRate {
+		audioData := make([]int16, frames*w.Channels)
+		copy(audioData, w.audioData)
+		return w.withAudio(targetRate, audioData, frames), nil
+	}
+
+	ratio := float64(w.SampleRate) / float64(targetRate)
+	cutoff := math.Min(1, 1/ratio)
+	halfWidth := resampleZeroCrossings / cutoff // In source frames
+	outFrames := int(math.Round(float64(frames) / ratio))
+
+	audioData := make([]int16, outFrames*w.Channels)
+	sums := make([]float64, w.Channels)
+	for i := 0; i < outFrames; i++ {
+		t := float64(i) * ratio
+		first := max(0, int(math.Ceil(t-halfWidth)))
+		last := min(frames-1, int(math.Floor(t+halfWidth)))
+
+		clear(sums)
+		var weightSum float64
+		for k := first; k <= last; k++ {
+			x := t - float64(k)
+			weight := cutoff * sinc(cutoff*x) * blackman(x/halfWidth)
+			weightSum += weight
+			for ch := range sums {
+				sums[ch] += weight * float64(w.audioData[k*w.Channels+ch])
+			}
+		}
+		if weightSum == 0 {
+			continue
+		}
+		for ch, sum := range sums {
+			// Normalizing by the weights keeps the gain at 1 near the edges
+			v := math.Round(sum / weightSum)
+			audioData[i*w.Channels+ch] = int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, v)))
+		}
+	}
+
+	return w.withAudio(targetRate, audioData, outFrames), nil
+}
+
+// withAudio returns a loaded waveform with the source of w and the given audio
+func (w *Waveform) withAudio(sampleRate int, audioData []int16, frames int) *Waveform {
+	audio := &Waveform{
+		SampleRate:    sampleRate,
+		Channels:      w.Channels,
+		BitsPerSample: w.BitsPerSample,
+		audioData:     audioData,
+		totalSamples:  frames,
+		source:        w.source,
+	}
+	return audio.track()
+}
+
+// sinc returns the normalized sinc function sin(πx)/(πx)
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman returns the Blackman window at x from -1 to 1 (0 outside)
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
@@ -0,0 +1,89 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	tmpFile := "/tmp/test_resample.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 2, sineSamples(44100, 2, 440, 0.5, 1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	resampled, err := waveform.Resample(48000)
+	if err != nil {
+		t.Fatalf("Resample failed: %v", err)
+	}
+	if resampled.SampleRate != 48000 || resampled.Channels != 2 {
+		t.Fatalf("Unexpected format: %d Hz, %d channels", resampled.SampleRate, resampled.Channels)
+	}
+	if resampled.Duration() != 1 {
+		t.Errorf("Expected a duration of 1s, got %.6fs", resampled.Duration())
+	}
+
+	// Away from the edges the result matches the tone sampled at the new rate
+	expected := sineSamples(48000, 2, 440, 0.5, 1)
+	for i := 2000; i < len(expected)-2000; i++ {
+		if diff := math.Abs(float64(resampled.audioData[i]) - float64(expected[i])); diff > 4 {
+			t.Fatalf("Sample %d: expected %d, got %d", i, expected[i], resampled.audioData[i])
+		}
+	}
+}
+
+func TestResampleAntiAliasing(t *testing.T) {
+	tmpFile := "/tmp/test_resample_alias.wav"
+	defer os.Remove(tmpFile)
+
+	// A 15 kHz tone is above the Nyquist frequency of 22050 Hz and must be filtered out
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 15000, 0.5, 1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	resampled, err := waveform.Resample(22050)
+	if err != nil {
+		t.Fatalf("Resample failed: %v", err)
+	}
+	levels, err := resampled.ChannelLevels(0.1, 0.9)
+	if err != nil {
+		t.Fatalf("ChannelLevels failed: %v", err)
+	}
+	if levels[0].Peak > 0.01 {
+		t.Errorf("Expected the tone to be filtered out, got a peak of %.4f", levels[0].Peak)
+	}
+}
+
+func TestResampleSameRate(t *testing.T) {
+	tmpFile := "/tmp/test_resample_same.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, sineSamples(44100, 1, 440, 0.5, 0.1))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	resampled, err := waveform.Resample(44100)
+	if err != nil {
+		t.Fatalf("Resample failed: %v", err)
+	}
+	for i, v := range waveform.audioData {
+		if resampled.audioData[i] != v {
+			t.Fatalf("Sample %d changed: %d to %d", i, v, resampled.audioData[i])
+		}
+	}
+
+	if _, err := waveform.Resample(0); err == nil {
+		t.Error("Expected an error for a zero sample rate")
+	}
+}
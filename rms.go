@@ -0,0 +1,89 @@
+package gowaveform
+
+import "math"
+
+// GenerateRMS computes the RMS level of every pixel of a view, normalized to 0.0..1.0.
+// The pixel layout matches GenerateView for the same options. All channels contribute
+// to each pixel, the same way they do for the min/max peaks.
+func (w *Waveform) GenerateRMS(opts WaveformOptions) ([]float64, error) {
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	numPixels := (endSample - startSample + samplesPerPixel - 1) / samplesPerPixel
+	levels := make([]float64, 0, numPixels)
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		count := samplesPerPixel
+		if pixelStart+count > endSample {
+			count = endSample - pixelStart
+		}
+		levels = append(levels, w.getRMSFromRange(pixelStart, count))
+	}
+
+	return levels, nil
+}
+
+// WaveformDataExtended is WaveformData with the RMS level of every pixel next to its
+// min/max pair, for DAW-style rendering of an RMS body inside the peak envelope.
+// It encodes as the audiowaveform JSON format with an extra "rms" array.
+type WaveformDataExtended struct {
+	WaveformData
+	RMS []int16 `json:"rms"` // RMS level of each pixel across all channels, on the peak scale (0 to 32767)
+}
+
+// GenerateViewExtended computes the min/max peaks and the RMS level of every pixel of
+// a view in a single pass. Peaks, bands and metadata match GenerateView for the same
+// options; the RMS levels are those of GenerateRMS scaled to the peak range, of the
+// unfiltered audio.
+func (w *Waveform) GenerateViewExtended(opts WaveformOptions) (*WaveformDataExtended, error) {
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	waveformData, err := w.newView(opts, samplesPerPixel)
+	if err != nil {
+		return nil, err
+	}
+	peaks, err := w.pixelPeaks(opts, startSample)
+	if err != nil {
+		return nil, err
+	}
+
+	numPixels := (endSample - startSample + samplesPerPixel - 1) / samplesPerPixel
+	rms := make([]int16, 0, numPixels)
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		count := min(samplesPerPixel, endSample-pixelStart)
+		lo, hi := peaks(pixelStart, count)
+		waveformData.Data = append(waveformData.Data, lo, hi)
+		level := math.Round(w.getRMSFromRange(pixelStart, count) * 32768)
+		rms = append(rms, int16(math.Min(level, math.MaxInt16)))
+	}
+
+	waveformData, err = w.finishView(waveformData, opts, startSample, endSample)
+	if err != nil {
+		return nil, err
+	}
+	return &WaveformDataExtended{WaveformData: *waveformData, RMS: rms}, nil
+}
+
+// getRMSFromRange calculates the RMS of a range of frames across all channels, normalized to 0.0..1.0
+func (w *Waveform) getRMSFromRange(startSample, sampleCount int) float64 {
+	startIdx := startSample * w.Channels
+	endIdx := (startSample + sampleCount) * w.Channels
+	if endIdx > len(w.audioData) {
+		endIdx = len(w.audioData)
+	}
+	if startIdx >= endIdx {
+		return 0
+	}
+
+	var sum float64
+	for _, sample := range w.audioData[startIdx:endIdx] {
+		v := float64(sample)
+		sum += v * v
+	}
+
+	return math.Sqrt(sum/float64(endIdx-startIdx)) / 32768.0
+}
@@ -0,0 +1,118 @@
+package gowaveform
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestGenerateRMSSineTone(t *testing.T) {
+	tmpFile := "/tmp/test_rms_tone.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 441)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// 1000 samples per pixel covers exactly 10 periods of a 441 Hz tone
+	levels, err := waveform.GenerateRMS(WaveformOptions{SamplesPerPixel: 1000})
+	if err != nil {
+		t.Fatalf("GenerateRMS failed: %v", err)
+	}
+
+	view, err := waveform.GenerateView(WaveformOptions{SamplesPerPixel: 1000})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if len(levels) != view.Length {
+		t.Errorf("Expected %d RMS levels to match view length, got %d", view.Length, len(levels))
+	}
+
+	expected := 16000 / math.Sqrt2 / 32768.0
+	for i, level := range levels[:len(levels)-1] {
+		if math.Abs(level-expected) > 0.005 {
+			t.Errorf("Pixel %d: expected RMS %.4f, got %.4f", i, expected, level)
+		}
+	}
+}
+
+func TestGenerateRMSSilence(t *testing.T) {
+	tmpFile := "/tmp/test_rms_silence.wav"
+	defer os.Remove(tmpFile)
+
+	writeTestWAV(t, tmpFile, 44100, 1, make([]int16, 4410))
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	levels, err := waveform.GenerateRMS(WaveformOptions{Width: 10})
+	if err != nil {
+		t.Fatalf("GenerateRMS failed: %v", err)
+	}
+	for i, level := range levels {
+		if level != 0 {
+			t.Errorf("Pixel %d: expected zero RMS for silence, got %f", i, level)
+		}
+	}
+}
+
+func TestGenerateViewExtended(t *testing.T) {
+	tmpFile := "/tmp/test_view_extended.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 441)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	opts := WaveformOptions{Width: 200, Metadata: true}
+	extended, err := waveform.GenerateViewExtended(opts)
+	if err != nil {
+		t.Fatalf("GenerateViewExtended failed: %v", err)
+	}
+	view, err := waveform.GenerateView(opts)
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	levels, err := waveform.GenerateRMS(opts)
+	if err != nil {
+		t.Fatalf("GenerateRMS failed: %v", err)
+	}
+
+	if extended.Length != view.Length || len(extended.RMS) != view.Length || extended.Metadata == nil {
+		t.Fatalf("Expected %d pixels with metadata, got length %d, %d RMS levels", view.Length, extended.Length, len(extended.RMS))
+	}
+	for i := range view.Data {
+		if extended.Data[i] != view.Data[i] {
+			t.Fatalf("Peak %d: expected %d, got %d", i, view.Data[i], extended.Data[i])
+		}
+	}
+	for i, level := range levels {
+		if math.Abs(float64(extended.RMS[i])-level*32768) > 1 {
+			t.Errorf("RMS %d: expected %.0f, got %d", i, level*32768, extended.RMS[i])
+		}
+	}
+
+	// The JSON layout is the audiowaveform format plus an rms array
+	jsonData, err := json.Marshal(extended)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(jsonData, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	for _, key := range []string{"version", "samples_per_pixel", "length", "data", "rms", "metadata"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Expected a %q field in %s", key, jsonData[:min(len(jsonData), 200)])
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package gowaveform
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// JSONSchema is the JSON Schema (draft 2020-12) of the JSON peaks gowaveform writes,
+// for services exchanging peak files to publish or validate against. ValidateJSON
+// checks the same rules and the ones a schema cannot express, such as the number of
+// peaks matching the length.
+//
+//go:embed waveform.schema.json
+var JSONSchema string
+
+// Errors returned, wrapped with details, by ValidateJSON and ReadJSONStrict
+var (
+	ErrInvalidJSON        = errors.New("invalid waveform JSON")
+	ErrUnsupportedVersion = errors.New("unsupported format version")
+)
+
+// strictPeaks is the JSON format with pointers, so missing fields can be told apart
+// from zero values, and float peaks, so both kinds of output can be checked
+type strictPeaks struct {
+	Version         *int            `json:"version"`
+	Channels        *int            `json:"channels"`
+	SampleRate      *int            `json:"sample_rate"`
+	SamplesPerPixel *int            `json:"samples_per_pixel"`
+	Bits            *int            `json:"bits"`
+	Length          *int            `json:"length"`
+	Data            *[]float64      `json:"data"`
+	Bands           []strictBand    `json:"bands"`
+	TimeAxis        *strictTimeAxis `json:"time_axis"`
+	Metadata        *Metadata       `json:"metadata"`
+}
+
+// strictBand is BandData with pointers
+type strictBand struct {
+	LowFrequency  *float64   `json:"low_frequency"`
+	HighFrequency *float64   `json:"high_frequency"`
+	Data          *[]float64 `json:"data"`
+}
+
+// strictTimeAxis is TimeAxis with pointers
+type strictTimeAxis struct {
+	Start *float64  `json:"start"`
+	End   *float64  `json:"end"`
+	Times []float64 `json:"times"`
+}
+
+// ValidateJSON checks that data is a single JSON object in the peak format described
+// by JSONSchema, with integer or float peaks. It rejects unknown fields, missing
+// required fields, unsupported versions, a channels field that does not match the
+// version, and peaks, bands or times that do not match the length. Errors wrap
+// ErrInvalidJSON, and ErrUnsupportedVersion for versions other than 1 and 2.
+func ValidateJSON(data []byte) error {
+	_, _, err := decodeStrict(data)
+	return err
+}
+
+// ReadJSONStrict reads peaks like ReadJSON, decompressing gzip or zstd input, but only
+// accepts JSON that passes ValidateJSON, so payloads from other services fail early
+// with a precise error instead of being read with zero values. Float peaks are valid
+// JSON but cannot be read into WaveformData.
+func ReadJSONStrict(r io.Reader) (*WaveformData, error) {
+	zr, err := NewDecompressReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	raw, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON peaks: %w", err)
+	}
+
+	p, float, err := decodeStrict(raw)
+	if err != nil {
+		return nil, err
+	}
+	if float {
+		return nil, fmt.Errorf("failed to read JSON peaks: float peaks are not supported")
+	}
+
+	data := &WaveformData{
+		Version:         *p.Version,
+		SampleRate:      *p.SampleRate,
+		SamplesPerPixel: *p.SamplesPerPixel,
+		Bits:            *p.Bits,
+		Length:          *p.Length,
+		Data:            toPeaks(*p.Data),
+		Metadata:        p.Metadata,
+	}
+	if p.Channels != nil {
+		data.Channels = *p.Channels
+	}
+	for _, band := range p.Bands {
+		data.Bands = append(data.Bands, BandData{
+			LowFrequency:  *band.LowFrequency,
+			HighFrequency: *band.HighFrequency,
+			Data:          toPeaks(*band.Data),
+		})
+	}
+	if p.TimeAxis != nil {
+		data.TimeAxis = &TimeAxis{Start: *p.TimeAxis.Start, End: *p.TimeAxis.End, Times: p.TimeAxis.Times}
+	}
+	data.restoreRange()
+	return data, nil
+}
+
+// invalidJSON returns an error wrapping ErrInvalidJSON
+func invalidJSON(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidJSON}, args...)...)
+}
+
+// decodeStrict decodes and checks JSON peaks, reporting whether the peaks are floats
+func decodeStrict(data []byte) (*strictPeaks, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var p strictPeaks
+	if err := dec.Decode(&p); err != nil {
+		return nil, false, invalidJSON("%v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, false, invalidJSON("unexpected data after the object")
+	}
+
+	for _, field := range []struct {
+		name    string
+		missing bool
+	}{
+		{"version", p.Version == nil},
+		{"sample_rate", p.SampleRate == nil},
+		{"samples_per_pixel", p.SamplesPerPixel == nil},
+		{"bits", p.Bits == nil},
+		{"length", p.Length == nil},
+		{"data", p.Data == nil},
+	} {
+		if field.missing {
+			return nil, false, invalidJSON("missing %s", field.name)
+		}
+	}
+
+	version, length := *p.Version, *p.Length
+	channels := 1
+	switch {
+	case version != 1 && version != 2:
+		return nil, false, fmt.Errorf("%w: %w: %d (supported: 1, 2)", ErrInvalidJSON, ErrUnsupportedVersion, version)
+	case version == 1 && p.Channels != nil:
+		return nil, false, invalidJSON("version 1 has no channels field")
+	case version == 2 && p.Channels == nil:
+		return nil, false, invalidJSON("missing channels, required in version 2")
+	case p.Channels != nil && *p.Channels < 1:
+		return nil, false, invalidJSON("invalid channels: %d", *p.Channels)
+	case *p.SampleRate < 1:
+		return nil, false, invalidJSON("invalid sample_rate: %d", *p.SampleRate)
+	case *p.SamplesPerPixel < 1:
+		return nil, false, invalidJSON("invalid samples_per_pixel: %d", *p.SamplesPerPixel)
+	case *p.Bits != 8 && *p.Bits != 16 && *p.Bits != 24 && *p.Bits != 32:
+		return nil, false, invalidJSON("invalid bits: %d (supported: 8, 16, 24, 32)", *p.Bits)
+	case length < 0:
+		return nil, false, invalidJSON("invalid length: %d", length)
+	}
+	if p.Channels != nil {
+		channels = *p.Channels
+	}
+
+	// One min/max pair per pixel, or one per channel per pixel
+	if n := len(*p.Data); n != 2*length && n != 2*length*channels {
+		return nil, false, invalidJSON("data has %d values, expected %d for length %d", n, 2*length, length)
+	}
+	peaks := [][]float64{*p.Data}
+	for i, band := range p.Bands {
+		switch {
+		case band.LowFrequency == nil || band.HighFrequency == nil || band.Data == nil:
+			return nil, false, invalidJSON("band %d: missing low_frequency, high_frequency or data", i)
+		case *band.LowFrequency < 0 || *band.HighFrequency <= *band.LowFrequency:
+			return nil, false, invalidJSON("band %d: invalid range %.1f to %.1f Hz", i, *band.LowFrequency, *band.HighFrequency)
+		case len(*band.Data) != 2*length:
+			return nil, false, invalidJSON("band %d: data has %d values, expected %d for length %d", i, len(*band.Data), 2*length, length)
+		}
+		peaks = append(peaks, *band.Data)
+	}
+	if axis := p.TimeAxis; axis != nil {
+		switch {
+		case axis.Start == nil || axis.End == nil || axis.Times == nil:
+			return nil, false, invalidJSON("time_axis: missing start, end or times")
+		case *axis.Start < 0 || *axis.End < *axis.Start:
+			return nil, false, invalidJSON("time_axis: invalid range %.3f to %.3f s", *axis.Start, *axis.End)
+		case len(axis.Times) != length:
+			return nil, false, invalidJSON("time_axis: %d times, expected %d for length %d", len(axis.Times), length, length)
+		}
+	}
+
+	// Peaks are all 16-bit integers, or all floats if any is not a whole number
+	float := false
+	for _, values := range peaks {
+		for _, v := range values {
+			float = float || v != math.Trunc(v)
+		}
+	}
+	low, high := -32768.0, 32767.0
+	if float {
+		low, high = -1, 1
+	}
+	for _, values := range peaks {
+		for _, v := range values {
+			if v < low || v > high {
+				return nil, false, invalidJSON("peak %g out of range %g to %g", v, low, high)
+			}
+		}
+	}
+	return &p, float, nil
+}
+
+// toPeaks converts peaks checked by decodeStrict to int16
+func toPeaks(values []float64) []int16 {
+	peaks := make([]int16, len(values))
+	for i, v := range values {
+		peaks[i] = int16(v)
+	}
+	return peaks
+}
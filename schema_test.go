@@ -0,0 +1,172 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// jsonFields returns the JSON names of the fields of a struct type
+func jsonFields(t reflect.Type) []string {
+	var names []string
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// schemaProperties returns the property names of an object in the schema
+func schemaProperties(object map[string]any) []string {
+	var names []string
+	for name := range object["properties"].(map[string]any) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestJSONSchemaMatchesFormat(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(JSONSchema), &schema); err != nil {
+		t.Fatalf("JSONSchema is not valid JSON: %v", err)
+	}
+	properties := schema["properties"].(map[string]any)
+	metadata := properties["metadata"].(map[string]any)
+
+	for _, tt := range []struct {
+		name   string
+		object map[string]any
+		typ    any
+	}{
+		{"peaks", schema, WaveformData{}},
+		{"bands", properties["bands"].(map[string]any)["items"].(map[string]any), BandData{}},
+		{"time_axis", properties["time_axis"].(map[string]any), TimeAxis{}},
+		{"metadata", metadata, Metadata{}},
+		{"metadata options", metadata["properties"].(map[string]any)["options"].(map[string]any), MetadataOptions{}},
+	} {
+		if got, want := schemaProperties(tt.object), jsonFields(reflect.TypeOf(tt.typ)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: schema has properties %v, the Go type has %v", tt.name, got, want)
+		}
+	}
+	if !reflect.DeepEqual(jsonFields(reflect.TypeOf(WaveformData{})), jsonFields(reflect.TypeOf(WaveformDataFloat{}))) {
+		t.Error("Integer and float peaks have different fields")
+	}
+}
+
+func TestValidateJSONOutput(t *testing.T) {
+	samples := make([]int16, 2*8000)
+	for i := range samples {
+		samples[i] = int16((i%200 - 100) * 300)
+	}
+	w := &Waveform{SampleRate: 8000, Channels: 2, BitsPerSample: 16, audioData: samples, totalSamples: 8000}
+
+	views := map[string]WaveformOptions{
+		"plain":      {Width: 100},
+		"version 1":  {Width: 20, FormatVersion: 1},
+		"bands":      {Width: 50, Bands: []float64{200, 2000}},
+		"symmetric":  {Width: 50, Symmetric: true},
+		"everything": {Start: 0.25, Width: 10, Bands: []float64{500}, TimeAxis: true, Metadata: true},
+	}
+	for name, opts := range views {
+		data, err := w.GenerateView(opts)
+		if err != nil {
+			continue // Views the generator rejects need no validating
+		}
+		out, err := GenerateJSON(data)
+		if err != nil {
+			t.Fatalf("%s: GenerateJSON failed: %v", name, err)
+		}
+		if err := ValidateJSON(out); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+
+		// Strict reading gives the same peaks as lenient reading
+		strict, err := ReadJSONStrict(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("%s: ReadJSONStrict failed: %v", name, err)
+		}
+		lenient, err := ReadJSON(bytes.NewReader(out))
+		if err != nil {
+			t.Fatalf("%s: ReadJSON failed: %v", name, err)
+		}
+		if !reflect.DeepEqual(strict, lenient) {
+			t.Errorf("%s: strict and lenient reading differ:\n%+v\n%+v", name, strict, lenient)
+		}
+
+		float, err := json.Marshal(data.Float())
+		if err != nil {
+			t.Fatalf("%s: marshaling float peaks failed: %v", name, err)
+		}
+		if err := ValidateJSON(float); err != nil {
+			t.Errorf("%s as floats: %v", name, err)
+		}
+	}
+}
+
+func TestValidateJSONErrors(t *testing.T) {
+	valid := `{"version": 2, "channels": 2, "sample_rate": 8000, "samples_per_pixel": 256, "bits": 16, "length": 2, "data": [-1, 1, -2, 2]}`
+	if err := ValidateJSON([]byte(valid)); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+	if _, err := ReadJSONStrict(strings.NewReader(valid)); err != nil {
+		t.Fatalf("ReadJSONStrict failed: %v", err)
+	}
+
+	// Replacements in the valid JSON
+	tests := []struct {
+		name     string
+		old, new string
+		err      error
+	}{
+		{"not JSON", `{"version"`, `["version"`, ErrInvalidJSON},
+		{"trailing data", `2]}`, `2]} {}`, ErrInvalidJSON},
+		{"unknown field", `"bits"`, `"colour": 1, "bits"`, ErrInvalidJSON},
+		{"missing field", `"samples_per_pixel": 256, `, ``, ErrInvalidJSON},
+		{"version 3", `"version": 2`, `"version": 3`, ErrUnsupportedVersion},
+		{"version 1 with channels", `"version": 2`, `"version": 1`, ErrInvalidJSON},
+		{"version 2 without channels", `"channels": 2, `, ``, ErrInvalidJSON},
+		{"wrong type", `"length": 2`, `"length": "2"`, ErrInvalidJSON},
+		{"fractional integer", `"sample_rate": 8000`, `"sample_rate": 8000.5`, ErrInvalidJSON},
+		{"zero sample rate", `"sample_rate": 8000`, `"sample_rate": 0`, ErrInvalidJSON},
+		{"unsupported bits", `"bits": 16`, `"bits": 12`, ErrInvalidJSON},
+		{"data short of length", `"length": 2`, `"length": 3`, ErrInvalidJSON},
+		{"null data", `[-1, 1, -2, 2]`, `null`, ErrInvalidJSON},
+		{"peak out of range", `[-1, 1, -2, 2]`, `[-1, 1, -2, 40000]`, ErrInvalidJSON},
+		{"float peak out of range", `[-1, 1, -2, 2]`, `[-0.5, 0.5, -0.25, 2]`, ErrInvalidJSON},
+		{"band data short of length", `"data"`, `"bands": [{"low_frequency": 0, "high_frequency": 200, "data": [0, 1]}], "data"`, ErrInvalidJSON},
+		{"band without data", `"data"`, `"bands": [{"low_frequency": 0, "high_frequency": 200}], "data"`, ErrInvalidJSON},
+		{"inverted band", `"data"`, `"bands": [{"low_frequency": 200, "high_frequency": 100, "data": [0, 1, 0, 1]}], "data"`, ErrInvalidJSON},
+		{"times short of length", `"data"`, `"time_axis": {"start": 0, "end": 1, "times": [0]}, "data"`, ErrInvalidJSON},
+		{"bad timestamp", `"data"`, `"metadata": {"generated": "yesterday"}, "data"`, ErrInvalidJSON},
+	}
+	for _, tt := range tests {
+		payload := strings.Replace(valid, tt.old, tt.new, 1)
+		if payload == valid {
+			t.Fatalf("%s: replacement did not apply", tt.name)
+		}
+		err := ValidateJSON([]byte(payload))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.err, err)
+		}
+		if _, err := ReadJSONStrict(strings.NewReader(payload)); !errors.Is(err, tt.err) {
+			t.Errorf("%s: ReadJSONStrict expected %v, got %v", tt.name, tt.err, err)
+		}
+	}
+
+	// Float peaks are valid but cannot be read into WaveformData
+	float := strings.Replace(valid, `[-1, 1, -2, 2]`, `[-0.5, 0.5, -0.25, 0.25]`, 1)
+	if err := ValidateJSON([]byte(float)); err != nil {
+		t.Errorf("Expected float peaks to be valid, got %v", err)
+	}
+	if _, err := ReadJSONStrict(strings.NewReader(float)); err == nil {
+		t.Error("Expected an error reading float peaks strictly")
+	}
+}
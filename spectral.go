@@ -0,0 +1,104 @@
+package gowaveform
+
+import (
+	"image/color"
+	"math"
+)
+
+// Crossover frequencies (Hz) used to split audio into low, mid and high bands
+const (
+	spectralLowCutoff  = 200.0
+	spectralHighCutoff = 2000.0
+)
+
+// BandEnergy holds the RMS energy of the low, mid and high frequency bands for one pixel
+type BandEnergy struct {
+	Low  float64
+	Mid  float64
+	High float64
+}
+
+// Color maps the band energies to a color where low frequencies are red,
+// mid frequencies are green and high frequencies are blue.
+// The dominant band is drawn at full intensity and the others are scaled relative to it.
+func (b BandEnergy) Color() color.RGBA {
+	maxEnergy := math.Max(b.Low, math.Max(b.Mid, b.High))
+	if maxEnergy <= 0 {
+		return color.RGBA{R: 128, G: 128, B: 128, A: 255}
+	}
+	return color.RGBA{
+		R: uint8(255 * b.Low / maxEnergy),
+		G: uint8(255 * b.Mid / maxEnergy),
+		B: uint8(255 * b.High / maxEnergy),
+		A: 255,
+	}
+}
+
+// GenerateBandEnergies computes the low/mid/high band energy for every pixel of a view.
+// The pixel layout matches GenerateView for the same options, so the result can be
+// used to color each min/max column of the corresponding WaveformData.
+func (w *Waveform) GenerateBandEnergies(opts WaveformOptions) ([]BandEnergy, error) {
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	lowBand := newBandPass(w.SampleRate, 0, spectralLowCutoff)
+	midBand := newBandPass(w.SampleRate, spectralLowCutoff, spectralHighCutoff)
+	highBand := newBandPass(w.SampleRate, spectralHighCutoff, 0)
+
+	// Warm up the filters on the audio just before the range
+	prerollStart := startSample - filterPreroll
+	if prerollStart < 0 {
+		prerollStart = 0
+	}
+	for i := prerollStart; i < startSample; i++ {
+		x := w.monoSample(i)
+		lowBand.process(x)
+		midBand.process(x)
+		highBand.process(x)
+	}
+
+	numPixels := (endSample - startSample + samplesPerPixel - 1) / samplesPerPixel
+	energies := make([]BandEnergy, 0, numPixels)
+
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		pixelEnd := pixelStart + samplesPerPixel
+		if pixelEnd > endSample {
+			pixelEnd = endSample
+		}
+
+		var low, mid, high float64
+		for i := pixelStart; i < pixelEnd; i++ {
+			x := w.monoSample(i)
+			l := lowBand.process(x)
+			m := midBand.process(x)
+			h := highBand.process(x)
+			low += l * l
+			mid += m * m
+			high += h * h
+		}
+
+		n := float64(pixelEnd - pixelStart)
+		energies = append(energies, BandEnergy{
+			Low:  math.Sqrt(low / n),
+			Mid:  math.Sqrt(mid / n),
+			High: math.Sqrt(high / n),
+		})
+	}
+
+	return energies, nil
+}
+
+// monoSample returns the average of all channels for a frame, normalized to -1.0..1.0
+func (w *Waveform) monoSample(frame int) float64 {
+	if w.Channels <= 0 {
+		return 0
+	}
+	offset := frame * w.Channels
+	var sum float64
+	for ch := 0; ch < w.Channels; ch++ {
+		sum += float64(w.audioData[offset+ch])
+	}
+	return sum / float64(w.Channels) / 32768.0
+}
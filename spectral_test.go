@@ -0,0 +1,112 @@
+package gowaveform
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerateBandEnergiesLowTone(t *testing.T) {
+	tmpFile := "/tmp/test_spectral_low.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 60)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	energies, err := waveform.GenerateBandEnergies(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateBandEnergies failed: %v", err)
+	}
+
+	// Skip the first pixels while the filters settle
+	for i, e := range energies[5:] {
+		if e.Low <= e.Mid || e.Low <= e.High {
+			t.Fatalf("Pixel %d: expected low band to dominate for 60 Hz tone, got %+v", i+5, e)
+		}
+	}
+
+	c := energies[50].Color()
+	if c.R != 255 || c.B > 50 {
+		t.Errorf("Expected red color for low tone, got %+v", c)
+	}
+}
+
+func TestGenerateBandEnergiesHighTone(t *testing.T) {
+	tmpFile := "/tmp/test_spectral_high.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 8000)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	energies, err := waveform.GenerateBandEnergies(WaveformOptions{Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateBandEnergies failed: %v", err)
+	}
+
+	for i, e := range energies[5:] {
+		if e.High <= e.Low || e.High <= e.Mid {
+			t.Fatalf("Pixel %d: expected high band to dominate for 8 kHz tone, got %+v", i+5, e)
+		}
+	}
+
+	c := energies[50].Color()
+	if c.B != 255 || c.R > 50 {
+		t.Errorf("Expected blue color for high tone, got %+v", c)
+	}
+}
+
+func TestGenerateBandEnergiesMatchesViewLayout(t *testing.T) {
+	tmpFile := "/tmp/test_spectral_layout.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	opts := WaveformOptions{Start: 0.25, End: 1.5, Width: 333}
+	view, err := waveform.GenerateView(opts)
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	energies, err := waveform.GenerateBandEnergies(opts)
+	if err != nil {
+		t.Fatalf("GenerateBandEnergies failed: %v", err)
+	}
+
+	if len(energies) != view.Length {
+		t.Errorf("Expected %d band energies to match view length, got %d", view.Length, len(energies))
+	}
+}
+
+func TestGenerateBandEnergiesInvalidRange(t *testing.T) {
+	tmpFile := "/tmp/test_spectral_invalid.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if _, err := waveform.GenerateBandEnergies(WaveformOptions{Start: 0.8, End: 0.2}); err == nil {
+		t.Error("Expected error for start after end")
+	}
+}
+
+func TestBandEnergySilenceColor(t *testing.T) {
+	c := BandEnergy{}.Color()
+	if c.R != c.G || c.G != c.B {
+		t.Errorf("Expected neutral gray for silence, got %+v", c)
+	}
+}
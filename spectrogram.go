@@ -0,0 +1,159 @@
+package gowaveform
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"math/cmplx"
+)
+
+// DefaultFFTSize is the FFT size used by GenerateSpectrogram when none is given
+const DefaultFFTSize = 1024
+
+// SpectrogramFloor is the lowest level reported in a spectrogram, in dBFS
+const SpectrogramFloor = -120.0
+
+// spectrogramRange is the dynamic range in dB mapped by SpectrogramColor
+const spectrogramRange = 90.0
+
+// Spectrogram holds the magnitude spectrum of every pixel of a view
+type Spectrogram struct {
+	SampleRate      int         `json:"sample_rate"`
+	FFTSize         int         `json:"fft_size"`
+	SamplesPerPixel int         `json:"samples_per_pixel"`
+	Columns         [][]float64 `json:"columns"` // Columns[pixel][bin] is the level in dBFS of bins 0 to FFTSize/2
+}
+
+// BinFrequency returns the center frequency in Hz of an FFT bin
+func (s *Spectrogram) BinFrequency(bin int) float64 {
+	return float64(bin) * float64(s.SampleRate) / float64(s.FFTSize)
+}
+
+// Level returns the loudest level in dBFS of a column between two frequencies in Hz.
+// If the range falls between two bins, the nearest bin is used.
+func (s *Spectrogram) Level(column int, low, high float64) float64 {
+	if column < 0 || column >= len(s.Columns) {
+		return SpectrogramFloor
+	}
+	bins := s.Columns[column]
+	binWidth := float64(s.SampleRate) / float64(s.FFTSize)
+
+	first := int(math.Ceil(low / binWidth))
+	last := int(math.Floor(high / binWidth))
+	if first > last {
+		first = int(math.Round((low + high) / 2 / binWidth))
+		last = first
+	}
+	if first < 0 {
+		first = 0
+	}
+	if last >= len(bins) {
+		last = len(bins) - 1
+	}
+
+	level := SpectrogramFloor
+	for bin := first; bin <= last; bin++ {
+		if bins[bin] > level {
+			level = bins[bin]
+		}
+	}
+	return level
+}
+
+// SpectrogramColor maps a level in dBFS to a heat color, from black for
+// 90 dB below full scale through blue, purple, red and yellow to white at 0 dBFS
+func SpectrogramColor(level float64) color.RGBA {
+	t := 1 + level/spectrogramRange
+	if t < 0 {
+		t = 0
+	}
+	if t > 1 {
+		t = 1
+	}
+
+	stops := []color.RGBA{
+		{0, 0, 0, 255},
+		{20, 10, 120, 255},
+		{150, 20, 150, 255},
+		{230, 50, 40, 255},
+		{250, 200, 30, 255},
+		{255, 255, 255, 255},
+	}
+	pos := t * float64(len(stops)-1)
+	i := int(pos)
+	if i >= len(stops)-1 {
+		return stops[len(stops)-1]
+	}
+	frac := pos - float64(i)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(float64(a) + (float64(b)-float64(a))*frac)
+	}
+	return color.RGBA{
+		R: lerp(stops[i].R, stops[i+1].R),
+		G: lerp(stops[i].G, stops[i+1].G),
+		B: lerp(stops[i].B, stops[i+1].B),
+		A: 255,
+	}
+}
+
+// GenerateSpectrogram computes a Hann-windowed FFT centered on every pixel of a view.
+// The pixel layout matches GenerateView for the same options. Channels are mixed to mono.
+// fftSize must be a power of two; 0 selects DefaultFFTSize. When a pixel spans more
+// samples than the FFT size, only the samples around its center are analyzed.
+func (w *Waveform) GenerateSpectrogram(opts WaveformOptions, fftSize int) (*Spectrogram, error) {
+	if fftSize == 0 {
+		fftSize = DefaultFFTSize
+	}
+	if !isPowerOfTwo(fftSize) || fftSize < 16 {
+		return nil, fmt.Errorf("invalid FFT size %d: must be a power of two of at least 16", fftSize)
+	}
+
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	window, windowSum := hannWindow(fftSize)
+	available := w.availableFrames()
+	buf := make([]complex128, fftSize)
+
+	spectrogram := &Spectrogram{
+		SampleRate:      w.SampleRate,
+		FFTSize:         fftSize,
+		SamplesPerPixel: samplesPerPixel,
+	}
+
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		pixelEnd := pixelStart + samplesPerPixel
+		if pixelEnd > endSample {
+			pixelEnd = endSample
+		}
+
+		// Window centered on the pixel, zero-padded outside the audio
+		frameStart := (pixelStart+pixelEnd)/2 - fftSize/2
+		for i := range buf {
+			frame := frameStart + i
+			var x float64
+			if frame >= 0 && frame < available {
+				x = w.monoSample(frame) * window[i]
+			}
+			buf[i] = complex(x, 0)
+		}
+
+		fft(buf)
+
+		column := make([]float64, fftSize/2+1)
+		for bin := range column {
+			// Scale so a full-scale sine reads 0 dBFS
+			magnitude := 2 * cmplx.Abs(buf[bin]) / windowSum
+			level := SpectrogramFloor
+			if magnitude > 0 {
+				level = math.Max(SpectrogramFloor, 20*math.Log10(magnitude))
+			}
+			column[bin] = level
+		}
+		spectrogram.Columns = append(spectrogram.Columns, column)
+	}
+
+	return spectrogram, nil
+}
@@ -0,0 +1,89 @@
+package gowaveform
+
+import (
+	"math"
+	"math/cmplx"
+	"os"
+	"testing"
+)
+
+func TestFFT(t *testing.T) {
+	const n = 64
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(math.Cos(2*math.Pi*5*float64(i)/n), 0)
+	}
+	fft(x)
+
+	for k, v := range x {
+		magnitude := cmplx.Abs(v)
+		if k == 5 || k == n-5 {
+			if math.Abs(magnitude-n/2) > 1e-9 {
+				t.Errorf("Bin %d: expected magnitude %d, got %f", k, n/2, magnitude)
+			}
+		} else if magnitude > 1e-9 {
+			t.Errorf("Bin %d: expected no energy, got %f", k, magnitude)
+		}
+	}
+}
+
+func TestGenerateSpectrogram(t *testing.T) {
+	tmpFile := "/tmp/test_spectrogram.wav"
+	defer os.Remove(tmpFile)
+
+	createToneWAV(t, tmpFile, 44100, 1.0, 1000)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	spectrogram, err := waveform.GenerateSpectrogram(WaveformOptions{Width: 20}, 2048)
+	if err != nil {
+		t.Fatalf("GenerateSpectrogram failed: %v", err)
+	}
+
+	view, _ := waveform.GenerateView(WaveformOptions{Width: 20})
+	if len(spectrogram.Columns) != view.Length {
+		t.Fatalf("Expected %d columns to match the view, got %d", view.Length, len(spectrogram.Columns))
+	}
+	if len(spectrogram.Columns[0]) != 2048/2+1 {
+		t.Errorf("Expected %d bins, got %d", 2048/2+1, len(spectrogram.Columns[0]))
+	}
+
+	// The tone has an amplitude of 16000/32768, about -6.2 dBFS
+	expectedLevel := 20 * math.Log10(16000.0/32768.0)
+	column := spectrogram.Columns[10]
+	peakBin := 0
+	for bin := range column {
+		if column[bin] > column[peakBin] {
+			peakBin = bin
+		}
+	}
+	if f := spectrogram.BinFrequency(peakBin); math.Abs(f-1000) > 44100.0/2048 {
+		t.Errorf("Expected peak near 1000 Hz, got %.1f Hz", f)
+	}
+	if level := spectrogram.Level(10, 900, 1100); math.Abs(level-expectedLevel) > 1.5 {
+		t.Errorf("Expected level near %.1f dBFS, got %.1f dBFS", expectedLevel, level)
+	}
+	if level := spectrogram.Level(10, 8000, 10000); level > expectedLevel-60 {
+		t.Errorf("Expected little energy at 8-10 kHz, got %.1f dBFS", level)
+	}
+
+	if _, err := waveform.GenerateSpectrogram(WaveformOptions{Width: 20}, 1000); err == nil {
+		t.Error("Expected error for FFT size that is not a power of two")
+	}
+}
+
+func TestSpectrogramColor(t *testing.T) {
+	if c := SpectrogramColor(SpectrogramFloor); c.R != 0 || c.G != 0 || c.B != 0 {
+		t.Errorf("Expected black at the floor, got %v", c)
+	}
+	if c := SpectrogramColor(0); c.R != 255 || c.G != 255 || c.B != 255 {
+		t.Errorf("Expected white at full scale, got %v", c)
+	}
+	quiet, loud := SpectrogramColor(-60), SpectrogramColor(-20)
+	if int(quiet.R)+int(quiet.G)+int(quiet.B) >= int(loud.R)+int(loud.G)+int(loud.B) {
+		t.Errorf("Expected louder levels to be brighter: %v vs %v", quiet, loud)
+	}
+}
@@ -0,0 +1,92 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// TimeFormat selects how time positions are labeled on rulers and axes
+type TimeFormat int
+
+const (
+	// TimeFormatSeconds labels time in seconds (e.g. 1.50)
+	TimeFormatSeconds TimeFormat = iota
+	// TimeFormatMinutes labels time as mm:ss.mmm (e.g. 01:02.500)
+	TimeFormatMinutes
+	// TimeFormatTimecode labels time as SMPTE non-drop-frame timecode hh:mm:ss:ff
+	TimeFormatTimecode
+	// TimeFormatSamples labels time as a sample index
+	TimeFormatSamples
+)
+
+// DefaultFrameRate is the frame rate used for SMPTE timecode when none is given
+const DefaultFrameRate = 30.0
+
+// String returns the name of the time format as accepted by ParseTimeFormat
+func (f TimeFormat) String() string {
+	switch f {
+	case TimeFormatMinutes:
+		return "minutes"
+	case TimeFormatTimecode:
+		return "timecode"
+	case TimeFormatSamples:
+		return "samples"
+	default:
+		return "seconds"
+	}
+}
+
+// ParseTimeFormat parses a time format name: seconds, minutes, timecode or samples
+func ParseTimeFormat(name string) (TimeFormat, error) {
+	switch strings.ToLower(name) {
+	case "seconds", "s", "":
+		return TimeFormatSeconds, nil
+	case "minutes", "mm:ss", "mm:ss.mmm":
+		return TimeFormatMinutes, nil
+	case "timecode", "smpte":
+		return TimeFormatTimecode, nil
+	case "samples":
+		return TimeFormatSamples, nil
+	default:
+		return TimeFormatSeconds, fmt.Errorf("unknown time format: %s (supported: seconds, minutes, timecode, samples)", name)
+	}
+}
+
+// FormatTime formats a time in seconds using the given format.
+// The sample rate is used for TimeFormatSamples and the frame rate for TimeFormatTimecode
+// (DefaultFrameRate if not positive). Seconds are printed with two decimals.
+func FormatTime(seconds float64, format TimeFormat, sampleRate int, frameRate float64) string {
+	sign := ""
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+
+	switch format {
+	case TimeFormatMinutes:
+		totalMillis := int64(math.Round(seconds * 1000))
+		minutes := totalMillis / 60000
+		secs := (totalMillis % 60000) / 1000
+		millis := totalMillis % 1000
+		return fmt.Sprintf("%s%02d:%02d.%03d", sign, minutes, secs, millis)
+
+	case TimeFormatTimecode:
+		if frameRate <= 0 {
+			frameRate = DefaultFrameRate
+		}
+		// Non-drop-frame timecode counts whole frames at the nominal rate
+		nominal := int64(math.Round(frameRate))
+		totalFrames := int64(math.Floor(seconds*frameRate + 1e-9))
+		frames := totalFrames % nominal
+		totalSeconds := totalFrames / nominal
+		return fmt.Sprintf("%s%02d:%02d:%02d:%02d", sign,
+			totalSeconds/3600, (totalSeconds/60)%60, totalSeconds%60, frames)
+
+	case TimeFormatSamples:
+		return fmt.Sprintf("%s%d", sign, int64(math.Round(seconds*float64(sampleRate))))
+
+	default:
+		return fmt.Sprintf("%s%.2f", sign, seconds)
+	}
+}
@@ -0,0 +1,50 @@
+package gowaveform
+
+import "testing"
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		seconds  float64
+		format   TimeFormat
+		fps      float64
+		expected string
+	}{
+		{1.5, TimeFormatSeconds, 0, "1.50"},
+		{62.5, TimeFormatMinutes, 0, "01:02.500"},
+		{0.0004, TimeFormatMinutes, 0, "00:00.000"},
+		{3725.04, TimeFormatMinutes, 0, "62:05.040"},
+		{1.5, TimeFormatTimecode, 25, "00:00:01:12"},
+		{3661.0, TimeFormatTimecode, 30, "01:01:01:00"},
+		{0.999, TimeFormatTimecode, 0, "00:00:00:29"},
+		{1.0, TimeFormatTimecode, 29.97, "00:00:00:29"},
+		{0.5, TimeFormatSamples, 0, "22050"},
+		{-1.5, TimeFormatMinutes, 0, "-00:01.500"},
+	}
+
+	for _, tt := range tests {
+		got := FormatTime(tt.seconds, tt.format, 44100, tt.fps)
+		if got != tt.expected {
+			t.Errorf("FormatTime(%v, %v, fps %v) = %q, expected %q", tt.seconds, tt.format, tt.fps, got, tt.expected)
+		}
+	}
+}
+
+func TestParseTimeFormat(t *testing.T) {
+	for _, format := range []TimeFormat{TimeFormatSeconds, TimeFormatMinutes, TimeFormatTimecode, TimeFormatSamples} {
+		parsed, err := ParseTimeFormat(format.String())
+		if err != nil {
+			t.Errorf("ParseTimeFormat(%q) failed: %v", format.String(), err)
+		}
+		if parsed != format {
+			t.Errorf("ParseTimeFormat(%q) = %v, expected %v", format.String(), parsed, format)
+		}
+	}
+
+	if format, err := ParseTimeFormat("SMPTE"); err != nil || format != TimeFormatTimecode {
+		t.Errorf("Expected SMPTE to parse as timecode, got %v (%v)", format, err)
+	}
+
+	if _, err := ParseTimeFormat("fortnights"); err == nil {
+		t.Error("Expected error for unknown time format")
+	}
+}
@@ -0,0 +1,88 @@
+package gowaveform
+
+import (
+	"math"
+	"slices"
+)
+
+// truePeakPhases are the coefficients of the 4x oversampling interpolation filter of
+// ITU-R BS.1770-4 Annex 2, one row of 12 taps per phase. Phase k interpolates the
+// point (2k+1)/8 of the way between the input samples 6 and 5 taps back.
+var truePeakPhases = [4][12]float64{
+	{0.0017089843750, 0.0109863281250, -0.0196533203125, 0.0332031250000, -0.0594482421875, 0.1373291015625, 0.9721679687500, -0.1022949218750, 0.0476074218750, -0.0266113281250, 0.0148925781250, -0.0083007812500},
+	{-0.0291748046875, 0.0292968750000, -0.0517578125000, 0.0891113281250, -0.1665039062500, 0.4650878906250, 0.7797851562500, -0.2003173828125, 0.1015625000000, -0.0582275390625, 0.0330810546875, -0.0189208984375},
+	{-0.0189208984375, 0.0330810546875, -0.0582275390625, 0.1015625000000, -0.2003173828125, 0.7797851562500, 0.4650878906250, -0.1665039062500, 0.0891113281250, -0.0517578125000, 0.0292968750000, -0.0291748046875},
+	{-0.0083007812500, 0.0148925781250, -0.0266113281250, 0.0476074218750, -0.1022949218750, 0.9721679687500, 0.1373291015625, -0.0594482421875, 0.0332031250000, -0.0196533203125, 0.0109863281250, 0.0017089843750},
+}
+
+// TruePeak returns the true peak of the audio in dBTP, the highest level of any channel
+// after 4x oversampling as specified by ITU-R BS.1770, and the times in seconds of the
+// inter-sample overs: points where the reconstructed signal exceeds full scale although
+// no sample does. Plain sample peaks under-report for hot masters, which clip in
+// converters and lossy encoders. Consecutive over samples count as one over, at its
+// highest point. The true peak is never below the sample peak; silence is -Inf.
+func TruePeak(w *Waveform) (dbTP float64, positions []float64) {
+	return w.truePeak(0, w.availableFrames())
+}
+
+// truePeak measures the true peak and overs of the frames from startFrame to endFrame
+func (w *Waveform) truePeak(startFrame, endFrame int) (dbTP float64, positions []float64) {
+	if w.Channels == 0 || startFrame >= endFrame {
+		return math.Inf(-1), nil
+	}
+	frames := w.availableFrames()
+	sample := func(frame, ch int) float64 {
+		if frame < 0 || frame >= frames {
+			return 0
+		}
+		return float64(w.audioData[frame*w.Channels+ch]) / 32768.0
+	}
+
+	// Overs of every channel, with the frame they follow
+	type over struct {
+		frame int
+		level float64
+		time  float64
+	}
+	var overs []over
+	var peak float64
+
+	var window [12]float64
+	for ch := 0; ch < w.Channels; ch++ {
+		for frame := startFrame; frame < endFrame; frame++ {
+			// The phases lie between this frame (tap 6) and the next (tap 5)
+			for j := range window {
+				window[j] = sample(frame+6-j, ch)
+			}
+			peak = math.Max(peak, math.Abs(window[6]))
+			for k, phase := range truePeakPhases {
+				var y float64
+				for j, h := range phase {
+					y += h * window[j]
+				}
+				y = math.Abs(y)
+				peak = math.Max(peak, y)
+				if y > 1 {
+					time := (float64(frame) + float64(2*k+1)/8) / float64(w.SampleRate)
+					overs = append(overs, over{frame: frame, level: y, time: time})
+				}
+			}
+		}
+	}
+
+	// Merge overs in the same or neighbouring frames of any channel into one
+	slices.SortStableFunc(overs, func(a, b over) int { return a.frame - b.frame })
+	var level float64
+	for i, o := range overs {
+		switch {
+		case i == 0 || o.frame > overs[i-1].frame+1:
+			positions = append(positions, o.time)
+			level = o.level
+		case o.level > level:
+			positions[len(positions)-1] = o.time
+			level = o.level
+		}
+	}
+
+	return ToDBFS(peak), positions
+}
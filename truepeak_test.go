@@ -0,0 +1,59 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+// hotSamples returns frames of a sine at a quarter of the sample rate with its peaks
+// between the samples, which read 3 dB below the amplitude
+func hotSamples(frames int, amplitude float64) []float64 {
+	samples := make([]float64, frames)
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(math.Pi/2*float64(i)+math.Pi/4)
+	}
+	return samples
+}
+
+func TestTruePeak(t *testing.T) {
+	// A quiet tone has no overs and reads its amplitude
+	dbTP, positions := TruePeak(toneWaveform(48000, 1, 1000, 0.5, 1))
+	if math.Abs(dbTP-ToDBFS(0.5)) > 0.1 || len(positions) != 0 {
+		t.Errorf("Expected a true peak of %.2f dBTP without overs, got %.2f and %v", ToDBFS(0.5), dbTP, positions)
+	}
+
+	// 1s of silence, 20 frames of a sine at 1.2 times full scale whose samples stay
+	// below full scale, and 1s of silence, in the right channel only
+	const rate = 48000
+	w := &Waveform{SampleRate: rate, Channels: 2, BitsPerSample: 16}
+	w.audioData = make([]int16, 2*rate)
+	for _, v := range hotSamples(20, 1.2) {
+		w.audioData = append(w.audioData, 0, int16(v*32768))
+	}
+	w.audioData = append(w.audioData, make([]int16, 2*rate)...)
+	w.totalSamples = len(w.audioData) / 2
+
+	levels, _ := w.ChannelLevels(0, 0)
+	if levels[1].PeakDBFS() > -1 {
+		t.Fatalf("Expected sample peaks below -1 dBFS, got %.2f", levels[1].PeakDBFS())
+	}
+	dbTP, positions = TruePeak(w)
+	// 4x oversampling reads the peaks to within a few tenths of a dB
+	if math.Abs(dbTP-ToDBFS(1.2)) > 0.3 {
+		t.Errorf("Expected a true peak of about %.2f dBTP, got %.2f", ToDBFS(1.2), dbTP)
+	}
+	if len(positions) != 10 {
+		t.Fatalf("Expected an over at each of the 10 peaks, got %v", positions)
+	}
+	for _, p := range positions {
+		if p < 1 || p > 1+20.0/rate {
+			t.Errorf("Expected overs within the hot frames, got one at %.6fs", p)
+		}
+	}
+
+	// Silence
+	silent := &Waveform{SampleRate: rate, Channels: 1, BitsPerSample: 16, audioData: make([]int16, rate), totalSamples: rate}
+	if dbTP, positions := TruePeak(silent); !math.IsInf(dbTP, -1) || positions != nil {
+		t.Errorf("Expected -Inf without overs for silence, got %.2f and %v", dbTP, positions)
+	}
+}
@@ -0,0 +1,60 @@
+package gowaveform
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSaveVectorscopePlot(t *testing.T) {
+	tmpWav := "/tmp/test_vectorscope.wav"
+	tmpPlot := "/tmp/test_vectorscope.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	// Left and right a quarter period apart, which draws a circle
+	left := sineSamples(44100, 1, 441, 0.5, 0.5)
+	samples := make([]int16, 0, 2*len(left))
+	for i, v := range left {
+		samples = append(samples, v, left[(i+25)%len(left)])
+	}
+	writeTestWAV(t, tmpWav, 44100, 2, samples)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	err = SaveVectorscopePlot(waveform, tmpPlot,
+		OptionSetStart(0.1),
+		OptionSetEnd(0.2),
+		OptionSetBackgroundColor("#000000"),
+		OptionSetForegroundColor("#00FF00"),
+		OptionSetTitle("Vectorscope"),
+	)
+	if err != nil {
+		t.Fatalf("SaveVectorscopePlot failed: %v", err)
+	}
+	verifyImageFile(t, tmpPlot)
+
+	if err := SaveVectorscopePlot(waveform, "/tmp/test_vectorscope.gif"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestSaveVectorscopePlotMono(t *testing.T) {
+	tmpWav := "/tmp/test_vectorscope_mono.wav"
+	tmpPlot := "/tmp/test_vectorscope_mono.jpg"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpWav, 44100, 0.5)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	if err := SaveVectorscopePlot(waveform, tmpPlot, OptionSetWidth(200), OptionSetHeight(200)); err != nil {
+		t.Fatalf("SaveVectorscopePlot failed: %v", err)
+	}
+	verifyImageFile(t, tmpPlot)
+}
@@ -0,0 +1,254 @@
+// Package video renders a waveform with a moving playhead to an MP4 or WebM file by
+// piping raw frames to an ffmpeg subprocess.
+package video
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/schollz/gowaveform"
+)
+
+// ErrFFmpegNotFound is returned when the ffmpeg executable cannot be found
+var ErrFFmpegNotFound = errors.New("ffmpeg not found")
+
+// config holds the configuration for rendering a video
+type config struct {
+	width         int
+	height        int
+	fps           float64
+	start         float64             // Start time in seconds (0 = beginning)
+	end           float64             // End time in seconds (0 = use full duration)
+	playheadColor string              // Hex color of the playhead
+	audioFile     string              // File to take the audio track from ("" = silent video)
+	ffmpegPath    string              // ffmpeg executable, looked up in PATH if not absolute
+	plotOpts      []gowaveform.Option // Styling of the waveform frame
+}
+
+// Option is the type all video options need to adhere to
+type Option func(*config)
+
+// OptionSetSize sets the video resolution in pixels (default: 1280x720).
+// Odd dimensions are rounded down to even, as required by the video codecs.
+func OptionSetSize(width, height int) Option {
+	return func(c *config) {
+		c.width = width
+		c.height = height
+	}
+}
+
+// OptionSetFPS sets the frame rate of the video (default: 30)
+func OptionSetFPS(fps float64) Option {
+	return func(c *config) {
+		c.fps = fps
+	}
+}
+
+// OptionSetStart sets the start time of the video in seconds
+func OptionSetStart(start float64) Option {
+	return func(c *config) {
+		c.start = start
+	}
+}
+
+// OptionSetEnd sets the end time of the video in seconds
+func OptionSetEnd(end float64) Option {
+	return func(c *config) {
+		c.end = end
+	}
+}
+
+// OptionSetBackgroundColor sets the background color using a hex color code
+func OptionSetBackgroundColor(hexColor string) Option {
+	return OptionPlot(gowaveform.OptionSetBackgroundColor(hexColor))
+}
+
+// OptionSetForegroundColor sets the waveform color using a hex color code
+func OptionSetForegroundColor(hexColor string) Option {
+	return OptionPlot(gowaveform.OptionSetForegroundColor(hexColor))
+}
+
+// OptionSetPlayheadColor sets the playhead color using a hex color code (default: red)
+func OptionSetPlayheadColor(hexColor string) Option {
+	return func(c *config) {
+		c.playheadColor = hexColor
+	}
+}
+
+// OptionAudio muxes the audio of the given file into the video, trimmed to the video's time range
+func OptionAudio(filename string) Option {
+	return func(c *config) {
+		c.audioFile = filename
+	}
+}
+
+// OptionFFmpegPath sets the ffmpeg executable to use (default: ffmpeg from PATH)
+func OptionFFmpegPath(path string) Option {
+	return func(c *config) {
+		c.ffmpegPath = path
+	}
+}
+
+// OptionPlot adds plot options (title, axes, RMS, spectral coloring, ...) to the waveform frame
+func OptionPlot(opts ...gowaveform.Option) Option {
+	return func(c *config) {
+		c.plotOpts = append(c.plotOpts, opts...)
+	}
+}
+
+// codecArgs returns the ffmpeg encoder arguments for the video and audio streams of an output file
+func codecArgs(filename string) (video, audio []string, err error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".mp4", ".m4v", ".mov":
+		return []string{"-c:v", "libx264", "-pix_fmt", "yuv420p"}, []string{"-c:a", "aac"}, nil
+	case ".webm":
+		return []string{"-c:v", "libvpx-vp9", "-pix_fmt", "yuv420p"}, []string{"-c:a", "libopus"}, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported video format: %s (supported: .mp4, .m4v, .mov, .webm)", ext)
+	}
+}
+
+// Save renders the waveform with a playhead sweeping across it in real time and encodes
+// it with ffmpeg. The container and codecs are chosen by the filename extension.
+// Unlike gowaveform.ExportFrames, the waveform is drawn once and frames are streamed
+// to ffmpeg without touching the disk.
+func Save(w *gowaveform.Waveform, filename string, opts ...Option) error {
+	c := config{
+		width:         gowaveform.DefaultFrameWidth,
+		height:        gowaveform.DefaultFrameHeight,
+		fps:           30,
+		playheadColor: "#DC1E1E",
+		ffmpegPath:    "ffmpeg",
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	c.width &^= 1
+	c.height &^= 1
+	if c.width <= 0 || c.height <= 0 {
+		return fmt.Errorf("invalid video size: %dx%d", c.width, c.height)
+	}
+	if c.fps <= 0 {
+		return fmt.Errorf("invalid frame rate: %g", c.fps)
+	}
+	playhead, err := parseHexColor(c.playheadColor)
+	if err != nil {
+		return err
+	}
+
+	videoArgs, audioArgs, err := codecArgs(filename)
+	if err != nil {
+		return err
+	}
+
+	ffmpeg, err := exec.LookPath(c.ffmpegPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrFFmpegNotFound, err)
+	}
+
+	// The waveform is drawn once; each frame only adds the playhead
+	plotOpts := append([]gowaveform.Option{
+		gowaveform.OptionSetWidth(c.width),
+		gowaveform.OptionSetHeight(c.height),
+		gowaveform.OptionSetStart(c.start),
+		gowaveform.OptionSetEnd(c.end),
+	}, c.plotOpts...)
+	background, err := gowaveform.RenderPlot(w, plotOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to render waveform: %w", err)
+	}
+
+	duration := background.End - background.Start
+	args := []string{
+		"-y", "-loglevel", "error",
+		"-f", "rawvideo", "-pix_fmt", "rgba",
+		"-s", fmt.Sprintf("%dx%d", c.width, c.height),
+		"-framerate", strconv.FormatFloat(c.fps, 'f', -1, 64),
+		"-i", "pipe:0",
+	}
+	if c.audioFile != "" {
+		args = append(args,
+			"-ss", strconv.FormatFloat(background.Start, 'f', -1, 64),
+			"-t", strconv.FormatFloat(duration, 'f', -1, 64),
+			"-i", c.audioFile,
+			"-map", "0:v:0", "-map", "1:a:0", "-shortest",
+		)
+		args = append(args, audioArgs...)
+	}
+	args = append(args, videoArgs...)
+	args = append(args, filename)
+
+	cmd := exec.Command(ffmpeg, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ffmpeg: %w", err)
+	}
+
+	writeErr := writeFrames(stdin, background, c.fps, playhead)
+	stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if writeErr != nil {
+		return fmt.Errorf("failed to write video frames: %w", writeErr)
+	}
+
+	return nil
+}
+
+// writeFrames writes one raw RGBA frame per 1/fps seconds of the background's time range,
+// each with the playhead at that frame's time
+func writeFrames(out io.Writer, background *gowaveform.PlotImage, fps float64, playhead color.RGBA) error {
+	frames := int(math.Ceil((background.End - background.Start) * fps))
+	frame := image.NewRGBA(background.Image.Bounds())
+	area := background.DataArea
+
+	for i := 0; i < frames; i++ {
+		copy(frame.Pix, background.Image.Pix)
+
+		// Two pixels wide so the playhead stays visible after chroma subsampling
+		x := background.TimeToX(background.Start + float64(i)/fps)
+		for dx := 0; dx < 2; dx++ {
+			if x+dx >= area.Max.X {
+				break
+			}
+			for y := area.Min.Y; y < area.Max.Y; y++ {
+				frame.SetRGBA(x+dx, y, playhead)
+			}
+		}
+
+		if _, err := out.Write(frame.Pix); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parseHexColor parses a #RGB or #RRGGBB color code
+func parseHexColor(hex string) (color.RGBA, error) {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) == 3 {
+		h = string([]byte{h[0], h[0], h[1], h[1], h[2], h[2]})
+	}
+	v, err := strconv.ParseUint(h, 16, 32)
+	if len(h) != 6 || err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid color: %s", hex)
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}, nil
+}
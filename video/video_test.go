@@ -0,0 +1,159 @@
+package video
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/schollz/gowaveform"
+)
+
+// loadTestWaveform writes a one second 16-bit mono WAV with a quiet ramp and loads it
+func loadTestWaveform(t *testing.T) (*gowaveform.Waveform, string) {
+	t.Helper()
+
+	samples := make([]int16, 8000)
+	for i := range samples {
+		samples[i] = int16(i%200 - 100)
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+len(samples)*2))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))    // PCM
+	binary.Write(buf, binary.LittleEndian, uint16(1))    // Channels
+	binary.Write(buf, binary.LittleEndian, uint32(8000)) // Sample rate
+	binary.Write(buf, binary.LittleEndian, uint32(16000))
+	binary.Write(buf, binary.LittleEndian, uint16(2))
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(len(samples)*2))
+	binary.Write(buf, binary.LittleEndian, samples)
+
+	filename := filepath.Join(t.TempDir(), "test.wav")
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to create test WAV file: %v", err)
+	}
+
+	w, err := gowaveform.LoadWaveform(filename)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+	return w, filename
+}
+
+func TestWriteFrames(t *testing.T) {
+	w, _ := loadTestWaveform(t)
+
+	background, err := gowaveform.RenderPlot(w, gowaveform.OptionSetWidth(320), gowaveform.OptionSetHeight(180))
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	red := color.RGBA{R: 255, A: 255}
+	if err := writeFrames(&out, background, 10, red); err != nil {
+		t.Fatalf("writeFrames failed: %v", err)
+	}
+
+	frameSize := 320 * 180 * 4
+	if out.Len() != 10*frameSize {
+		t.Fatalf("Expected 10 frames of %d bytes, got %d bytes", frameSize, out.Len())
+	}
+
+	// The playhead moves right from the start of the data area, one step per frame
+	y := background.DataArea.Min.Y + background.DataArea.Dy()/2
+	last := -1
+	for i := 0; i < 10; i++ {
+		frame := out.Bytes()[i*frameSize : (i+1)*frameSize]
+		x := -1
+		for px := 0; px < 320; px++ {
+			offset := (y*320 + px) * 4
+			if frame[offset] == 255 && frame[offset+1] == 0 && frame[offset+2] == 0 {
+				x = px
+				break
+			}
+		}
+		if x <= last {
+			t.Fatalf("Frame %d: expected the playhead right of %d, found it at %d", i, last, x)
+		}
+		last = x
+	}
+	if first := background.TimeToX(0); first != background.DataArea.Min.X {
+		t.Errorf("Expected the first frame's playhead at %d, got %d", background.DataArea.Min.X, first)
+	}
+}
+
+func TestSaveErrors(t *testing.T) {
+	w, _ := loadTestWaveform(t)
+	dir := t.TempDir()
+
+	err := Save(w, filepath.Join(dir, "out.mp4"), OptionFFmpegPath(filepath.Join(dir, "no-ffmpeg")))
+	if !errors.Is(err, ErrFFmpegNotFound) {
+		t.Errorf("Expected ErrFFmpegNotFound, got %v", err)
+	}
+
+	if err := Save(w, filepath.Join(dir, "out.gif")); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if err := Save(w, filepath.Join(dir, "out.mp4"), OptionSetFPS(0)); err == nil {
+		t.Error("Expected error for zero frame rate")
+	}
+	if err := Save(w, filepath.Join(dir, "out.mp4"), OptionSetPlayheadColor("red")); err == nil {
+		t.Error("Expected error for invalid playhead color")
+	}
+}
+
+func TestSave(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+
+	w, wavFile := loadTestWaveform(t)
+	output := filepath.Join(t.TempDir(), "out.mp4")
+
+	err := Save(w, output,
+		OptionSetSize(321, 181),
+		OptionSetFPS(12),
+		OptionSetForegroundColor("#00AA00"),
+		OptionAudio(wavFile),
+	)
+	if err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		t.Fatalf("Expected video file: %v", err)
+	}
+	if info.Size() == 0 {
+		t.Error("Expected a non-empty video file")
+	}
+}
+
+func TestParseHexColor(t *testing.T) {
+	tests := map[string]color.RGBA{
+		"#FF8000": {R: 255, G: 128, B: 0, A: 255},
+		"0a0B0c":  {R: 10, G: 11, B: 12, A: 255},
+		"#F80":    {R: 255, G: 136, B: 0, A: 255},
+	}
+	for hex, want := range tests {
+		got, err := parseHexColor(hex)
+		if err != nil || got != want {
+			t.Errorf("parseHexColor(%q) = %v, %v; want %v", hex, got, err, want)
+		}
+	}
+
+	for _, hex := range []string{"", "#12345", "#GGGGGG", "red"} {
+		if _, err := parseHexColor(hex); err == nil {
+			t.Errorf("Expected error for %q", hex)
+		}
+	}
+}
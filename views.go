@@ -0,0 +1,210 @@
+package gowaveform
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// viewsChunkFrames is the number of frames GenerateViews scans at a time. A chunk is
+// small enough to stay in cache while every view takes its peaks from it.
+const viewsChunkFrames = 8192
+
+// viewPlan tracks how one view of a GenerateViews call is computed
+type viewPlan struct {
+	opts            WaveformOptions
+	startSample     int
+	endSample       int
+	samplesPerPixel int
+	data            *WaveformData
+	source          *viewPlan // Finer view whose pixels this view is merged from, nil if scanned
+
+	// Scan state for views read directly from the audio
+	pixelEnd int
+	min, max int16
+}
+
+// GenerateViews generates several views (zoom levels and ranges) in a single pass over
+// the audio. Views whose pixels line up with a finer view are merged from that view's
+// peaks instead of rescanning, and the remaining views share one traversal of the samples.
+// Filtered views are computed on their own, as their peaks differ from the others.
+// The result has one entry per option, identical to calling GenerateView for each.
+func (w *Waveform) GenerateViews(opts []WaveformOptions) ([]*WaveformData, error) {
+	plans := make([]*viewPlan, len(opts))
+	for i, o := range opts {
+		startSample, endSample, samplesPerPixel, err := w.resolveRange(o)
+		if err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+		data, err := w.newView(o, samplesPerPixel)
+		if err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+		plans[i] = &viewPlan{
+			opts:            o,
+			startSample:     startSample,
+			endSample:       endSample,
+			samplesPerPixel: samplesPerPixel,
+			data:            data,
+		}
+	}
+
+	// Plan from finest to coarsest so every source is computed before the views merged from it
+	ordered := make([]*viewPlan, len(plans))
+	copy(ordered, plans)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].samplesPerPixel < ordered[j].samplesPerPixel
+	})
+
+	var scanned []*viewPlan
+	for i, p := range ordered {
+		for _, candidate := range ordered[:i] {
+			if p.canMergeFrom(candidate) && (p.source == nil || candidate.samplesPerPixel > p.source.samplesPerPixel) {
+				p.source = candidate
+			}
+		}
+		if p.source == nil && p.opts.Filter == nil {
+			scanned = append(scanned, p)
+		}
+	}
+
+	w.scanViews(scanned)
+
+	for i, p := range plans {
+		if p.opts.Filter == nil {
+			continue
+		}
+		peaks, err := w.pixelPeaks(p.opts, p.startSample)
+		if err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+		for pixelStart := p.startSample; pixelStart < p.endSample; pixelStart += p.samplesPerPixel {
+			lo, hi := peaks(pixelStart, min(p.samplesPerPixel, p.endSample-pixelStart))
+			p.data.Data = append(p.data.Data, lo, hi)
+		}
+	}
+
+	for _, p := range ordered {
+		if p.source != nil {
+			p.mergeFromSource()
+		}
+	}
+
+	results := make([]*WaveformData, len(plans))
+	for i, p := range plans {
+		data, err := w.finishView(p.data, p.opts, p.startSample, p.endSample)
+		if err != nil {
+			return nil, fmt.Errorf("view %d: %w", i, err)
+		}
+		results[i] = data
+	}
+
+	return results, nil
+}
+
+// canMergeFrom reports whether every pixel of p covers a whole number of pixels of src
+func (p *viewPlan) canMergeFrom(src *viewPlan) bool {
+	if p.opts.Filter != nil || src.opts.Filter != nil {
+		return false
+	}
+	if p.samplesPerPixel%src.samplesPerPixel != 0 {
+		return false
+	}
+	if p.startSample < src.startSample || p.endSample > src.endSample {
+		return false
+	}
+	if (p.startSample-src.startSample)%src.samplesPerPixel != 0 {
+		return false
+	}
+	// The last pixel may be partial, so it must end where src ends or on a src pixel boundary
+	return p.endSample == src.endSample || (p.endSample-src.startSample)%src.samplesPerPixel == 0
+}
+
+// mergeFromSource fills in the peaks of p by merging the pixels of its source view
+func (p *viewPlan) mergeFromSource() {
+	src := p.source
+	srcData := src.data.Data
+
+	for pixelStart := p.startSample; pixelStart < p.endSample; pixelStart += p.samplesPerPixel {
+		pixelEnd := pixelStart + p.samplesPerPixel
+		if pixelEnd > p.endSample {
+			pixelEnd = p.endSample
+		}
+
+		first := (pixelStart - src.startSample) / src.samplesPerPixel
+		last := (pixelEnd - src.startSample + src.samplesPerPixel - 1) / src.samplesPerPixel
+
+		min, max := srcData[2*first], srcData[2*first+1]
+		for k := first + 1; k < last; k++ {
+			if srcData[2*k] < min {
+				min = srcData[2*k]
+			}
+			if srcData[2*k+1] > max {
+				max = srcData[2*k+1]
+			}
+		}
+		p.data.Data = append(p.data.Data, min, max)
+	}
+}
+
+// scanViews computes the peaks of all views in one traversal of the audio, chunk by chunk
+func (w *Waveform) scanViews(views []*viewPlan) {
+	if len(views) == 0 {
+		return
+	}
+
+	lo, hi := views[0].startSample, views[0].endSample
+	for _, v := range views {
+		if v.startSample < lo {
+			lo = v.startSample
+		}
+		if v.endSample > hi {
+			hi = v.endSample
+		}
+		v.pixelEnd = v.startSample + v.samplesPerPixel
+		if v.pixelEnd > v.endSample {
+			v.pixelEnd = v.endSample
+		}
+		v.min, v.max = math.MaxInt16, math.MinInt16
+	}
+
+	for chunkStart := lo; chunkStart < hi; chunkStart += viewsChunkFrames {
+		chunkEnd := chunkStart + viewsChunkFrames
+		if chunkEnd > hi {
+			chunkEnd = hi
+		}
+
+		for _, v := range views {
+			from, to := chunkStart, chunkEnd
+			if from < v.startSample {
+				from = v.startSample
+			}
+			if to > v.endSample {
+				to = v.endSample
+			}
+
+			for from < to {
+				segmentEnd := to
+				if segmentEnd > v.pixelEnd {
+					segmentEnd = v.pixelEnd
+				}
+				v.min, v.max = w.scanPeaks(from, segmentEnd, v.min, v.max)
+				from = segmentEnd
+
+				if from == v.pixelEnd {
+					v.data.Data = append(v.data.Data, v.min, v.max)
+					v.min, v.max = math.MaxInt16, math.MinInt16
+					v.pixelEnd += v.samplesPerPixel
+					if v.pixelEnd > v.endSample {
+						v.pixelEnd = v.endSample
+					}
+				}
+			}
+		}
+	}
+}
+
+// scanPeaks extends min and max with all channels of the frames from start to end
+func (w *Waveform) scanPeaks(start, end int, min, max int16) (int16, int16) {
+	return peakScan(w.audioData[start*w.Channels:end*w.Channels], min, max)
+}
@@ -0,0 +1,146 @@
+package gowaveform
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateViewsMatchesGenerateView(t *testing.T) {
+	tmpFile := "/tmp/test_views.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 3.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	views := []WaveformOptions{
+		{SamplesPerPixel: 1024},                           // Merged from the 256 view
+		{SamplesPerPixel: 256},                            // Scanned
+		{Width: 800},                                      // Scanned, odd samples per pixel
+		{Start: 0.5, End: 1.5, SamplesPerPixel: 512},      // Merged from the 256 view
+		{Start: 0.123, End: 2.0, SamplesPerPixel: 100},    // Unaligned start, scanned
+		{Start: 1.0, SamplesPerPixel: 300},                // Scanned, 300 is not a multiple of 100
+		{SamplesPerPixel: 256, FormatVersion: 1},          // Duplicate zoom in version 1
+		{Start: 2.5, End: 3.0, Width: 10, Metadata: true}, // Short range with metadata
+	}
+
+	results, err := waveform.GenerateViews(views)
+	if err != nil {
+		t.Fatalf("GenerateViews failed: %v", err)
+	}
+	if len(results) != len(views) {
+		t.Fatalf("Expected %d views, got %d", len(views), len(results))
+	}
+
+	for i, opts := range views {
+		expected, err := waveform.GenerateView(opts)
+		if err != nil {
+			t.Fatalf("GenerateView %d failed: %v", i, err)
+		}
+		// Generation timestamps differ between calls
+		if expected.Metadata != nil {
+			expected.Metadata.Generated = results[i].Metadata.Generated
+		}
+		if !reflect.DeepEqual(results[i], expected) {
+			t.Errorf("View %d (%+v) differs from GenerateView: length %d vs %d",
+				i, opts, results[i].Length, expected.Length)
+		}
+	}
+}
+
+func TestGenerateViewsPlan(t *testing.T) {
+	coarse := &viewPlan{startSample: 512, endSample: 10000, samplesPerPixel: 1024}
+	fine := &viewPlan{startSample: 0, endSample: 10000, samplesPerPixel: 256}
+	if !coarse.canMergeFrom(fine) {
+		t.Error("Expected aligned coarse view to merge from fine view")
+	}
+
+	unaligned := &viewPlan{startSample: 100, endSample: 10000, samplesPerPixel: 1024}
+	if unaligned.canMergeFrom(fine) {
+		t.Error("Expected unaligned view to be scanned")
+	}
+
+	partial := &viewPlan{startSample: 0, endSample: 5000, samplesPerPixel: 1024}
+	if partial.canMergeFrom(fine) {
+		t.Error("Expected view ending inside a source pixel to be scanned")
+	}
+}
+
+func TestGenerateViewsErrors(t *testing.T) {
+	tmpFile := "/tmp/test_views_errors.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 1.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	if _, err := waveform.GenerateViews([]WaveformOptions{{SamplesPerPixel: 256}, {Start: 2, End: 1}}); err == nil {
+		t.Error("Expected error for invalid range")
+	}
+
+	results, err := waveform.GenerateViews(nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("Expected no views, got %v, %v", results, err)
+	}
+}
+
+func amenViews() []WaveformOptions {
+	return []WaveformOptions{
+		{Width: 1000},             // Overview
+		{SamplesPerPixel: 64},     // Detail
+		{SamplesPerPixel: 256},    // Merged from detail
+		{SamplesPerPixel: 1024},   // Merged from detail
+		{Start: 1.0, Width: 2000}, // Scrolled detail
+	}
+}
+
+func BenchmarkGenerateViews_AmenBreak(b *testing.B) {
+	const amenFile = "data/amen_170.wav"
+
+	if _, err := os.Stat(amenFile); os.IsNotExist(err) {
+		b.Skip("Skipping benchmark: data/amen_170.wav not found")
+	}
+
+	waveform, err := LoadWaveform(amenFile)
+	if err != nil {
+		b.Fatalf("LoadWaveform failed: %v", err)
+	}
+	views := amenViews()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := waveform.GenerateViews(views); err != nil {
+			b.Fatalf("GenerateViews failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkGenerateViewSeparately_AmenBreak(b *testing.B) {
+	const amenFile = "data/amen_170.wav"
+
+	if _, err := os.Stat(amenFile); os.IsNotExist(err) {
+		b.Skip("Skipping benchmark: data/amen_170.wav not found")
+	}
+
+	waveform, err := LoadWaveform(amenFile)
+	if err != nil {
+		b.Fatalf("LoadWaveform failed: %v", err)
+	}
+	views := amenViews()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, opts := range views {
+			if _, err := waveform.GenerateView(opts); err != nil {
+				b.Fatalf("GenerateView failed: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gowaveform
+
+// ViewState is the visible range of a navigable waveform display, such as a terminal
+// UI or a web client asking a backend for peaks. Navigation methods return a new
+// state and do not know the file; call ClampTo with its duration after each step,
+// which gives the same result as the ZoomAt and Pan functions.
+type ViewState struct {
+	Start float64 // Start time in seconds
+	End   float64 // End time in seconds
+	Width int     // Width of the display in pixels
+}
+
+// NewViewState returns a view of a whole file of the given duration
+func NewViewState(duration float64, width int) ViewState {
+	return ViewState{End: max(duration, 0), Width: width}
+}
+
+// Duration returns the length of the view in seconds
+func (v ViewState) Duration() float64 {
+	return v.End - v.Start
+}
+
+// Center returns the time in seconds at the middle of the view
+func (v ViewState) Center() float64 {
+	return (v.Start + v.End) / 2
+}
+
+// Zoom divides the duration of the view by factor around its center: factors above
+// 1 zoom in and factors below 1 zoom out. A factor that is not positive is ignored.
+func (v ViewState) Zoom(factor float64) ViewState {
+	return v.ZoomAt(factor, v.Center())
+}
+
+// ZoomAt divides the duration of the view by factor and centers it on center
+// seconds; a factor of 1 only recenters and one that is not positive keeps the
+// duration
+func (v ViewState) ZoomAt(factor, center float64) ViewState {
+	v.Start, v.End = zoomRange(v.Start, v.End, factor, center)
+	return v
+}
+
+// Pan moves the view by factor times its duration, left for negative factors
+func (v ViewState) Pan(factor float64) ViewState {
+	v.Start, v.End = panRange(v.Start, v.End, factor)
+	return v
+}
+
+// ZoomToRegion shows exactly the region from start to end seconds, in either order
+func (v ViewState) ZoomToRegion(start, end float64) ViewState {
+	v.Start, v.End = min(start, end), max(start, end)
+	return v
+}
+
+// ClampTo shortens the view to at most duration seconds and shifts it, keeping its
+// length, to lie within the file
+func (v ViewState) ClampTo(duration float64) ViewState {
+	v.Start, v.End = clampView(v.Start, v.End, duration)
+	return v
+}
+
+// Options returns the options that generate the view, for further settings such as
+// bands or metadata
+func (v ViewState) Options() WaveformOptions {
+	return WaveformOptions{Start: v.Start, End: v.End, Width: v.Width}
+}
+
+// GenerateViewState generates the peaks of a view state, clamped to the audio
+func (w *Waveform) GenerateViewState(v ViewState) (*WaveformData, error) {
+	return w.GenerateView(v.ClampTo(w.Duration()).Options())
+}
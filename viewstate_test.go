@@ -0,0 +1,76 @@
+package gowaveform
+
+import (
+	"math"
+	"os"
+	"testing"
+)
+
+func TestViewStateNavigation(t *testing.T) {
+	v := NewViewState(10, 100)
+	if v.Start != 0 || v.End != 10 || v.Width != 100 {
+		t.Fatalf("Expected the whole file at 100 pixels, got %+v", v)
+	}
+
+	tests := []struct {
+		name       string
+		got        ViewState
+		start, end float64
+	}{
+		{"zoom in", v.Zoom(2), 2.5, 7.5},
+		{"zoom out past file", v.Zoom(2).Zoom(0.25).ClampTo(10), 0, 10},
+		{"invalid zoom", v.Zoom(0), 0, 10},
+		{"pan", v.Zoom(2).Pan(0.5), 5, 10},
+		{"pan past end", v.Zoom(2).Pan(1).ClampTo(10), 5, 10},
+		{"pan past start", v.Zoom(2).Pan(-1).ClampTo(10), 0, 5},
+		{"zoom at", v.ZoomAt(4, 1), -0.25, 2.25},
+		{"zoom at past start", v.ZoomAt(4, 1).ClampTo(10), 0, 2.5},
+		{"recenter", v.Zoom(2).ZoomAt(1, 9).ClampTo(10), 5, 10},
+		{"region", v.ZoomToRegion(6, 3), 3, 6},
+		{"region past end", v.ZoomToRegion(8, 12).ClampTo(10), 6, 10},
+	}
+
+	for _, tt := range tests {
+		if math.Abs(tt.got.Start-tt.start) > 1e-9 || math.Abs(tt.got.End-tt.end) > 1e-9 {
+			t.Errorf("%s: expected %.2f to %.2f, got %.2f to %.2f", tt.name, tt.start, tt.end, tt.got.Start, tt.got.End)
+		}
+		if tt.got.Width != 100 {
+			t.Errorf("%s: expected the width to be kept, got %d", tt.name, tt.got.Width)
+		}
+	}
+
+	// Clamped states agree with the standalone helpers
+	start, end := Pan(2.5, 7.5, 10, 1)
+	if got := v.Zoom(2).Pan(1).ClampTo(10); got.Start != start || got.End != end {
+		t.Errorf("Expected Pan to give %.2f to %.2f, got %.2f to %.2f", start, end, got.Start, got.End)
+	}
+	start, end = ZoomAt(0, 10, 10, 4, 1)
+	if got := v.ZoomAt(4, 1).ClampTo(10); got.Start != start || got.End != end {
+		t.Errorf("Expected ZoomAt to give %.2f to %.2f, got %.2f to %.2f", start, end, got.Start, got.End)
+	}
+}
+
+func TestGenerateViewState(t *testing.T) {
+	tmpFile := "/tmp/test_view_state.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// A view panned past the end is clamped to the last second
+	v := NewViewState(waveform.Duration(), 50).Zoom(2).Pan(2)
+	data, err := waveform.GenerateViewState(v)
+	if err != nil {
+		t.Fatalf("GenerateViewState failed: %v", err)
+	}
+	if data.Length != 50 {
+		t.Errorf("Expected 50 pixels, got %d", data.Length)
+	}
+	if math.Abs(data.Start-1.0) > 1e-9 || math.Abs(data.End-2.0) > 1e-9 {
+		t.Errorf("Expected 1.0s to 2.0s, got %.4f to %.4f", data.Start, data.End)
+	}
+}
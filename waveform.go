@@ -1,40 +1,75 @@
 package gowaveform
 
 import (
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"sync/atomic"
 
 	"github.com/schollz/audiomorph"
 )
 
 // Waveform represents a loaded WAV file with its audio data
 type Waveform struct {
-	SampleRate      int
-	Channels        int
-	BitsPerSample   int
-	audioData       []int16 // All audio samples in int16 format (interleaved for multi-channel)
-	totalSamples    int     // Total number of frames (not individual channel samples)
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	audioData     []int16       // All audio samples in int16 format (interleaved for multi-channel)
+	totalSamples  int           // Total number of frames (not individual channel samples)
+	source        string        // File the audio was loaded from, recorded in metadata
+	overview      *WaveformData // Full-file view precomputed with OptionPrecomputeOverview
+	overviewWidth int           // Width the overview was requested at, kept for edits
+	memory        *atomic.Int64 // Bytes counted in LiveMemoryUsage (nil if not counted)
+
+	// Progressive loading state, see OptionProgressive
+	loadedFrames atomic.Int64  // Frames decoded so far
+	loadDone     chan struct{} // Closed when background decoding ends (nil if loaded at once)
+	loadErr      error         // Error from background decoding, valid once loadDone is closed
 }
 
 // WaveformData represents the JSON output format compatible with audiowaveform
 type WaveformData struct {
-	Version         int     `json:"version"`
-	Channels        int     `json:"channels"`
-	SampleRate      int     `json:"sample_rate"`
-	SamplesPerPixel int     `json:"samples_per_pixel"`
-	Bits            int     `json:"bits"`
-	Length          int     `json:"length"`
-	Data            []int16 `json:"data"`
+	Version         int        `json:"version"`
+	Channels        int        `json:"channels,omitempty"` // Omitted in version 1 output, which is always single channel
+	SampleRate      int        `json:"sample_rate"`
+	SamplesPerPixel int        `json:"samples_per_pixel"`
+	Bits            int        `json:"bits"`
+	Length          int        `json:"length"`
+	Data            []int16    `json:"data"`
+	Bands           []BandData `json:"bands,omitempty"`     // Per-band peaks, only present when WaveformOptions.Bands is set
+	TimeAxis        *TimeAxis  `json:"time_axis,omitempty"` // Pixel times, only present when WaveformOptions.TimeAxis is set
+	Metadata        *Metadata  `json:"metadata,omitempty"`  // Provenance, only present when WaveformOptions.Metadata is set
+
+	// The range the view covers, for renderers; not part of the audiowaveform format
+	// (set WaveformOptions.TimeAxis to include the range in JSON)
+	Start          float64 `json:"-"` // Time in seconds of the start of the first pixel
+	End            float64 `json:"-"` // Time in seconds of the end of the last pixel
+	SourceDuration float64 `json:"-"` // Duration in seconds of the whole source
+}
+
+// TimeAxis gives the absolute times of a view, so clients do not have to reconstruct
+// them from samples_per_pixel and the requested start
+type TimeAxis struct {
+	Start float64   `json:"start"` // Time in seconds of the start of the first pixel
+	End   float64   `json:"end"`   // Time in seconds of the end of the last pixel
+	Times []float64 `json:"times"` // Time in seconds of the start of each pixel
 }
 
 // WaveformOptions defines parameters for waveform generation
 type WaveformOptions struct {
-	Start           float64 // Start time in seconds
-	End             float64 // End time in seconds (0 means end of file)
-	SamplesPerPixel int     // Zoom level (samples per pixel). Ignored if Width is specified.
-	Width           int     // Target width in pixels. If specified, SamplesPerPixel is calculated automatically.
+	Start           float64   // Start time in seconds
+	End             float64   // End time in seconds (0 means end of file)
+	SamplesPerPixel int       // Zoom level (samples per pixel). Ignored if Width is specified.
+	Width           int       // Target width in pixels. If specified, SamplesPerPixel is calculated automatically.
+	Bands           []float64 // Optional band edges in Hz (e.g. [200, 2000] for low/mid/high); adds per-band peaks to the output
+	Metadata        bool      // Add a metadata block (source, duration, timestamp, tool version, options) to the output
+	Float           bool      // Emit peaks as normalized floats (-1.0..1.0) in JSON output; see WaveformDataFloat
+	FormatVersion   int       // audiowaveform format version: 2 (default) or 1 (single channel, no channels field)
+	Symmetric       bool      // Mirror the larger of |min| and |max| of each pixel around zero, as many podcast players draw it
+	TimeAxis        bool      // Add the start and end time and the start time of each pixel (in seconds) to the output
+	Filter          *Filter   // Optional filter (HighPass, LowPass or BandPass) applied to the audio before peaks and bands are taken
 }
 
 // WAVHeader represents the WAV file header
@@ -47,13 +82,60 @@ type WAVHeader struct {
 }
 
 // LoadWaveform loads a WAV file into memory for generating multiple views
-func LoadWaveform(filename string) (*Waveform, error) {
-	// Decode audio file using audiomorph
-	audio, err := audiomorph.DecodeFile(filename)
+func LoadWaveform(filename string, opts ...LoadOption) (*Waveform, error) {
+	return loadWaveform(filename, newLoadConfig(opts))
+}
+
+// loadWaveform loads a file from disk with a resolved load configuration
+func loadWaveform(filename string, c loadConfig) (*Waveform, error) {
+	decode, registered := registeredDecoder(filename)
+	if !registered && isWAVFile(filename) {
+		// The progressive decoder allocates for the size declared in the header
+		if err := checkWAVFileLimits(filename, c, c.progressive); err != nil {
+			return nil, fmt.Errorf("failed to decode audio file: %w", err)
+		}
+		if c.progressive {
+			return loadWAVProgressive(filename, c)
+		}
+	}
+
+	var audio *audiomorph.Audio
+	var err error
+	if registered {
+		audio, err = decodeFileWithRegistered(decode, filename)
+	} else {
+		// Decode audio file using audiomorph, falling back to ffmpeg if enabled
+		audio, err = audiomorph.DecodeFile(filename)
+		if err != nil && c.ffmpegFallback {
+			var ffmpegErr error
+			if audio, ffmpegErr = decodeFFmpeg(filename); ffmpegErr == nil {
+				err = nil
+			} else {
+				err = fmt.Errorf("%w (ffmpeg fallback: %w)", err, ffmpegErr)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("failed to decode audio file: %w", err)
+		}
+	}
 	if err != nil {
+		return nil, err
+	}
+	if err := checkAudioLimits(audio, c); err != nil {
 		return nil, fmt.Errorf("failed to decode audio file: %w", err)
 	}
 
+	waveform := newWaveform(audio, c)
+	waveform.source = filename
+	if err := waveform.finishLoad(c); err != nil {
+		return nil, err
+	}
+	return waveform, nil
+}
+
+// newWaveform converts decoded audio into a Waveform with interleaved int16 samples,
+// dithered and mixed down as set in c
+func newWaveform(audio *audiomorph.Audio, c loadConfig) *Waveform {
 	// Calculate total samples (frames)
 	// audiomorph provides deinterlaced data: Data[channel][sample]
 	totalSamples := 0
@@ -61,47 +143,57 @@ func LoadWaveform(filename string) (*Waveform, error) {
 		totalSamples = len(audio.Data[0])
 	}
 
+	d := newDitherer(c.dither, audio.BitDepth, audio.NumChannels)
+	m := newChannelMixer(c.mono, audio.NumChannels)
+	channels := audio.NumChannels
+	if m != nil {
+		channels = 1
+	}
+
 	// Convert deinterlaced data to interleaved int16 format
 	// audiomorph Data is [][]int where each int is a sample value
-	audioData := make([]int16, totalSamples*audio.NumChannels)
-	
+	audioData := getInt16s(&samplePool, totalSamples*channels)[:totalSamples*channels]
+
 	for sampleIdx := 0; sampleIdx < totalSamples; sampleIdx++ {
 		for channelIdx := 0; channelIdx < audio.NumChannels; channelIdx++ {
-			// Convert int sample to int16
-			sample := audio.Data[channelIdx][sampleIdx]
-			
-			// Scale based on bit depth
-			var sample16 int16
-			switch audio.BitDepth {
-			case 8:
-				// 8-bit samples are typically 0-255, convert to signed 16-bit
-				sample16 = int16((sample - 128) << 8)
-			case 16:
-				sample16 = int16(sample)
-			case 24:
-				// 24-bit samples, scale to 16-bit
-				sample16 = int16(sample >> 8)
-			case 32:
-				// 32-bit samples, scale to 16-bit
-				sample16 = int16(sample >> 16)
-			default:
-				sample16 = int16(sample)
+			v := d.toInt16(audio.Data[channelIdx][sampleIdx], audio.BitDepth, channelIdx)
+			if m == nil {
+				// Store in interleaved format
+				audioData[sampleIdx*audio.NumChannels+channelIdx] = v
+			} else if mixed, ok := m.mix(channelIdx, v); ok {
+				audioData[sampleIdx] = mixed
 			}
-			
-			// Store in interleaved format
-			audioData[sampleIdx*audio.NumChannels+channelIdx] = sample16
 		}
 	}
 
 	waveform := &Waveform{
 		SampleRate:    audio.SampleRate,
-		Channels:      audio.NumChannels,
+		Channels:      channels,
 		BitsPerSample: audio.BitDepth,
 		audioData:     audioData,
 		totalSamples:  totalSamples,
 	}
 
-	return waveform, nil
+	return waveform.track()
+}
+
+// toInt16 converts a decoded sample to int16, scaling based on bit depth
+func toInt16(sample, bitDepth int) int16 {
+	switch bitDepth {
+	case 8:
+		// 8-bit samples are typically 0-255, convert to signed 16-bit
+		return int16((sample - 128) << 8)
+	case 16:
+		return int16(sample)
+	case 24:
+		// 24-bit samples, scale to 16-bit
+		return int16(sample >> 8)
+	case 32:
+		// 32-bit samples, scale to 16-bit
+		return int16(sample >> 16)
+	default:
+		return int16(sample)
+	}
 }
 
 // Duration returns the total duration of the audio in seconds
@@ -114,6 +206,134 @@ func (w *Waveform) Duration() float64 {
 
 // GenerateView generates a waveform view from the loaded audio data
 func (w *Waveform) GenerateView(opts WaveformOptions) (*WaveformData, error) {
+	startSample, endSample, samplesPerPixel, err := w.resolveRange(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	waveformData, err := w.newView(opts, samplesPerPixel)
+	if err != nil {
+		return nil, err
+	}
+	peaks, err := w.pixelPeaks(opts, startSample)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process the range
+	samplesToRead := endSample - startSample
+	samplesRead := 0
+	waveformData.Data = getInt16s(&peakPool, 2*((samplesToRead+samplesPerPixel-1)/samplesPerPixel))
+
+	for samplesRead < samplesToRead {
+		samplesToProcess := samplesPerPixel
+		if samplesRead+samplesToProcess > samplesToRead {
+			samplesToProcess = samplesToRead - samplesRead
+		}
+
+		// Calculate min/max from audio data
+		currentSample := startSample + samplesRead
+		min, max := peaks(currentSample, samplesToProcess)
+
+		waveformData.Data = append(waveformData.Data, min, max)
+		samplesRead += samplesToProcess
+	}
+
+	return w.finishView(waveformData, opts, startSample, endSample)
+}
+
+// newView initializes the header of a view with no peaks
+func (w *Waveform) newView(opts WaveformOptions, samplesPerPixel int) (*WaveformData, error) {
+	version := opts.FormatVersion
+	if version == 0 {
+		version = 2
+	}
+	if version != 1 && version != 2 {
+		return nil, fmt.Errorf("unsupported format version: %d (supported: 1, 2)", version)
+	}
+
+	return &WaveformData{
+		Version:         version,
+		Channels:        w.Channels,
+		SampleRate:      w.SampleRate,
+		SamplesPerPixel: samplesPerPixel,
+		Bits:            w.BitsPerSample,
+		Length:          0,
+		Data:            []int16{},
+	}, nil
+}
+
+// finishView sets the length and range of a view once its peaks are filled in and adds
+// the optional version 1 layout, band peaks and metadata
+func (w *Waveform) finishView(waveformData *WaveformData, opts WaveformOptions, startSample, endSample int) (*WaveformData, error) {
+	waveformData.Length = len(waveformData.Data) / 2
+	waveformData.Start = float64(startSample) / float64(w.SampleRate)
+	waveformData.End = float64(endSample) / float64(w.SampleRate)
+	waveformData.SourceDuration = w.Duration()
+
+	if waveformData.Version == 1 {
+		// Version 1 has no channels field; peaks are already taken across all channels
+		waveformData.Channels = 0
+	}
+
+	if len(opts.Bands) > 0 {
+		bands, err := w.generateBandPeaks(startSample, endSample, waveformData.SamplesPerPixel, opts.Bands, opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		waveformData.Bands = bands
+	}
+
+	if opts.Symmetric {
+		mirrorPeaks(waveformData.Data)
+		for i := range waveformData.Bands {
+			mirrorPeaks(waveformData.Bands[i].Data)
+		}
+	}
+
+	if opts.TimeAxis {
+		waveformData.TimeAxis = w.newTimeAxis(startSample, endSample, waveformData.SamplesPerPixel)
+	}
+
+	if opts.Metadata {
+		waveformData.Metadata = w.newMetadata(opts)
+	}
+
+	return waveformData, nil
+}
+
+// newTimeAxis returns the times of the pixels of a view of the frames from startSample
+// to endSample
+func (w *Waveform) newTimeAxis(startSample, endSample, samplesPerPixel int) *TimeAxis {
+	rate := float64(w.SampleRate)
+	axis := &TimeAxis{
+		Start: float64(startSample) / rate,
+		End:   float64(endSample) / rate,
+		Times: make([]float64, 0, (endSample-startSample+samplesPerPixel-1)/samplesPerPixel),
+	}
+	for pixelStart := startSample; pixelStart < endSample; pixelStart += samplesPerPixel {
+		axis.Times = append(axis.Times, float64(pixelStart)/rate)
+	}
+	return axis
+}
+
+// mirrorPeaks replaces each min/max pair with -m, m where m is the larger magnitude
+// of the pair, clamped so it fits in an int16 on both sides
+func mirrorPeaks(data []int16) {
+	for i := 0; i+1 < len(data); i += 2 {
+		data[i], data[i+1] = mirrorPair(data[i], data[i+1])
+	}
+}
+
+// mirrorPair returns the symmetric form of one min/max pair, see mirrorPeaks
+func mirrorPair(lo, hi int16) (int16, int16) {
+	m := min(max(-int32(lo), int32(hi), 0), math.MaxInt16)
+	return int16(-m), int16(m)
+}
+
+// resolveRange converts the time range in opts to sample positions and determines
+// the number of samples per pixel, so all views share the same pixel layout
+func (w *Waveform) resolveRange(opts WaveformOptions) (int, int, int, error) {
 	startSample := int(opts.Start * float64(w.SampleRate))
 	endSample := w.totalSamples
 	if opts.End > 0 {
@@ -127,7 +347,7 @@ func (w *Waveform) GenerateView(opts WaveformOptions) (*WaveformData, error) {
 		endSample = w.totalSamples
 	}
 	if startSample >= endSample {
-		return nil, fmt.Errorf("invalid range: start must be before end")
+		return 0, 0, 0, fmt.Errorf("invalid range: start must be before end")
 	}
 
 	// Calculate samples per pixel based on width or use the specified value
@@ -143,38 +363,16 @@ func (w *Waveform) GenerateView(opts WaveformOptions) (*WaveformData, error) {
 		samplesPerPixel = 256 // Default zoom level
 	}
 
-	// Initialize waveform data
-	waveformData := &WaveformData{
-		Version:         2,
-		Channels:        w.Channels,
-		SampleRate:      w.SampleRate,
-		SamplesPerPixel: samplesPerPixel,
-		Bits:            w.BitsPerSample,
-		Length:          0,
-		Data:            []int16{},
-	}
-
-	// Process the range
-	samplesToRead := endSample - startSample
-	samplesRead := 0
-
-	for samplesRead < samplesToRead {
-		samplesToProcess := samplesPerPixel
-		if samplesRead+samplesToProcess > samplesToRead {
-			samplesToProcess = samplesToRead - samplesRead
+	// While loading progressively, serve only the decoded part. The pixel layout is
+	// kept from the full range so views line up as more audio arrives.
+	if available := w.availableFrames(); endSample > available {
+		endSample = available
+		if endSample < startSample {
+			endSample = startSample
 		}
-
-		// Calculate min/max from audio data
-		currentSample := startSample + samplesRead
-		min, max := w.getPeaksFromRange(currentSample, samplesToProcess)
-
-		waveformData.Data = append(waveformData.Data, min, max)
-		samplesRead += samplesToProcess
 	}
 
-	waveformData.Length = len(waveformData.Data) / 2
-
-	return waveformData, nil
+	return startSample, endSample, samplesPerPixel, nil
 }
 
 // getPeaksFromRange calculates min and max peaks from a range of samples in the audio data
@@ -198,15 +396,7 @@ func (w *Waveform) getPeaksFromRange(startSample, sampleCount int) (int16, int16
 	}
 
 	// Process all samples in the range (all channels)
-	for i := startIdx; i < endIdx; i++ {
-		sample := w.audioData[i]
-		if sample < min {
-			min = sample
-		}
-		if sample > max {
-			max = sample
-		}
-	}
+	min, max = peakScan(w.audioData[startIdx:endIdx], min, max)
 
 	if min == math.MaxInt16 && max == math.MinInt16 {
 		min, max = 0, 0
@@ -215,13 +405,80 @@ func (w *Waveform) getPeaksFromRange(startSample, sampleCount int) (int16, int16
 	return min, max
 }
 
-// ReadWAVHeader reads and parses a WAV file header using audiomorph
+// ReadWAVHeader reads the format and the location of the audio data of a WAV file,
+// seeking past other chunks without reading them. DataSize is the size declared in
+// the header, which can be larger than the data actually in the file, and DataOffset
+// is counted from the position r was at. A chunk before the data that runs past the
+// end of the file returns ErrDataSizeMismatch, as decoders would allocate its size.
 func ReadWAVHeader(r io.ReadSeeker) (*WAVHeader, error) {
-	// audiomorph's DecodeFile only works with filenames, not io.Reader
-	// For backward compatibility with this function, we need to handle this differently
-	// However, since audiomorph doesn't support io.Reader, we'll return an error
-	// suggesting the use of LoadWaveform instead
-	return nil, fmt.Errorf("ReadWAVHeader with io.ReadSeeker is not supported with audiomorph; use LoadWaveform instead")
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if _, err := r.Seek(start, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	if string(riff[:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a RIFF WAVE file")
+	}
+
+	header := &WAVHeader{}
+	hasFormat := false
+	offset := int64(len(riff))
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			if !hasFormat {
+				return nil, fmt.Errorf("missing fmt chunk")
+			}
+			return nil, fmt.Errorf("missing data chunk")
+		}
+		offset += int64(len(chunk))
+		id, size := string(chunk[:4]), binary.LittleEndian.Uint32(chunk[4:])
+		if id != "data" && start+offset+int64(size) > end {
+			return nil, fmt.Errorf("%w: %q chunk of %d bytes runs past the end of the file", ErrDataSizeMismatch, id, size)
+		}
+
+		switch id {
+		case "fmt ":
+			var body [16]byte
+			if size < uint32(len(body)) {
+				return nil, fmt.Errorf("invalid fmt chunk: %d bytes", size)
+			}
+			if _, err := io.ReadFull(r, body[:]); err != nil {
+				return nil, fmt.Errorf("invalid fmt chunk: %w", err)
+			}
+			header.Channels = binary.LittleEndian.Uint16(body[2:4])
+			header.SampleRate = binary.LittleEndian.Uint32(body[4:8])
+			header.BitsPerSample = binary.LittleEndian.Uint16(body[14:16])
+			if header.Channels == 0 || header.SampleRate == 0 || header.BitsPerSample == 0 {
+				return nil, fmt.Errorf("invalid fmt chunk: %d channels, %d Hz, %d bits",
+					header.Channels, header.SampleRate, header.BitsPerSample)
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return nil, fmt.Errorf("data chunk before fmt chunk")
+			}
+			header.DataSize, header.DataOffset = size, offset
+			return header, nil
+		}
+
+		// Chunks are padded to an even size
+		offset += int64(size) + int64(size%2)
+		if _, err := r.Seek(start+offset, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to skip %q chunk: %w", id, err)
+		}
+	}
 }
 
 // GenerateWaveformData reads a WAV file and generates waveform data
@@ -231,6 +488,7 @@ func GenerateWaveformData(filename string, opts WaveformOptions) (*WaveformData,
 	if err != nil {
 		return nil, err
 	}
+	defer waveform.releaseAudio()
 
 	return waveform.GenerateView(opts)
 }
@@ -240,11 +498,16 @@ func GenerateJSON(data *WaveformData) ([]byte, error) {
 	return json.MarshalIndent(data, "", "  ")
 }
 
-// GenerateWaveformJSON is a convenience function that generates JSON directly from a WAV file
+// GenerateWaveformJSON is a convenience function that generates JSON directly from a WAV file.
+// If opts.Float is set, the output is a WaveformDataFloat.
 func GenerateWaveformJSON(filename string, opts WaveformOptions) ([]byte, error) {
 	data, err := GenerateWaveformData(filename, opts)
 	if err != nil {
 		return nil, err
 	}
+	defer data.Release()
+	if opts.Float {
+		return json.MarshalIndent(data.Float(), "", "  ")
+	}
 	return GenerateJSON(data)
 }
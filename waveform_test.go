@@ -3,6 +3,8 @@ package gowaveform
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/json"
+	"math"
 	"os"
 	"testing"
 )
@@ -47,14 +49,89 @@ func createTestWAV(t *testing.T, filename string, sampleRate uint32, duration fl
 	}
 }
 
+// createToneWAV creates a 16-bit mono WAV file containing a sine wave at the given frequency
+func createToneWAV(t *testing.T, filename string, sampleRate uint32, duration float64, freq float64) {
+	t.Helper()
+
+	numSamples := int(float64(sampleRate) * duration)
+	samples := make([]int16, numSamples)
+	for i := range samples {
+		samples[i] = int16(16000 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	writeTestWAV(t, filename, sampleRate, 1, samples)
+}
+
+// writeTestWAV writes interleaved 16-bit samples to a WAV file
+func writeTestWAV(t *testing.T, filename string, sampleRate uint32, channels uint16, samples []int16) {
+	t.Helper()
+
+	if err := os.WriteFile(filename, testWAVBytes(sampleRate, channels, samples), 0644); err != nil {
+		t.Fatalf("Failed to create test WAV file: %v", err)
+	}
+}
+
+// testWAVBytes returns a 16-bit WAV file of interleaved samples
+func testWAVBytes(sampleRate uint32, channels uint16, samples []int16) []byte {
+	dataSize := uint32(len(samples) * 2)
+	blockAlign := channels * 2
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	binary.Write(buf, binary.LittleEndian, channels)
+	binary.Write(buf, binary.LittleEndian, sampleRate)
+	binary.Write(buf, binary.LittleEndian, sampleRate*uint32(blockAlign))
+	binary.Write(buf, binary.LittleEndian, blockAlign)
+	binary.Write(buf, binary.LittleEndian, uint16(16))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, dataSize)
+	binary.Write(buf, binary.LittleEndian, samples)
+	return buf.Bytes()
+}
+
+// TestRampFixture checks testdata/ramp.wav, which the waveformhttp and pregen tests
+// share: one second of 16-bit mono audio at 8000 Hz with a quiet ramp
+func TestRampFixture(t *testing.T) {
+	samples := make([]int16, 8000)
+	for i := range samples {
+		samples[i] = int16(i%200 - 100)
+	}
+	want := testWAVBytes(8000, 1, samples)
+	got, err := os.ReadFile("testdata/ramp.wav")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("testdata/ramp.wav differs from the ramp it should hold")
+	}
+}
+
 func TestReadWAVHeader(t *testing.T) {
 	tmpFile := "/tmp/test_header.wav"
 	defer os.Remove(tmpFile)
 
 	createTestWAV(t, tmpFile, 44100, 1.0)
 
-	// ReadWAVHeader is no longer supported with audiomorph
-	// Instead, use LoadWaveform to get audio metadata
+	f, err := os.Open(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open test WAV file: %v", err)
+	}
+	defer f.Close()
+	header, err := ReadWAVHeader(f)
+	if err != nil {
+		t.Fatalf("ReadWAVHeader failed: %v", err)
+	}
+	if header.SampleRate != 44100 || header.Channels != 1 || header.BitsPerSample != 16 {
+		t.Errorf("Expected 44100 Hz, 1 channel, 16 bits, got %+v", header)
+	}
+	if header.DataSize != 88200 || header.DataOffset != 44 {
+		t.Errorf("Expected 88200 bytes of data at offset 44, got %d at %d", header.DataSize, header.DataOffset)
+	}
+
 	waveform, err := LoadWaveform(tmpFile)
 	if err != nil {
 		t.Fatalf("LoadWaveform failed: %v", err)
@@ -184,11 +261,40 @@ func TestGenerateWaveformJSON(t *testing.T) {
 	t.Logf("Generated JSON sample:\n%s", jsonStr[:min(len(jsonStr), 500)])
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+func TestGenerateWaveformJSONVersion1(t *testing.T) {
+	tmpFile := "/tmp/test_json_v1.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 0.5)
+
+	v2, err := GenerateWaveformData(tmpFile, WaveformOptions{SamplesPerPixel: 256})
+	if err != nil {
+		t.Fatalf("GenerateWaveformData failed: %v", err)
+	}
+
+	jsonData, err := GenerateWaveformJSON(tmpFile, WaveformOptions{SamplesPerPixel: 256, FormatVersion: 1})
+	if err != nil {
+		t.Fatalf("GenerateWaveformJSON failed: %v", err)
+	}
+
+	if bytes.Contains(jsonData, []byte("\"channels\"")) {
+		t.Error("Version 1 JSON should not have a 'channels' field")
+	}
+	if !bytes.Contains(jsonData, []byte("\"version\": 1")) {
+		t.Error("Expected version 1")
+	}
+
+	var v1 WaveformData
+	if err := json.Unmarshal(jsonData, &v1); err != nil {
+		t.Fatalf("Failed to unmarshal JSON: %v", err)
+	}
+	if v1.Length != v2.Length || len(v1.Data) != len(v2.Data) {
+		t.Errorf("Expected the same peaks as version 2, got length %d vs %d", v1.Length, v2.Length)
+	}
+
+	if _, err := GenerateWaveformData(tmpFile, WaveformOptions{SamplesPerPixel: 256, FormatVersion: 3}); err == nil {
+		t.Error("Expected error for unsupported format version")
 	}
-	return b
 }
 
 func TestWaveformGenerateViewWithWidth(t *testing.T) {
@@ -267,6 +373,128 @@ func TestWaveformGenerateViewWithWidthAndRange(t *testing.T) {
 	}
 }
 
+func TestWaveformGenerateViewTimeAxis(t *testing.T) {
+	tmpFile := "/tmp/test_view_time_axis.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// 0.5s to 1.0s in pixels of 4410 samples (0.1s), the last one partial
+	data, err := waveform.GenerateView(WaveformOptions{Start: 0.5, End: 0.95, SamplesPerPixel: 4410, TimeAxis: true})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	axis := data.TimeAxis
+	if axis == nil {
+		t.Fatal("Expected a time axis")
+	}
+	if axis.Start != 0.5 || math.Abs(axis.End-0.95) > 1e-9 {
+		t.Errorf("Expected 0.5s to 0.95s, got %.4f to %.4f", axis.Start, axis.End)
+	}
+	expected := []float64{0.5, 0.6, 0.7, 0.8, 0.9}
+	if len(axis.Times) != data.Length || len(axis.Times) != len(expected) {
+		t.Fatalf("Expected %d times, got %v", len(expected), axis.Times)
+	}
+	for i, e := range expected {
+		if math.Abs(axis.Times[i]-e) > 1e-9 {
+			t.Errorf("Pixel %d: expected %.2fs, got %.4fs", i, e, axis.Times[i])
+		}
+	}
+
+	jsonData, err := GenerateJSON(data)
+	if err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+	if !bytes.Contains(jsonData, []byte(`"time_axis"`)) {
+		t.Error("Expected a time_axis block in the JSON output")
+	}
+
+	data, err = waveform.GenerateView(WaveformOptions{Width: 10})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if data.TimeAxis != nil {
+		t.Error("Expected no time axis unless requested")
+	}
+}
+
+func TestWaveformGenerateViewRange(t *testing.T) {
+	tmpFile := "/tmp/test_view_range.wav"
+	defer os.Remove(tmpFile)
+
+	createTestWAV(t, tmpFile, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// An end past the file is clamped, and the view records the clamped range
+	data, err := waveform.GenerateView(WaveformOptions{Start: 0.5, End: 3.0, Width: 100})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+	if data.Start != 0.5 || math.Abs(data.End-2.0) > 1e-9 {
+		t.Errorf("Expected 0.5s to 2.0s, got %.4f to %.4f", data.Start, data.End)
+	}
+	if math.Abs(data.SourceDuration-2.0) > 1e-9 {
+		t.Errorf("Expected a source duration of 2.0s, got %.4f", data.SourceDuration)
+	}
+
+	jsonData, err := GenerateJSON(data)
+	if err != nil {
+		t.Fatalf("GenerateJSON failed: %v", err)
+	}
+	if bytes.Contains(jsonData, []byte(`"start"`)) || bytes.Contains(jsonData, []byte(`"source_duration"`)) {
+		t.Errorf("Expected the range to stay out of the JSON output, got %s", jsonData)
+	}
+
+	// Loaded peaks without a time axis are assumed to cover the whole source
+	loaded, err := ReadJSON(bytes.NewReader(jsonData))
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	want := float64(loaded.Length*loaded.SamplesPerPixel) / float64(loaded.SampleRate)
+	if loaded.Start != 0 || loaded.End != want {
+		t.Errorf("Expected 0s to %.4fs, got %.4f to %.4f", want, loaded.Start, loaded.End)
+	}
+}
+
+func TestWaveformGenerateViewSymmetric(t *testing.T) {
+	tmpFile := "/tmp/test_view_symmetric.wav"
+	defer os.Remove(tmpFile)
+
+	// Lopsided pixels: a large negative peak, a large positive peak, full scale and silence
+	samples := []int16{-20000, 1000, 500, 16000, -32768, 100, 0, 0}
+	writeTestWAV(t, tmpFile, 44100, 1, samples)
+
+	waveform, err := LoadWaveform(tmpFile)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	data, err := waveform.GenerateView(WaveformOptions{SamplesPerPixel: 2, Symmetric: true})
+	if err != nil {
+		t.Fatalf("GenerateView failed: %v", err)
+	}
+
+	expected := []int16{-20000, 20000, -16000, 16000, -32767, 32767, 0, 0}
+	if len(data.Data) != len(expected) {
+		t.Fatalf("Expected %d values, got %d", len(expected), len(data.Data))
+	}
+	for i := range expected {
+		if data.Data[i] != expected[i] {
+			t.Errorf("Value %d: expected %d, got %d", i, expected[i], data.Data[i])
+		}
+	}
+}
+
 func TestInvalidWAVFile(t *testing.T) {
 	tmpFile := "/tmp/test_invalid.wav"
 	defer os.Remove(tmpFile)
@@ -0,0 +1,216 @@
+package waveformhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlobInfo describes an object in a BlobStore
+type BlobInfo struct {
+	Size    int64     // Size of the whole object in bytes
+	ModTime time.Time // Last modification time, zero if the store does not report one
+}
+
+// BlobStore reads objects from a storage service such as an S3 or GCS bucket. Names
+// are slash-separated paths like those of fs.FS. A missing object returns an error
+// wrapping fs.ErrNotExist.
+type BlobStore interface {
+	// Get returns the whole object and its info
+	Get(ctx context.Context, name string) (io.ReadCloser, BlobInfo, error)
+	// Range returns up to length bytes of the object from offset, or the rest of the
+	// object when length is negative, together with the info of the whole object
+	Range(ctx context.Context, name string, offset, length int64) (io.ReadCloser, BlobInfo, error)
+}
+
+// contextFS is implemented by file systems that can bind the context of a request,
+// so a canceled request stops its reads
+type contextFS interface {
+	withContext(ctx context.Context) fs.FS
+}
+
+// BlobFS returns a file system reading from store, to pass to Handler. Stat probes an
+// object with a one byte range read instead of downloading it, so a cached waveform
+// costs a single small request; the object is only fetched when it is decoded.
+//
+//	store := waveformhttp.NewS3Store("my-bucket", "eu-west-1")
+//	http.Handle("/waveforms/", http.StripPrefix("/waveforms", waveformhttp.Handler(waveformhttp.BlobFS(store))))
+func BlobFS(store BlobStore) fs.FS {
+	return blobFS{store: store, ctx: context.Background()}
+}
+
+// blobFS is the fs.FS returned by BlobFS
+type blobFS struct {
+	store BlobStore
+	ctx   context.Context
+}
+
+func (b blobFS) withContext(ctx context.Context) fs.FS {
+	b.ctx = ctx
+	return b
+}
+
+// Open implements fs.FS. The object is fetched on the first read.
+func (b blobFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	return &blobFile{fs: b, name: name}, nil
+}
+
+// Stat implements fs.StatFS with a one byte range read
+func (b blobFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	body, info, err := b.store.Range(b.ctx, name, 0, 1)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: err}
+	}
+	body.Close()
+	return blobFileInfo{name: path.Base(name), info: info}, nil
+}
+
+// blobFile is an object opened by blobFS
+type blobFile struct {
+	fs   blobFS
+	name string
+	body io.ReadCloser
+	info BlobInfo
+}
+
+// Stat implements fs.File
+func (f *blobFile) Stat() (fs.FileInfo, error) {
+	if f.body != nil {
+		return blobFileInfo{name: path.Base(f.name), info: f.info}, nil
+	}
+	return f.fs.Stat(f.name)
+}
+
+// Read implements fs.File
+func (f *blobFile) Read(p []byte) (int, error) {
+	if f.body == nil {
+		body, info, err := f.fs.store.Get(f.fs.ctx, f.name)
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+		f.body, f.info = body, info
+	}
+	return f.body.Read(p)
+}
+
+// Close implements fs.File
+func (f *blobFile) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	return f.body.Close()
+}
+
+// blobFileInfo implements fs.FileInfo for an object
+type blobFileInfo struct {
+	name string
+	info BlobInfo
+}
+
+func (i blobFileInfo) Name() string       { return i.name }
+func (i blobFileInfo) Size() int64        { return i.info.Size }
+func (i blobFileInfo) Mode() fs.FileMode  { return 0444 }
+func (i blobFileInfo) ModTime() time.Time { return i.info.ModTime }
+func (i blobFileInfo) IsDir() bool        { return false }
+func (i blobFileInfo) Sys() any           { return nil }
+
+// fetchRange sends a GET request for a range of an object, as used by the S3 and GCS
+// stores, and returns the body limited to the range and the info of the whole object.
+// authorize adds the credentials once the range is set, as S3 signs it.
+func fetchRange(client *http.Client, req *http.Request, offset, length int64, authorize func(*http.Request) error) (io.ReadCloser, BlobInfo, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if offset > 0 || length >= 0 {
+		// An empty range cannot be requested, so one byte is fetched and cut off below
+		rangeHeader := fmt.Sprintf("bytes=%d-", offset)
+		if length >= 0 {
+			rangeHeader += strconv.FormatInt(offset+max(length, 1)-1, 10)
+		}
+		req.Header.Set("Range", rangeHeader)
+	}
+	if err := authorize(req); err != nil {
+		return nil, BlobInfo{}, fmt.Errorf("failed to authorize request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+
+	info := BlobInfo{Size: resp.ContentLength}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if info.Size, err = contentRangeSize(resp.Header.Get("Content-Range")); err != nil {
+			resp.Body.Close()
+			return nil, BlobInfo{}, err
+		}
+		return limitBody(resp.Body, length), info, nil
+
+	case http.StatusOK:
+		// The server ignored the range and sent the whole object
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil && err != io.EOF {
+			resp.Body.Close()
+			return nil, BlobInfo{}, fmt.Errorf("failed to skip to offset %d: %w", offset, err)
+		}
+		return limitBody(resp.Body, length), info, nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The range starts at or after the end of the object, e.g. any range of an empty object
+		resp.Body.Close()
+		if info.Size, err = contentRangeSize(resp.Header.Get("Content-Range")); err != nil {
+			return nil, BlobInfo{}, err
+		}
+		return io.NopCloser(strings.NewReader("")), info, nil
+	}
+
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, BlobInfo{}, fs.ErrNotExist
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return nil, BlobInfo{}, fmt.Errorf("%w: %s", fs.ErrPermission, resp.Status)
+	}
+	return nil, BlobInfo{}, fmt.Errorf("unexpected response: %s", resp.Status)
+}
+
+// contentRangeSize returns the total size from a Content-Range header such as
+// "bytes 0-0/1234" or "bytes */1234"
+func contentRangeSize(header string) (int64, error) {
+	_, total, ok := strings.Cut(header, "/")
+	if !ok {
+		return 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return size, nil
+}
+
+// limitBody returns body limited to length bytes, or body itself when length is negative
+func limitBody(body io.ReadCloser, length int64) io.ReadCloser {
+	if length < 0 {
+		return body
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.LimitReader(body, length), body}
+}
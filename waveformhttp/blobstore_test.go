@@ -0,0 +1,135 @@
+package waveformhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// blobServer serves the files of testFS like a bucket, recording the Range header of
+// each request
+func blobServer(t *testing.T, ranges *[]string) *httptest.Server {
+	files := testFS()
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*ranges = append(*ranges, r.Header.Get("Range"))
+		file, ok := files[strings.TrimPrefix(r.URL.Path, "/bucket/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "", modTime, strings.NewReader(string(file.Data)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchRange(t *testing.T) {
+	var ranges []string
+	server := blobServer(t, &ranges)
+	size := int64(len(testWAV()))
+
+	tests := []struct {
+		offset, length int64
+		header         string
+		read           int64
+	}{
+		{0, -1, "", size},
+		{0, 12, "bytes=0-11", 12},
+		{100, -1, "bytes=100-", size - 100},
+		{0, 0, "bytes=0-0", 0},
+		{size, 10, fmt.Sprintf("bytes=%d-%d", size, size+9), 0}, // Past the end, without a modification time
+	}
+	for _, tt := range tests {
+		ranges = nil
+		req := httptest.NewRequest(http.MethodGet, server.URL+"/bucket/song.wav", nil)
+		req.RequestURI = ""
+		body, info, err := fetchRange(nil, req, tt.offset, tt.length, func(*http.Request) error { return nil })
+		if err != nil {
+			t.Fatalf("fetchRange(%d, %d) failed: %v", tt.offset, tt.length, err)
+		}
+		n, _ := io.Copy(io.Discard, body)
+		body.Close()
+		if n != tt.read || info.Size != size || tt.offset < size && info.ModTime.IsZero() {
+			t.Errorf("fetchRange(%d, %d): expected %d of %d bytes, got %d of %d (modified %v)",
+				tt.offset, tt.length, tt.read, size, n, info.Size, info.ModTime)
+		}
+		if ranges[0] != tt.header {
+			t.Errorf("fetchRange(%d, %d): expected Range %q, got %q", tt.offset, tt.length, tt.header, ranges[0])
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, server.URL+"/bucket/missing.wav", nil)
+	req.RequestURI = ""
+	if _, _, err := fetchRange(nil, req, 0, 1, func(*http.Request) error { return nil }); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestFetchRangeIgnored(t *testing.T) {
+	// Servers that do not support ranges send the whole object
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "0123456789")
+	}))
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, server.URL, nil)
+	req.RequestURI = ""
+	body, info, err := fetchRange(nil, req, 3, 4, func(*http.Request) error { return nil })
+	if err != nil {
+		t.Fatalf("fetchRange failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "3456" || info.Size != 10 {
+		t.Errorf("Expected bytes 3456 of 10, got %q of %d", data, info.Size)
+	}
+}
+
+func TestHandlerBlobFS(t *testing.T) {
+	var ranges []string
+	server := blobServer(t, &ranges)
+	store := &S3Store{Bucket: "bucket", Region: "us-east-1", Endpoint: server.URL}
+	h := Handler(BlobFS(store))
+
+	rec := get(h, "/stems/bass.wav.json?width=100", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	data, err := gowaveform.ReadJSON(rec.Body)
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if data.Length != 100 {
+		t.Errorf("Expected 100 pixels, got %d", data.Length)
+	}
+
+	// The object is probed with a one byte range and then downloaded once; a cached
+	// waveform only needs the probe
+	get(h, "/stems/bass.wav.png", nil)
+	expected := []string{"bytes=0-0", "", "bytes=0-0"}
+	if strings.Join(ranges, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected requests with ranges %q, got %q", expected, ranges)
+	}
+
+	if rec := get(h, "/missing.wav.json", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing object, got %d", rec.Code)
+	}
+
+	// Requests stop reading when the client goes away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fsys := BlobFS(store).(contextFS).withContext(ctx)
+	if _, err := fs.Stat(fsys, "song.wav"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package waveformhttp
+
+import (
+	"errors"
+	"sync"
+)
+
+// errCallAborted is shared with waiting callers when the call they joined panicked
+var errCallAborted = errors.New("request aborted")
+
+// call is a piece of work in progress that identical concurrent requests wait for
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+	dups  int // Requests that joined the call after it started
+}
+
+// group runs work keyed by what it produces only once at a time: callers asking for a
+// key that is already in progress wait for that call and share its result, so a burst
+// of identical requests costs one decode or rendering
+type group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// do runs fn for key unless a call for key is in progress, in which case it waits for
+// that call. shared reports whether the result was shared with other callers.
+func (g *group[T]) do(key string, fn func() (T, error)) (value T, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = map[string]*call[T]{}
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		<-c.done
+		return c.value, c.err, true
+	}
+	c := &call[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	// The call is removed before waiters are released, so a request arriving later
+	// starts afresh and sees a changed file
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		shared = c.dups > 0
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.err = errCallAborted
+	c.value, c.err = fn()
+	return c.value, c.err, false
+}
@@ -0,0 +1,132 @@
+package waveformhttp
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// waitForDups waits until n callers joined calls in progress in g
+func waitForDups[T any](t *testing.T, g *group[T], n int) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		g.mu.Lock()
+		dups := 0
+		for _, c := range g.calls {
+			dups += c.dups
+		}
+		g.mu.Unlock()
+		if dups == n {
+			return
+		}
+	}
+	t.Fatalf("Timed out waiting for %d callers to join", n)
+}
+
+func TestGroup(t *testing.T) {
+	var g group[int]
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	const n = 50
+	values := make([]int, n)
+	shared := make([]bool, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], _, shared[i] = g.do("key", func() (int, error) {
+				calls.Add(1)
+				<-release
+				return 42, nil
+			})
+		}()
+	}
+	waitForDups(t, &g, n-1)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected one call, got %d", calls.Load())
+	}
+	for i := range n {
+		if values[i] != 42 || !shared[i] {
+			t.Fatalf("Caller %d: expected the shared value 42, got %d (shared %v)", i, values[i], shared[i])
+		}
+	}
+
+	// A finished call is not reused
+	if value, _, shared := g.do("key", func() (int, error) { return 7, nil }); value != 7 || shared {
+		t.Errorf("Expected a fresh call, got %d (shared %v)", value, shared)
+	}
+}
+
+func TestGenerateRetriesCanceled(t *testing.T) {
+	h := Handler(testFS()).(*handler)
+
+	// The request that started the work went away; this one is still waiting
+	attempts := 0
+	body, err := h.generate(context.Background(), "id", func() ([]byte, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, context.Canceled
+		}
+		return []byte("ok"), nil
+	})
+	if err != nil || string(body) != "ok" || attempts != 2 {
+		t.Errorf("Expected a retry, got %q, %v after %d attempts", body, err, attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := h.generate(ctx, "id", func() ([]byte, error) { return nil, ctx.Err() }); err != context.Canceled {
+		t.Errorf("Expected context.Canceled for a canceled request, got %v", err)
+	}
+}
+
+// gatedFS counts the files opened and holds each open until released
+type gatedFS struct {
+	fstest.MapFS
+	opens   atomic.Int32
+	release chan struct{}
+}
+
+func (g *gatedFS) Open(name string) (fs.File, error) {
+	g.opens.Add(1)
+	<-g.release
+	return g.MapFS.Open(name)
+}
+
+func TestHandlerCoalescing(t *testing.T) {
+	fsys := &gatedFS{MapFS: testFS(), release: make(chan struct{})}
+	h := Handler(fsys).(*handler)
+
+	const n = 50
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes[i] = get(h, "/song.wav.png?width=300", nil).Code
+		}()
+	}
+	waitForDups(t, &h.renders, n-1)
+	close(fsys.release)
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i, code)
+		}
+	}
+	if opens := fsys.opens.Load(); opens != 1 {
+		t.Errorf("Expected the file to be decoded once, got %d opens", opens)
+	}
+}
@@ -0,0 +1,51 @@
+package waveformhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGCSStore(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		if r.URL.Path != "/bucket/song.wav" {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader("0123456789"))
+	}))
+	defer server.Close()
+
+	store := NewGCSStore("bucket")
+	store.Endpoint = server.URL
+	store.Token = func(ctx context.Context) (string, error) {
+		return "token", nil
+	}
+
+	body, info, err := store.Get(context.Background(), "song.wav")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "0123456789" || info.Size != 10 {
+		t.Errorf("Expected the whole object, got %q of %d bytes", data, info.Size)
+	}
+	if gotAuth != "Bearer token" {
+		t.Errorf("Expected a bearer token, got %q", gotAuth)
+	}
+
+	store.Token = func(ctx context.Context) (string, error) {
+		return "", errors.New("no credentials")
+	}
+	if _, _, err := store.Range(context.Background(), "song.wav", 0, 1); err == nil {
+		t.Error("Expected an error when the token cannot be obtained")
+	}
+}
@@ -0,0 +1,207 @@
+package waveformhttp
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// DefaultLiveIdle is how long a live stream waits for a file to grow before ending
+const DefaultLiveIdle = 30 * time.Second
+
+// livePoll is the longest time between checks of a live file for new samples
+const livePoll = 250 * time.Millisecond
+
+// errLiveFormat is returned for live requests of files that are not PCM WAV
+var errLiveFormat = errors.New("live streaming needs a PCM WAV file")
+
+// liveMessage is a WebSocket message of a live stream. The first message carries the
+// format; each one carries the peaks completed since the previous, starting at pixel
+// Offset. The last message has Final set and includes the partial pixel at the end.
+type liveMessage struct {
+	SampleRate      int     `json:"sample_rate,omitempty"`
+	SamplesPerPixel int     `json:"samples_per_pixel,omitempty"`
+	Offset          int     `json:"offset"`
+	Data            []int16 `json:"data"`
+	Final           bool    `json:"final,omitempty"`
+}
+
+// wavFormat is the sample layout of a PCM WAV file
+type wavFormat struct {
+	channels   int
+	sampleRate int
+	bits       int
+}
+
+// readWAVHeader reads a WAV file up to the start of its samples
+func readWAVHeader(r io.Reader) (wavFormat, error) {
+	var f wavFormat
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil || string(riff[:4]) != "RIFF" || string(riff[8:]) != "WAVE" {
+		return f, errLiveFormat
+	}
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return f, fmt.Errorf("%w: no data chunk", errLiveFormat)
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+		switch string(chunk[:4]) {
+		case "fmt ":
+			if size < 16 {
+				return f, fmt.Errorf("%w: short fmt chunk", errLiveFormat)
+			}
+			fmtChunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return f, fmt.Errorf("%w: short fmt chunk", errLiveFormat)
+			}
+			tag := binary.LittleEndian.Uint16(fmtChunk)
+			f.channels = int(binary.LittleEndian.Uint16(fmtChunk[2:]))
+			f.sampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:]))
+			f.bits = int(binary.LittleEndian.Uint16(fmtChunk[14:]))
+			// 0xFFFE is WAVE_FORMAT_EXTENSIBLE, which recorders use for PCM too
+			if (tag != 1 && tag != 0xFFFE) || f.channels < 1 || f.sampleRate < 1 || (f.bits != 8 && f.bits != 16 && f.bits != 24 && f.bits != 32) {
+				return f, fmt.Errorf("%w: unsupported format %d with %d bits", errLiveFormat, tag, f.bits)
+			}
+		case "data":
+			if f.channels == 0 {
+				return f, fmt.Errorf("%w: data before fmt chunk", errLiveFormat)
+			}
+			// The size is left out: recorders fill it in when they finish
+			return f, nil
+		default:
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return f, fmt.Errorf("%w: no data chunk", errLiveFormat)
+			}
+		}
+	}
+}
+
+// toInt16 converts little-endian PCM samples of bits bits to int16, keeping the most
+// significant bits
+func toInt16(samples []int16, data []byte, bits int) []int16 {
+	width := bits / 8
+	for i := 0; i+width <= len(data); i += width {
+		var s int16
+		switch bits {
+		case 8:
+			s = int16(data[i]-128) << 8 // 8-bit WAV is unsigned
+		default:
+			s = int16(binary.LittleEndian.Uint16(data[i+width-2:]))
+		}
+		samples = append(samples, s)
+	}
+	return samples
+}
+
+// serveLive streams the peaks of a WAV file that is still being written over a
+// WebSocket: the samples already in the file first, then new ones as they are
+// appended, until the file has not grown for the idle time or the client goes away
+func (h *handler) serveLive(w http.ResponseWriter, r *http.Request, root fs.FS, name string, v view) {
+	f, err := root.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	format, err := readWAVHeader(f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	samplesPerPixel := v.samplesPerPixel
+	if samplesPerPixel == 0 {
+		samplesPerPixel = 256
+	}
+	reducer, err := gowaveform.NewPeakReducer(format.channels, samplesPerPixel)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgradeWebSocket(w, r)
+	if errors.Is(err, errNotWebSocket) {
+		w.Header().Set("Upgrade", "websocket")
+		http.Error(w, err.Error(), http.StatusUpgradeRequired)
+		return
+	} else if err != nil {
+		return // The connection is gone
+	}
+
+	// The stream ends when the client closes the connection
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	go func() {
+		conn.readLoop()
+		cancel()
+	}()
+
+	send := func(m liveMessage) error {
+		payload, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return conn.writeText(payload)
+	}
+	first := liveMessage{SampleRate: format.sampleRate, SamplesPerPixel: samplesPerPixel, Data: []int16{}}
+	if err := send(first); err != nil {
+		conn.conn.Close()
+		return
+	}
+
+	poll := min(livePoll, h.config.liveIdle/4)
+	frame := format.channels * format.bits / 8
+	buf := make([]byte, 64*1024-64*1024%frame)
+	var pending []byte // Bytes of a partial frame
+	var samples []int16
+	lastGrowth := time.Now()
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			lastGrowth = time.Now()
+			data := append(pending, buf[:n]...)
+			whole := len(data) - len(data)%frame
+			samples = toInt16(samples[:0], data[:whole], format.bits)
+			pending = append([]byte(nil), data[whole:]...)
+
+			offset := reducer.Pixels()
+			if peaks := reducer.Write(samples); len(peaks) > 0 {
+				if send(liveMessage{Offset: offset, Data: peaks}) != nil {
+					conn.conn.Close()
+					return
+				}
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			conn.close(1011) // Internal error
+			return
+		}
+
+		// At the end of what has been written so far
+		if time.Since(lastGrowth) >= h.config.liveIdle {
+			offset := reducer.Pixels()
+			last := reducer.Flush()
+			if last == nil {
+				last = []int16{}
+			}
+			send(liveMessage{Offset: offset, Data: last, Final: true})
+			conn.close(1000) // Normal closure
+			return
+		}
+		select {
+		case <-ctx.Done():
+			conn.close(1000) // Answering the client's close
+			return
+		case <-time.After(poll):
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package waveformhttp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// dialLive opens a WebSocket to target on server and returns the connection and a
+// reader positioned after the handshake
+func dialLive(t *testing.T, server *httptest.Server, target string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+	io.WriteString(conn, "GET "+target+" HTTP/1.1\r\nHost: test\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n"+
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	if err != nil {
+		t.Fatalf("ReadResponse failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("Expected 101, got %d", resp.StatusCode)
+	}
+	// The example key and answer of RFC 6455
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("Unexpected Sec-WebSocket-Accept %q", accept)
+	}
+	return conn, r
+}
+
+// readFrame reads an unmasked server frame
+func readFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatalf("Reading a frame failed: %v", err)
+	}
+	length := int(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = int(binary.BigEndian.Uint64(ext[:]))
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatalf("Reading a frame failed: %v", err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+// readMessage reads a live message
+func readMessage(t *testing.T, r *bufio.Reader) liveMessage {
+	t.Helper()
+	opcode, payload := readFrame(t, r)
+	if opcode != opText {
+		t.Fatalf("Expected a text frame, got opcode %d: %q", opcode, payload)
+	}
+	var m liveMessage
+	if err := json.Unmarshal(payload, &m); err != nil {
+		t.Fatalf("Invalid message %q: %v", payload, err)
+	}
+	return m
+}
+
+// appendRamp appends n 16-bit samples of the ramp of testWAV to a file
+func appendRamp(t *testing.T, name string, from, n int) {
+	t.Helper()
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16((from+i)%200 - 100)
+	}
+	if err := binary.Write(f, binary.LittleEndian, samples); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLiveStream(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "recording.wav")
+	// The header of testWAV without its samples, as a recorder writes it first
+	if err := os.WriteFile(name, testWAV()[:44], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	appendRamp(t, name, 0, 1000)
+
+	server := httptest.NewServer(Handler(os.DirFS(dir), OptionLiveIdle(300*time.Millisecond)))
+	defer server.Close()
+	_, r := dialLive(t, server, "/recording.wav.live?samples_per_pixel=100")
+
+	first := readMessage(t, r)
+	if first.SampleRate != 8000 || first.SamplesPerPixel != 100 || len(first.Data) != 0 {
+		t.Fatalf("Expected the format first, got %+v", first)
+	}
+
+	// The samples already written, then the ones appended while streaming
+	var peaks []int16
+	next := func() liveMessage {
+		m := readMessage(t, r)
+		if m.Offset != len(peaks)/2 {
+			t.Fatalf("Expected offset %d, got %d", len(peaks)/2, m.Offset)
+		}
+		peaks = append(peaks, m.Data...)
+		return m
+	}
+	for len(peaks) < 20 {
+		if next().Final {
+			t.Fatal("Stream ended before the file stopped growing")
+		}
+	}
+	appendRamp(t, name, 1000, 550)
+	for !next().Final {
+	}
+
+	if len(peaks) != 32 {
+		t.Fatalf("Expected 16 pixels, got %d values", len(peaks))
+	}
+	if peaks[0] != -100 || peaks[1] != -1 {
+		t.Errorf("Expected the first pixel to span -100 to -1, got %d and %d", peaks[0], peaks[1])
+	}
+	if peaks[30] != 0 || peaks[31] != 49 {
+		t.Errorf("Expected the partial last pixel to span 0 to 49, got %d and %d", peaks[30], peaks[31])
+	}
+	if opcode, payload := readFrame(t, r); opcode != opClose || binary.BigEndian.Uint16(payload) != 1000 {
+		t.Errorf("Expected a normal close, got opcode %d: %v", opcode, payload)
+	}
+}
+
+func TestLiveStreamClientClose(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "recording.wav"), testWAV(), 0o644)
+	server := httptest.NewServer(Handler(os.DirFS(dir)))
+	defer server.Close()
+	conn, r := dialLive(t, server, "/recording.wav.live")
+	readMessage(t, r)
+	readMessage(t, r)
+
+	// A masked close frame with an empty payload
+	conn.Write([]byte{0x80 | opClose, 0x80, 1, 2, 3, 4})
+	if opcode, payload := readFrame(t, r); opcode != opClose || binary.BigEndian.Uint16(payload) != 1000 {
+		t.Errorf("Expected the server to close, got opcode %d: %v", opcode, payload)
+	}
+}
+
+func TestLiveStreamErrors(t *testing.T) {
+	fsys := testFS()
+	fsys["notes.txt.wav"] = &fstest.MapFile{Data: []byte("not a WAV file")}
+	h := Handler(fsys)
+
+	if rec := get(h, "/song.wav.live", nil); rec.Code != http.StatusUpgradeRequired {
+		t.Errorf("Expected 426 without a WebSocket handshake, got %d", rec.Code)
+	}
+	if rec := get(h, "/notes.txt.wav.live", nil); rec.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for a file that is not a WAV, got %d", rec.Code)
+	}
+	if rec := get(h, "/missing.wav.live", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a missing file, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,173 @@
+package waveformhttp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// decodeBuckets are the upper bounds in seconds of the decode duration histogram
+var decodeBuckets = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// metrics counts the requests, cache lookups and decodes of a handler, for
+// exposition in the Prometheus text format
+type metrics struct {
+	mu       sync.Mutex
+	requests map[requestKey]uint64
+	hits     uint64
+	misses   uint64
+	decodes  []uint64 // Decodes per bucket of decodeBuckets, and one past the last
+	decodeN  uint64
+	decodeS  float64 // Total decode time in seconds
+}
+
+// requestKey labels a request counter
+type requestKey struct {
+	endpoint string
+	code     int
+}
+
+// newMetrics returns empty metrics
+func newMetrics() *metrics {
+	return &metrics{
+		requests: map[requestKey]uint64{},
+		decodes:  make([]uint64, len(decodeBuckets)+1),
+	}
+}
+
+// request counts a response with status code to endpoint
+func (m *metrics) request(endpoint string, code int) {
+	m.mu.Lock()
+	m.requests[requestKey{endpoint, code}]++
+	m.mu.Unlock()
+}
+
+// cacheLookup counts a lookup of a decoded file in the cache
+func (m *metrics) cacheLookup(hit bool) {
+	m.mu.Lock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+	m.mu.Unlock()
+}
+
+// decode records how long decoding an audio file took
+func (m *metrics) decode(d time.Duration) {
+	seconds := d.Seconds()
+	i, _ := slices.BinarySearch(decodeBuckets, seconds)
+	m.mu.Lock()
+	m.decodes[i]++
+	m.decodeN++
+	m.decodeS += seconds
+	m.mu.Unlock()
+}
+
+// write writes the metrics in the Prometheus text format, with the number and size of
+// the cached files
+func (m *metrics) write(w io.Writer, cached int, cachedBytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fmt.Fprintln(w, "# HELP waveformhttp_requests_total Requests served, by endpoint and status code.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_requests_total counter")
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b requestKey) int {
+		if c := strings.Compare(a.endpoint, b.endpoint); c != 0 {
+			return c
+		}
+		return a.code - b.code
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "waveformhttp_requests_total{endpoint=%q,code=\"%d\"} %d\n", key.endpoint, key.code, m.requests[key])
+	}
+
+	fmt.Fprintln(w, "# HELP waveformhttp_cache_hits_total Lookups of decoded audio answered from the cache.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_cache_hits_total counter")
+	fmt.Fprintf(w, "waveformhttp_cache_hits_total %d\n", m.hits)
+	fmt.Fprintln(w, "# HELP waveformhttp_cache_misses_total Lookups of decoded audio that had to decode the file.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_cache_misses_total counter")
+	fmt.Fprintf(w, "waveformhttp_cache_misses_total %d\n", m.misses)
+	fmt.Fprintln(w, "# HELP waveformhttp_cache_entries Decoded audio files in the cache.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_cache_entries gauge")
+	fmt.Fprintf(w, "waveformhttp_cache_entries %d\n", cached)
+	fmt.Fprintln(w, "# HELP waveformhttp_cache_bytes Memory held by the decoded audio files in the cache.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_cache_bytes gauge")
+	fmt.Fprintf(w, "waveformhttp_cache_bytes %d\n", cachedBytes)
+
+	fmt.Fprintln(w, "# HELP waveformhttp_decode_duration_seconds Time taken to decode an audio file.")
+	fmt.Fprintln(w, "# TYPE waveformhttp_decode_duration_seconds histogram")
+	var cumulative uint64
+	for i, le := range decodeBuckets {
+		cumulative += m.decodes[i]
+		fmt.Fprintf(w, "waveformhttp_decode_duration_seconds_bucket{le=\"%g\"} %d\n", le, cumulative)
+	}
+	fmt.Fprintf(w, "waveformhttp_decode_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.decodeN)
+	fmt.Fprintf(w, "waveformhttp_decode_duration_seconds_sum %g\n", m.decodeS)
+	fmt.Fprintf(w, "waveformhttp_decode_duration_seconds_count %d\n", m.decodeN)
+}
+
+// endpoint returns the label of the endpoint a request path is for: the output format,
+// healthz or metrics, or other for paths that are not served
+func endpoint(urlPath string) string {
+	switch p := path.Clean("/" + urlPath); p {
+	case healthzPath:
+		return "healthz"
+	case metricsPath:
+		return "metrics"
+	default:
+		switch format := path.Ext(p); format {
+		case ".json", ".dat", ".png", ".stats", ".live":
+			return format[1:]
+		}
+		return "other"
+	}
+}
+
+// statusRecorder remembers the status code written to a ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// WriteHeader implements the http.ResponseWriter interface
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write implements the http.ResponseWriter interface
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Hijack implements the http.Hijacker interface, counting a taken over connection
+// as 101 Switching Protocols
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil && r.code == 0 {
+		r.code = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,76 @@
+package waveformhttp
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHealthz(t *testing.T) {
+	h := Handler(testFS(), OptionRateLimit(1, 1), OptionSignedURLs([]byte("key")))
+	for range 3 {
+		rec := get(h, "/healthz", nil)
+		if rec.Code != http.StatusOK || rec.Body.String() != "ok\n" {
+			t.Fatalf("Expected 200 ok, got %d: %q", rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	if rec := get(Handler(testFS()), "/metrics", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without OptionMetrics, got %d", rec.Code)
+	}
+
+	h := Handler(testFS(), OptionMetrics(true))
+	get(h, "/song.wav.json?width=100", nil)
+	get(h, "/song.wav.json?width=50", nil)
+	get(h, "/song.wav.png", nil)
+	get(h, "/missing.wav.json", nil)
+	get(h, "/song.wav.json?width=-1", nil)
+
+	rec := get(h, "/metrics", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Expected the Prometheus text format, got %q", ct)
+	}
+	body := rec.Body.String()
+	for _, line := range []string{
+		`waveformhttp_requests_total{endpoint="json",code="200"} 2`,
+		`waveformhttp_requests_total{endpoint="json",code="400"} 1`,
+		`waveformhttp_requests_total{endpoint="json",code="404"} 1`,
+		`waveformhttp_requests_total{endpoint="png",code="200"} 1`,
+		"waveformhttp_cache_hits_total 2",
+		"waveformhttp_cache_misses_total 1",
+		"waveformhttp_cache_entries 1",
+		`waveformhttp_decode_duration_seconds_bucket{le="+Inf"} 1`,
+		"waveformhttp_decode_duration_seconds_count 1",
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected %q in the metrics:\n%s", line, body)
+		}
+	}
+
+	// The scrape itself is counted for the next one
+	if body := get(h, "/metrics", nil).Body.String(); !strings.Contains(body, `waveformhttp_requests_total{endpoint="metrics",code="200"} 1`) {
+		t.Errorf("Expected the scrape to be counted:\n%s", body)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	for path, want := range map[string]string{
+		"/song.wav.json":  "json",
+		"/a/b.mp3.dat":    "dat",
+		"/song.wav.png":   "png",
+		"/song.wav.stats": "stats",
+		"/healthz":        "healthz",
+		"/x/../metrics":   "metrics",
+		"/song.wav":       "other",
+		"/song.wav.exe":   "other",
+	} {
+		if got := endpoint(path); got != want {
+			t.Errorf("endpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
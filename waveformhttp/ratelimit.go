@@ -0,0 +1,87 @@
+package waveformhttp
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle clients are dropped from a rateLimiter
+const sweepInterval = time.Minute
+
+// rateLimiter is a token bucket per client: each request takes a token, and tokens
+// are added at rate per second up to burst
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// bucket holds the tokens of one client
+type bucket struct {
+	tokens float64
+	last   time.Time // When tokens was last updated
+}
+
+// newRateLimiter returns a rate limiter allowing rate requests per second with bursts
+// of up to burst requests
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(max(burst, 1)),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// allow takes a token for client at now. Without one it returns false and how long
+// until the next token is added.
+func (l *rateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Clients whose buckets have refilled are no different from new ones
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for key, b := range l.buckets {
+			if l.refill(b, now) >= l.burst {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	if l.refill(b, now) < 1 {
+		wait := time.Duration(math.Ceil((1 - b.tokens) / l.rate * float64(time.Second)))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// refill adds the tokens earned since the bucket was last updated and returns them
+func (l *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed*l.rate, l.burst)
+		b.last = now
+	}
+	return b.tokens
+}
+
+// clientIP returns the IP address a request came from. Behind a reverse proxy this is
+// the proxy, unless a middleware sets RemoteAddr from a trusted forwarding header.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
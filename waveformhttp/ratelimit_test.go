@@ -0,0 +1,62 @@
+package waveformhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, 3)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A burst of three, then one more every half second
+	for i := range 3 {
+		if ok, _ := l.allow("a", now); !ok {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+	}
+	ok, wait := l.allow("a", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Errorf("Expected to wait 500ms, got %v (allowed %v)", wait, ok)
+	}
+	if ok, _ := l.allow("b", now); !ok {
+		t.Error("Expected other clients to be unaffected")
+	}
+	if ok, _ := l.allow("a", now.Add(500*time.Millisecond)); !ok {
+		t.Error("Expected a request to be allowed after the wait")
+	}
+
+	// Refilled buckets are dropped on the next sweep
+	l.allow("c", now.Add(time.Hour))
+	if len(l.buckets) != 1 {
+		t.Errorf("Expected only the new client after a sweep, got %d", len(l.buckets))
+	}
+}
+
+func TestHandlerRateLimit(t *testing.T) {
+	h := Handler(testFS(), OptionRateLimit(0.5, 2))
+
+	for i := range 2 {
+		if rec := get(h, "/song.wav.json", nil); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+	rec := get(h, "/song.wav.json", nil)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if retry := rec.Header().Get("Retry-After"); retry != "2" {
+		t.Errorf("Expected Retry-After 2, got %q", retry)
+	}
+
+	// Limits apply per IP address, whatever the port
+	req := httptest.NewRequest(http.MethodGet, "/song.wav.json", nil)
+	req.RemoteAddr = "203.0.113.7:4242"
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for another client, got %d", rec.Code)
+	}
+}
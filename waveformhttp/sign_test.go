@@ -0,0 +1,109 @@
+package waveformhttp
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifySignature(t *testing.T) {
+	key := []byte("secret")
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	signed, err := SignURL(key, "stems/../song.wav.png?width=800&fg=F50", now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SignURL failed: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", signed, err)
+	}
+	if u.Path != "/song.wav.png" {
+		t.Errorf("Expected the cleaned path /song.wav.png, got %q", u.Path)
+	}
+
+	expires, err := verifySignature([][]byte{key}, u.Path, u.Query(), now)
+	if err != nil || !expires.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected a valid signature until %v, got %v, %v", now.Add(time.Hour), expires, err)
+	}
+	if _, err := verifySignature([][]byte{key}, u.Path, u.Query(), now.Add(time.Hour)); !errors.Is(err, errExpired) {
+		t.Errorf("Expected an expired URL, got %v", err)
+	}
+
+	tampered := u.Query()
+	tampered.Set("width", "8000")
+	if _, err := verifySignature([][]byte{key}, u.Path, tampered, now); !errors.Is(err, errSignature) {
+		t.Errorf("Expected a changed parameter to be refused, got %v", err)
+	}
+	if _, err := verifySignature([][]byte{key}, "/stems/bass.wav.png", u.Query(), now); !errors.Is(err, errSignature) {
+		t.Errorf("Expected another file to be refused, got %v", err)
+	}
+	if _, err := verifySignature([][]byte{[]byte("other")}, u.Path, u.Query(), now); !errors.Is(err, errSignature) {
+		t.Errorf("Expected another key to be refused, got %v", err)
+	}
+
+	// Expiry is not revealed for URLs that are not authentic
+	if _, err := verifySignature([][]byte{[]byte("other")}, u.Path, u.Query(), now.Add(2*time.Hour)); !errors.Is(err, errSignature) {
+		t.Errorf("Expected an invalid signature rather than an expired URL, got %v", err)
+	}
+
+	if _, err := SignURL(nil, "/song.wav.png", now); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+}
+
+func TestHandlerSignedURLs(t *testing.T) {
+	oldKey, newKey := []byte("old"), []byte("new")
+	h := Handler(testFS(), OptionSignedURLs(newKey, oldKey), OptionMaxAge(24*time.Hour))
+
+	signed, err := SignURL(newKey, "/song.wav.json?width=100", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SignURL failed: %v", err)
+	}
+	rec := get(h, signed, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a signed URL, got %d: %s", rec.Code, rec.Body)
+	}
+	// Caches keep the response no longer than the URL is valid
+	cacheControl := rec.Header().Get("Cache-Control")
+	if cacheControl != "public, max-age=3599" && cacheControl != "public, max-age=3600" {
+		t.Errorf("Expected max-age capped at the expiry, got %q", cacheControl)
+	}
+
+	// A renewed URL for the same view keeps the ETag
+	renewed, _ := SignURL(newKey, "/song.wav.json?width=100", time.Now().Add(2*time.Hour))
+	if etag := get(h, renewed, nil).Header().Get("ETag"); etag != rec.Header().Get("ETag") {
+		t.Errorf("Expected the same ETag for a renewed URL, got %q and %q", etag, rec.Header().Get("ETag"))
+	}
+
+	// Links signed with the old key keep working during a rotation
+	rotated, _ := SignURL(oldKey, "/song.wav.json", time.Now().Add(time.Hour))
+	if rec := get(h, rotated, nil); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for the old key, got %d", rec.Code)
+	}
+
+	expired, _ := SignURL(newKey, "/song.wav.json", time.Now().Add(-time.Minute))
+	missing, _ := SignURL(newKey, "/missing.wav.json", time.Now().Add(time.Hour))
+	wrongKey, _ := SignURL([]byte("guess"), "/song.wav.json", time.Now().Add(time.Hour))
+	for name, target := range map[string]string{
+		"unsigned":      "/song.wav.json",
+		"missing file":  "/missing.wav.json",
+		"changed view":  strings.Replace(signed, "width=100", "width=200", 1),
+		"added option":  signed + "&height=50",
+		"changed file":  strings.Replace(signed, "song.wav", "stems/bass.wav", 1),
+		"wrong key":     wrongKey,
+		"expired":       expired,
+		"bad signature": "/song.wav.json?expires=9999999999&sig=!!!",
+	} {
+		if rec := get(h, target, nil); rec.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", name, rec.Code)
+		}
+	}
+
+	// Signed requests for files that do not exist are still not found
+	if rec := get(h, missing, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a signed URL of a missing file, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,727 @@
+// Package waveformhttp serves waveform data and images for the audio files in a
+// directory, as an http.Handler that can be mounted into any Go web application.
+//
+// A request for an audio file with an extra extension returns its peaks:
+//
+//	GET /song.mp3.json   audiowaveform JSON
+//	GET /song.mp3.dat    audiowaveform binary peaks
+//	GET /song.mp3.png    waveform image
+//	GET /song.mp3.stats  duration, peak and RMS level as JSON (see Waveform.StatsRange)
+//	GET /song.wav.live   peaks of a WAV file while it is recorded, over a WebSocket
+//
+// The query parameters start and end (seconds), width (pixels) and samples_per_pixel
+// select the view, and height sets the image height. Images are styled further with
+// fg and bg (hex colors, e.g. fg=%23FF5500 or fg=F50), style (filled or bars) and
+// split=true for a lane per channel, on top of OptionPlot, so one handler can serve
+// differently branded images. Stats cover the selection from start to end.
+//
+// Decoded audio is kept in a small cache, and responses carry ETag, Last-Modified and
+// Cache-Control headers so browsers and proxies can cache them too. Identical requests
+// arriving at the same time share a single decode and rendering. Options limit the
+// file size, duration and width a request may ask for and the request rate per
+// client, and OptionSignedURLs only serves URLs signed with SignURL until they expire.
+//
+// GET /healthz answers 200 for health checks, and with OptionMetrics GET /metrics
+// returns Prometheus metrics of the requests, the cache and decoding.
+//
+// BlobFS serves audio from object storage instead of a directory, with the S3Store and
+// GCSStore implementations of BlobStore for S3 and Google Cloud Storage buckets.
+package waveformhttp
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"image/png"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// Defaults used unless options are given
+const (
+	DefaultCacheSize = 16        // Decoded audio files kept in memory
+	DefaultMaxAge    = time.Hour // Cache-Control max-age of responses
+	DefaultMaxWidth  = 1 << 20   // Largest width in pixels of JSON and binary peaks
+	MaxImageSize     = 8192      // Largest accepted image width or height in pixels
+)
+
+// maxDigests is how many file hashes OptionContentHash keeps before starting over
+const maxDigests = 4096
+
+// Paths that are not audio files
+const (
+	healthzPath = "/healthz" // Answers 200 while the handler is up
+	metricsPath = "/metrics" // Prometheus metrics, with OptionMetrics
+)
+
+var (
+	// errLoadAudio is returned when an audio file cannot be decoded; the cause is
+	// not sent to clients
+	errLoadAudio = errors.New("failed to load audio")
+	// errTooLarge is returned for audio files over the size or duration limit
+	errTooLarge = errors.New("audio file too large")
+	// errTooWide is returned for views with more pixels than the width limit
+	errTooWide = errors.New("too many pixels")
+)
+
+// config holds the configuration of a handler
+type config struct {
+	cacheSize   int                     // Decoded audio files kept in memory (0 = no cache)
+	cacheMemory int64                   // Bytes the cached audio files may hold (0 = no limit)
+	maxAge      time.Duration           // Cache-Control max-age of responses
+	maxFileSize int64                   // Largest audio file in bytes (0 = no limit)
+	maxDuration time.Duration           // Longest audio file (0 = no limit)
+	maxWidth    int                     // Largest width in pixels of JSON and binary peaks
+	rateLimit   float64                 // Requests per second per client IP (0 = no limit)
+	rateBurst   int                     // Requests a client IP may make at once
+	loadOpts    []gowaveform.LoadOption // Options for loading the audio
+	plotOpts    []gowaveform.Option     // Styling of the waveform images
+	signingKeys [][]byte                // Keys of signed URLs (nil = no signature needed)
+	metrics     bool                    // Serve Prometheus metrics at /metrics
+	contentHash bool                    // Derive ETags from the file contents
+	liveIdle    time.Duration           // How long a live stream waits for new samples
+}
+
+// Option is the type all handler options need to adhere to
+type Option func(*config)
+
+// OptionCacheSize sets how many decoded audio files are kept in memory (default: 16);
+// 0 decodes the file for every request
+func OptionCacheSize(n int) Option {
+	return func(c *config) {
+		c.cacheSize = max(n, 0)
+	}
+}
+
+// OptionCacheMemory limits the cache to audio files holding at most bytes of memory
+// together, as reported by Waveform.MemoryUsage (default: no limit), evicting the least
+// recently used files first. The file loaded last is kept even if it alone is larger.
+func OptionCacheMemory(bytes int64) Option {
+	return func(c *config) {
+		c.cacheMemory = max(bytes, 0)
+	}
+}
+
+// OptionMaxAge sets the Cache-Control max-age of responses (default: one hour); 0
+// makes clients revalidate every time, which the ETag keeps cheap
+func OptionMaxAge(d time.Duration) Option {
+	return func(c *config) {
+		c.maxAge = max(d, 0)
+	}
+}
+
+// OptionMaxFileSize rejects audio files larger than size bytes with 413 Request
+// Entity Too Large before they are read (default: no limit)
+func OptionMaxFileSize(size int64) Option {
+	return func(c *config) {
+		c.maxFileSize = max(size, 0)
+	}
+}
+
+// OptionMaxDuration rejects audio files longer than d with 413 Request Entity Too
+// Large (default: no limit). The duration is only known once a file is decoded, so
+// combine it with OptionMaxFileSize to bound the cost of decoding.
+func OptionMaxDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.maxDuration = max(d, 0)
+	}
+}
+
+// OptionMaxWidth sets the largest number of pixels of JSON and binary peaks (default:
+// DefaultMaxWidth), whether requested with width or implied by samples_per_pixel.
+// Image sizes are limited to MaxImageSize.
+func OptionMaxWidth(pixels int) Option {
+	return func(c *config) {
+		c.maxWidth = max(pixels, 1)
+	}
+}
+
+// OptionRateLimit limits each client IP address to perSecond requests per second on
+// average, with bursts of up to burst requests (default: no limit). Clients over the
+// limit get 429 Too Many Requests with a Retry-After header. Behind a reverse proxy,
+// set the RemoteAddr of requests to the client's address before they reach the handler.
+func OptionRateLimit(perSecond float64, burst int) Option {
+	return func(c *config) {
+		c.rateLimit, c.rateBurst = max(perSecond, 0), burst
+	}
+}
+
+// OptionSignedURLs refuses requests that are not signed with one of keys by SignURL,
+// or whose signature has expired, with 403 Forbidden, so a public deployment behind a
+// CDN only serves the files and views the application hands out links to. The
+// signature is checked before the file is looked up, so unsigned requests cannot tell
+// which files exist. To rotate keys, sign with the new key and list it along with
+// the old one until links signed with the old one have expired.
+func OptionSignedURLs(keys ...[]byte) Option {
+	return func(c *config) {
+		c.signingKeys = nil
+		for _, key := range keys {
+			if len(key) > 0 {
+				c.signingKeys = append(c.signingKeys, key)
+			}
+		}
+	}
+}
+
+// OptionContentHash derives ETags from a SHA-256 of the audio file instead of its size
+// and modification time (default: false), so servers with copies of the same files
+// but different modification times, such as replicas behind a CDN, send the same
+// ETags. Each version of a file is read once more to hash it, which with BlobFS is a
+// download the default avoids.
+func OptionContentHash(enabled bool) Option {
+	return func(c *config) {
+		c.contentHash = enabled
+	}
+}
+
+// OptionMetrics serves metrics in the Prometheus text format at /metrics (default:
+// false): requests by endpoint and status code, cache hits and misses, the files in the
+// cache and a histogram of decode durations. Like /healthz, which is always served, it
+// is exempt from the rate limit and signed URLs, so put it behind a route that only
+// the scraper can reach when the handler is public.
+func OptionMetrics(enabled bool) Option {
+	return func(c *config) {
+		c.metrics = enabled
+	}
+}
+
+// OptionLiveIdle sets how long a live stream waits for its file to grow before it
+// sends the final peaks and closes (default: DefaultLiveIdle)
+func OptionLiveIdle(d time.Duration) Option {
+	return func(c *config) {
+		c.liveIdle = max(d, time.Millisecond)
+	}
+}
+
+// OptionLoad adds options for loading the audio, e.g. gowaveform.OptionFFmpegFallback
+func OptionLoad(opts ...gowaveform.LoadOption) Option {
+	return func(c *config) {
+		c.loadOpts = append(c.loadOpts, opts...)
+	}
+}
+
+// OptionPlot adds plot options for the waveform images, such as colors or a title.
+// The view window, width and height come from the request.
+func OptionPlot(opts ...gowaveform.Option) Option {
+	return func(c *config) {
+		c.plotOpts = append(c.plotOpts, opts...)
+	}
+}
+
+// handler serves the peaks of the audio files in root
+type handler struct {
+	root    fs.FS
+	config  config
+	limiter *rateLimiter // nil without a rate limit
+	metrics *metrics     // nil without OptionMetrics
+
+	mu         sync.Mutex
+	cache      map[string]*list.Element // Cached waveforms by cache key, most recent first in lru
+	lru        *list.List
+	cacheBytes int64             // MemoryUsage of the cached waveforms
+	digests    map[string]string // SHA-256 of the files by cache key, with OptionContentHash
+
+	// Identical concurrent requests share one decode and one rendering
+	loads   group[*gowaveform.Waveform]
+	renders group[[]byte]
+	hashes  group[string]
+}
+
+// cacheEntry is a decoded audio file in the cache
+type cacheEntry struct {
+	key      string
+	waveform *gowaveform.Waveform
+	size     int64 // MemoryUsage when added
+}
+
+// view is the part of the audio and the output a request asks for
+type view struct {
+	start, end      float64
+	width, height   int
+	samplesPerPixel int
+	styling         []gowaveform.Option // Styling of images, on top of OptionPlot
+}
+
+// Handler returns an http.Handler that serves JSON peaks, binary peaks and PNG images
+// for the audio files in root. Mount it under a prefix with http.StripPrefix:
+//
+//	http.Handle("/waveforms/", http.StripPrefix("/waveforms", waveformhttp.Handler(os.DirFS("audio"))))
+func Handler(root fs.FS, opts ...Option) http.Handler {
+	c := config{
+		cacheSize: DefaultCacheSize,
+		maxAge:    DefaultMaxAge,
+		maxWidth:  DefaultMaxWidth,
+		liveIdle:  DefaultLiveIdle,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	h := &handler{
+		root:    root,
+		config:  c,
+		cache:   map[string]*list.Element{},
+		lru:     list.New(),
+		digests: map[string]string{},
+	}
+	if c.rateLimit > 0 {
+		h.limiter = newRateLimiter(c.rateLimit, c.rateBurst)
+	}
+	if c.metrics {
+		h.metrics = newMetrics()
+	}
+	return h
+}
+
+// ServeHTTP implements the http.Handler interface
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.metrics != nil {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			code := rec.code
+			if code == 0 {
+				code = http.StatusOK // Nothing written
+			}
+			h.metrics.request(endpoint(r.URL.Path), code)
+		}()
+		w = rec
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Health checks and scrapes are answered before the limits meant for clients
+	switch path.Clean("/" + r.URL.Path) {
+	case healthzPath:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		fmt.Fprintln(w, "ok")
+		return
+	case metricsPath:
+		if h.metrics == nil {
+			break
+		}
+		h.mu.Lock()
+		cached, cachedBytes := h.lru.Len(), h.cacheBytes
+		h.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		h.metrics.write(w, cached, cachedBytes)
+		return
+	}
+
+	if h.limiter != nil {
+		if ok, wait := h.limiter.allow(clientIP(r), time.Now()); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	// Signed URLs are checked first, so nothing is revealed without a valid one
+	query := r.URL.Query()
+	maxAge := h.config.maxAge
+	if len(h.config.signingKeys) > 0 {
+		expires, err := verifySignature(h.config.signingKeys, path.Clean("/"+r.URL.Path), query, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		// Caches must not serve the response past the expiry of the URL
+		maxAge = max(min(maxAge, time.Until(expires)), 0)
+		query.Del(SignatureParam)
+		query.Del(ExpiresParam)
+	}
+
+	// The last extension selects the output, the rest names the audio file
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	format := path.Ext(name)
+	name = strings.TrimSuffix(name, format)
+	contentType, ok := map[string]string{
+		".json":  "application/json",
+		".dat":   "application/octet-stream",
+		".png":   "image/png",
+		".stats": "application/json",
+		".live":  "", // A WebSocket
+	}[format]
+	if !ok || !fs.ValidPath(name) || path.Ext(name) == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	v, err := parseView(query, format, h.config.maxWidth)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reads from a BlobFS stop when the client goes away
+	root := h.root
+	if c, ok := root.(contextFS); ok {
+		root = c.withContext(r.Context())
+	}
+
+	info, err := fs.Stat(root, name)
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if h.config.maxFileSize > 0 && info.Size() > h.config.maxFileSize {
+		http.Error(w, fmt.Sprintf("%v: %d bytes, the limit is %d", errTooLarge, info.Size(), h.config.maxFileSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if format == ".live" {
+		h.serveLive(w, r, root, name, v)
+		return
+	}
+
+	// The ETag changes with the file and the request, so a replaced file is served
+	// fresh and an unchanged one is not rendered again for clients that have it. A
+	// signed URL renewed for the same view keeps its ETag.
+	version := fmt.Sprintf("%d\x00%d", info.Size(), info.ModTime().UnixNano())
+	if h.config.contentHash {
+		if version, err = h.digest(root, name, info); err != nil {
+			http.Error(w, errLoadAudio.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	id := fmt.Sprintf("%s\x00%s\x00%s\x00%s", name, version, format, query.Encode())
+	hash := fnv.New64a()
+	hash.Write([]byte(id))
+	etag := fmt.Sprintf(`"%x"`, hash.Sum64())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	if modTime := info.ModTime(); !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+	if notModified(r, etag, info.ModTime()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.generate(r.Context(), id, func() ([]byte, error) {
+		waveform, err := h.load(root, name, info)
+		if errors.Is(err, errTooLarge) {
+			return nil, err
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %w", errLoadAudio, err)
+		}
+		return h.render(waveform, v, format)
+	})
+	switch {
+	case errors.Is(err, errLoadAudio):
+		http.Error(w, errLoadAudio.Error(), http.StatusInternalServerError)
+		return
+	case errors.Is(err, errTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	case errors.Is(err, gowaveform.ErrInvalidWindow), errors.Is(err, errTooWide):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", info.ModTime(), bytes.NewReader(body))
+}
+
+// parseView reads the view from the query parameters of a request for format, with
+// widths of JSON and binary peaks limited to maxWidth
+func parseView(query url.Values, format string, maxWidth int) (view, error) {
+	v := view{}
+	floats := map[string]*float64{"start": &v.start, "end": &v.end}
+	ints := map[string]*int{"width": &v.width, "height": &v.height, "samples_per_pixel": &v.samplesPerPixel}
+	for key, values := range query {
+		value := values[len(values)-1]
+		var err error
+		switch {
+		case floats[key] != nil:
+			*floats[key], err = strconv.ParseFloat(value, 64)
+			if err == nil && (math.IsNaN(*floats[key]) || math.IsInf(*floats[key], 0)) {
+				err = strconv.ErrSyntax
+			}
+		case ints[key] != nil:
+			*ints[key], err = strconv.Atoi(value)
+		case key == "fg" || key == "bg":
+			switch {
+			case !isHexColor(value):
+				err = strconv.ErrSyntax
+			case key == "fg":
+				v.styling = append(v.styling, gowaveform.OptionSetForegroundColor(value))
+			default:
+				v.styling = append(v.styling, gowaveform.OptionSetBackgroundColor(value))
+			}
+		case key == "style":
+			var style gowaveform.WaveStyle
+			style, err = gowaveform.ParseWaveStyle(value)
+			v.styling = append(v.styling, gowaveform.OptionWaveStyle(style))
+		case key == "split":
+			var split bool
+			split, err = strconv.ParseBool(value)
+			v.styling = append(v.styling, gowaveform.OptionSplitChannels(split))
+		default:
+			continue // Ignore unknown parameters such as cache busters
+		}
+		if err != nil {
+			return v, fmt.Errorf("invalid %s: %q", key, value)
+		}
+	}
+
+	if format == ".png" {
+		maxWidth = MaxImageSize
+	}
+	switch {
+	case v.start < 0 || v.end < 0:
+		return v, fmt.Errorf("start and end must not be negative")
+	case v.end > 0 && v.end <= v.start:
+		return v, fmt.Errorf("end must be after start")
+	case v.width < 0 || v.width > maxWidth:
+		return v, fmt.Errorf("width must be between 0 and %d", maxWidth)
+	case v.height < 0 || v.height > MaxImageSize:
+		return v, fmt.Errorf("height must be between 0 and %d", MaxImageSize)
+	case v.samplesPerPixel < 0:
+		return v, fmt.Errorf("samples_per_pixel must not be negative")
+	}
+	return v, nil
+}
+
+// isHexColor reports whether s is a color of 3 or 6 hex digits, optionally after a #
+func isHexColor(s string) bool {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 3 && len(s) != 6 {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 16, 32)
+	return err == nil
+}
+
+// notModified reports whether the client already has the response with etag, for a
+// file last modified at modTime. If-Modified-Since only counts without If-None-Match.
+func notModified(r *http.Request, etag string, modTime time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return matchETag(header, etag)
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || modTime.IsZero() {
+		return false
+	}
+	return !modTime.Truncate(time.Second).After(since)
+}
+
+// matchETag reports whether an If-None-Match header lists the ETag
+func matchETag(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// generate returns the body rendered by fn, sharing it with concurrent requests for the
+// same id so a burst of them renders once
+func (h *handler) generate(ctx context.Context, id string, fn func() ([]byte, error)) ([]byte, error) {
+	for {
+		body, err, _ := h.renders.do(id, fn)
+		// Reads are bound to the request that started the work; when that client went
+		// away, the requests still waiting try again instead of failing with it
+		if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			continue
+		}
+		return body, err
+	}
+}
+
+// fileKey identifies a version of a file by its name, size and modification time
+func fileKey(name string, info fs.FileInfo) string {
+	return fmt.Sprintf("%s\x00%d\x00%d", name, info.Size(), info.ModTime().UnixNano())
+}
+
+// digest returns the hex SHA-256 of a file in root, hashing each version of the file
+// once
+func (h *handler) digest(root fs.FS, name string, info fs.FileInfo) (string, error) {
+	key := fileKey(name, info)
+	h.mu.Lock()
+	sum, ok := h.digests[key]
+	h.mu.Unlock()
+	if ok {
+		return sum, nil
+	}
+
+	sum, err, _ := h.hashes.do(key, func() (string, error) {
+		f, err := root.Open(name)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		hash := sha256.New()
+		if _, err := io.Copy(hash, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	})
+	if err != nil {
+		return "", err
+	}
+	h.mu.Lock()
+	if len(h.digests) >= maxDigests {
+		clear(h.digests)
+	}
+	h.digests[key] = sum
+	h.mu.Unlock()
+	return sum, nil
+}
+
+// load returns the decoded audio file in root, from the cache if it has not changed
+func (h *handler) load(root fs.FS, name string, info fs.FileInfo) (*gowaveform.Waveform, error) {
+	key := fileKey(name, info)
+
+	h.mu.Lock()
+	if e, ok := h.cache[key]; ok {
+		h.lru.MoveToFront(e)
+		h.mu.Unlock()
+		if h.metrics != nil {
+			h.metrics.cacheLookup(true)
+		}
+		return e.Value.(*cacheEntry).waveform, nil
+	}
+	h.mu.Unlock()
+	if h.metrics != nil {
+		h.metrics.cacheLookup(false)
+	}
+
+	// Decode without holding the lock so other files are served meanwhile, once for
+	// all the requests that need the file at the same time
+	waveform, err, _ := h.loads.do(key, func() (*gowaveform.Waveform, error) {
+		began := time.Now()
+		waveform, err := gowaveform.LoadWaveformFS(root, name, h.config.loadOpts...)
+		if h.metrics != nil {
+			h.metrics.decode(time.Since(began))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if limit := h.config.maxDuration; limit > 0 && waveform.Duration() > limit.Seconds() {
+			return nil, fmt.Errorf("%w: %.1fs long, the limit is %s", errTooLarge, waveform.Duration(), limit)
+		}
+		if h.config.cacheSize == 0 {
+			return waveform, nil
+		}
+
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if e, ok := h.cache[key]; ok {
+			return e.Value.(*cacheEntry).waveform, nil // Decoded by a concurrent request
+		}
+		entry := &cacheEntry{key: key, waveform: waveform, size: waveform.MemoryUsage()}
+		h.cache[key] = h.lru.PushFront(entry)
+		h.cacheBytes += entry.size
+		for h.lru.Len() > h.config.cacheSize || (h.config.cacheMemory > 0 && h.cacheBytes > h.config.cacheMemory && h.lru.Len() > 1) {
+			oldest := h.lru.Remove(h.lru.Back()).(*cacheEntry)
+			delete(h.cache, oldest.key)
+			h.cacheBytes -= oldest.size
+		}
+		return waveform, nil
+	})
+	return waveform, err
+}
+
+// render encodes the view of a waveform in the requested format
+func (h *handler) render(waveform *gowaveform.Waveform, v view, format string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if format == ".png" {
+		opts := append([]gowaveform.Option{}, h.config.plotOpts...)
+		if v.start > 0 {
+			opts = append(opts, gowaveform.OptionSetStart(v.start))
+		}
+		if v.end > 0 {
+			opts = append(opts, gowaveform.OptionSetEnd(v.end))
+		}
+		if v.width > 0 {
+			opts = append(opts, gowaveform.OptionSetWidth(v.width))
+		}
+		if v.height > 0 {
+			opts = append(opts, gowaveform.OptionSetHeight(v.height))
+		}
+		opts = append(opts, v.styling...)
+		img, err := gowaveform.RenderPlot(waveform, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if err := png.Encode(&buf, img.Image); err != nil {
+			return nil, fmt.Errorf("failed to encode PNG: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	if v.start >= waveform.Duration() {
+		return nil, fmt.Errorf("%w: start %g is after the end of the audio (%g)", gowaveform.ErrInvalidWindow, v.start, waveform.Duration())
+	}
+	if format == ".stats" {
+		stats, err := waveform.StatsRange(v.start, v.end)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.NewEncoder(&buf).Encode(stats); err != nil {
+			return nil, fmt.Errorf("failed to encode stats: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	if v.width == 0 {
+		// Without a width the pixels follow from samples_per_pixel, or GenerateView's
+		// default of 256, and are only known now
+		end := waveform.Duration()
+		if v.end > 0 {
+			end = min(v.end, end)
+		}
+		samplesPerPixel := v.samplesPerPixel
+		if samplesPerPixel == 0 {
+			samplesPerPixel = 256
+		}
+		pixels := math.Ceil((end - v.start) * float64(waveform.SampleRate) / float64(samplesPerPixel))
+		if pixels > float64(h.config.maxWidth) {
+			return nil, fmt.Errorf("%w: the view is %.0f pixels wide, the limit is %d; raise samples_per_pixel or set a width", errTooWide, pixels, h.config.maxWidth)
+		}
+	}
+	data, err := waveform.GenerateView(gowaveform.WaveformOptions{
+		Start:           v.start,
+		End:             v.end,
+		Width:           v.width,
+		SamplesPerPixel: v.samplesPerPixel,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer data.Release()
+	if format == ".dat" {
+		err = gowaveform.WriteBinary(&buf, data)
+	} else {
+		err = gowaveform.WriteJSON(&buf, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
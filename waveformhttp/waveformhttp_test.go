@@ -0,0 +1,331 @@
+package waveformhttp
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/schollz/gowaveform"
+)
+
+// testWAV returns one second of 16-bit mono audio with a quiet ramp, from the WAV
+// fixture shared with the other packages
+func testWAV() []byte {
+	data, err := os.ReadFile("../testdata/ramp.wav")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// testFS returns a file system with two WAV files, one in a subdirectory
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"song.wav":       {Data: testWAV()},
+		"stems/bass.wav": {Data: testWAV()},
+	}
+}
+
+// get requests target from h and returns the recorded response
+func get(h http.Handler, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandlerFormats(t *testing.T) {
+	h := Handler(testFS())
+
+	rec := get(h, "/song.wav.json?width=100", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for JSON, got %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Expected a JSON content type, got %q", ct)
+	}
+	data, err := gowaveform.ReadJSON(rec.Body)
+	if err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if data.Length != 100 {
+		t.Errorf("Expected 100 pixels, got %d", data.Length)
+	}
+
+	rec = get(h, "/stems/bass.wav.dat?samples_per_pixel=80&start=0.5", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for binary peaks, got %d: %s", rec.Code, rec.Body)
+	}
+	data, err = gowaveform.ReadBinary(rec.Body)
+	if err != nil {
+		t.Fatalf("ReadBinary failed: %v", err)
+	}
+	if data.SamplesPerPixel != 80 || data.Length != 50 {
+		t.Errorf("Expected 50 pixels of 80 samples, got %d of %d", data.Length, data.SamplesPerPixel)
+	}
+
+	rec = get(h, "/song.wav.png?width=200&height=50", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for PNG, got %d: %s", rec.Code, rec.Body)
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 50 {
+		t.Errorf("Expected a 200x50 image, got %dx%d", b.Dx(), b.Dy())
+	}
+
+	rec = get(h, "/song.wav.stats?start=0.25&end=0.75", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for stats, got %d: %s", rec.Code, rec.Body)
+	}
+	var stats gowaveform.RangeStats
+	if err := json.NewDecoder(rec.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode stats: %v", err)
+	}
+	if stats.Duration != 0.5 || stats.Peak != 100.0/32768 || len(stats.Levels) != 1 {
+		t.Errorf("Expected 0.5s of the ramp, got %+v", stats)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	h := Handler(testFS())
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/missing.wav.json", http.StatusNotFound},
+		{"/song.wav.txt", http.StatusNotFound},
+		{"/song.json", http.StatusNotFound},
+		{"/stems.json", http.StatusNotFound},
+		{"/../song.wav.json", http.StatusOK}, // Cleaned to /song.wav.json
+		{"/song.wav.json?width=abc", http.StatusBadRequest},
+		{"/song.wav.json?start=NaN", http.StatusBadRequest},
+		{"/song.wav.json?start=0.5&end=0.2", http.StatusBadRequest},
+		{"/song.wav.json?start=5", http.StatusBadRequest},
+		{"/song.wav.png?start=5", http.StatusBadRequest},
+		{"/song.wav.png?width=100000", http.StatusBadRequest},
+		{"/song.wav.png?fg=zzz", http.StatusBadRequest},
+		{"/song.wav.png?bg=%2312345", http.StatusBadRequest},
+		{"/song.wav.png?style=wavy", http.StatusBadRequest},
+		{"/song.wav.png?split=maybe", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := get(h, tt.target, nil); rec.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.target, tt.status, rec.Code)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/song.wav.json", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestHandlerImageStyle(t *testing.T) {
+	h := Handler(testFS(), OptionPlot(gowaveform.OptionSparkline(true)))
+
+	decode := func(target string) image.Image {
+		t.Helper()
+		rec := get(h, target, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", target, rec.Code, rec.Body)
+		}
+		img, err := png.Decode(rec.Body)
+		if err != nil {
+			t.Fatalf("%s: failed to decode PNG: %v", target, err)
+		}
+		return img
+	}
+
+	// The colors of the query replace the defaults; the quiet ramp is drawn at the center
+	img := decode("/song.wav.png?width=40&height=21&bg=%23000&fg=00FF00")
+	if b := img.Bounds(); b.Dx() != 40 || b.Dy() != 21 {
+		t.Errorf("Expected a 40x21 image, got %dx%d", b.Dx(), b.Dy())
+	}
+	if got := color.RGBAModel.Convert(img.At(5, 0)); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected a black background, got %v", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(5, 10)); got != (color.RGBA{0, 255, 0, 255}) {
+		t.Errorf("Expected a green waveform, got %v", got)
+	}
+
+	// Bars leave a gap every fourth column
+	img = decode("/song.wav.png?width=40&height=21&style=bars&fg=000")
+	if got := color.RGBAModel.Convert(img.At(3, 10)); got != (color.RGBA{255, 255, 255, 255}) {
+		t.Errorf("Expected a gap between bars, got %v", got)
+	}
+	if got := color.RGBAModel.Convert(img.At(4, 10)); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("Expected a bar, got %v", got)
+	}
+
+	// A mono file has a single lane
+	decode("/song.wav.png?width=40&height=21&split=true")
+}
+
+func TestHandlerCaching(t *testing.T) {
+	h := Handler(testFS(), OptionCacheSize(1), OptionMaxAge(0)).(*handler)
+
+	rec := get(h, "/song.wav.json", nil)
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Expected an ETag")
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=0" {
+		t.Errorf("Expected max-age=0, got %q", cc)
+	}
+
+	// Revalidation with the ETag skips rendering
+	rec = get(h, "/song.wav.json", http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for a matching ETag, got %d", rec.Code)
+	}
+	if other := get(h, "/song.wav.json?width=10", nil).Header().Get("ETag"); other == etag {
+		t.Error("Expected a different ETag for a different view")
+	}
+
+	// Only the most recently used file stays decoded
+	get(h, "/stems/bass.wav.json", nil)
+	if len(h.cache) != 1 || h.lru.Len() != 1 {
+		t.Fatalf("Expected one cached waveform, got %d", len(h.cache))
+	}
+	if entry := h.lru.Front().Value.(*cacheEntry); entry.waveform == nil || !strings.HasPrefix(entry.key, "stems/bass.wav\x00") {
+		t.Errorf("Expected the bass stem to be cached, got %q", entry.key)
+	}
+}
+
+func TestHandlerIfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fsys := testFS()
+	fsys["song.wav"].ModTime = modified
+	h := Handler(fsys)
+
+	rec := get(h, "/song.wav.png", nil)
+	if lm := rec.Header().Get("Last-Modified"); lm != modified.Format(http.TimeFormat) {
+		t.Fatalf("Expected Last-Modified %q, got %q", modified.Format(http.TimeFormat), lm)
+	}
+	for since, want := range map[time.Time]int{
+		modified:                 http.StatusNotModified,
+		modified.Add(time.Hour):  http.StatusNotModified,
+		modified.Add(-time.Hour): http.StatusOK,
+	} {
+		rec := get(h, "/song.wav.png", http.Header{"If-Modified-Since": {since.Format(http.TimeFormat)}})
+		if rec.Code != want {
+			t.Errorf("Expected %d for If-Modified-Since %s, got %d", want, since, rec.Code)
+		}
+	}
+
+	// If-None-Match takes precedence
+	rec = get(h, "/song.wav.png", http.Header{
+		"If-None-Match":     {`"other"`},
+		"If-Modified-Since": {modified.Format(http.TimeFormat)},
+	})
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for a stale ETag, got %d", rec.Code)
+	}
+
+	// Without a modification time there is nothing to compare
+	rec = get(Handler(testFS()), "/song.wav.png", http.Header{"If-Modified-Since": {modified.Format(http.TimeFormat)}})
+	if rec.Code != http.StatusOK || rec.Header().Get("Last-Modified") != "" {
+		t.Errorf("Expected 200 without Last-Modified, got %d and %q", rec.Code, rec.Header().Get("Last-Modified"))
+	}
+}
+
+func TestHandlerContentHash(t *testing.T) {
+	replica := func(modified time.Time) http.Handler {
+		fsys := testFS()
+		fsys["song.wav"].ModTime = modified
+		return Handler(fsys, OptionContentHash(true))
+	}
+	a := get(replica(time.Unix(1000, 0)), "/song.wav.json", nil).Header().Get("ETag")
+	b := get(replica(time.Unix(2000, 0)), "/song.wav.json", nil).Header().Get("ETag")
+	if a == "" || a != b {
+		t.Errorf("Expected the same ETag for the same contents, got %q and %q", a, b)
+	}
+
+	fsys := testFS()
+	fsys["song.wav"].Data = append(fsys["song.wav"].Data, 0, 0)
+	if c := get(Handler(fsys, OptionContentHash(true)), "/song.wav.json", nil).Header().Get("ETag"); c == a {
+		t.Error("Expected a different ETag for different contents")
+	}
+
+	// Without OptionContentHash, the modification time counts
+	stat := func(modified time.Time) string {
+		fsys := testFS()
+		fsys["song.wav"].ModTime = modified
+		return get(Handler(fsys), "/song.wav.json", nil).Header().Get("ETag")
+	}
+	if stat(time.Unix(1000, 0)) == stat(time.Unix(2000, 0)) {
+		t.Error("Expected ETags to differ with the modification time")
+	}
+}
+
+func TestHandlerCacheMemory(t *testing.T) {
+	// Each file holds 8000 16-bit samples
+	h := Handler(testFS(), OptionCacheMemory(20000)).(*handler)
+	get(h, "/song.wav.json", nil)
+	get(h, "/stems/bass.wav.json", nil)
+	if h.lru.Len() != 1 || h.cacheBytes != 16000 {
+		t.Fatalf("Expected one cached waveform of 16000 bytes, got %d holding %d bytes", h.lru.Len(), h.cacheBytes)
+	}
+	if entry := h.lru.Front().Value.(*cacheEntry); !strings.HasPrefix(entry.key, "stems/bass.wav\x00") {
+		t.Errorf("Expected the bass stem to be cached, got %q", entry.key)
+	}
+
+	// A file over the budget on its own is still cached
+	h = Handler(testFS(), OptionCacheMemory(1000)).(*handler)
+	get(h, "/song.wav.json", nil)
+	if h.lru.Len() != 1 {
+		t.Errorf("Expected the last file to stay cached, got %d", h.lru.Len())
+	}
+
+	h = Handler(testFS(), OptionCacheMemory(40000)).(*handler)
+	get(h, "/song.wav.json", nil)
+	get(h, "/stems/bass.wav.json", nil)
+	if h.lru.Len() != 2 || h.cacheBytes != 32000 {
+		t.Errorf("Expected both waveforms cached, got %d holding %d bytes", h.lru.Len(), h.cacheBytes)
+	}
+}
+
+func TestHandlerLimits(t *testing.T) {
+	size := int64(len(testWAV()))
+
+	tests := []struct {
+		name   string
+		opts   []Option
+		target string
+		status int
+	}{
+		{"file within the size limit", []Option{OptionMaxFileSize(size)}, "/song.wav.json", http.StatusOK},
+		{"file over the size limit", []Option{OptionMaxFileSize(size - 1)}, "/song.wav.json", http.StatusRequestEntityTooLarge},
+		{"file within the duration limit", []Option{OptionMaxDuration(time.Second)}, "/song.wav.png", http.StatusOK},
+		{"file over the duration limit", []Option{OptionMaxDuration(500 * time.Millisecond)}, "/song.wav.png", http.StatusRequestEntityTooLarge},
+		{"width over the limit", []Option{OptionMaxWidth(1000)}, "/song.wav.json?width=1001", http.StatusBadRequest},
+		{"samples per pixel over the limit", []Option{OptionMaxWidth(1000)}, "/song.wav.json?samples_per_pixel=1", http.StatusBadRequest},
+		{"samples per pixel in a short view", []Option{OptionMaxWidth(1000)}, "/song.wav.dat?samples_per_pixel=1&start=0.5&end=0.6", http.StatusOK},
+		{"default samples per pixel", []Option{OptionMaxWidth(31)}, "/song.wav.json", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Handler(testFS(), tt.opts...)
+			if rec := get(h, tt.target, nil); rec.Code != tt.status {
+				t.Errorf("Expected %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package waveformhttp
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// websocketGUID is appended to the client's key to compute the handshake answer
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// WebSocket opcodes used by the server
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// maxControlPayload is the largest payload of a control frame
+const maxControlPayload = 125
+
+// errNotWebSocket is returned for requests that are not a WebSocket handshake
+var errNotWebSocket = errors.New("websocket upgrade required")
+
+// wsConn is the server side of a WebSocket connection. It only sends text messages;
+// what the client sends is read to answer pings and notice when it closes.
+type wsConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+
+	mu sync.Mutex // Serializes writes
+}
+
+// isWebSocket reports whether a request asks to upgrade to a WebSocket
+func isWebSocket(r *http.Request) bool {
+	return headerHasToken(r.Header, "Connection", "upgrade") && headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerHasToken reports whether a comma-separated header lists token, ignoring case
+func headerHasToken(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// upgradeWebSocket completes the WebSocket handshake of a request and takes over its
+// connection. Without a valid handshake nothing is written and errNotWebSocket is
+// returned.
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !isWebSocket(r) || r.Method != http.MethodGet || key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, errNotWebSocket
+	}
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take over the connection: %w", err)
+	}
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &wsConn{conn: conn, rw: rw}, nil
+}
+
+// writeFrame sends a single unfragmented frame
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+	c.rw.Write(header)
+	c.rw.Write(payload)
+	return c.rw.Flush()
+}
+
+// writeText sends a text message
+func (c *wsConn) writeText(payload []byte) error {
+	return c.writeFrame(opText, payload)
+}
+
+// close sends a close frame with a status code and closes the connection
+func (c *wsConn) close(code uint16) error {
+	c.writeFrame(opClose, binary.BigEndian.AppendUint16(nil, code))
+	return c.conn.Close()
+}
+
+// readLoop reads the frames the client sends until it closes the connection or
+// breaks the protocol, answering pings. Data messages are discarded.
+func (c *wsConn) readLoop() error {
+	for {
+		var header [2]byte
+		if _, err := io.ReadFull(c.rw, header[:]); err != nil {
+			return err
+		}
+		opcode := header[0] & 0x0F
+		masked := header[1]&0x80 != 0
+		length := uint64(header[1] & 0x7F)
+		switch length {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			length = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			length = binary.BigEndian.Uint64(ext[:])
+		}
+		// Clients must mask their frames, and control frames are short
+		if !masked || (opcode >= opClose && length > maxControlPayload) {
+			return errors.New("invalid websocket frame")
+		}
+		var mask [4]byte
+		if _, err := io.ReadFull(c.rw, mask[:]); err != nil {
+			return err
+		}
+
+		if opcode < opClose {
+			if _, err := io.CopyN(io.Discard, c.rw, int64(length)); err != nil {
+				return err
+			}
+			continue
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.rw, payload); err != nil {
+			return err
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+		switch opcode {
+		case opClose:
+			return io.EOF
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return err
+			}
+		}
+	}
+}
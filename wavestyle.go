@@ -0,0 +1,185 @@
+package gowaveform
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strings"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+)
+
+// WaveStyle selects how the waveform itself is drawn
+type WaveStyle int
+
+const (
+	// WaveStyleFilled fills the area between the minimum and maximum of each column
+	WaveStyleFilled WaveStyle = iota
+	// WaveStyleBars draws separate vertical bars, as many web players do
+	WaveStyleBars
+)
+
+// Size of the bars of WaveStyleBars in pixels at DefaultDPI; they scale with the DPI
+const (
+	barWidth = 3
+	barGap   = 1
+)
+
+// String returns the name of the style as accepted by ParseWaveStyle
+func (s WaveStyle) String() string {
+	switch s {
+	case WaveStyleBars:
+		return "bars"
+	default:
+		return "filled"
+	}
+}
+
+// ParseWaveStyle parses a wave style name: filled or bars
+func ParseWaveStyle(name string) (WaveStyle, error) {
+	switch strings.ToLower(name) {
+	case "filled", "":
+		return WaveStyleFilled, nil
+	case "bars":
+		return WaveStyleBars, nil
+	default:
+		return WaveStyleFilled, fmt.Errorf("unknown wave style: %s (supported: filled, bars)", name)
+	}
+}
+
+// OptionWaveStyle sets how the waveform is drawn (default: WaveStyleFilled)
+func OptionWaveStyle(style WaveStyle) Option {
+	return func(c *PlotConfig) {
+		c.waveStyle = style
+	}
+}
+
+// OptionSplitChannels draws each channel in its own lane, top to bottom, instead of
+// the peaks of all channels together. Spectral colors and the RMS body are taken
+// across all channels and draw the channels together.
+func OptionSplitChannels(split bool) Option {
+	return func(c *PlotConfig) {
+		c.splitChannels = split
+	}
+}
+
+// splitView generates a view of each channel with the layout of opts and interleaves
+// them into one view with a min/max pair per channel per pixel, as audiowaveform
+// --split-channels does
+func (w *Waveform) splitView(opts WaveformOptions) (*WaveformData, error) {
+	views := make([]*WaveformData, w.Channels)
+	for ch := range views {
+		channel, err := w.Channel(ch)
+		if err != nil {
+			return nil, err
+		}
+		views[ch], err = channel.GenerateView(opts)
+		channel.releaseAudio()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	split := *views[0]
+	split.Channels = len(views)
+	split.Data = make([]int16, 0, 2*split.Length*len(views))
+	for i := 0; i < split.Length; i++ {
+		for _, view := range views {
+			split.Data = append(split.Data, view.Data[i*2], view.Data[i*2+1])
+		}
+	}
+	for _, view := range views {
+		view.Release()
+	}
+	return &split, nil
+}
+
+// lanePeaks returns the lowest minimum and highest maximum of the pairs from channel
+// first up to last in column col of a view with pairs min/max pairs per column
+func lanePeaks(data *WaveformData, col, pairs, first, last int) (int16, int16) {
+	lo, hi := int16(math.MaxInt16), int16(math.MinInt16)
+	for ch := first; ch < last; ch++ {
+		i := (col*pairs + ch) * 2
+		lo = min(lo, data.Data[i])
+		hi = max(hi, data.Data[i+1])
+	}
+	return lo, hi
+}
+
+// laneChannels returns the number of lanes a view is drawn in and the channels of
+// each lane: one lane with all channels unless split
+func laneChannels(data *WaveformData, split bool) (lanes int, channels func(lane int) (first, last int)) {
+	pairs := data.peakChannels()
+	if !split || pairs == 1 {
+		return 1, func(int) (int, int) { return 0, pairs }
+	}
+	return pairs, func(lane int) (int, int) { return lane, lane + 1 }
+}
+
+// waveShape draws the peaks of a view filled or as bars, in a lane per channel when
+// split; each lane maps the amplitude range -1 to 1 onto its share of the y-axis
+type waveShape struct {
+	data  *WaveformData
+	style WaveStyle
+	split bool
+	color color.Color
+	pixel vg.Length // Size of a pixel on the canvas
+}
+
+// Plot implements the plot.Plotter interface
+func (s *waveShape) Plot(c draw.Canvas, plt *plot.Plot) {
+	if s.data.Length == 0 {
+		return
+	}
+	trX, trY := plt.Transforms(&c)
+	pairs := s.data.peakChannels()
+	lanes, channels := laneChannels(s.data, s.split)
+	pixelDuration := float64(s.data.SamplesPerPixel) / float64(s.data.SampleRate)
+
+	for lane := 0; lane < lanes; lane++ {
+		first, last := channels(lane)
+		center, scale := 1-float64(2*lane+1)/float64(lanes), 1/float64(lanes)
+		y := func(peak int16) vg.Length {
+			return trY(center + float64(peak)/32768*scale)
+		}
+
+		if s.style == WaveStyleBars {
+			// Bars at a fixed pixel pitch, each covering the columns under it and its gap
+			x0, x1 := trX(s.data.Start), trX(s.data.Start+float64(s.data.Length)*pixelDuration)
+			step := (barWidth + barGap) * s.pixel
+			bars := max(1, int((x1-x0+barGap*s.pixel)/step))
+			for b := 0; b < bars; b++ {
+				firstCol := b * s.data.Length / bars
+				lastCol := max(firstCol+1, (b+1)*s.data.Length/bars)
+				lo, hi := int16(math.MaxInt16), int16(math.MinInt16)
+				for col := firstCol; col < lastCol; col++ {
+					l, h := lanePeaks(s.data, col, pairs, first, last)
+					lo, hi = min(lo, l), max(hi, h)
+				}
+				bottom, top := y(lo), y(hi)
+				if top-bottom < s.pixel {
+					mid := (top + bottom) / 2
+					bottom, top = mid-s.pixel/2, mid+s.pixel/2
+				}
+				left := x0 + vg.Length(b)*step
+				bar := []vg.Point{{X: left, Y: bottom}, {X: left + barWidth*s.pixel, Y: bottom}, {X: left + barWidth*s.pixel, Y: top}, {X: left, Y: top}}
+				c.FillPolygon(s.color, c.ClipPolygonXY(bar))
+			}
+			continue
+		}
+
+		// Along the maxima from left to right, then back along the minima
+		outline := make([]vg.Point, 0, 2*s.data.Length)
+		for col := 0; col < s.data.Length; col++ {
+			_, hi := lanePeaks(s.data, col, pairs, first, last)
+			outline = append(outline, vg.Point{X: trX(s.data.Start + float64(col)*pixelDuration), Y: y(hi)})
+		}
+		for col := s.data.Length - 1; col >= 0; col-- {
+			lo, _ := lanePeaks(s.data, col, pairs, first, last)
+			outline = append(outline, vg.Point{X: trX(s.data.Start + float64(col)*pixelDuration), Y: y(lo)})
+		}
+		c.FillPolygon(s.color, c.ClipPolygonXY(outline))
+	}
+}
@@ -0,0 +1,93 @@
+package gowaveform
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestParseWaveStyle(t *testing.T) {
+	for _, style := range []WaveStyle{WaveStyleFilled, WaveStyleBars} {
+		got, err := ParseWaveStyle(style.String())
+		if err != nil || got != style {
+			t.Errorf("ParseWaveStyle(%q) = %v, %v; expected %v", style.String(), got, err, style)
+		}
+	}
+	if style, err := ParseWaveStyle(""); err != nil || style != WaveStyleFilled {
+		t.Errorf("Expected an empty name to select filled, got %v, %v", style, err)
+	}
+	if _, err := ParseWaveStyle("wavy"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
+
+func TestFastRendererBars(t *testing.T) {
+	data := &WaveformData{Length: 8, Data: make([]int16, 16)}
+	for i := 0; i < data.Length; i++ {
+		data.Data[2*i], data.Data[2*i+1] = -16384, 16384
+	}
+	img, err := fastRenderer{}.DrawWaveform(data, Style{Width: 8, Height: 9, Foreground: color.Black, WaveStyle: WaveStyleBars})
+	if err != nil {
+		t.Fatalf("DrawWaveform failed: %v", err)
+	}
+
+	// Bars of three pixels with a one pixel gap, from 0.5 to -0.5 (rows 2 to 6)
+	rgba := img.(*image.RGBA)
+	for x := 0; x < 8; x++ {
+		for y := 0; y < 9; y++ {
+			inside := x%4 != 3 && y >= 2 && y <= 6
+			if drawn := rgba.RGBAAt(x, y).R == 0; drawn != inside {
+				t.Errorf("Pixel (%d, %d): expected drawn=%v, got %v", x, y, inside, drawn)
+			}
+		}
+	}
+}
+
+func TestSplitChannels(t *testing.T) {
+	// Full scale on the left channel, silence on the right
+	w := &Waveform{SampleRate: 8000, Channels: 2, BitsPerSample: 16, totalSamples: 800, audioData: make([]int16, 1600)}
+	for frame := 0; frame < 800; frame++ {
+		w.audioData[2*frame] = int16(30000 * (1 - 2*(frame%2)))
+	}
+
+	data, err := w.splitView(WaveformOptions{Width: 4})
+	if err != nil {
+		t.Fatalf("splitView failed: %v", err)
+	}
+	if data.Length != 4 || data.Channels != 2 || len(data.Data) != 16 {
+		t.Fatalf("Expected 4 pixels of 2 channels, got %d pixels, %d channels, %d values", data.Length, data.Channels, len(data.Data))
+	}
+	for i := 0; i < data.Length; i++ {
+		left, right := data.Data[4*i:4*i+2], data.Data[4*i+2:4*i+4]
+		if left[0] != -30000 || left[1] != 30000 || right[0] != 0 || right[1] != 0 {
+			t.Errorf("Pixel %d: expected left ±30000 and silent right, got %v %v", i, left, right)
+		}
+	}
+
+	// Left in the top lane, right in the bottom lane as a one pixel line
+	result, err := RenderPlot(w, OptionSparkline(true), OptionSetWidth(8), OptionSetHeight(10),
+		OptionSetForegroundColor("#000000"), OptionSplitChannels(true))
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+	img := result.Image
+	for y := 0; y < 10; y++ {
+		inside := y <= 4 || y == 7
+		r, _, _, _ := img.At(0, y).RGBA()
+		if drawn := r == 0; drawn != inside {
+			t.Errorf("Row %d: expected drawn=%v, got %v", y, inside, drawn)
+		}
+	}
+
+	// The full pipeline draws lanes and bars along with axes
+	for _, style := range []WaveStyle{WaveStyleFilled, WaveStyleBars} {
+		result, err := RenderPlot(w, OptionSetWidth(200), OptionSetHeight(100), OptionSetTitle("split"),
+			OptionWaveStyle(style), OptionSplitChannels(true))
+		if err != nil {
+			t.Fatalf("RenderPlot with %v failed: %v", style, err)
+		}
+		if b := result.Image.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+			t.Errorf("Expected a 200x100 image, got %dx%d", b.Dx(), b.Dy())
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// CuePoint is a marker stored in the cue chunk of a WAV file
+type CuePoint struct {
+	ID    uint32  `json:"id"`
+	Time  float64 `json:"time"`            // Position in seconds
+	Label string  `json:"label,omitempty"` // Text of the matching labl chunk
+}
+
+// LoopPoint is a sustain loop stored in the smpl chunk of a WAV file
+type LoopPoint struct {
+	ID        uint32  `json:"id"`
+	Start     float64 `json:"start"`           // Loop start in seconds
+	End       float64 `json:"end"`             // Loop end in seconds (the last frame played)
+	PlayCount uint32  `json:"play_count"`      // Times to play the loop (0 = forever)
+	Label     string  `json:"label,omitempty"` // Text of the labl chunk with the loop's ID
+	Type      uint32  `json:"type,omitempty"`  // 0 = forward, 1 = alternating, 2 = backward
+}
+
+// WAVMarkers holds the cue points and loops embedded in a WAV file
+type WAVMarkers struct {
+	Cues  []CuePoint  `json:"cues"`
+	Loops []LoopPoint `json:"loops"`
+}
+
+// riffChunk is a chunk of a RIFF file without its padding byte
+type riffChunk struct {
+	id   string
+	body []byte
+}
+
+// ReadWAVMarkers reads the cue points, their labels and the loops of a WAV file.
+// Times are converted to seconds with the sample rate of the fmt chunk.
+func ReadWAVMarkers(r io.Reader) (*WAVMarkers, error) {
+	chunks, err := readRIFFChunks(r)
+	if err != nil {
+		return nil, err
+	}
+	sampleRate, err := riffSampleRate(chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	markers := &WAVMarkers{Cues: []CuePoint{}, Loops: []LoopPoint{}}
+	labels := map[uint32]string{}
+	for _, c := range chunks {
+		switch {
+		case c.id == "cue ":
+			if len(c.body) < 4 {
+				return nil, fmt.Errorf("invalid cue chunk")
+			}
+			count := int(binary.LittleEndian.Uint32(c.body))
+			if len(c.body) < 4+count*24 {
+				return nil, fmt.Errorf("invalid cue chunk: %d points in %d bytes", count, len(c.body))
+			}
+			for i := 0; i < count; i++ {
+				p := c.body[4+i*24:]
+				markers.Cues = append(markers.Cues, CuePoint{
+					ID:   binary.LittleEndian.Uint32(p),
+					Time: float64(binary.LittleEndian.Uint32(p[20:])) / float64(sampleRate),
+				})
+			}
+		case c.id == "LIST" && len(c.body) >= 4 && string(c.body[:4]) == "adtl":
+			sub, err := splitRIFFChunks(c.body[4:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid adtl list: %w", err)
+			}
+			for _, s := range sub {
+				if (s.id == "labl" || s.id == "note") && len(s.body) >= 4 {
+					id := binary.LittleEndian.Uint32(s.body)
+					if _, ok := labels[id]; !ok || s.id == "labl" {
+						labels[id] = strings.TrimRight(string(s.body[4:]), "\x00")
+					}
+				}
+			}
+		case c.id == "smpl":
+			if len(c.body) < 36 {
+				return nil, fmt.Errorf("invalid smpl chunk")
+			}
+			count := int(binary.LittleEndian.Uint32(c.body[28:]))
+			if len(c.body) < 36+count*24 {
+				return nil, fmt.Errorf("invalid smpl chunk: %d loops in %d bytes", count, len(c.body))
+			}
+			for i := 0; i < count; i++ {
+				p := c.body[36+i*24:]
+				markers.Loops = append(markers.Loops, LoopPoint{
+					ID:        binary.LittleEndian.Uint32(p),
+					Type:      binary.LittleEndian.Uint32(p[4:]),
+					Start:     float64(binary.LittleEndian.Uint32(p[8:])) / float64(sampleRate),
+					End:       float64(binary.LittleEndian.Uint32(p[12:])) / float64(sampleRate),
+					PlayCount: binary.LittleEndian.Uint32(p[20:]),
+				})
+			}
+		}
+	}
+
+	for i := range markers.Cues {
+		markers.Cues[i].Label = labels[markers.Cues[i].ID]
+	}
+	for i := range markers.Loops {
+		markers.Loops[i].Label = labels[markers.Loops[i].ID]
+	}
+	sort.SliceStable(markers.Cues, func(i, j int) bool { return markers.Cues[i].Time < markers.Cues[j].Time })
+
+	return markers, nil
+}
+
+// WriteWAVMarkers copies the WAV file read from r to w with its cue points, labels and
+// loops replaced by markers. Other chunks, including the audio, are copied unchanged.
+// Cue points and loops with an ID of 0 are numbered after the highest ID in use.
+func WriteWAVMarkers(w io.Writer, r io.Reader, markers *WAVMarkers) error {
+	chunks, err := readRIFFChunks(r)
+	if err != nil {
+		return err
+	}
+	sampleRate, err := riffSampleRate(chunks)
+	if err != nil {
+		return err
+	}
+
+	cues, loops, err := assignMarkerIDs(markers)
+	if err != nil {
+		return err
+	}
+
+	var out []riffChunk
+	var smplHeader []byte
+	for _, c := range chunks {
+		switch {
+		case c.id == "cue ":
+		case c.id == "LIST" && len(c.body) >= 4 && string(c.body[:4]) == "adtl":
+		case c.id == "smpl":
+			if len(c.body) >= 36 {
+				smplHeader = c.body[:28]
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	toFrame := func(t float64) uint32 {
+		return uint32(math.Max(0, math.Round(t*float64(sampleRate))))
+	}
+
+	if len(cues) > 0 {
+		body := binary.LittleEndian.AppendUint32(nil, uint32(len(cues)))
+		for _, cue := range cues {
+			frame := toFrame(cue.Time)
+			body = binary.LittleEndian.AppendUint32(body, cue.ID)
+			body = binary.LittleEndian.AppendUint32(body, frame)
+			body = append(body, "data"...)
+			body = binary.LittleEndian.AppendUint32(body, 0)
+			body = binary.LittleEndian.AppendUint32(body, 0)
+			body = binary.LittleEndian.AppendUint32(body, frame)
+		}
+		out = append(out, riffChunk{id: "cue ", body: body})
+	}
+
+	var adtl bytes.Buffer
+	adtl.WriteString("adtl")
+	addLabel := func(id uint32, label string) {
+		if label == "" {
+			return
+		}
+		body := binary.LittleEndian.AppendUint32(nil, id)
+		body = append(body, label...)
+		body = append(body, 0)
+		writeRIFFChunk(&adtl, riffChunk{id: "labl", body: body})
+	}
+	for _, cue := range cues {
+		addLabel(cue.ID, cue.Label)
+	}
+	for _, loop := range loops {
+		addLabel(loop.ID, loop.Label)
+	}
+	if adtl.Len() > 4 {
+		out = append(out, riffChunk{id: "LIST", body: adtl.Bytes()})
+	}
+
+	if len(loops) > 0 {
+		body := smplHeader
+		if body == nil {
+			body = make([]byte, 28)
+			binary.LittleEndian.PutUint32(body[8:], uint32(1e9/float64(sampleRate))) // Sample period in ns
+			binary.LittleEndian.PutUint32(body[12:], 60)                             // MIDI unity note (middle C)
+		}
+		body = append(append([]byte{}, body...), make([]byte, 8)...)
+		binary.LittleEndian.PutUint32(body[28:], uint32(len(loops)))
+		for _, loop := range loops {
+			body = binary.LittleEndian.AppendUint32(body, loop.ID)
+			body = binary.LittleEndian.AppendUint32(body, loop.Type)
+			body = binary.LittleEndian.AppendUint32(body, toFrame(loop.Start))
+			body = binary.LittleEndian.AppendUint32(body, toFrame(loop.End))
+			body = binary.LittleEndian.AppendUint32(body, 0)
+			body = binary.LittleEndian.AppendUint32(body, loop.PlayCount)
+		}
+		out = append(out, riffChunk{id: "smpl", body: body})
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("WAVE")
+	for _, c := range out {
+		writeRIFFChunk(&buf, c)
+	}
+	if _, err := io.WriteString(w, "RIFF"); err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(buf.Len())); err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write WAV file: %w", err)
+	}
+	return nil
+}
+
+// WriteAudacityLabels writes the markers as an Audacity label track: one tab-separated
+// line of start, end and label per marker. Cue points become point labels and loops
+// become region labels.
+func (m *WAVMarkers) WriteAudacityLabels(w io.Writer) error {
+	type label struct {
+		start, end float64
+		text       string
+	}
+	labels := make([]label, 0, len(m.Cues)+len(m.Loops))
+	for _, cue := range m.Cues {
+		labels = append(labels, label{cue.Time, cue.Time, cue.Label})
+	}
+	for _, loop := range m.Loops {
+		text := loop.Label
+		if text == "" {
+			text = fmt.Sprintf("loop %d", loop.ID)
+		}
+		labels = append(labels, label{loop.Start, loop.End, text})
+	}
+	sort.SliceStable(labels, func(i, j int) bool { return labels[i].start < labels[j].start })
+
+	for _, l := range labels {
+		if _, err := fmt.Fprintf(w, "%.6f\t%.6f\t%s\n", l.start, l.end, l.text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignMarkerIDs numbers cue points and loops without an ID and rejects duplicate IDs
+func assignMarkerIDs(markers *WAVMarkers) ([]CuePoint, []LoopPoint, error) {
+	cues := append([]CuePoint{}, markers.Cues...)
+	loops := append([]LoopPoint{}, markers.Loops...)
+
+	used := map[uint32]bool{}
+	var next uint32
+	claim := func(id uint32) error {
+		if used[id] {
+			return fmt.Errorf("duplicate marker ID: %d", id)
+		}
+		used[id] = true
+		next = max(next, id)
+		return nil
+	}
+	for _, c := range cues {
+		if c.ID != 0 {
+			if err := claim(c.ID); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	for _, l := range loops {
+		if l.ID != 0 {
+			if err := claim(l.ID); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for i := range cues {
+		if cues[i].ID == 0 {
+			next++
+			cues[i].ID = next
+		}
+	}
+	for i := range loops {
+		if loops[i].ID == 0 {
+			next++
+			loops[i].ID = next
+		}
+		if loops[i].End < loops[i].Start {
+			return nil, nil, fmt.Errorf("loop %d ends before it starts", loops[i].ID)
+		}
+	}
+
+	return cues, loops, nil
+}
+
+// readRIFFChunks reads a RIFF WAVE file and splits it into its top-level chunks
+func readRIFFChunks(r io.Reader) ([]riffChunk, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV file: %w", err)
+	}
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a RIFF WAVE file")
+	}
+	return splitRIFFChunks(data[12:])
+}
+
+// splitRIFFChunks splits consecutive chunks, skipping the padding byte after odd sizes.
+// A truncated last chunk keeps the bytes that are present, as many encoders write
+// a wrong size for the data chunk.
+func splitRIFFChunks(data []byte) ([]riffChunk, error) {
+	var chunks []riffChunk
+	for len(data) >= 8 {
+		id := string(data[:4])
+		size := int(binary.LittleEndian.Uint32(data[4:8]))
+		data = data[8:]
+		if size > len(data) {
+			size = len(data)
+		}
+		chunks = append(chunks, riffChunk{id: id, body: data[:size]})
+		data = data[size:]
+		if size%2 == 1 && len(data) > 0 {
+			data = data[1:]
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no chunks found")
+	}
+	return chunks, nil
+}
+
+// writeRIFFChunk writes a chunk header and body, padded to an even length
+func writeRIFFChunk(buf *bytes.Buffer, c riffChunk) {
+	buf.WriteString(c.id)
+	binary.Write(buf, binary.LittleEndian, uint32(len(c.body)))
+	buf.Write(c.body)
+	if len(c.body)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+// riffSampleRate returns the sample rate from the fmt chunk
+func riffSampleRate(chunks []riffChunk) (int, error) {
+	for _, c := range chunks {
+		if c.id == "fmt " && len(c.body) >= 8 {
+			if rate := binary.LittleEndian.Uint32(c.body[4:8]); rate > 0 {
+				return int(rate), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("missing or invalid fmt chunk")
+}
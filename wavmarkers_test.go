@@ -0,0 +1,122 @@
+package gowaveform
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWAVMarkersRoundTrip(t *testing.T) {
+	tmpFile := "/tmp/test_wavmarkers.wav"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 44100, 1, make([]int16, 44100))
+
+	original, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	markers, err := ReadWAVMarkers(bytes.NewReader(original))
+	if err != nil {
+		t.Fatalf("ReadWAVMarkers failed: %v", err)
+	}
+	if len(markers.Cues) != 0 || len(markers.Loops) != 0 {
+		t.Errorf("Expected no markers in a plain WAV file, got %+v", markers)
+	}
+
+	var out bytes.Buffer
+	err = WriteWAVMarkers(&out, bytes.NewReader(original), &WAVMarkers{
+		Cues: []CuePoint{
+			{Time: 0.75, Label: "chorus"},
+			{ID: 7, Time: 0.25, Label: "verse"},
+			{Time: 0.5},
+		},
+		Loops: []LoopPoint{{Start: 0.1, End: 0.2, Label: "sustain"}},
+	})
+	if err != nil {
+		t.Fatalf("WriteWAVMarkers failed: %v", err)
+	}
+
+	// The RIFF size covers the whole file and the audio is unchanged
+	written := out.Bytes()
+	if size := binary.LittleEndian.Uint32(written[4:8]); int(size) != len(written)-8 {
+		t.Errorf("Expected a RIFF size of %d, got %d", len(written)-8, size)
+	}
+	if !bytes.Contains(written, original[36:]) {
+		t.Error("Expected the data chunk to be copied unchanged")
+	}
+
+	markers, err = ReadWAVMarkers(bytes.NewReader(written))
+	if err != nil {
+		t.Fatalf("ReadWAVMarkers failed: %v", err)
+	}
+	if len(markers.Cues) != 3 {
+		t.Fatalf("Expected 3 cue points, got %d", len(markers.Cues))
+	}
+	expected := []CuePoint{{ID: 7, Time: 0.25, Label: "verse"}, {ID: 9, Time: 0.5}, {ID: 8, Time: 0.75, Label: "chorus"}}
+	for i, cue := range markers.Cues {
+		if cue.ID != expected[i].ID || cue.Label != expected[i].Label || math.Abs(cue.Time-expected[i].Time) > 1e-6 {
+			t.Errorf("Cue %d: expected %+v, got %+v", i, expected[i], cue)
+		}
+	}
+	if len(markers.Loops) != 1 {
+		t.Fatalf("Expected 1 loop, got %d", len(markers.Loops))
+	}
+	loop := markers.Loops[0]
+	if loop.ID != 10 || loop.Label != "sustain" || math.Abs(loop.Start-0.1) > 1e-6 || math.Abs(loop.End-0.2) > 1e-6 {
+		t.Errorf("Unexpected loop: %+v", loop)
+	}
+
+	// Writing again replaces the markers instead of adding to them
+	var again bytes.Buffer
+	if err := WriteWAVMarkers(&again, bytes.NewReader(written), &WAVMarkers{Cues: []CuePoint{{Time: 0.5}}}); err != nil {
+		t.Fatalf("WriteWAVMarkers failed: %v", err)
+	}
+	markers, err = ReadWAVMarkers(&again)
+	if err != nil {
+		t.Fatalf("ReadWAVMarkers failed: %v", err)
+	}
+	if len(markers.Cues) != 1 || len(markers.Loops) != 0 {
+		t.Errorf("Expected only the new cue point, got %+v", markers)
+	}
+}
+
+func TestWriteWAVMarkersDuplicateID(t *testing.T) {
+	tmpFile := "/tmp/test_wavmarkers_duplicate.wav"
+	defer os.Remove(tmpFile)
+	writeTestWAV(t, tmpFile, 44100, 1, make([]int16, 100))
+
+	f, err := os.Open(tmpFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	markers := &WAVMarkers{Cues: []CuePoint{{ID: 1}, {ID: 1, Time: 0.001}}}
+	if err := WriteWAVMarkers(&bytes.Buffer{}, f, markers); err == nil {
+		t.Error("Expected an error for duplicate IDs")
+	}
+}
+
+func TestWriteAudacityLabels(t *testing.T) {
+	markers := &WAVMarkers{
+		Cues:  []CuePoint{{ID: 1, Time: 1.5, Label: "drop"}},
+		Loops: []LoopPoint{{ID: 2, Start: 0.5, End: 1}},
+	}
+
+	var buf bytes.Buffer
+	if err := markers.WriteAudacityLabels(&buf); err != nil {
+		t.Fatalf("WriteAudacityLabels failed: %v", err)
+	}
+	expected := "0.500000\t1.000000\tloop 2\n1.500000\t1.500000\tdrop\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	if _, err := ReadWAVMarkers(strings.NewReader("not a wav file")); err == nil {
+		t.Error("Expected an error for a file that is not a WAV file")
+	}
+}
@@ -0,0 +1,152 @@
+package gowaveform
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Weighting is a frequency weighting for sound level measurements as defined by
+// IEC 61672-1
+type Weighting int
+
+const (
+	// WeightingZ measures the level unweighted (flat)
+	WeightingZ Weighting = iota
+	// WeightingA follows the sensitivity of hearing at moderate levels, rolling off
+	// the lows steeply; the usual weighting for environmental and workplace noise
+	WeightingA
+	// WeightingC is flat down to about 30 Hz, for loud and low-frequency noise such
+	// as music venues
+	WeightingC
+)
+
+// DefaultLevelWindow is the window of the level overlay in milliseconds, as for the
+// "fast" time weighting of sound level meters
+const DefaultLevelWindow = 125
+
+// Pole frequencies in Hz of the A- and C-weighting curves of IEC 61672-1
+const (
+	weightingPole1 = 20.598997
+	weightingPole2 = 107.65265
+	weightingPole3 = 737.86223
+	weightingPole4 = 12194.217
+)
+
+// String returns the letter of the weighting
+func (wt Weighting) String() string {
+	switch wt {
+	case WeightingA:
+		return "A"
+	case WeightingC:
+		return "C"
+	default:
+		return "Z"
+	}
+}
+
+// weightingFilters returns the stages of a weighting filter for a sample rate, with
+// a gain of 0 dB at 1 kHz. The analog curve is mapped with the bilinear transform,
+// its poles prewarped so they keep their frequencies; a pole above the Nyquist
+// frequency is left out. Up to 4 kHz the curve is within 0.5 dB of IEC 61672-1, and
+// within its class 1 tolerances above.
+func weightingFilters(weighting Weighting, sampleRate int) []*biquad {
+	fs := float64(sampleRate)
+	k := 2 * fs
+	prewarp := func(freq float64) float64 {
+		return k * math.Tan(math.Pi*freq/fs)
+	}
+
+	// Analog sections (b2 s² + b1 s + b0) / (a2 s² + a1 s + a0)
+	bilinear := func(b2, b1, b0, a2, a1, a0 float64) *biquad {
+		return newBiquad(
+			b2*k*k+b1*k+b0, 2*(b0-b2*k*k), b2*k*k-b1*k+b0,
+			a2*k*k+a1*k+a0, 2*(a0-a2*k*k), a2*k*k-a1*k+a0,
+		)
+	}
+
+	var stages []*biquad
+	switch weighting {
+	case WeightingA, WeightingC:
+		w1 := prewarp(weightingPole1)
+		stages = append(stages, bilinear(1, 0, 0, 1, 2*w1, w1*w1))
+		if weighting == WeightingA {
+			w2, w3 := prewarp(weightingPole2), prewarp(weightingPole3)
+			stages = append(stages, bilinear(1, 0, 0, 1, w2+w3, w2*w3))
+		}
+		if weightingPole4 < fs/2 {
+			w4 := prewarp(weightingPole4)
+			stages = append(stages, bilinear(0, 0, w4*w4, 1, 2*w4, w4*w4))
+		}
+	default:
+		return nil
+	}
+
+	// Normalize to 0 dB at 1 kHz
+	gain := 1.0
+	for _, stage := range stages {
+		gain *= stage.response(1000, sampleRate)
+	}
+	stages[0].b0 /= gain
+	stages[0].b1 /= gain
+	stages[0].b2 /= gain
+	return stages
+}
+
+// response returns the magnitude of the filter's frequency response at freq (Hz)
+func (f *biquad) response(freq float64, sampleRate int) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/float64(sampleRate)))
+	num := complex(f.b0, 0) + complex(f.b1, 0)*z + complex(f.b2, 0)*z*z
+	den := 1 + complex(f.a1, 0)*z + complex(f.a2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+// LevelTimeline returns the weighted short-term level of the audio in consecutive
+// windows of windowMs milliseconds, in dBFS (a full-scale square wave is 0 dB, a
+// full-scale sine at 1 kHz about -3 dB), e.g. to monitor noise over a venue or
+// environmental recording. All channels contribute to each window; the last window
+// may be shorter. Add a calibration offset to read dB SPL. It returns nil for a
+// window that is not positive or an unknown weighting.
+func LevelTimeline(w *Waveform, weighting Weighting, windowMs int) []float64 {
+	return w.levelTimeline(0, w.availableFrames(), weighting, windowMs)
+}
+
+// levelTimeline computes the weighted level in windows of windowMs starting at
+// startFrame; the filters are warmed up on the audio before startFrame
+func (w *Waveform) levelTimeline(startFrame, endFrame int, weighting Weighting, windowMs int) []float64 {
+	if w.Channels == 0 || windowMs <= 0 || startFrame >= endFrame || weighting < WeightingZ || weighting > WeightingC {
+		return nil
+	}
+	window := max(1, windowMs*w.SampleRate/1000)
+
+	// Each channel needs its own filter state
+	filters := make([][]*biquad, w.Channels)
+	for ch := range filters {
+		filters[ch] = weightingFilters(weighting, w.SampleRate)
+	}
+	sample := func(frame, ch int) float64 {
+		y := float64(w.audioData[frame*w.Channels+ch]) / 32768.0
+		for _, stage := range filters[ch] {
+			y = stage.process(y)
+		}
+		return y
+	}
+	for frame := max(startFrame-filterPreroll, 0); frame < startFrame; frame++ {
+		for ch := range filters {
+			sample(frame, ch)
+		}
+	}
+
+	levels := make([]float64, 0, (endFrame-startFrame+window-1)/window)
+	for start := startFrame; start < endFrame; start += window {
+		end := min(start+window, endFrame)
+		var energy float64
+		for frame := start; frame < end; frame++ {
+			for ch := range filters {
+				y := sample(frame, ch)
+				energy += y * y
+			}
+		}
+		levels = append(levels, ToDBFS(math.Sqrt(energy/float64((end-start)*w.Channels))))
+	}
+	return levels
+}
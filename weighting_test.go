@@ -0,0 +1,87 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+// toneWaveform returns an in-memory waveform of a sine tone on every channel
+func toneWaveform(sampleRate, channels int, frequency, amplitude, seconds float64) *Waveform {
+	samples := sineSamples(sampleRate, channels, frequency, amplitude, seconds)
+	return &Waveform{SampleRate: sampleRate, Channels: channels, BitsPerSample: 16, audioData: samples, totalSamples: len(samples) / channels}
+}
+
+func TestWeightingCurves(t *testing.T) {
+	// Relative response in dB from the tables of IEC 61672-1, and the tolerance; near
+	// the Nyquist frequency the digital curve falls off less steeply
+	tests := []struct {
+		frequency float64
+		a, c      float64
+		tolerance float64
+	}{
+		{31.5, -39.4, -3.0, 0.5},
+		{63, -26.2, -0.8, 0.5},
+		{125, -16.1, -0.2, 0.5},
+		{250, -8.6, 0.0, 0.5},
+		{500, -3.2, 0.0, 0.5},
+		{1000, 0, 0, 0.5},
+		{2000, 1.2, -0.2, 0.5},
+		{4000, 1.0, -0.8, 0.5},
+		{8000, -1.1, -3.0, 1.0},
+	}
+	const rate = 48000
+	level := func(frequency float64, weighting Weighting) float64 {
+		t.Helper()
+		levels := LevelTimeline(toneWaveform(rate, 1, frequency, 0.5, 1), weighting, 1000)
+		if len(levels) != 1 {
+			t.Fatalf("Expected 1 window, got %d", len(levels))
+		}
+		return levels[0]
+	}
+
+	for _, tt := range tests {
+		flat := level(tt.frequency, WeightingZ)
+		if math.Abs(flat-ToDBFS(0.5/math.Sqrt2)) > 0.1 {
+			t.Errorf("%.1f Hz: expected the unweighted level of the sine, got %.2f dBFS", tt.frequency, flat)
+		}
+		if got := level(tt.frequency, WeightingA) - flat; math.Abs(got-tt.a) > tt.tolerance {
+			t.Errorf("%.1f Hz: expected A-weighting of %.1f dB, got %.2f dB", tt.frequency, tt.a, got)
+		}
+		if got := level(tt.frequency, WeightingC) - flat; math.Abs(got-tt.c) > tt.tolerance {
+			t.Errorf("%.1f Hz: expected C-weighting of %.1f dB, got %.2f dB", tt.frequency, tt.c, got)
+		}
+	}
+
+	// Below the highest pole the curve still holds at a low sample rate
+	low := LevelTimeline(toneWaveform(16000, 1, 100, 0.5, 1), WeightingA, 1000)
+	if got := low[0] - ToDBFS(0.5/math.Sqrt2); math.Abs(got+19.1) > 0.5 {
+		t.Errorf("Expected A-weighting of -19.1 dB at 100 Hz and 16 kHz, got %.2f dB", got)
+	}
+}
+
+func TestLevelTimeline(t *testing.T) {
+	// 1s of a 1 kHz tone at half scale followed by 0.5s of silence, in stereo
+	w := toneWaveform(44100, 2, 1000, 0.5, 1)
+	w.audioData = append(w.audioData, make([]int16, 44100)...)
+	w.totalSamples += 22050
+
+	levels := LevelTimeline(w, WeightingA, 250)
+	if len(levels) != 6 {
+		t.Fatalf("Expected 6 windows, got %d", len(levels))
+	}
+	for i, l := range levels[:4] {
+		if math.Abs(l-ToDBFS(0.5/math.Sqrt2)) > 0.2 {
+			t.Errorf("Window %d: expected about -9 dBFS, got %.2f", i, l)
+		}
+	}
+	if levels[5] > -100 {
+		t.Errorf("Expected a silent window far below the tone, got %.2f", levels[5])
+	}
+
+	if LevelTimeline(w, WeightingA, 0) != nil || LevelTimeline(w, Weighting(7), 100) != nil {
+		t.Error("Expected nil for a zero window and an unknown weighting")
+	}
+	if WeightingA.String() != "A" || WeightingC.String() != "C" || WeightingZ.String() != "Z" {
+		t.Error("Unexpected weighting names")
+	}
+}
@@ -0,0 +1,114 @@
+package gowaveform
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidWindow is returned, wrapped with the reason, when the view window options
+// of a plot contradict each other or do not fit the audio
+var ErrInvalidWindow = errors.New("invalid view window")
+
+// window is the view range requested by OptionSetStart, OptionSetEnd, OptionSetZoom
+// and OptionSetCenter. Each part is recorded separately and resolved against the
+// duration of the audio once all options are applied, so their order does not matter.
+type window struct {
+	start, end, duration, center             float64
+	hasStart, hasEnd, hasDuration, hasCenter bool
+}
+
+// OptionSetStart sets the start time in seconds for the waveform view. See
+// OptionSetZoom for how it combines with the other view window options.
+func OptionSetStart(start float64) Option {
+	return func(c *PlotConfig) {
+		c.window.start, c.window.hasStart = start, true
+	}
+}
+
+// OptionSetEnd sets the end time in seconds for the waveform view; 0 means the end of
+// the audio. See OptionSetZoom for how it combines with the other view window options.
+func OptionSetEnd(end float64) Option {
+	return func(c *PlotConfig) {
+		c.window.end, c.window.hasEnd = end, end != 0
+	}
+}
+
+// OptionSetZoom sets the duration in seconds to display. Together with at most one
+// other view window option it gives the range, whatever the order of the options:
+//
+//   - start and end: from start to end
+//   - a duration with a start or an end: the duration from the start or up to the end
+//   - a duration with a center: the duration around the center
+//   - a center with a start or an end: the range mirrored around the center
+//   - a duration alone: the duration around the middle of the audio
+//   - a start or an end alone: from the start to the end of the audio, or from the
+//     beginning up to the end
+//
+// Setting three or more of them, negative times, a duration that is not positive or a
+// range that ends before it starts or starts after the audio ends makes plotting fail
+// with ErrInvalidWindow. A range running past either end of the audio is cut off there.
+func OptionSetZoom(duration float64) Option {
+	return func(c *PlotConfig) {
+		c.window.duration, c.window.hasDuration = duration, true
+	}
+}
+
+// OptionSetCenter sets the time in seconds at the center of the view. It needs a
+// duration (OptionSetZoom), a start or an end to give the range; see OptionSetZoom.
+func OptionSetCenter(center float64) Option {
+	return func(c *PlotConfig) {
+		c.window.center, c.window.hasCenter = center, true
+	}
+}
+
+// resolve returns the range in seconds the window covers in audio of totalDuration
+// seconds, following the precedence documented on OptionSetZoom
+func (w window) resolve(totalDuration float64) (start, end float64, err error) {
+	set := 0
+	for _, has := range []bool{w.hasStart, w.hasEnd, w.hasDuration, w.hasCenter} {
+		if has {
+			set++
+		}
+	}
+	switch {
+	case set > 2:
+		return 0, 0, fmt.Errorf("%w: set at most two of start, end, zoom and center", ErrInvalidWindow)
+	case w.hasStart && w.start < 0, w.hasEnd && w.end < 0, w.hasCenter && w.center < 0:
+		return 0, 0, fmt.Errorf("%w: times must not be negative", ErrInvalidWindow)
+	case w.hasDuration && w.duration <= 0:
+		return 0, 0, fmt.Errorf("%w: zoom duration must be positive, got %g", ErrInvalidWindow, w.duration)
+	}
+
+	start, end = 0, totalDuration
+	switch {
+	case w.hasStart && w.hasEnd:
+		start, end = w.start, w.end
+	case w.hasDuration && w.hasStart:
+		start, end = w.start, w.start+w.duration
+	case w.hasDuration && w.hasEnd:
+		start, end = w.end-w.duration, w.end
+	case w.hasDuration && w.hasCenter:
+		start, end = w.center-w.duration/2, w.center+w.duration/2
+	case w.hasCenter && w.hasStart:
+		start, end = w.start, 2*w.center-w.start
+	case w.hasCenter && w.hasEnd:
+		start, end = 2*w.center-w.end, w.end
+	case w.hasDuration:
+		start, end = totalDuration/2-w.duration/2, totalDuration/2+w.duration/2
+	case w.hasCenter:
+		return 0, 0, fmt.Errorf("%w: a center needs a zoom duration, start or end", ErrInvalidWindow)
+	case w.hasStart:
+		start = w.start
+	case w.hasEnd:
+		end = w.end
+	}
+
+	if end <= start {
+		return 0, 0, fmt.Errorf("%w: end %g is not after start %g", ErrInvalidWindow, end, start)
+	}
+	start, end = max(start, 0), min(end, totalDuration)
+	if start >= end {
+		return 0, 0, fmt.Errorf("%w: start %g is after the end of the audio (%g)", ErrInvalidWindow, start, totalDuration)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,103 @@
+package gowaveform
+
+import (
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestWindowResolve(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []Option
+		start, end float64
+	}{
+		{"whole file", nil, 0, 10},
+		{"start and end", []Option{OptionSetStart(2), OptionSetEnd(4)}, 2, 4},
+		{"start alone", []Option{OptionSetStart(6)}, 6, 10},
+		{"end alone", []Option{OptionSetEnd(3)}, 0, 3},
+		{"end of 0 is the end of the audio", []Option{OptionSetStart(6), OptionSetEnd(0)}, 6, 10},
+		{"zoom alone", []Option{OptionSetZoom(2)}, 4, 6},
+		{"zoom from start", []Option{OptionSetStart(1), OptionSetZoom(2)}, 1, 3},
+		{"zoom before start", []Option{OptionSetZoom(2), OptionSetStart(1)}, 1, 3},
+		{"zoom up to end", []Option{OptionSetEnd(8), OptionSetZoom(2)}, 6, 8},
+		{"zoom around center", []Option{OptionSetCenter(3), OptionSetZoom(2)}, 2, 4},
+		{"center and start", []Option{OptionSetCenter(3), OptionSetStart(1)}, 1, 5},
+		{"center and end", []Option{OptionSetEnd(5), OptionSetCenter(4)}, 3, 5},
+		{"cut off at the end", []Option{OptionSetStart(9), OptionSetZoom(5)}, 9, 10},
+		{"cut off at the beginning", []Option{OptionSetCenter(0.5), OptionSetZoom(2)}, 0, 1.5},
+		{"zoom longer than the file", []Option{OptionSetZoom(20)}, 0, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultPlotConfig()
+			for _, opt := range tt.opts {
+				opt(&config)
+			}
+			start, end, err := config.window.resolve(10)
+			if err != nil {
+				t.Fatalf("resolve failed: %v", err)
+			}
+			if math.Abs(start-tt.start) > 1e-9 || math.Abs(end-tt.end) > 1e-9 {
+				t.Errorf("Expected %g to %g, got %g to %g", tt.start, tt.end, start, end)
+			}
+		})
+	}
+}
+
+func TestWindowResolveErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+	}{
+		{"over-constrained", []Option{OptionSetStart(1), OptionSetEnd(3), OptionSetZoom(2)}},
+		{"negative start", []Option{OptionSetStart(-1)}},
+		{"negative end", []Option{OptionSetEnd(-2)}},
+		{"zero zoom", []Option{OptionSetZoom(0)}},
+		{"end before start", []Option{OptionSetStart(5), OptionSetEnd(4)}},
+		{"start after the audio", []Option{OptionSetStart(12)}},
+		{"center alone", []Option{OptionSetCenter(5)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := defaultPlotConfig()
+			for _, opt := range tt.opts {
+				opt(&config)
+			}
+			if _, _, err := config.window.resolve(10); !errors.Is(err, ErrInvalidWindow) {
+				t.Errorf("Expected ErrInvalidWindow, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSavePlotWindow(t *testing.T) {
+	tmpWav := "/tmp/test_plot_window.wav"
+	tmpPlot := "/tmp/test_plot_window.png"
+	defer os.Remove(tmpWav)
+	defer os.Remove(tmpPlot)
+
+	createTestWAV(t, tmpWav, 44100, 2.0)
+
+	waveform, err := LoadWaveform(tmpWav)
+	if err != nil {
+		t.Fatalf("LoadWaveform failed: %v", err)
+	}
+
+	// The zoom duration used to be stored as a negative end, which a later end replaced
+	img, err := RenderPlot(waveform, OptionSetZoom(0.5), OptionSetEnd(1.5))
+	if err != nil {
+		t.Fatalf("RenderPlot failed: %v", err)
+	}
+	if img.Start != 1 || img.End != 1.5 {
+		t.Errorf("Expected the view from 1s to 1.5s, got %g to %g", img.Start, img.End)
+	}
+
+	err = SavePlot(waveform, tmpPlot, OptionSetStart(3))
+	if !errors.Is(err, ErrInvalidWindow) {
+		t.Errorf("Expected ErrInvalidWindow for a start after the audio, got %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package gowaveform
+
+// ZoomIn narrows the view from start to end seconds by factor (e.g. 1.25 shows 80% of
+// the current duration), keeping its center. The result is clamped like ZoomAt.
+func ZoomIn(start, end, totalDuration, factor float64) (newStart, newEnd float64) {
+	return ZoomAt(start, end, totalDuration, factor, (start+end)/2)
+}
+
+// ZoomOut widens the view from start to end seconds by factor (e.g. 1.25 shows 125% of
+// the current duration), keeping its center. The result is clamped like ZoomAt.
+func ZoomOut(start, end, totalDuration, factor float64) (newStart, newEnd float64) {
+	if factor <= 0 {
+		return clampView(start, end, totalDuration)
+	}
+	return ZoomAt(start, end, totalDuration, 1/factor, (start+end)/2)
+}
+
+// ZoomAt divides the duration of the view from start to end seconds by factor and
+// centers the result on center; a factor of 1 only recenters. The view never grows
+// beyond totalDuration and is shifted, keeping its duration, so it lies within 0 to
+// totalDuration. A factor that is not positive leaves the duration unchanged.
+func ZoomAt(start, end, totalDuration, factor, center float64) (newStart, newEnd float64) {
+	newStart, newEnd = zoomRange(start, end, factor, center)
+	return clampView(newStart, newEnd, totalDuration)
+}
+
+// Pan moves the view from start to end seconds by factor times its duration, left for
+// negative factors and right for positive ones (e.g. 0.05 moves 5% of the view to the
+// right). The duration is kept; a view that would run past either end of the file
+// stops there.
+func Pan(start, end, totalDuration, factor float64) (newStart, newEnd float64) {
+	newStart, newEnd = panRange(start, end, factor)
+	return clampView(newStart, newEnd, totalDuration)
+}
+
+// zoomRange divides the duration of a view by factor and centers it on center,
+// without clamping; a factor that is not positive keeps the duration
+func zoomRange(start, end, factor, center float64) (float64, float64) {
+	duration := end - start
+	if factor > 0 {
+		duration /= factor
+	}
+	return center - duration/2, center + duration/2
+}
+
+// panRange moves a view by factor times its duration, without clamping
+func panRange(start, end, factor float64) (float64, float64) {
+	step := (end - start) * factor
+	return start + step, end + step
+}
+
+// clampView shortens a view to at most totalDuration and shifts it to lie within 0 to
+// totalDuration
+func clampView(start, end, totalDuration float64) (float64, float64) {
+	totalDuration = max(totalDuration, 0)
+	duration := min(max(end-start, 0), totalDuration)
+	start = min(max(start, 0), totalDuration-duration)
+	return start, start + duration
+}
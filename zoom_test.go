@@ -0,0 +1,36 @@
+package gowaveform
+
+import (
+	"math"
+	"testing"
+)
+
+func TestZoomAndPan(t *testing.T) {
+	tests := []struct {
+		name       string
+		got        func() (float64, float64)
+		start, end float64
+	}{
+		{"zoom in keeps center", func() (float64, float64) { return ZoomIn(2, 6, 10, 2) }, 3, 5},
+		{"zoom out keeps center", func() (float64, float64) { return ZoomOut(4, 6, 10, 2) }, 3, 7},
+		{"zoom out stops at file", func() (float64, float64) { return ZoomOut(1, 9, 10, 2) }, 0, 10},
+		{"zoom out shifts from start", func() (float64, float64) { return ZoomOut(0, 2, 10, 2) }, 0, 4},
+		{"zoom out shifts from end", func() (float64, float64) { return ZoomOut(8, 10, 10, 2) }, 6, 10},
+		{"zoom at center", func() (float64, float64) { return ZoomAt(0, 4, 10, 2, 5) }, 4, 6},
+		{"recenter near end", func() (float64, float64) { return ZoomAt(0, 4, 10, 1, 9) }, 6, 10},
+		{"invalid factor", func() (float64, float64) { return ZoomIn(2, 6, 10, 0) }, 2, 6},
+		{"pan right", func() (float64, float64) { return Pan(2, 6, 10, 0.5) }, 4, 8},
+		{"pan left", func() (float64, float64) { return Pan(2, 6, 10, -0.25) }, 1, 5},
+		{"pan past start", func() (float64, float64) { return Pan(1, 5, 10, -1) }, 0, 4},
+		{"pan past end", func() (float64, float64) { return Pan(5, 9, 10, 1) }, 6, 10},
+		{"view longer than file", func() (float64, float64) { return Pan(0, 20, 10, 0) }, 0, 10},
+		{"empty file", func() (float64, float64) { return ZoomOut(0, 1, 0, 2) }, 0, 0},
+	}
+
+	for _, tt := range tests {
+		start, end := tt.got()
+		if math.Abs(start-tt.start) > 1e-9 || math.Abs(end-tt.end) > 1e-9 {
+			t.Errorf("%s: expected %.2f to %.2f, got %.2f to %.2f", tt.name, tt.start, tt.end, start, end)
+		}
+	}
+}